@@ -0,0 +1,101 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Authenticator adds the credentials of the caller to a request
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc is a function that adds credentials to a request
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate calls the function
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// APIKey authenticates requests sent through the API gateway with a Companies House API key
+type APIKey string
+
+// Authenticate sends the key as the username of basic authentication
+func (k APIKey) Authenticate(req *http.Request) error {
+	if k == "" {
+		return fmt.Errorf("api key is empty")
+	}
+	req.SetBasicAuth(string(k), "")
+	return nil
+}
+
+// BearerToken authenticates requests sent through the API gateway with an OAuth2 access token
+type BearerToken string
+
+// Authenticate sends the token in the Authorization header
+func (t BearerToken) Authenticate(req *http.Request) error {
+	if t == "" {
+		return fmt.Errorf("bearer token is empty")
+	}
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+// Identity types of the ERIC-Identity-Type header
+const (
+	IdentityTypeOAuth2 = "oauth2"
+	IdentityTypeKey    = "key"
+)
+
+// EricHeaders authenticates requests sent to the API directly rather than through the API gateway, such as
+// from integration tests, by setting the identity headers that the gateway would set
+type EricHeaders struct {
+	Identity     string
+	IdentityType string
+	// AuthorisedUser is the email, forename and surname of an oauth2 user, in the form
+	// "demo@ch.gov.uk;forename=Demo;surname=User"
+	AuthorisedUser string
+	// AuthorisedRoles is the space separated roles of an oauth2 user, such as the admin penalty lookup role
+	AuthorisedRoles string
+	// AuthorisedKeyRoles is * for an API key with elevated privileges
+	AuthorisedKeyRoles string
+}
+
+// OAuth2User returns the headers of an oauth2 user
+func OAuth2User(id, email, forename, surname string) EricHeaders {
+	return EricHeaders{
+		Identity:       id,
+		IdentityType:   IdentityTypeOAuth2,
+		AuthorisedUser: fmt.Sprintf("%s;forename=%s;surname=%s", email, forename, surname),
+	}
+}
+
+// ElevatedAPIKey returns the headers of an API key with elevated privileges
+func ElevatedAPIKey(key string) EricHeaders {
+	return EricHeaders{
+		Identity:           key,
+		IdentityType:       IdentityTypeKey,
+		AuthorisedKeyRoles: "*",
+	}
+}
+
+// Authenticate sets the headers that are not empty
+func (h EricHeaders) Authenticate(req *http.Request) error {
+	if h.Identity == "" || h.IdentityType == "" {
+		return fmt.Errorf("identity and identity type are required")
+	}
+	headers := map[string]string{
+		"ERIC-Identity":             h.Identity,
+		"ERIC-Identity-Type":        h.IdentityType,
+		"ERIC-Authorised-User":      h.AuthorisedUser,
+		"ERIC-Authorised-Roles":     h.AuthorisedRoles,
+		"ERIC-Authorised-Key-Roles": h.AuthorisedKeyRoles,
+	}
+	for name, value := range headers {
+		if value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	return nil
+}
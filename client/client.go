@@ -0,0 +1,282 @@
+// Package client is a typed Go client for the penalty payment API. It shares the request and response models
+// of penalty-payment-api-core, so callers do not need to build the HTTP requests themselves.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+)
+
+const (
+	// DefaultTimeout is the time allowed for each attempt of a request
+	DefaultTimeout = 30 * time.Second
+	// DefaultMaxAttempts is the number of times an idempotent request is sent before the error is returned
+	DefaultMaxAttempts = 3
+	// DefaultRetryDelay is the delay before the first retry, the delay doubles for each retry after it
+	DefaultRetryDelay = 250 * time.Millisecond
+	// DefaultMaxRetryDelay is the longest delay between retries. A Retry-After longer than this is not waited for.
+	DefaultMaxRetryDelay = 10 * time.Second
+
+	userAgent = "penalty-payment-api-client"
+)
+
+// Client calls the penalty payment API. Requests that do not change anything are retried when the API or
+// the network fails, requests that do are sent once.
+type Client struct {
+	BaseURL       string
+	HTTPClient    *http.Client
+	Authenticator Authenticator
+	// AcceptLanguage is sent as the Accept-Language of every request, reasons and descriptions are in
+	// English if it is empty
+	AcceptLanguage string
+	MaxAttempts    uint
+	RetryDelay     time.Duration
+	MaxRetryDelay  time.Duration
+}
+
+// NewClient returns a Client for the API at the base URL that authenticates every request with the
+// authenticator, which can be nil if the API is called without authentication
+func NewClient(baseURL string, authenticator Authenticator) *Client {
+	return &Client{
+		BaseURL:       strings.TrimSuffix(baseURL, "/"),
+		HTTPClient:    &http.Client{Timeout: DefaultTimeout},
+		Authenticator: authenticator,
+		MaxAttempts:   DefaultMaxAttempts,
+		RetryDelay:    DefaultRetryDelay,
+		MaxRetryDelay: DefaultMaxRetryDelay,
+	}
+}
+
+// APIError is returned when the API responds with an error status. Code is the stable error code of the
+// problem, such as PENALTY_ALREADY_PAID, and is empty if the response was not a problem.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Field      string
+	RequestID  string
+	// RetryAfter is how long the API asked the client to wait before trying again
+	RetryAfter time.Duration
+
+	body []byte
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("penalty payment api responded with status %d", e.StatusCode)
+	if e.Code != "" {
+		message += " " + e.Code
+	}
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	return message
+}
+
+// request is a call to the API, path is relative to the base URL
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// noRetry is set if an error status is an expected answer, such as the health checks
+	noRetry bool
+	// idempotencyKey is sent in the Idempotency-Key header, which makes a POST safe to retry
+	idempotencyKey string
+}
+
+// get sends a GET request and decodes the json response into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	body, err := c.send(ctx, request{method: http.MethodGet, path: path, query: query})
+	if err != nil {
+		return err
+	}
+	return decode(body, out)
+}
+
+// send sends the request and returns the body of the response, retrying the request if it is idempotent
+// and it failed for a reason that may not last
+func (c *Client) send(ctx context.Context, req request) ([]byte, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request body: %v", err)
+		}
+	}
+
+	attempts := c.MaxAttempts
+	if attempts == 0 || req.noRetry || (!isIdempotent(req.method) && req.idempotencyKey == "") {
+		attempts = 1
+	}
+
+	var respBody []byte
+	err := retry.Do(
+		func() error {
+			var err error
+			respBody, err = c.sendOnce(ctx, req, body)
+			return err
+		},
+		retry.Context(ctx),
+		retry.Attempts(attempts),
+		retry.Delay(c.RetryDelay),
+		retry.MaxDelay(c.MaxRetryDelay),
+		retry.DelayType(retryDelay),
+		retry.RetryIf(func(err error) bool {
+			return c.isRetryable(ctx, err)
+		}),
+		retry.LastErrorOnly(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return respBody, nil
+}
+
+func (c *Client) sendOnce(ctx context.Context, req request, body []byte) ([]byte, error) {
+	u := c.BaseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	httpReq.Header.Set("Accept", utils.ProblemContentType+", application/json")
+	httpReq.Header.Set("User-Agent", userAgent)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.idempotencyKey)
+	}
+	if c.AcceptLanguage != "" {
+		httpReq.Header.Set("Accept-Language", c.AcceptLanguage)
+	}
+	if c.Authenticator != nil {
+		if err = c.Authenticator.Authenticate(httpReq); err != nil {
+			return nil, retry.Unrecoverable(fmt.Errorf("error authenticating request: %v", err))
+		}
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %v", err)
+	}
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		return nil, newAPIError(httpResp, respBody)
+	}
+	return respBody, nil
+}
+
+// newAPIError reads the problem, or legacy message, from an error response
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		body:       body,
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case utils.ProblemContentType:
+		var problem utils.Problem
+		if err := json.Unmarshal(body, &problem); err == nil {
+			apiErr.Code = problem.Code
+			apiErr.Message = problem.Detail
+			apiErr.Field = problem.Field
+			apiErr.RequestID = problem.RequestID
+		}
+	case "application/json":
+		var message struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &message); err == nil && message.Message != "" {
+			apiErr.Message = message.Message
+		}
+	}
+	return apiErr
+}
+
+func decode(body []byte, out interface{}) error {
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error decoding response body: %v", err)
+	}
+	return nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryable returns true for network errors and for the statuses that mean the API may be able to
+// handle the request later, unless the API asked for a longer wait than the maximum retry delay
+func (c *Client) isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr)
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return c.MaxRetryDelay <= 0 || apiErr.RetryAfter <= c.MaxRetryDelay
+	}
+	return false
+}
+
+// retryDelay waits for as long as the API asked for, or backs off exponentially if it did not
+func retryDelay(n uint, err error, config *retry.Config) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	return retry.BackOffDelay(n, err, config)
+}
+
+// pathSegments escapes each segment and joins them into a path
+func pathSegments(segments ...string) string {
+	var path strings.Builder
+	for _, segment := range segments {
+		path.WriteString("/")
+		path.WriteString(url.PathEscape(segment))
+	}
+	return path.String()
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestClient(handler http.HandlerFunc, authenticator Authenticator) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	c := NewClient(server.URL, authenticator)
+	c.HTTPClient = server.Client()
+	c.RetryDelay = time.Millisecond
+	c.MaxRetryDelay = 10 * time.Millisecond
+	return c, server.Close
+}
+
+func TestUnitClientRetries(t *testing.T) {
+	Convey("Given an API that is unavailable for the first request", t, func() {
+		var requests int
+		status := http.StatusServiceUnavailable
+		c, closeServer := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.WriteHeader(status)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"reference_type":"LATE_FILING"}]`))
+		}, nil)
+		defer closeServer()
+
+		Convey("Then a GET is retried", func() {
+			penaltyRefTypes, err := c.GetPenaltyReferenceTypes(context.Background())
+
+			So(err, ShouldBeNil)
+			So(requests, ShouldEqual, 2)
+			So(penaltyRefTypes, ShouldHaveLength, 1)
+			So(penaltyRefTypes[0].ReferenceType, ShouldEqual, "LATE_FILING")
+		})
+
+		Convey("Then a POST is not retried", func() {
+			_, err := c.LookupPenalty(context.Background(), PenaltyLookupRequest{PenaltyRef: "A1234567"})
+
+			var apiErr *APIError
+			So(errors.As(err, &apiErr), ShouldBeTrue)
+			So(apiErr.StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+			So(requests, ShouldEqual, 1)
+		})
+
+		Convey("Then a client error is not retried", func() {
+			status = http.StatusNotFound
+			_, err := c.GetPenaltyReferenceTypes(context.Background())
+
+			So(err, ShouldNotBeNil)
+			So(requests, ShouldEqual, 1)
+		})
+	})
+
+	Convey("Given an API that is unavailable for the first create payable request", t, func() {
+		var idempotencyKeys []string
+		c, closeServer := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKeys = append(idempotencyKeys, r.Header.Get("Idempotency-Key"))
+			if len(idempotencyKeys) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			utils.WriteJSONWithStatus(w, r, map[string]string{"payable_ref": "PR_123"}, http.StatusCreated)
+		}, nil)
+		defer closeServer()
+
+		Convey("Then a POST with an idempotency key is retried with the same key", func() {
+			created, err := c.CreatePayableWithIdempotencyKey(context.Background(), "12345678", "key-1", nil)
+
+			So(err, ShouldBeNil)
+			So(created.PayableRef, ShouldEqual, "PR_123")
+			So(idempotencyKeys, ShouldResemble, []string{"key-1", "key-1"})
+		})
+	})
+
+	Convey("Given an API that asks the client to wait longer than the maximum retry delay", t, func() {
+		var requests int
+		c, closeServer := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}, nil)
+		defer closeServer()
+
+		Convey("Then the request is not retried", func() {
+			_, err := c.GetPenaltyReferenceTypes(context.Background())
+
+			var apiErr *APIError
+			So(errors.As(err, &apiErr), ShouldBeTrue)
+			So(apiErr.RetryAfter, ShouldEqual, time.Minute)
+			So(requests, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestUnitClientErrors(t *testing.T) {
+	Convey("A problem response is returned as an APIError with its code and field", t, func() {
+		c, closeServer := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			utils.WriteProblem(w, r, utils.NewProblem(http.StatusBadRequest, utils.ErrorCodePenaltyAlreadyPaid,
+				"the penalty has already been paid").WithField("transactions[0]"))
+		}, nil)
+		defer closeServer()
+
+		_, err := c.CreatePayable(context.Background(), "12345678", nil)
+
+		var apiErr *APIError
+		So(errors.As(err, &apiErr), ShouldBeTrue)
+		So(apiErr.StatusCode, ShouldEqual, http.StatusBadRequest)
+		So(apiErr.Code, ShouldEqual, utils.ErrorCodePenaltyAlreadyPaid)
+		So(apiErr.Field, ShouldEqual, "transactions[0]")
+		So(apiErr.Error(), ShouldEqual, "penalty payment api responded with status 400 PENALTY_ALREADY_PAID: the penalty has already been paid")
+	})
+
+	Convey("A message response is returned as an APIError with the message", t, func() {
+		c, closeServer := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			utils.WriteJSONWithStatus(w, r, map[string]string{"message": "company not found"}, http.StatusNotFound)
+		}, nil)
+		defer closeServer()
+
+		_, err := c.GetPenalties(context.Background(), "12345678", utils.LateFilingPenaltyRefType, nil)
+
+		var apiErr *APIError
+		So(errors.As(err, &apiErr), ShouldBeTrue)
+		So(apiErr.Code, ShouldBeEmpty)
+		So(apiErr.Message, ShouldEqual, "company not found")
+	})
+}
+
+func TestUnitClientRequests(t *testing.T) {
+	Convey("Given a client that records the request", t, func() {
+		var received *http.Request
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+		}
+
+		Convey("Then the penalties query is sent as query parameters", func() {
+			c, closeServer := newTestClient(handler, nil)
+			defer closeServer()
+			isPaid := false
+
+			_, err := c.GetPenalties(context.Background(), "OE123456", utils.SanctionsRoePenaltyRefType, &PenaltiesQuery{
+				PayableStatus: []string{"OPEN", "CLOSED"},
+				IsPaid:        &isPaid,
+				Sort:          "-due_date",
+				ItemsPerPage:  10,
+			})
+
+			So(err, ShouldBeNil)
+			So(received.URL.Path, ShouldEqual, "/company/OE123456/penalties/SANCTIONS_ROE")
+			So(received.URL.RawQuery, ShouldEqual, "is_paid=false&items_per_page=10&payable_status=OPEN%2CCLOSED&sort=-due_date")
+		})
+
+		Convey("Then the payable search is sent as query parameters", func() {
+			c, closeServer := newTestClient(handler, nil)
+			defer closeServer()
+
+			_, err := c.SearchPayables(context.Background(), &PayableSearchQuery{
+				Email:         "demo@ch.gov.uk",
+				PenaltyRef:    "A1234567",
+				PayablesQuery: PayablesQuery{Status: []string{"paid", "pending"}, CreatedFrom: "2025-03-01", ItemsPerPage: 10},
+			})
+
+			So(err, ShouldBeNil)
+			So(received.URL.Path, ShouldEqual, "/penalty-payment-api/admin/payable-resources")
+			So(received.URL.RawQuery, ShouldEqual,
+				"created_from=2025-03-01&email=demo%40ch.gov.uk&items_per_page=10&penalty_ref=A1234567&status=paid%2Cpending")
+		})
+
+		Convey("Then an API key is sent with basic authentication", func() {
+			c, closeServer := newTestClient(handler, APIKey("my-key"))
+			defer closeServer()
+
+			_, err := c.GetPayable(context.Background(), "12345678", "PR_123")
+
+			So(err, ShouldBeNil)
+			key, _, ok := received.BasicAuth()
+			So(ok, ShouldBeTrue)
+			So(key, ShouldEqual, "my-key")
+		})
+
+		Convey("Then a bearer token is sent in the Authorization header", func() {
+			c, closeServer := newTestClient(handler, BearerToken("token"))
+			defer closeServer()
+
+			_, err := c.GetPayable(context.Background(), "12345678", "PR_123")
+
+			So(err, ShouldBeNil)
+			So(received.Header.Get("Authorization"), ShouldEqual, "Bearer token")
+		})
+
+		Convey("Then the identity headers of an oauth2 user are sent", func() {
+			c, closeServer := newTestClient(handler, OAuth2User("Y2VkZWVlMzhlZWFjY2M4MzQ3MT", "demo@ch.gov.uk", "Demo", "User"))
+			defer closeServer()
+
+			_, err := c.GetPayable(context.Background(), "12345678", "PR_123")
+
+			So(err, ShouldBeNil)
+			So(received.Header.Get("ERIC-Identity"), ShouldEqual, "Y2VkZWVlMzhlZWFjY2M4MzQ3MT")
+			So(received.Header.Get("ERIC-Identity-Type"), ShouldEqual, IdentityTypeOAuth2)
+			So(received.Header.Get("ERIC-Authorised-User"), ShouldEqual, "demo@ch.gov.uk;forename=Demo;surname=User")
+			So(received.Header.Get("ERIC-Authorised-Key-Roles"), ShouldBeEmpty)
+		})
+
+		Convey("Then a request is not sent if it cannot be authenticated", func() {
+			c, closeServer := newTestClient(handler, APIKey(""))
+			defer closeServer()
+
+			_, err := c.GetPayable(context.Background(), "12345678", "PR_123")
+
+			So(err, ShouldNotBeNil)
+			So(received, ShouldBeNil)
+		})
+	})
+}
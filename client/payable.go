@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+)
+
+// createPayableRequest is the body of a create payable request, the customer and the user creating the
+// payable resource are taken from the path and the identity of the request
+type createPayableRequest struct {
+	Transactions []models.TransactionItem `json:"transactions"`
+}
+
+// CreatePayable creates a payable resource for the transactions of the customer. It requires an oauth2
+// user, who is recorded as the creator of the payable resource. It is not retried, use
+// CreatePayableWithIdempotencyKey for a request that can be.
+func (c *Client) CreatePayable(ctx context.Context, customerCode string, transactions []models.TransactionItem) (*models.CreatedPayableResource, error) {
+	return c.CreatePayableWithIdempotencyKey(ctx, customerCode, "", transactions)
+}
+
+// CreatePayableWithIdempotencyKey creates a payable resource like CreatePayable, sending the Idempotency-Key
+// so that the request is retried without creating another payable resource. Sending the same key again
+// returns the payable resource that was created, the key must not be reused for other transactions.
+func (c *Client) CreatePayableWithIdempotencyKey(ctx context.Context, customerCode, idempotencyKey string,
+	transactions []models.TransactionItem) (*models.CreatedPayableResource, error) {
+	body, err := c.send(ctx, request{
+		method:         http.MethodPost,
+		path:           pathSegments("company", customerCode, "penalties", "payable"),
+		body:           createPayableRequest{Transactions: transactions},
+		idempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var created models.CreatedPayableResource
+	if err = decode(body, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetPayable returns the payable resource of the customer
+func (c *Client) GetPayable(ctx context.Context, customerCode, payableRef string) (*models.PayableResource, error) {
+	var payableResource models.PayableResource
+	err := c.get(ctx, pathSegments("company", customerCode, "penalties", "payable", payableRef), nil, &payableResource)
+	if err != nil {
+		return nil, err
+	}
+	return &payableResource, nil
+}
+
+// PayablesQuery filters and pages the payable resources of a customer, fields that are not set are not sent
+type PayablesQuery struct {
+	// Status is pending, paid, expired or cancelled
+	Status []string
+	// CreatedFrom and CreatedTo are dates in the format YYYY-MM-DD, both are inclusive
+	CreatedFrom  string
+	CreatedTo    string
+	StartIndex   int
+	ItemsPerPage int
+}
+
+func (q *PayablesQuery) values() url.Values {
+	values := url.Values{}
+	if q == nil {
+		return values
+	}
+	if len(q.Status) > 0 {
+		values.Set("status", strings.Join(q.Status, ","))
+	}
+	if q.CreatedFrom != "" {
+		values.Set("created_from", q.CreatedFrom)
+	}
+	if q.CreatedTo != "" {
+		values.Set("created_to", q.CreatedTo)
+	}
+	if q.StartIndex > 0 {
+		values.Set("start_index", strconv.Itoa(q.StartIndex))
+	}
+	if q.ItemsPerPage > 0 {
+		values.Set("items_per_page", strconv.Itoa(q.ItemsPerPage))
+	}
+	return values
+}
+
+// ListPayables returns the payable resources of the customer, newest first, the query can be nil. An oauth2
+// user only gets the payable resources they created unless they have the admin penalty lookup role.
+func (c *Client) ListPayables(ctx context.Context, customerCode string, query *PayablesQuery) (*PayableResourceList, error) {
+	var payables PayableResourceList
+	err := c.get(ctx, pathSegments("company", customerCode, "penalties", "payable"), query.values(), &payables)
+	if err != nil {
+		return nil, err
+	}
+	return &payables, nil
+}
+
+// PayableSearchQuery searches the payable resources of every customer, at least one of Email, PaymentReference
+// or PenaltyRef is required. The payable resources found can be filtered and paged like ListPayables.
+type PayableSearchQuery struct {
+	Email            string
+	PaymentReference string
+	PenaltyRef       string
+	CustomerCode     string
+	PayablesQuery
+}
+
+func (q *PayableSearchQuery) values() url.Values {
+	if q == nil {
+		return url.Values{}
+	}
+	values := q.PayablesQuery.values()
+	for name, value := range map[string]string{
+		"email":             q.Email,
+		"payment_reference": q.PaymentReference,
+		"penalty_ref":       q.PenaltyRef,
+		"customer_code":     q.CustomerCode,
+	} {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	return values
+}
+
+// SearchPayables searches the payable resources of every customer, newest first. It requires an API key with
+// elevated privileges.
+func (c *Client) SearchPayables(ctx context.Context, query *PayableSearchQuery) (*PayableResourceList, error) {
+	var payables PayableResourceList
+	err := c.get(ctx, pathSegments("penalty-payment-api", "admin", "payable-resources"), query.values(), &payables)
+	if err != nil {
+		return nil, err
+	}
+	return &payables, nil
+}
+
+// CancelPayable cancels the pending payable resource of the customer so that it can no longer be paid. It
+// requires the user who created it or an API key with elevated privileges.
+func (c *Client) CancelPayable(ctx context.Context, customerCode, payableRef string) error {
+	_, err := c.send(ctx, request{
+		method: http.MethodDelete,
+		path:   pathSegments("company", customerCode, "penalties", "payable", payableRef),
+	})
+	return err
+}
+
+// GetPaymentDetails returns the payment details of the payable resource, as read by the payments API
+func (c *Client) GetPaymentDetails(ctx context.Context, customerCode, payableRef string) (*models.PaymentDetails, error) {
+	var paymentDetails models.PaymentDetails
+	err := c.get(ctx, pathSegments("company", customerCode, "penalties", "payable", payableRef, "payment"), nil, &paymentDetails)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentDetails, nil
+}
+
+// GetReceipt returns the receipt of the paid payable resource, format is html or pdf
+func (c *Client) GetReceipt(ctx context.Context, customerCode, payableRef, format string) ([]byte, error) {
+	return c.send(ctx, request{
+		method: http.MethodGet,
+		path:   pathSegments("company", customerCode, "penalties", "payable", payableRef, "receipt"),
+		query:  url.Values{"format": {format}},
+	})
+}
+
+// MarkAsPaid marks the payable resource as paid by the payment session with the reference. It requires an
+// API key with elevated privileges and is not retried, as the payment is also recorded in the finance system.
+func (c *Client) MarkAsPaid(ctx context.Context, customerCode, payableRef, paymentReference string) error {
+	_, err := c.send(ctx, request{
+		method: http.MethodPatch,
+		path:   pathSegments("company", customerCode, "penalties", "payable", payableRef, "payment"),
+		body:   models.PatchResourceRequest{Reference: paymentReference},
+	})
+	return err
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+)
+
+// PenaltiesQuery filters, sorts and pages the penalties of a customer, fields that are not set are not sent
+type PenaltiesQuery struct {
+	// Type is penalty or other
+	Type           string
+	PayableStatus  []string
+	IsPaid         *bool
+	MadeUpDateFrom string
+	MadeUpDateTo   string
+	// Sort is due_date or amount, prefixed with - for descending order. The amount is the outstanding amount.
+	Sort         string
+	StartIndex   int
+	ItemsPerPage int
+}
+
+func (q *PenaltiesQuery) values() url.Values {
+	values := url.Values{}
+	if q == nil {
+		return values
+	}
+	setIfNotEmpty := func(name, value string) {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+	setIfNotEmpty("type", q.Type)
+	setIfNotEmpty("payable_status", strings.Join(q.PayableStatus, ","))
+	if q.IsPaid != nil {
+		values.Set("is_paid", strconv.FormatBool(*q.IsPaid))
+	}
+	setIfNotEmpty("made_up_date_from", q.MadeUpDateFrom)
+	setIfNotEmpty("made_up_date_to", q.MadeUpDateTo)
+	setIfNotEmpty("sort", q.Sort)
+	if q.StartIndex > 0 {
+		values.Set("start_index", strconv.Itoa(q.StartIndex))
+	}
+	if q.ItemsPerPage > 0 {
+		values.Set("items_per_page", strconv.Itoa(q.ItemsPerPage))
+	}
+	return values
+}
+
+// GetPenalties returns the penalties of the penalty reference type for the customer, the query can be nil
+func (c *Client) GetPenalties(ctx context.Context, customerCode, penaltyRefType string, query *PenaltiesQuery) (*models.TransactionListResponse, error) {
+	var penalties models.TransactionListResponse
+	err := c.get(ctx, pathSegments("company", customerCode, "penalties", penaltyRefType), query.values(), &penalties)
+	if err != nil {
+		return nil, err
+	}
+	return &penalties, nil
+}
+
+// GetLateFilingPenalties returns the late filing penalties of the customer
+//
+// Deprecated: use GetPenalties with the LATE_FILING penalty reference type
+func (c *Client) GetLateFilingPenalties(ctx context.Context, customerCode string) (*models.TransactionListResponse, error) {
+	var penalties models.TransactionListResponse
+	err := c.get(ctx, pathSegments("company", customerCode, "penalties", "late-filing"), nil, &penalties)
+	if err != nil {
+		return nil, err
+	}
+	return &penalties, nil
+}
+
+// GetPenaltyStatement returns the penalties of every penalty reference type for the customer
+func (c *Client) GetPenaltyStatement(ctx context.Context, customerCode string) (*PenaltyStatement, error) {
+	var statement PenaltyStatement
+	err := c.get(ctx, pathSegments("company", customerCode, "penalties", "statement"), nil, &statement)
+	if err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// GetPenaltyPayability explains whether the penalty can be paid. If amount is zero the outstanding amount
+// of the penalty is assumed.
+func (c *Client) GetPenaltyPayability(ctx context.Context, customerCode, penaltyRefType, penaltyRef string,
+	amount float64) (*PenaltyPayability, error) {
+	query := url.Values{}
+	if amount != 0 {
+		query.Set("amount", strconv.FormatFloat(amount, 'f', -1, 64))
+	}
+
+	var payability PenaltyPayability
+	err := c.get(ctx, pathSegments("company", customerCode, "penalties", penaltyRefType, penaltyRef, "payability"), query, &payability)
+	if err != nil {
+		return nil, err
+	}
+	return &payability, nil
+}
+
+// ExportPenalties returns the penalty ledger of the customer
+func (c *Client) ExportPenalties(ctx context.Context, customerCode, penaltyRefType string) (*PenaltyExport, error) {
+	var export PenaltyExport
+	err := c.get(ctx, pathSegments("company", customerCode, "penalties", penaltyRefType, "export"),
+		url.Values{"format": {"json"}}, &export)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ExportPenaltiesCSV returns the penalty ledger of the customer as a CSV file
+func (c *Client) ExportPenaltiesCSV(ctx context.Context, customerCode, penaltyRefType string) ([]byte, error) {
+	body, err := c.send(ctx, request{
+		method: http.MethodGet,
+		path:   pathSegments("company", customerCode, "penalties", penaltyRefType, "export"),
+		query:  url.Values{"format": {"csv"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// LookupPenalty finds the customer that a penalty belongs to. Lookups are rate limited so they are not
+// retried, a rate limited lookup returns an APIError with the time to wait in RetryAfter.
+func (c *Client) LookupPenalty(ctx context.Context, lookup PenaltyLookupRequest) (*PenaltyLookup, error) {
+	body, err := c.send(ctx, request{
+		method: http.MethodPost,
+		path:   "/penalty-payment-api/penalty-lookup",
+		body:   lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var penaltyLookup PenaltyLookup
+	if err = decode(body, &penaltyLookup); err != nil {
+		return nil, err
+	}
+	return &penaltyLookup, nil
+}
+
+// BulkGetPenalties returns the penalties of up to 100 customers. It requires an API key with elevated
+// privileges. The penalty reference type is LATE_FILING if it is empty.
+func (c *Client) BulkGetPenalties(ctx context.Context, customerCodes []string, penaltyRefType string) (*BulkPenalties, error) {
+	body, err := c.send(ctx, request{
+		method: http.MethodPost,
+		path:   "/penalty-payment-api/admin/penalties",
+		body: struct {
+			CustomerCodes        []string `json:"customer_codes"`
+			PenaltyReferenceType string   `json:"penalty_reference_type,omitempty"`
+		}{customerCodes, penaltyRefType},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var bulkPenalties BulkPenalties
+	if err = decode(body, &bulkPenalties); err != nil {
+		return nil, err
+	}
+	return &bulkPenalties, nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Healthcheck returns the health of the API
+func (c *Client) Healthcheck(ctx context.Context) (*Healthcheck, error) {
+	body, err := c.send(ctx, request{
+		method:  http.MethodGet,
+		path:    "/penalty-payment-api/healthcheck",
+		noRetry: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var healthcheck Healthcheck
+	if err = decode(body, &healthcheck); err != nil {
+		return nil, err
+	}
+	return &healthcheck, nil
+}
+
+// FinanceHealthcheck returns the health of the finance system. If the finance system is in a maintenance
+// window the health is returned with an APIError with status 503 Service Unavailable.
+func (c *Client) FinanceHealthcheck(ctx context.Context) (*FinanceHealthcheck, error) {
+	var healthcheck FinanceHealthcheck
+	body, err := c.send(ctx, request{
+		method:  http.MethodGet,
+		path:    "/penalty-payment-api/healthcheck/finance-system",
+		noRetry: true,
+	})
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable {
+		if decodeErr := json.Unmarshal(apiErr.body, &healthcheck); decodeErr != nil {
+			return nil, err
+		}
+		return &healthcheck, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = decode(body, &healthcheck); err != nil {
+		return nil, err
+	}
+	return &healthcheck, nil
+}
+
+// GetPenaltyReferenceTypes returns the penalty reference types the API serves
+func (c *Client) GetPenaltyReferenceTypes(ctx context.Context) ([]PenaltyReferenceType, error) {
+	var penaltyRefTypes []PenaltyReferenceType
+	if err := c.get(ctx, "/penalty-payment-api/penalty-reference-types", nil, &penaltyRefTypes); err != nil {
+		return nil, err
+	}
+	return penaltyRefTypes, nil
+}
+
+// GetMaintenanceWindows returns the maintenance windows that have not finished. It requires an API key
+// with elevated privileges.
+func (c *Client) GetMaintenanceWindows(ctx context.Context) ([]ManagedMaintenanceWindow, error) {
+	var maintenanceWindows []ManagedMaintenanceWindow
+	if err := c.get(ctx, "/penalty-payment-api/admin/maintenance-windows", nil, &maintenanceWindows); err != nil {
+		return nil, err
+	}
+	return maintenanceWindows, nil
+}
+
+// CreateMaintenanceWindow adds a maintenance window. It requires an API key with elevated privileges.
+func (c *Client) CreateMaintenanceWindow(ctx context.Context, name string, start, end time.Time) (*ManagedMaintenanceWindow, error) {
+	body, err := c.send(ctx, request{
+		method: http.MethodPost,
+		path:   "/penalty-payment-api/admin/maintenance-windows",
+		body:   MaintenanceWindow{Name: name, StartTime: start, EndTime: end},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var maintenanceWindow ManagedMaintenanceWindow
+	if err = decode(body, &maintenanceWindow); err != nil {
+		return nil, err
+	}
+	return &maintenanceWindow, nil
+}
+
+// CancelMaintenanceWindow cancels a maintenance window. It requires an API key with elevated privileges.
+func (c *Client) CancelMaintenanceWindow(ctx context.Context, id string) error {
+	_, err := c.send(ctx, request{
+		method: http.MethodDelete,
+		path:   pathSegments("penalty-payment-api", "admin", "maintenance-windows", id),
+	})
+	return err
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+)
+
+// The responses that are not in penalty-payment-api-core. They match the responses of the handlers and the
+// OpenAPI document, which the contract tests check.
+
+// Healthcheck is the health of the API and the version of the penalty config it is serving
+type Healthcheck struct {
+	Message       string              `json:"message"`
+	PenaltyConfig PenaltyConfigStatus `json:"penalty_config"`
+}
+
+// PenaltyConfigStatus is the version of the penalty details and penalty types files being served
+type PenaltyConfigStatus struct {
+	Version  int       `json:"version"`
+	Hash     string    `json:"hash"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// FinanceHealthcheck is the health of the finance system
+type FinanceHealthcheck struct {
+	Message            string             `json:"message"`
+	MaintenanceEndTime *time.Time         `json:"maintenance_end_time,omitempty"`
+	CurrentMaintenance *MaintenanceWindow `json:"current_maintenance,omitempty"`
+	NextMaintenance    *MaintenanceWindow `json:"next_maintenance,omitempty"`
+}
+
+// MaintenanceWindow is a period of finance system maintenance
+type MaintenanceWindow struct {
+	Name      string    `json:"name,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// ManagedMaintenanceWindow is a maintenance window managed through the admin endpoints
+type ManagedMaintenanceWindow struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CreatedBy   string     `json:"created_by"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy string     `json:"cancelled_by,omitempty"`
+}
+
+// PenaltyReferenceType is a type of penalty the API serves and how its penalty references are recognised
+type PenaltyReferenceType struct {
+	ReferenceType       string `json:"reference_type"`
+	CompanyCode         string `json:"company_code"`
+	Description         string `json:"description"`
+	ResourceKind        string `json:"resource_kind"`
+	ReferenceStartsWith string `json:"reference_starts_with"`
+	ReferenceRegex      string `json:"reference_regex"`
+	EnabledFrom         string `json:"enabled_from,omitempty"`
+	EnabledTo           string `json:"enabled_to,omitempty"`
+	PaymentsEnabled     bool   `json:"payments_enabled"`
+	Reason              string `json:"reason,omitempty"`
+}
+
+// PenaltyLookupRequest finds the customer of a penalty, CompanyNumber or Postcode must match the customer
+type PenaltyLookupRequest struct {
+	PenaltyRef    string `json:"penalty_ref"`
+	CompanyNumber string `json:"company_number,omitempty"`
+	Postcode      string `json:"postcode,omitempty"`
+}
+
+// PenaltyLookup is the customer and details of a penalty found by a lookup
+type PenaltyLookup struct {
+	CustomerCode         string                     `json:"customer_code"`
+	PenaltyReferenceType string                     `json:"penalty_reference_type"`
+	Penalty              models.TransactionListItem `json:"penalty"`
+}
+
+// BulkPenalties is the penalties of each customer of a bulk lookup, in the order they were requested
+type BulkPenalties struct {
+	PenaltyReferenceType string                `json:"penalty_reference_type"`
+	Results              []BulkPenaltiesResult `json:"results"`
+}
+
+// BulkPenaltiesResult is the status that GET penalties would have responded with for the customer, and the
+// penalties if it succeeded or the error if it did not
+type BulkPenaltiesResult struct {
+	CustomerCode string                          `json:"customer_code"`
+	Status       int                             `json:"status"`
+	Penalties    *models.TransactionListResponse `json:"penalties,omitempty"`
+	Error        string                          `json:"error,omitempty"`
+}
+
+// PenaltyStatement is the penalties of every penalty reference type with their totals
+type PenaltyStatement struct {
+	Sections         []PenaltyStatementSection `json:"sections"`
+	OutstandingTotal float64                   `json:"outstanding_total"`
+	PayableTotal     float64                   `json:"payable_total"`
+	Complete         bool                      `json:"complete"`
+}
+
+// PenaltyStatementSection is the penalties of a penalty reference type
+type PenaltyStatementSection struct {
+	PenaltyReferenceType string                       `json:"penalty_reference_type"`
+	CompanyCode          string                       `json:"company_code"`
+	Items                []models.TransactionListItem `json:"items"`
+	OutstandingTotal     float64                      `json:"outstanding_total"`
+	PayableTotal         float64                      `json:"payable_total"`
+	Error                string                       `json:"error,omitempty"`
+}
+
+// PenaltyPayability explains why a penalty can or cannot be paid
+type PenaltyPayability struct {
+	PenaltyRef    string               `json:"penalty_ref"`
+	Payable       bool                 `json:"payable"`
+	PayableStatus string               `json:"payable_status"`
+	FailedRules   []FailedRule         `json:"failed_rules"`
+	E5Transaction E5TransactionDetails `json:"e5_transaction"`
+}
+
+// FailedRule is a rule that stops a penalty being paid. An advisory rule is reported but does not stop a
+// payable resource being created for the penalty.
+type FailedRule struct {
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	Advisory bool   `json:"advisory,omitempty"`
+}
+
+// E5TransactionDetails are the fields of the E5 transaction that the payable rules are checked against
+type E5TransactionDetails struct {
+	TransactionType    string  `json:"transaction_type"`
+	TransactionSubType string  `json:"transaction_sub_type"`
+	DunningStatus      string  `json:"dunning_status"`
+	AccountStatus      string  `json:"account_status"`
+	OriginalAmount     float64 `json:"original_amount"`
+	OutstandingAmount  float64 `json:"outstanding_amount"`
+	IsPaid             bool    `json:"is_paid"`
+}
+
+// PenaltyExport is the penalty ledger of a customer
+type PenaltyExport struct {
+	CustomerCode         string              `json:"customer_code"`
+	PenaltyReferenceType string              `json:"penalty_reference_type"`
+	Items                []PenaltyExportItem `json:"items"`
+}
+
+// PenaltyExportItem is a transaction in the penalty ledger with the date it was paid through the service
+type PenaltyExportItem struct {
+	PenaltyRef       string  `json:"penalty_ref"`
+	Type             string  `json:"type"`
+	Reason           string  `json:"reason"`
+	MadeUpDate       string  `json:"made_up_date"`
+	TransactionDate  string  `json:"transaction_date"`
+	DueDate          string  `json:"due_date"`
+	OriginalAmount   float64 `json:"original_amount"`
+	Outstanding      float64 `json:"outstanding"`
+	IsPaid           bool    `json:"is_paid"`
+	IsDCA            bool    `json:"is_dca"`
+	PayableStatus    string  `json:"payable_status"`
+	PaidDate         string  `json:"paid_date,omitempty"`
+	PaymentReference string  `json:"payment_reference,omitempty"`
+}
+
+// PayableResourceList is a page of the payable resources of a customer
+type PayableResourceList struct {
+	ItemsPerPage int                       `json:"items_per_page"`
+	StartIndex   int                       `json:"start_index"`
+	TotalResults int                       `json:"total_results"`
+	Items        []PayableResourceListItem `json:"items"`
+}
+
+// PayableResourceListItem is a payable resource with whether its payment has been processed in E5, which is
+// not_paid, pending, processed or failed
+type PayableResourceListItem struct {
+	models.PayableResource
+	E5ProcessingState string     `json:"e5_processing_state"`
+	E5CommandError    string     `json:"e5_command_error,omitempty"`
+	E5ProcessedAt     *time.Time `json:"e5_processed_at,omitempty"`
+}
@@ -0,0 +1,28 @@
+package dao
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrIdempotencyKeyInUse is returned when an idempotency key cannot be reserved because it has already been
+// used by the same user for the same customer, and has not expired
+var ErrIdempotencyKeyInUse = errors.New("idempotency key in use")
+
+// IdempotencyKeyDao is an Idempotency-Key sent when creating a payable resource, stored in the idempotency keys
+// collection with a hash of the request it was sent with and the payable resource that was created. The
+// payable ref is empty while the request is being handled.
+type IdempotencyKeyDao struct {
+	Key          string    `bson:"key"`
+	CustomerCode string    `bson:"customer_code"`
+	CreatedBy    string    `bson:"created_by"`
+	RequestHash  string    `bson:"request_hash"`
+	PayableRef   string    `bson:"payable_ref"`
+	CreatedAt    time.Time `bson:"created_at"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+}
+
+// IsComplete reports whether the payable resource of the idempotency key has been created
+func (i IdempotencyKeyDao) IsComplete() bool {
+	return i.PayableRef != ""
+}
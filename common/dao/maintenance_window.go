@@ -0,0 +1,29 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrMaintenanceWindowNotFound is returned when there is no maintenance window to cancel with the given id
+var ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+
+// MaintenanceWindowDao is a period of E5 maintenance added at runtime, stored in the maintenance windows
+// collection
+type MaintenanceWindowDao struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Name        string             `bson:"name"`
+	StartTime   time.Time          `bson:"start_time"`
+	EndTime     time.Time          `bson:"end_time"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	CreatedBy   string             `bson:"created_by"`
+	CancelledAt *time.Time         `bson:"cancelled_at,omitempty"`
+	CancelledBy string             `bson:"cancelled_by,omitempty"`
+}
+
+// IsCancelled reports whether the maintenance window has been cancelled
+func (m MaintenanceWindowDao) IsCancelled() bool {
+	return m.CancelledAt != nil
+}
@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/constants"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/e5"
 	"github.com/companieshouse/penalty-payment-api/common/interfaces"
@@ -70,18 +71,34 @@ func (m *MongoCollectionWrapper) InsertOne(ctx context.Context, document interfa
 	return m.collection.InsertOne(ctx, document, opts...)
 }
 
+func (m *MongoCollectionWrapper) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	return m.collection.Find(ctx, filter, opts...)
+}
+
 func (m *MongoCollectionWrapper) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
 	return m.collection.FindOne(ctx, filter, opts...)
 }
 
+func (m *MongoCollectionWrapper) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return m.collection.CountDocuments(ctx, filter, opts...)
+}
+
 func (m *MongoCollectionWrapper) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
 	return m.collection.UpdateOne(ctx, filter, update, opts...)
 }
 
+func (m *MongoCollectionWrapper) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return m.collection.UpdateMany(ctx, filter, update, opts...)
+}
+
 func (m *MongoCollectionWrapper) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
 	return m.collection.DeleteOne(ctx, filter, opts...)
 }
 
+func (m *MongoCollectionWrapper) CreateIndexes(ctx context.Context, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) ([]string, error) {
+	return m.collection.Indexes().CreateMany(ctx, models, opts...)
+}
+
 // MongoPayableResourceService is an implementation of the PayableResourceDaoService interface using
 // MongoDB as the backend driver.
 type MongoPayableResourceService struct {
@@ -189,6 +206,58 @@ func (m *MongoAccountPenaltiesService) GetAccountPenalties(customerCode string,
 	return &resource, nil
 }
 
+// GetAccountPenaltiesByPenaltyRef finds the account penalties of the company code that contain the penalty
+// reference, using the index on the transaction references as a reverse index from penalty to customer
+func (m *MongoAccountPenaltiesService) GetAccountPenaltiesByPenaltyRef(companyCode, penaltyRef, requestId string) (*models.AccountPenaltiesDao, error) {
+	logContext := log.Data{
+		"company_code": companyCode,
+		"penalty_ref":  penaltyRef,
+	}
+
+	var resource models.AccountPenaltiesDao
+
+	collection := m.db.Collection(m.CollectionName)
+	dbResource := collection.FindOne(context.Background(), bson.M{
+		"company_code":               companyCode,
+		"data.transaction_reference": penaltyRef,
+	})
+
+	err := dbResource.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			log.DebugC(requestId, "no document found in account_penalties collection for penalty reference", logContext)
+			return nil, err
+		}
+		log.ErrorC(requestId, err, logContext)
+		return nil, err
+	}
+
+	err = dbResource.Decode(&resource)
+	if err != nil {
+		log.ErrorC(requestId, err, logContext)
+		return nil, err
+	}
+
+	return &resource, nil
+}
+
+// CreateIndexes creates the indexes of the account_penalties collection if they do not already exist
+func (m *MongoAccountPenaltiesService) CreateIndexes(requestId string) error {
+	collection := m.db.Collection(m.CollectionName)
+	_, err := collection.CreateIndexes(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "company_code", Value: 1}, {Key: "data.transaction_reference", Value: 1}},
+			Options: options.Index().SetName("company_code_transaction_reference"),
+		},
+	})
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"collection": m.CollectionName})
+		return err
+	}
+
+	return nil
+}
+
 // UpdateAccountPenaltyAsPaid will update the penalty status of an item in account_penalties database collection
 func (m *MongoAccountPenaltiesService) UpdateAccountPenaltyAsPaid(customerCode string, companyCode string, penaltyRef, requestId string) error {
 	log.InfoC(requestId, "updating penalty as paid in account_penalties collection", log.Data{
@@ -321,14 +390,28 @@ func (m *MongoPayableResourceService) SaveE5Error(customerCode, payableRef, requ
 	return nil
 }
 
-// CreatePayableResource will store the payable request into the database
+// CreatePayableResource will store the payable request into the database. The penalty references of a pending
+// payable resource are stored in the document so that the unique index on them stops a penalty of the customer
+// being in two pending payable resources, even when they are created at the same time.
 func (m *MongoPayableResourceService) CreatePayableResource(dao *models.PayableResourceDao, requestId string) error {
 
 	dao.ID = primitive.NewObjectID()
 
+	record := PayableResourceRecordDao{PayableResourceDao: *dao}
+	if dao.Data.Payment.Status == constants.Pending.String() {
+		for penaltyRef := range dao.Data.Transactions {
+			record.PendingPenaltyRefs = append(record.PendingPenaltyRefs, penaltyRef)
+		}
+	}
+
 	collection := m.db.Collection(m.CollectionName)
-	_, err := collection.InsertOne(context.Background(), dao)
+	_, err := collection.InsertOne(context.Background(), record)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			log.InfoC(requestId, "penalty in a pending payable resource", log.Data{"customer_code": dao.CustomerCode,
+				"penalty_refs": record.PendingPenaltyRefs})
+			return ErrPenaltyInPendingPayableResource
+		}
 		log.ErrorC(requestId, err)
 		return err
 	}
@@ -363,6 +446,364 @@ func (m *MongoPayableResourceService) GetPayableResource(customerCode, payableRe
 	return &resource, nil
 }
 
+// GetPaidPayableResources gets the payable requests of the customer that have been paid from the database
+func (m *MongoPayableResourceService) GetPaidPayableResources(customerCode, requestId string) ([]models.PayableResourceDao, error) {
+	collection := m.db.Collection(m.CollectionName)
+
+	opts := options.Find().SetSort(bson.D{{Key: "data.payment.paid_at", Value: 1}})
+	cursor, err := collection.Find(context.Background(), bson.M{"customer_code": customerCode, "data.payment.status": constants.Paid.String()}, opts)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode})
+		return nil, err
+	}
+
+	resources := []models.PayableResourceDao{}
+	err = cursor.All(context.Background(), &resources)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode})
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// pendingPayableResourcesFilter matches the pending payable resources of the customer for any of the penalty
+// references, which are the keys of the transactions
+func pendingPayableResourcesFilter(customerCode string, penaltyRefs []string) bson.M {
+	penaltyRefFilters := bson.A{}
+	for _, penaltyRef := range penaltyRefs {
+		penaltyRefFilters = append(penaltyRefFilters, bson.M{"data.transactions." + penaltyRef: bson.M{"$exists": true}})
+	}
+	return bson.M{
+		"customer_code":       customerCode,
+		"data.payment.status": constants.Pending.String(),
+		"$or":                 penaltyRefFilters,
+	}
+}
+
+// GetPendingPayableResources gets the pending payable resources of the customer for any of the penalty
+// references that were created after the given time from the database, newest first
+func (m *MongoPayableResourceService) GetPendingPayableResources(customerCode string, penaltyRefs []string, createdAfter time.Time,
+	requestId string) ([]models.PayableResourceDao, error) {
+	filter := pendingPayableResourcesFilter(customerCode, penaltyRefs)
+	filter["data.created_at"] = bson.M{"$gt": createdAfter}
+
+	collection := m.db.Collection(m.CollectionName)
+
+	opts := options.Find().SetSort(bson.D{{Key: "data.created_at", Value: -1}})
+	cursor, err := collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode, "penalty_refs": penaltyRefs})
+		return nil, err
+	}
+
+	resources := []models.PayableResourceDao{}
+	err = cursor.All(context.Background(), &resources)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode, "penalty_refs": penaltyRefs})
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// GetPayableResourceInPayment gets a pending payable resource of the customer for any of the penalty references
+// whose payment was started after the given time from the database
+func (m *MongoPayableResourceService) GetPayableResourceInPayment(customerCode string, penaltyRefs []string, startedAfter time.Time,
+	requestId string) (*models.PayableResourceDao, error) {
+	filter := pendingPayableResourcesFilter(customerCode, penaltyRefs)
+	filter["payment_started_at"] = bson.M{"$gt": startedAfter}
+
+	collection := m.db.Collection(m.CollectionName)
+	dbResource := collection.FindOne(context.Background(), filter)
+
+	err := dbResource.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode, "penalty_refs": penaltyRefs})
+		return nil, err
+	}
+
+	var resource models.PayableResourceDao
+	err = dbResource.Decode(&resource)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode, "penalty_refs": penaltyRefs})
+		return nil, err
+	}
+
+	return &resource, nil
+}
+
+// SetPaymentStarted stores when the payment of the payable resource was started in the document. It is not
+// part of the payable resource model, like the e5 command error.
+func (m *MongoPayableResourceService) SetPaymentStarted(customerCode, payableRef string, startedAt time.Time, requestId string) error {
+	filter := bson.M{"customer_code": customerCode, "payable_ref": payableRef}
+	update := bson.M{"$set": bson.M{"payment_started_at": startedAt}}
+
+	collection := m.db.Collection(m.CollectionName)
+
+	_, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode, "payable_ref": payableRef})
+		return err
+	}
+
+	log.DebugC(requestId, "set payment started in mongo document", log.Data{"customer_code": customerCode,
+		"payable_ref": payableRef, "payment_started_at": startedAt})
+
+	return nil
+}
+
+// SaveE5Processed stores when the payment of the payable resource was processed in E5 in the document, clearing
+// any e5 command error of an earlier attempt. The payment status is paid before the E5 commands are run.
+func (m *MongoPayableResourceService) SaveE5Processed(customerCode, payableRef string, processedAt time.Time, requestId string) error {
+	filter := bson.M{"customer_code": customerCode, "payable_ref": payableRef}
+	update := bson.M{
+		"$set":   bson.M{"e5_processed_at": processedAt},
+		"$unset": bson.M{"e5_command_error": ""},
+	}
+
+	collection := m.db.Collection(m.CollectionName)
+
+	_, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode, "payable_ref": payableRef})
+		return err
+	}
+
+	log.DebugC(requestId, "set e5 processed in mongo document", log.Data{"customer_code": customerCode,
+		"payable_ref": payableRef, "e5_processed_at": processedAt})
+
+	return nil
+}
+
+// expirablePayableResourcesFilter matches the pending payable resources created before createdBefore that are
+// not locked by a payment started after paymentStartedBefore
+func expirablePayableResourcesFilter(createdBefore, paymentStartedBefore time.Time) bson.M {
+	return bson.M{
+		"data.payment.status": constants.Pending.String(),
+		"data.created_at":     bson.M{"$lte": createdBefore},
+		"$or": bson.A{
+			bson.M{"payment_started_at": bson.M{"$exists": false}},
+			bson.M{"payment_started_at": bson.M{"$lte": paymentStartedBefore}},
+		},
+	}
+}
+
+// expirePayableResourcesUpdate sets the payment status to expired and records when it expired
+var expirePayableResourcesUpdate = bson.M{
+	"$set":         bson.M{"data.payment.status": PaymentStatusExpired},
+	"$currentDate": bson.M{"expired_at": true},
+}
+
+// ExpirePayableResource marks the payable resource as expired in the database if it can be expired
+func (m *MongoPayableResourceService) ExpirePayableResource(customerCode, payableRef string, createdBefore,
+	paymentStartedBefore time.Time, requestId string) (bool, error) {
+	filter := expirablePayableResourcesFilter(createdBefore, paymentStartedBefore)
+	filter["customer_code"] = customerCode
+	filter["payable_ref"] = payableRef
+
+	collection := m.db.Collection(m.CollectionName)
+
+	result, err := collection.UpdateOne(context.Background(), filter, expirePayableResourcesUpdate)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode, "payable_ref": payableRef})
+		return false, err
+	}
+
+	expired := result.ModifiedCount > 0
+	if expired {
+		log.InfoC(requestId, "payable resource expired", log.Data{"customer_code": customerCode, "payable_ref": payableRef})
+	}
+
+	return expired, nil
+}
+
+// ExpirePayableResources marks all the payable resources that can be expired as expired in the database
+func (m *MongoPayableResourceService) ExpirePayableResources(createdBefore, paymentStartedBefore time.Time,
+	requestId string) (int64, error) {
+	filter := expirablePayableResourcesFilter(createdBefore, paymentStartedBefore)
+
+	collection := m.db.Collection(m.CollectionName)
+
+	result, err := collection.UpdateMany(context.Background(), filter, expirePayableResourcesUpdate)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"created_before": createdBefore})
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// CancelPayableResource marks the payable resource as cancelled in the database if it can be cancelled
+func (m *MongoPayableResourceService) CancelPayableResource(customerCode, payableRef, cancelledBy string,
+	paymentStartedBefore time.Time, requestId string) (bool, error) {
+	filter := bson.M{
+		"customer_code":       customerCode,
+		"payable_ref":         payableRef,
+		"data.payment.status": constants.Pending.String(),
+		"$or": bson.A{
+			bson.M{"payment_started_at": bson.M{"$exists": false}},
+			bson.M{"payment_started_at": bson.M{"$lte": paymentStartedBefore}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"data.payment.status": PaymentStatusCancelled,
+			"cancelled_at":        time.Now().Truncate(time.Millisecond),
+			"cancelled_by":        cancelledBy,
+		},
+	}
+
+	collection := m.db.Collection(m.CollectionName)
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode, "payable_ref": payableRef})
+		return false, err
+	}
+
+	cancelled := result.ModifiedCount > 0
+	if cancelled {
+		log.InfoC(requestId, "cancelled payable resource", log.Data{"customer_code": customerCode,
+			"payable_ref": payableRef, "cancelled_by": cancelledBy})
+	}
+
+	return cancelled, nil
+}
+
+// payableResourceQueryFilter matches the payable resources of the query
+func payableResourceQueryFilter(query PayableResourceQuery) bson.M {
+	filter := bson.M{}
+	if query.CustomerCode != "" {
+		filter["customer_code"] = query.CustomerCode
+	}
+	if query.CreatedByID != "" {
+		filter["data.created_by.id"] = query.CreatedByID
+	}
+	if query.CreatedByEmail != "" {
+		filter["data.created_by.email"] = query.CreatedByEmail
+	}
+	if query.PaymentReference != "" {
+		filter["data.payment.reference"] = query.PaymentReference
+	}
+	if query.PenaltyRef != "" {
+		filter["data.transactions."+query.PenaltyRef] = bson.M{"$exists": true}
+	}
+	if len(query.Statuses) > 0 {
+		filter["data.payment.status"] = bson.M{"$in": query.Statuses}
+	}
+	createdAt := bson.M{}
+	if !query.CreatedFrom.IsZero() {
+		createdAt["$gte"] = query.CreatedFrom
+	}
+	if !query.CreatedBefore.IsZero() {
+		createdAt["$lt"] = query.CreatedBefore
+	}
+	if len(createdAt) > 0 {
+		filter["data.created_at"] = createdAt
+	}
+	return filter
+}
+
+// caseInsensitiveCollation compares strings ignoring case, the email index is created with it so that
+// searching by email can use the index
+var caseInsensitiveCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// payableResourceQueryCollation is the collation of the query, case-insensitive when searching by email
+func payableResourceQueryCollation(query PayableResourceQuery) *options.Collation {
+	if query.CreatedByEmail != "" {
+		return caseInsensitiveCollation
+	}
+	return nil
+}
+
+// SearchPayableResources gets the page of payable resources that match the query from the database, newest
+// first, and counts how many match it
+func (m *MongoPayableResourceService) SearchPayableResources(query PayableResourceQuery,
+	requestId string) ([]PayableResourceRecordDao, int64, error) {
+	filter := payableResourceQueryFilter(query)
+	logContext := log.Data{"customer_code": query.CustomerCode, "statuses": query.Statuses}
+
+	collection := m.db.Collection(m.CollectionName)
+
+	collation := payableResourceQueryCollation(query)
+	total, err := collection.CountDocuments(context.Background(), filter, options.Count().SetCollation(collation))
+	if err != nil {
+		log.ErrorC(requestId, err, logContext)
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "data.created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(query.StartIndex)).
+		SetCollation(collation)
+	if query.ItemsPerPage > 0 {
+		opts.SetLimit(int64(query.ItemsPerPage))
+	}
+	cursor, err := collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		log.ErrorC(requestId, err, logContext)
+		return nil, 0, err
+	}
+
+	resources := []PayableResourceRecordDao{}
+	err = cursor.All(context.Background(), &resources)
+	if err != nil {
+		log.ErrorC(requestId, err, logContext)
+		return nil, 0, err
+	}
+
+	return resources, total, nil
+}
+
+// CreateIndexes creates the indexes of the payable resources collection used to list the payable resources of a
+// customer and to search them by email, payment reference, penalty reference and date, if they do not already
+// exist. Penalty references are the keys of the transactions so they are indexed by a wildcard index. The unique
+// index on the penalty references of the pending payable resources only allows one pending payable resource of a
+// penalty of the customer, documents stored before the penalty references were stored are not indexed.
+func (m *MongoPayableResourceService) CreateIndexes(requestId string) error {
+	collection := m.db.Collection(m.CollectionName)
+	_, err := collection.CreateIndexes(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "customer_code", Value: 1}, {Key: "data.created_at", Value: -1}},
+			Options: options.Index().SetName("customer_code_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "data.created_by.email", Value: 1}, {Key: "data.created_at", Value: -1}},
+			Options: options.Index().SetName("created_by_email_created_at").SetCollation(caseInsensitiveCollation),
+		},
+		{
+			Keys:    bson.D{{Key: "data.payment.reference", Value: 1}},
+			Options: options.Index().SetName("payment_reference").SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "data.transactions.$**", Value: 1}},
+			Options: options.Index().SetName("transactions"),
+		},
+		{
+			Keys:    bson.D{{Key: "data.created_at", Value: -1}},
+			Options: options.Index().SetName("created_at"),
+		},
+		{
+			Keys: bson.D{{Key: "customer_code", Value: 1}, {Key: "pending_penalty_refs", Value: 1}},
+			Options: options.Index().SetName("customer_code_pending_penalty_refs").SetUnique(true).
+				SetPartialFilterExpression(bson.M{
+					"data.payment.status":  constants.Pending.String(),
+					"pending_penalty_refs": bson.M{"$exists": true},
+				}),
+		},
+	})
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"collection": m.CollectionName})
+		return err
+	}
+
+	return nil
+}
+
 // UpdatePaymentDetails will save the document back to Mongo
 func (m *MongoPayableResourceService) UpdatePaymentDetails(dao *models.PayableResourceDao, requestId string) error {
 	filter := bson.M{"_id": dao.ID}
@@ -405,3 +846,200 @@ func (m *MongoPayableResourceService) Shutdown() {
 		log.Info("disconnected from mongodb successfully")
 	}
 }
+
+// MongoMaintenanceWindowService is an implementation of the MaintenanceWindowDaoService interface using
+// MongoDB as the backend driver.
+type MongoMaintenanceWindowService struct {
+	db             interfaces.MongoDatabaseInterface
+	CollectionName string
+}
+
+// CreateMaintenanceWindow will store the maintenance window into the database
+func (m *MongoMaintenanceWindowService) CreateMaintenanceWindow(dao *MaintenanceWindowDao, requestId string) error {
+	dao.ID = primitive.NewObjectID()
+
+	collection := m.db.Collection(m.CollectionName)
+	_, err := collection.InsertOne(context.Background(), dao)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"name": dao.Name})
+		return err
+	}
+
+	log.InfoC(requestId, "created maintenance window", log.Data{"_id": dao.ID, "name": dao.Name,
+		"start_time": dao.StartTime, "end_time": dao.EndTime})
+
+	return nil
+}
+
+// GetMaintenanceWindows gets the maintenance windows that end after the given time from the database,
+// including cancelled windows
+func (m *MongoMaintenanceWindowService) GetMaintenanceWindows(endAfter time.Time, requestId string) ([]MaintenanceWindowDao, error) {
+	collection := m.db.Collection(m.CollectionName)
+
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+	cursor, err := collection.Find(context.Background(), bson.M{"end_time": bson.M{"$gt": endAfter}}, opts)
+	if err != nil {
+		log.ErrorC(requestId, err)
+		return nil, err
+	}
+
+	maintenanceWindows := []MaintenanceWindowDao{}
+	err = cursor.All(context.Background(), &maintenanceWindows)
+	if err != nil {
+		log.ErrorC(requestId, err)
+		return nil, err
+	}
+
+	return maintenanceWindows, nil
+}
+
+// CancelMaintenanceWindow records who cancelled the maintenance window and when, cancelled windows are
+// kept for audit
+func (m *MongoMaintenanceWindowService) CancelMaintenanceWindow(id, cancelledBy, requestId string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.DebugC(requestId, "invalid maintenance window id", log.Data{"_id": id})
+		return ErrMaintenanceWindowNotFound
+	}
+
+	filter := bson.M{"_id": objectID, "cancelled_at": bson.M{"$exists": false}}
+	update := bson.M{
+		"$set": bson.M{
+			"cancelled_at": time.Now().Truncate(time.Millisecond),
+			"cancelled_by": cancelledBy,
+		},
+	}
+
+	collection := m.db.Collection(m.CollectionName)
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"_id": id})
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		log.DebugC(requestId, "no maintenance window found to cancel", log.Data{"_id": id})
+		return ErrMaintenanceWindowNotFound
+	}
+
+	log.InfoC(requestId, "cancelled maintenance window", log.Data{"_id": id, "cancelled_by": cancelledBy})
+
+	return nil
+}
+
+// MongoIdempotencyKeyService is an implementation of the IdempotencyKeyDaoService interface using
+// MongoDB as the backend driver.
+type MongoIdempotencyKeyService struct {
+	db             interfaces.MongoDatabaseInterface
+	CollectionName string
+}
+
+// idempotencyKeyFilter matches the idempotency key used by the user for the customer
+func idempotencyKeyFilter(key, customerCode, createdBy string) bson.M {
+	return bson.M{"key": key, "customer_code": customerCode, "created_by": createdBy}
+}
+
+// ReserveIdempotencyKey stores the idempotency key into the database, replacing the key if it has expired
+// but not yet been removed by the time to live index. The unique index stops a key that has not expired
+// from being reserved again.
+func (m *MongoIdempotencyKeyService) ReserveIdempotencyKey(dao *IdempotencyKeyDao, requestId string) error {
+	filter := idempotencyKeyFilter(dao.Key, dao.CustomerCode, dao.CreatedBy)
+	filter["expires_at"] = bson.M{"$lte": dao.CreatedAt}
+
+	collection := m.db.Collection(m.CollectionName)
+	_, err := collection.UpdateOne(context.Background(), filter, bson.M{"$set": dao}, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			log.DebugC(requestId, "idempotency key in use", log.Data{"customer_code": dao.CustomerCode})
+			return ErrIdempotencyKeyInUse
+		}
+		log.ErrorC(requestId, err, log.Data{"customer_code": dao.CustomerCode})
+		return err
+	}
+
+	log.InfoC(requestId, "reserved idempotency key", log.Data{"customer_code": dao.CustomerCode, "expires_at": dao.ExpiresAt})
+
+	return nil
+}
+
+// GetIdempotencyKey gets the idempotency key from the database, nil is returned if the key is not found or
+// has expired
+func (m *MongoIdempotencyKeyService) GetIdempotencyKey(key, customerCode, createdBy, requestId string) (*IdempotencyKeyDao, error) {
+	filter := idempotencyKeyFilter(key, customerCode, createdBy)
+	filter["expires_at"] = bson.M{"$gt": time.Now()}
+
+	collection := m.db.Collection(m.CollectionName)
+	dbResource := collection.FindOne(context.Background(), filter)
+
+	err := dbResource.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			log.DebugC(requestId, "no idempotency key found", log.Data{"customer_code": customerCode})
+			return nil, nil
+		}
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode})
+		return nil, err
+	}
+
+	var idempotencyKey IdempotencyKeyDao
+	err = dbResource.Decode(&idempotencyKey)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": customerCode})
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+// CompleteIdempotencyKey stores the payable ref of the idempotency key into the database
+func (m *MongoIdempotencyKeyService) CompleteIdempotencyKey(dao *IdempotencyKeyDao, requestId string) error {
+	filter := idempotencyKeyFilter(dao.Key, dao.CustomerCode, dao.CreatedBy)
+	update := bson.M{"$set": bson.M{"payable_ref": dao.PayableRef}}
+
+	collection := m.db.Collection(m.CollectionName)
+	_, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": dao.CustomerCode, "payable_ref": dao.PayableRef})
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the idempotency key from the database if no payable resource was created
+func (m *MongoIdempotencyKeyService) ReleaseIdempotencyKey(dao *IdempotencyKeyDao, requestId string) error {
+	filter := idempotencyKeyFilter(dao.Key, dao.CustomerCode, dao.CreatedBy)
+	filter["payable_ref"] = ""
+
+	collection := m.db.Collection(m.CollectionName)
+	_, err := collection.DeleteOne(context.Background(), filter)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"customer_code": dao.CustomerCode})
+		return err
+	}
+
+	return nil
+}
+
+// CreateIndexes creates the indexes of the idempotency keys collection if they do not already exist. Keys are
+// unique to the user and customer, and are removed by mongodb once they have expired.
+func (m *MongoIdempotencyKeyService) CreateIndexes(requestId string) error {
+	collection := m.db.Collection(m.CollectionName)
+	_, err := collection.CreateIndexes(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}, {Key: "customer_code", Value: 1}, {Key: "created_by", Value: 1}},
+			Options: options.Index().SetName("key_customer_code_created_by").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{"collection": m.CollectionName})
+		return err
+	}
+
+	return nil
+}
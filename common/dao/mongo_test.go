@@ -1,12 +1,17 @@
 package dao
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/companieshouse/penalty-payment-api-core/constants"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/e5"
 	"github.com/companieshouse/penalty-payment-api/mocks"
@@ -213,6 +218,63 @@ func TestUnitMongo_UpdateAccountPenalties(t *testing.T) {
 	})
 }
 
+func TestUnitMongo_GetAccountPenaltiesByPenaltyRef(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForAccountPenaltiesService(t)
+
+	defer ctrl.Finish()
+
+	Convey("get account penalties by penalty reference should return", t, func() {
+		mockDatabase.EXPECT().Collection("account_penalties").Return(mockCollection)
+
+		Convey("success when the penalty reference is found", func() {
+			result := mongo.NewSingleResultFromDocument(bson.M{
+				"customer_code": customerCode,
+				"company_code":  companyCode,
+			}, nil, nil)
+
+			mockCollection.EXPECT().FindOne(gomock.Any(), bson.M{"company_code": companyCode, "data.transaction_reference": "A1234567"}).Return(result)
+
+			resource, err := svc.GetAccountPenaltiesByPenaltyRef(companyCode, "A1234567", "")
+
+			So(err, ShouldBeNil)
+			So(resource.CustomerCode, ShouldEqual, customerCode)
+		})
+
+		Convey("error when the penalty reference is not found", func() {
+			result := mongo.NewSingleResultFromDocument(bson.M{}, mongo.ErrNoDocuments, nil)
+
+			mockCollection.EXPECT().FindOne(gomock.Any(), gomock.Any()).Return(result)
+
+			resource, err := svc.GetAccountPenaltiesByPenaltyRef(companyCode, "A1234567", "")
+
+			So(err, ShouldEqual, mongo.ErrNoDocuments)
+			So(resource, ShouldBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_CreateAccountPenaltiesIndexes(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForAccountPenaltiesService(t)
+
+	defer ctrl.Finish()
+
+	Convey("create account penalties indexes should return", t, func() {
+		mockDatabase.EXPECT().Collection("account_penalties").Return(mockCollection)
+
+		Convey("success when the indexes are created", func() {
+			mockCollection.EXPECT().CreateIndexes(gomock.Any(), gomock.Any()).Return([]string{"company_code_transaction_reference"}, nil)
+
+			So(svc.CreateIndexes(""), ShouldBeNil)
+		})
+
+		Convey("error when the indexes cannot be created", func() {
+			mockCollection.EXPECT().CreateIndexes(gomock.Any(), gomock.Any()).Return(nil, errors.New("error creating indexes"))
+
+			So(svc.CreateIndexes(""), ShouldNotBeNil)
+		})
+	})
+}
+
 func TestUnitMongo_CreatePayableResource(t *testing.T) {
 	ctrl, svc, mockCollection, mockDatabase, dao := setUpForPayableResourceService(t)
 
@@ -229,12 +291,38 @@ func TestUnitMongo_CreatePayableResource(t *testing.T) {
 			So(err, ShouldBeNil)
 		})
 
+		Convey("success storing the penalty references of a pending payable resource", func() {
+			pending := &models.PayableResourceDao{Data: models.PayableResourceDataDao{
+				Payment:      models.PaymentDao{Status: constants.Pending.String()},
+				Transactions: map[string]models.TransactionDao{"A1234567": {Amount: 5}},
+			}}
+			mockCollection.EXPECT().InsertOne(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, document interface{}, _ ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+					So(document.(PayableResourceRecordDao).PendingPenaltyRefs, ShouldResemble, []string{"A1234567"})
+					return nil, nil
+				})
+
+			err := svc.CreatePayableResource(pending, "")
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("error penalty in pending payable resource when a penalty is in another pending payable resource", func() {
+			duplicateKeyErr := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+			mockCollection.EXPECT().InsertOne(gomock.Any(), gomock.Any()).Return(nil, duplicateKeyErr)
+
+			err := svc.CreatePayableResource(dao, "")
+
+			So(err, ShouldEqual, ErrPenaltyInPendingPayableResource)
+		})
+
 		Convey("error when creating payable resource", func() {
 			mockCollection.EXPECT().InsertOne(gomock.Any(), gomock.Any()).Return(nil, errors.New("error creating payable resource"))
 
 			err := svc.CreatePayableResource(dao, "")
 
 			So(err, ShouldNotBeNil)
+			So(err, ShouldNotEqual, ErrPenaltyInPendingPayableResource)
 		})
 
 	})
@@ -372,6 +460,480 @@ func TestUnitMongo_SaveE5Error(t *testing.T) {
 	})
 }
 
+func TestUnitMongo_GetPaidPayableResources(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	Convey("get paid payable resources should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("success when getting paid payable resources", func() {
+			cursor, err := mongo.NewCursorFromDocuments([]interface{}{
+				bson.M{"customer_code": customerCode, "payable_ref": payableRef, "data": bson.M{"payment": bson.M{"status": "paid"}}},
+			}, nil, nil)
+			So(err, ShouldBeNil)
+			mockCollection.EXPECT().Find(gomock.Any(), bson.M{"customer_code": customerCode, "data.payment.status": "paid"}, gomock.Any()).Return(cursor, nil)
+
+			resources, err := svc.GetPaidPayableResources(customerCode, "")
+
+			So(err, ShouldBeNil)
+			So(resources, ShouldHaveLength, 1)
+			So(resources[0].PayableRef, ShouldEqual, payableRef)
+			So(resources[0].IsPaid(), ShouldBeTrue)
+		})
+
+		Convey("error when getting paid payable resources", func() {
+			mockCollection.EXPECT().Find(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error getting paid payable resources"))
+
+			resources, err := svc.GetPaidPayableResources(customerCode, "")
+
+			So(resources, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_GetPendingPayableResources(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	createdAfter := time.Date(2025, time.March, 16, 19, 10, 0, 0, time.UTC)
+
+	Convey("get pending payable resources should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("success when getting pending payable resources", func() {
+			cursor, err := mongo.NewCursorFromDocuments([]interface{}{
+				bson.M{"customer_code": customerCode, "payable_ref": payableRef, "data": bson.M{"payment": bson.M{"status": "pending"}}},
+			}, nil, nil)
+			So(err, ShouldBeNil)
+			mockCollection.EXPECT().Find(gomock.Any(), bson.M{
+				"customer_code":       customerCode,
+				"data.payment.status": "pending",
+				"$or":                 bson.A{bson.M{"data.transactions." + penaltyRef: bson.M{"$exists": true}}},
+				"data.created_at":     bson.M{"$gt": createdAfter},
+			}, gomock.Any()).Return(cursor, nil)
+
+			resources, err := svc.GetPendingPayableResources(customerCode, []string{penaltyRef}, createdAfter, "")
+
+			So(err, ShouldBeNil)
+			So(resources, ShouldHaveLength, 1)
+			So(resources[0].PayableRef, ShouldEqual, payableRef)
+		})
+
+		Convey("error when getting pending payable resources", func() {
+			mockCollection.EXPECT().Find(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error getting pending payable resources"))
+
+			resources, err := svc.GetPendingPayableResources(customerCode, []string{penaltyRef}, createdAfter, "")
+
+			So(resources, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_GetPayableResourceInPayment(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	startedAfter := time.Date(2025, time.March, 16, 19, 10, 0, 0, time.UTC)
+
+	Convey("get payable resource in payment should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("success when a payment for the penalty has been started", func() {
+			result := mongo.NewSingleResultFromDocument(bson.M{"customer_code": customerCode, "payable_ref": payableRef}, nil, nil)
+			mockCollection.EXPECT().FindOne(gomock.Any(), bson.M{
+				"customer_code":       customerCode,
+				"data.payment.status": "pending",
+				"$or":                 bson.A{bson.M{"data.transactions." + penaltyRef: bson.M{"$exists": true}}},
+				"payment_started_at":  bson.M{"$gt": startedAfter},
+			}).Return(result)
+
+			resource, err := svc.GetPayableResourceInPayment(customerCode, []string{penaltyRef}, startedAfter, "")
+
+			So(err, ShouldBeNil)
+			So(resource.PayableRef, ShouldEqual, payableRef)
+		})
+
+		Convey("nil when no payment for the penalty has been started", func() {
+			result := mongo.NewSingleResultFromDocument(bson.M{}, mongo.ErrNoDocuments, nil)
+			mockCollection.EXPECT().FindOne(gomock.Any(), gomock.Any()).Return(result)
+
+			resource, err := svc.GetPayableResourceInPayment(customerCode, []string{penaltyRef}, startedAfter, "")
+
+			So(err, ShouldBeNil)
+			So(resource, ShouldBeNil)
+		})
+
+		Convey("error when getting the payable resource in payment", func() {
+			result := mongo.NewSingleResultFromDocument(bson.M{}, errors.New("error getting payable resource"), nil)
+			mockCollection.EXPECT().FindOne(gomock.Any(), gomock.Any()).Return(result)
+
+			resource, err := svc.GetPayableResourceInPayment(customerCode, []string{penaltyRef}, startedAfter, "")
+
+			So(err, ShouldNotBeNil)
+			So(resource, ShouldBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_SetPaymentStarted(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	startedAt := time.Date(2025, time.March, 16, 19, 10, 0, 0, time.UTC)
+
+	Convey("set payment started should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("success when the payment started is set", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(),
+				bson.M{"customer_code": customerCode, "payable_ref": payableRef},
+				bson.M{"$set": bson.M{"payment_started_at": startedAt}}).Return(nil, nil)
+
+			err := svc.SetPaymentStarted(customerCode, payableRef, startedAt, "")
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("error when setting the payment started", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error updating payable resource"))
+
+			err := svc.SetPaymentStarted(customerCode, payableRef, startedAt, "")
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_SaveE5Processed(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	processedAt := time.Date(2025, time.March, 16, 19, 12, 0, 0, time.UTC)
+
+	Convey("save e5 processed should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("success when the e5 processed time is set and the e5 command error cleared", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(),
+				bson.M{"customer_code": customerCode, "payable_ref": payableRef},
+				bson.M{
+					"$set":   bson.M{"e5_processed_at": processedAt},
+					"$unset": bson.M{"e5_command_error": ""},
+				}).Return(nil, nil)
+
+			err := svc.SaveE5Processed(customerCode, payableRef, processedAt, "")
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("error when setting the e5 processed time", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error updating payable resource"))
+
+			err := svc.SaveE5Processed(customerCode, payableRef, processedAt, "")
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_CancelPayableResource(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	paymentStartedBefore := time.Date(2025, time.March, 16, 17, 40, 0, 0, time.UTC)
+
+	Convey("cancel payable resource should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("true when the payable resource is cancelled", func() {
+			var update bson.M
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), bson.M{
+				"customer_code":       customerCode,
+				"payable_ref":         payableRef,
+				"data.payment.status": "pending",
+				"$or": bson.A{
+					bson.M{"payment_started_at": bson.M{"$exists": false}},
+					bson.M{"payment_started_at": bson.M{"$lte": paymentStartedBefore}},
+				},
+			}, gomock.Any()).DoAndReturn(func(_ context.Context, _, u interface{}, _ ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+				update = u.(bson.M)
+				return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+			})
+
+			cancelled, err := svc.CancelPayableResource(customerCode, payableRef, "user-id", paymentStartedBefore, "")
+
+			So(err, ShouldBeNil)
+			So(cancelled, ShouldBeTrue)
+			set := update["$set"].(bson.M)
+			So(set["data.payment.status"], ShouldEqual, PaymentStatusCancelled)
+			So(set["cancelled_by"], ShouldEqual, "user-id")
+			So(set["cancelled_at"], ShouldNotBeNil)
+		})
+
+		Convey("false when the payable resource cannot be cancelled", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(&mongo.UpdateResult{}, nil)
+
+			cancelled, err := svc.CancelPayableResource(customerCode, payableRef, "user-id", paymentStartedBefore, "")
+
+			So(err, ShouldBeNil)
+			So(cancelled, ShouldBeFalse)
+		})
+
+		Convey("error when cancelling the payable resource", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error updating payable resource"))
+
+			cancelled, err := svc.CancelPayableResource(customerCode, payableRef, "user-id", paymentStartedBefore, "")
+
+			So(err, ShouldNotBeNil)
+			So(cancelled, ShouldBeFalse)
+		})
+	})
+}
+
+func TestUnitMongo_ExpirePayableResource(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	createdBefore := time.Date(2025, time.March, 15, 19, 10, 0, 0, time.UTC)
+	paymentStartedBefore := time.Date(2025, time.March, 16, 17, 40, 0, 0, time.UTC)
+
+	Convey("expire payable resource should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("true when the payable resource is expired", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), bson.M{
+				"customer_code":       customerCode,
+				"payable_ref":         payableRef,
+				"data.payment.status": "pending",
+				"data.created_at":     bson.M{"$lte": createdBefore},
+				"$or": bson.A{
+					bson.M{"payment_started_at": bson.M{"$exists": false}},
+					bson.M{"payment_started_at": bson.M{"$lte": paymentStartedBefore}},
+				},
+			}, bson.M{
+				"$set":         bson.M{"data.payment.status": PaymentStatusExpired},
+				"$currentDate": bson.M{"expired_at": true},
+			}).Return(&mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+
+			expired, err := svc.ExpirePayableResource(customerCode, payableRef, createdBefore, paymentStartedBefore, "")
+
+			So(err, ShouldBeNil)
+			So(expired, ShouldBeTrue)
+		})
+
+		Convey("false when the payable resource cannot be expired", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(&mongo.UpdateResult{}, nil)
+
+			expired, err := svc.ExpirePayableResource(customerCode, payableRef, createdBefore, paymentStartedBefore, "")
+
+			So(err, ShouldBeNil)
+			So(expired, ShouldBeFalse)
+		})
+
+		Convey("error when expiring the payable resource", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error updating payable resource"))
+
+			expired, err := svc.ExpirePayableResource(customerCode, payableRef, createdBefore, paymentStartedBefore, "")
+
+			So(err, ShouldNotBeNil)
+			So(expired, ShouldBeFalse)
+		})
+	})
+}
+
+func TestUnitMongo_ExpirePayableResources(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	createdBefore := time.Date(2025, time.March, 15, 19, 10, 0, 0, time.UTC)
+	paymentStartedBefore := time.Date(2025, time.March, 16, 17, 40, 0, 0, time.UTC)
+
+	Convey("expire payable resources should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("how many payable resources were expired", func() {
+			mockCollection.EXPECT().UpdateMany(gomock.Any(), bson.M{
+				"data.payment.status": "pending",
+				"data.created_at":     bson.M{"$lte": createdBefore},
+				"$or": bson.A{
+					bson.M{"payment_started_at": bson.M{"$exists": false}},
+					bson.M{"payment_started_at": bson.M{"$lte": paymentStartedBefore}},
+				},
+			}, gomock.Any()).Return(&mongo.UpdateResult{MatchedCount: 3, ModifiedCount: 3}, nil)
+
+			expired, err := svc.ExpirePayableResources(createdBefore, paymentStartedBefore, "")
+
+			So(err, ShouldBeNil)
+			So(expired, ShouldEqual, int64(3))
+		})
+
+		Convey("error when expiring payable resources", func() {
+			mockCollection.EXPECT().UpdateMany(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error updating payable resources"))
+
+			expired, err := svc.ExpirePayableResources(createdBefore, paymentStartedBefore, "")
+
+			So(err, ShouldNotBeNil)
+			So(expired, ShouldEqual, int64(0))
+		})
+	})
+}
+
+func TestUnitMongo_SearchPayableResources(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	createdFrom := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	createdBefore := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	query := PayableResourceQuery{
+		CustomerCode:  customerCode,
+		CreatedByID:   "user-id",
+		Statuses:      []string{"paid", "pending"},
+		CreatedFrom:   createdFrom,
+		CreatedBefore: createdBefore,
+		StartIndex:    25,
+		ItemsPerPage:  25,
+	}
+	filter := bson.M{
+		"customer_code":       customerCode,
+		"data.created_by.id":  "user-id",
+		"data.payment.status": bson.M{"$in": []string{"paid", "pending"}},
+		"data.created_at":     bson.M{"$gte": createdFrom, "$lt": createdBefore},
+	}
+
+	Convey("search payable resources should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("the page of payable resources and how many match the query", func() {
+			cursor, err := mongo.NewCursorFromDocuments([]interface{}{
+				bson.M{"customer_code": customerCode, "payable_ref": payableRef, "e5_command_error": "create_payment",
+					"data": bson.M{"payment": bson.M{"status": "paid", "reference": "payment-ref"}}},
+			}, nil, nil)
+			So(err, ShouldBeNil)
+			mockCollection.EXPECT().CountDocuments(gomock.Any(), filter, gomock.Any()).Return(int64(26), nil)
+			mockCollection.EXPECT().Find(gomock.Any(), filter, gomock.Any()).Return(cursor, nil)
+
+			resources, total, err := svc.SearchPayableResources(query, "")
+
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, int64(26))
+			So(resources, ShouldHaveLength, 1)
+			So(resources[0].PayableRef, ShouldEqual, payableRef)
+			So(resources[0].Data.Payment.Reference, ShouldEqual, "payment-ref")
+			So(resources[0].E5CommandError, ShouldEqual, "create_payment")
+		})
+
+		Convey("every payable resource of the customer when the query has no filters", func() {
+			cursor, err := mongo.NewCursorFromDocuments([]interface{}{}, nil, nil)
+			So(err, ShouldBeNil)
+			mockCollection.EXPECT().CountDocuments(gomock.Any(), bson.M{"customer_code": customerCode}, gomock.Any()).Return(int64(0), nil)
+			mockCollection.EXPECT().Find(gomock.Any(), bson.M{"customer_code": customerCode}, gomock.Any()).Return(cursor, nil)
+
+			resources, total, err := svc.SearchPayableResources(PayableResourceQuery{CustomerCode: customerCode}, "")
+
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, int64(0))
+			So(resources, ShouldBeEmpty)
+		})
+
+		Convey("the payable resources matching the email case-insensitively, payment reference and penalty reference", func() {
+			searchFilter := bson.M{
+				"data.created_by.email":           "Demo@ch.gov.uk",
+				"data.payment.reference":          "payment-ref",
+				"data.transactions." + penaltyRef: bson.M{"$exists": true},
+			}
+			cursor, err := mongo.NewCursorFromDocuments([]interface{}{}, nil, nil)
+			So(err, ShouldBeNil)
+			mockCollection.EXPECT().CountDocuments(gomock.Any(), searchFilter, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ interface{}, opts ...*options.CountOptions) (int64, error) {
+					So(opts[0].Collation, ShouldResemble, &options.Collation{Locale: "en", Strength: 2})
+					return 0, nil
+				})
+			mockCollection.EXPECT().Find(gomock.Any(), searchFilter, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+					So(opts[0].Collation, ShouldResemble, &options.Collation{Locale: "en", Strength: 2})
+					return cursor, nil
+				})
+
+			_, _, err = svc.SearchPayableResources(PayableResourceQuery{
+				CreatedByEmail:   "Demo@ch.gov.uk",
+				PaymentReference: "payment-ref",
+				PenaltyRef:       penaltyRef,
+			}, "")
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("error when counting payable resources", func() {
+			mockCollection.EXPECT().CountDocuments(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), errors.New("error counting payable resources"))
+
+			resources, _, err := svc.SearchPayableResources(query, "")
+
+			So(resources, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("error when finding payable resources", func() {
+			mockCollection.EXPECT().CountDocuments(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(26), nil)
+			mockCollection.EXPECT().Find(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error finding payable resources"))
+
+			resources, _, err := svc.SearchPayableResources(query, "")
+
+			So(resources, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_CreatePayableResourcesIndexes(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase, _ := setUpForPayableResourceService(t)
+
+	defer ctrl.Finish()
+
+	Convey("create payable resources indexes should return", t, func() {
+		mockDatabase.EXPECT().Collection("payable_resources").Return(mockCollection)
+
+		Convey("success when the search indexes are created", func() {
+			mockCollection.EXPECT().CreateIndexes(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, indexes []mongo.IndexModel, _ ...*options.CreateIndexesOptions) ([]string, error) {
+					So(indexes, ShouldHaveLength, 6)
+					So(indexes[1].Options.Collation, ShouldResemble, &options.Collation{Locale: "en", Strength: 2})
+					So(indexes[3].Keys, ShouldResemble, bson.D{{Key: "data.transactions.$**", Value: 1}})
+					So(*indexes[5].Options.Unique, ShouldBeTrue)
+					So(indexes[5].Options.PartialFilterExpression, ShouldResemble, bson.M{
+						"data.payment.status":  constants.Pending.String(),
+						"pending_penalty_refs": bson.M{"$exists": true},
+					})
+					return []string{"customer_code_created_at", "created_by_email_created_at", "payment_reference",
+						"transactions", "created_at", "customer_code_pending_penalty_refs"}, nil
+				})
+
+			err := svc.CreateIndexes("")
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("error when creating the indexes", func() {
+			mockCollection.EXPECT().CreateIndexes(gomock.Any(), gomock.Any()).Return(nil, errors.New("error creating indexes"))
+
+			err := svc.CreateIndexes("")
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
 func TestUnitMongo_PayableResourceService_Shutdown(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -392,6 +954,129 @@ func TestUnitMongo_PayableResourceService_Shutdown(t *testing.T) {
 	})
 }
 
+func TestUnitMongo_CreateMaintenanceWindow(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase := setUpForMaintenanceWindowService(t)
+
+	defer ctrl.Finish()
+
+	Convey("create maintenance window should return", t, func() {
+		mockDatabase.EXPECT().Collection("maintenance_windows").Return(mockCollection)
+
+		Convey("success when creating a maintenance window", func() {
+			mockCollection.EXPECT().InsertOne(gomock.Any(), gomock.Any()).Return(&mongo.InsertOneResult{}, nil)
+			maintenanceWindow := &MaintenanceWindowDao{Name: "E5 upgrade"}
+
+			err := svc.CreateMaintenanceWindow(maintenanceWindow, "")
+
+			So(err, ShouldBeNil)
+			So(maintenanceWindow.ID.IsZero(), ShouldBeFalse)
+		})
+
+		Convey("error when creating a maintenance window", func() {
+			mockCollection.EXPECT().InsertOne(gomock.Any(), gomock.Any()).Return(nil, errors.New("error creating maintenance window"))
+
+			err := svc.CreateMaintenanceWindow(&MaintenanceWindowDao{Name: "E5 upgrade"}, "")
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_GetMaintenanceWindows(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase := setUpForMaintenanceWindowService(t)
+
+	defer ctrl.Finish()
+
+	Convey("get maintenance windows should return", t, func() {
+		mockDatabase.EXPECT().Collection("maintenance_windows").Return(mockCollection)
+		endAfter := time.Date(2025, time.March, 16, 12, 0, 0, 0, time.UTC)
+
+		Convey("success when getting maintenance windows", func() {
+			cursor, err := mongo.NewCursorFromDocuments([]interface{}{
+				bson.M{"name": "E5 upgrade", "start_time": endAfter.Add(time.Hour), "end_time": endAfter.Add(2 * time.Hour)},
+				bson.M{"name": "Cancelled", "start_time": endAfter.Add(time.Hour), "end_time": endAfter.Add(2 * time.Hour), "cancelled_at": endAfter},
+			}, nil, nil)
+			So(err, ShouldBeNil)
+			mockCollection.EXPECT().Find(gomock.Any(), bson.M{"end_time": bson.M{"$gt": endAfter}}, gomock.Any()).Return(cursor, nil)
+
+			maintenanceWindows, err := svc.GetMaintenanceWindows(endAfter, "")
+
+			So(err, ShouldBeNil)
+			So(maintenanceWindows, ShouldHaveLength, 2)
+			So(maintenanceWindows[0].Name, ShouldEqual, "E5 upgrade")
+			So(maintenanceWindows[0].IsCancelled(), ShouldBeFalse)
+			So(maintenanceWindows[1].IsCancelled(), ShouldBeTrue)
+		})
+
+		Convey("error when getting maintenance windows", func() {
+			mockCollection.EXPECT().Find(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error getting maintenance windows"))
+
+			maintenanceWindows, err := svc.GetMaintenanceWindows(endAfter, "")
+
+			So(maintenanceWindows, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_CancelMaintenanceWindow(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase := setUpForMaintenanceWindowService(t)
+
+	defer ctrl.Finish()
+
+	id := primitive.NewObjectID().Hex()
+
+	Convey("cancel maintenance window should return", t, func() {
+		Convey("success when cancelling a maintenance window", func() {
+			mockDatabase.EXPECT().Collection("maintenance_windows").Return(mockCollection)
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(&mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+
+			err := svc.CancelMaintenanceWindow(id, "admin-key", "")
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("not found when the maintenance window does not exist or is already cancelled", func() {
+			mockDatabase.EXPECT().Collection("maintenance_windows").Return(mockCollection)
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(&mongo.UpdateResult{}, nil)
+
+			err := svc.CancelMaintenanceWindow(id, "admin-key", "")
+
+			So(err, ShouldEqual, ErrMaintenanceWindowNotFound)
+		})
+
+		Convey("not found when the id is invalid", func() {
+			err := svc.CancelMaintenanceWindow("invalid", "admin-key", "")
+
+			So(err, ShouldEqual, ErrMaintenanceWindowNotFound)
+		})
+
+		Convey("error when cancelling a maintenance window", func() {
+			mockDatabase.EXPECT().Collection("maintenance_windows").Return(mockCollection)
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error cancelling maintenance window"))
+
+			err := svc.CancelMaintenanceWindow(id, "admin-key", "")
+
+			So(err, ShouldNotBeNil)
+			So(err, ShouldNotEqual, ErrMaintenanceWindowNotFound)
+		})
+	})
+}
+
+func setUpForMaintenanceWindowService(t *testing.T) (*gomock.Controller, MongoMaintenanceWindowService,
+	*mocks.MockMongoCollectionInterface, *mocks.MockMongoDatabaseInterface) {
+	ctrl := gomock.NewController(t)
+
+	mockCollection := mocks.NewMockMongoCollectionInterface(ctrl)
+	mockDatabase := mocks.NewMockMongoDatabaseInterface(ctrl)
+
+	svc := MongoMaintenanceWindowService{
+		db:             mockDatabase,
+		CollectionName: "maintenance_windows",
+	}
+	return ctrl, svc, mockCollection, mockDatabase
+}
+
 func setUpForAccountPenaltiesService(t *testing.T) (*gomock.Controller, MongoAccountPenaltiesService,
 	*mocks.MockMongoCollectionInterface, *mocks.MockMongoDatabaseInterface, *models.AccountPenaltiesDao) {
 	ctrl := gomock.NewController(t)
@@ -421,3 +1106,144 @@ func setUpForPayableResourceService(t *testing.T) (*gomock.Controller, MongoPaya
 	}
 	return ctrl, svc, mockCollection, mockDatabase, dao
 }
+
+func TestUnitMongo_ReserveIdempotencyKey(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase := setUpForIdempotencyKeyService(t)
+
+	defer ctrl.Finish()
+
+	Convey("reserve idempotency key should return", t, func() {
+		mockDatabase.EXPECT().Collection("idempotency_keys").Return(mockCollection)
+		idempotencyKey := &IdempotencyKeyDao{Key: "key-1", CustomerCode: "12345678", CreatedBy: "user-id"}
+
+		Convey("success when the key is reserved", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&mongo.UpdateResult{UpsertedCount: 1}, nil)
+
+			err := svc.ReserveIdempotencyKey(idempotencyKey, "")
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("error in use when the key has already been reserved", func() {
+			duplicateKeyErr := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, duplicateKeyErr)
+
+			err := svc.ReserveIdempotencyKey(idempotencyKey, "")
+
+			So(err, ShouldEqual, ErrIdempotencyKeyInUse)
+		})
+
+		Convey("error when reserving the key", func() {
+			mockCollection.EXPECT().UpdateOne(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error reserving idempotency key"))
+
+			err := svc.ReserveIdempotencyKey(idempotencyKey, "")
+
+			So(err, ShouldNotBeNil)
+			So(err, ShouldNotEqual, ErrIdempotencyKeyInUse)
+		})
+	})
+}
+
+func TestUnitMongo_GetIdempotencyKey(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase := setUpForIdempotencyKeyService(t)
+
+	defer ctrl.Finish()
+
+	Convey("get idempotency key should return", t, func() {
+		mockDatabase.EXPECT().Collection("idempotency_keys").Return(mockCollection)
+
+		Convey("success when getting the idempotency key", func() {
+			result := mongo.NewSingleResultFromDocument(bson.M{"key": "key-1", "payable_ref": "PR_123"}, nil, nil)
+			mockCollection.EXPECT().FindOne(gomock.Any(), gomock.Any()).Return(result)
+
+			idempotencyKey, err := svc.GetIdempotencyKey("key-1", "12345678", "user-id", "")
+
+			So(err, ShouldBeNil)
+			So(idempotencyKey.PayableRef, ShouldEqual, "PR_123")
+			So(idempotencyKey.IsComplete(), ShouldBeTrue)
+		})
+
+		Convey("nil when the idempotency key is not found", func() {
+			result := mongo.NewSingleResultFromDocument(bson.M{}, mongo.ErrNoDocuments, nil)
+			mockCollection.EXPECT().FindOne(gomock.Any(), gomock.Any()).Return(result)
+
+			idempotencyKey, err := svc.GetIdempotencyKey("key-1", "12345678", "user-id", "")
+
+			So(err, ShouldBeNil)
+			So(idempotencyKey, ShouldBeNil)
+		})
+
+		Convey("error when getting the idempotency key", func() {
+			result := mongo.NewSingleResultFromDocument(bson.M{}, errors.New("error getting idempotency key"), nil)
+			mockCollection.EXPECT().FindOne(gomock.Any(), gomock.Any()).Return(result)
+
+			idempotencyKey, err := svc.GetIdempotencyKey("key-1", "12345678", "user-id", "")
+
+			So(err, ShouldNotBeNil)
+			So(idempotencyKey, ShouldBeNil)
+		})
+	})
+}
+
+func TestUnitMongo_CompleteAndReleaseIdempotencyKey(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase := setUpForIdempotencyKeyService(t)
+
+	defer ctrl.Finish()
+
+	idempotencyKey := &IdempotencyKeyDao{Key: "key-1", CustomerCode: "12345678", CreatedBy: "user-id", PayableRef: "PR_123"}
+
+	Convey("complete idempotency key should record the payable ref", t, func() {
+		mockDatabase.EXPECT().Collection("idempotency_keys").Return(mockCollection)
+		mockCollection.EXPECT().UpdateOne(gomock.Any(), bson.M{"key": "key-1", "customer_code": "12345678", "created_by": "user-id"},
+			bson.M{"$set": bson.M{"payable_ref": "PR_123"}}).Return(&mongo.UpdateResult{MatchedCount: 1}, nil)
+
+		err := svc.CompleteIdempotencyKey(idempotencyKey, "")
+
+		So(err, ShouldBeNil)
+	})
+
+	Convey("release idempotency key should only delete a key without a payable ref", t, func() {
+		mockDatabase.EXPECT().Collection("idempotency_keys").Return(mockCollection)
+		mockCollection.EXPECT().DeleteOne(gomock.Any(), bson.M{"key": "key-1", "customer_code": "12345678", "created_by": "user-id",
+			"payable_ref": ""}).Return(nil, errors.New("error releasing idempotency key"))
+
+		err := svc.ReleaseIdempotencyKey(idempotencyKey, "")
+
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestUnitMongo_CreateIdempotencyKeysIndexes(t *testing.T) {
+	ctrl, svc, mockCollection, mockDatabase := setUpForIdempotencyKeyService(t)
+
+	defer ctrl.Finish()
+
+	Convey("create idempotency keys indexes should create the unique and time to live indexes", t, func() {
+		mockDatabase.EXPECT().Collection("idempotency_keys").Return(mockCollection)
+		mockCollection.EXPECT().CreateIndexes(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, indexes []mongo.IndexModel, _ ...*options.CreateIndexesOptions) ([]string, error) {
+				So(indexes, ShouldHaveLength, 2)
+				So(*indexes[0].Options.Unique, ShouldBeTrue)
+				So(*indexes[1].Options.ExpireAfterSeconds, ShouldEqual, int32(0))
+				return []string{"key_customer_code_created_by", "expires_at"}, nil
+			})
+
+		err := svc.CreateIndexes("")
+
+		So(err, ShouldBeNil)
+	})
+}
+
+func setUpForIdempotencyKeyService(t *testing.T) (*gomock.Controller, MongoIdempotencyKeyService,
+	*mocks.MockMongoCollectionInterface, *mocks.MockMongoDatabaseInterface) {
+	ctrl := gomock.NewController(t)
+
+	mockCollection := mocks.NewMockMongoCollectionInterface(ctrl)
+	mockDatabase := mocks.NewMockMongoDatabaseInterface(ctrl)
+
+	svc := MongoIdempotencyKeyService{
+		db:             mockDatabase,
+		CollectionName: "idempotency_keys",
+	}
+	return ctrl, svc, mockCollection, mockDatabase
+}
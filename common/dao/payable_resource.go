@@ -0,0 +1,47 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+)
+
+// The payment statuses of a payable resource that can no longer be paid. The payment statuses of
+// penalty-payment-api-core are only pending and paid.
+const (
+	// PaymentStatusExpired is the payment status of a pending payable resource that was not paid before it expired
+	PaymentStatusExpired = "expired"
+	// PaymentStatusCancelled is the payment status of a pending payable resource that was cancelled by its creator
+	// or an internal API key
+	PaymentStatusCancelled = "cancelled"
+)
+
+// ErrPenaltyInPendingPayableResource is returned when a payable resource cannot be created because one of its
+// penalties is in another pending payable resource of the customer
+var ErrPenaltyInPendingPayableResource = errors.New("penalty in a pending payable resource")
+
+// PayableResourceQuery filters and paginates payable resources, the zero value of a field does not filter.
+// The created by email is matched case-insensitively.
+type PayableResourceQuery struct {
+	CustomerCode     string
+	CreatedByID      string
+	CreatedByEmail   string
+	PaymentReference string
+	PenaltyRef       string
+	Statuses         []string
+	CreatedFrom      time.Time
+	CreatedBefore    time.Time
+	StartIndex       int
+	ItemsPerPage     int
+}
+
+// PayableResourceRecordDao is a payable resource with the fields stored in its document that are not part of the
+// penalty-payment-api-core model
+type PayableResourceRecordDao struct {
+	models.PayableResourceDao `bson:",inline"`
+	E5CommandError            string     `bson:"e5_command_error,omitempty"`
+	E5ProcessedAt             *time.Time `bson:"e5_processed_at,omitempty"`
+	PaymentStartedAt          *time.Time `bson:"payment_started_at,omitempty"`
+	PendingPenaltyRefs        []string   `bson:"pending_penalty_refs,omitempty"`
+}
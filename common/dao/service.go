@@ -1,6 +1,8 @@
 package dao
 
 import (
+	"time"
+
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/e5"
 	"github.com/companieshouse/penalty-payment-api/common/interfaces"
@@ -9,14 +11,36 @@ import (
 
 // PayableResourceDaoService interface declares how to interact with the persistence layer regardless of underlying technology
 type PayableResourceDaoService interface {
-	// CreatePayableResource will persist a newly created resource
+	// CreatePayableResource will persist a newly created resource, ErrPenaltyInPendingPayableResource is returned
+	// if any of its penalties is in another pending resource of the customer
 	CreatePayableResource(dao *models.PayableResourceDao, requestId string) error
 	// GetPayableResource will find a single payable resource with the given customerCode and payableRef
 	GetPayableResource(customerCode, payableRef string, requestId string) (*models.PayableResourceDao, error)
+	// GetPaidPayableResources will find the payable resources of the given customerCode that have been paid
+	GetPaidPayableResources(customerCode string, requestId string) ([]models.PayableResourceDao, error)
 	// UpdatePaymentDetails will update the resource with changed values
 	UpdatePaymentDetails(dao *models.PayableResourceDao, requestId string) error
 	// SaveE5Error stored which command to E5 failed e.g. create, authorise or confirm
 	SaveE5Error(customerCode, payableRef string, requestId string, action e5.Action) error
+	// SaveE5Processed will record when all the commands to E5 for the resource succeeded
+	SaveE5Processed(customerCode, payableRef string, processedAt time.Time, requestId string) error
+	// GetPendingPayableResources will find the pending payable resources of the given customerCode created after
+	// the given time that are for any of the penaltyRefs
+	GetPendingPayableResources(customerCode string, penaltyRefs []string, createdAfter time.Time, requestId string) ([]models.PayableResourceDao, error)
+	// GetPayableResourceInPayment will find a pending payable resource of the given customerCode for any of the
+	// penaltyRefs whose payment was started after the given time, nil is returned if there is none
+	GetPayableResourceInPayment(customerCode string, penaltyRefs []string, startedAfter time.Time, requestId string) (*models.PayableResourceDao, error)
+	// SetPaymentStarted will record when the payments platform started taking payment for the resource
+	SetPaymentStarted(customerCode, payableRef string, startedAt time.Time, requestId string) error
+	// ExpirePayableResource will mark the resource as expired if it is pending, was created before createdBefore
+	// and its payment was not started after paymentStartedBefore, reporting whether it was expired
+	ExpirePayableResource(customerCode, payableRef string, createdBefore, paymentStartedBefore time.Time, requestId string) (bool, error)
+	// CancelPayableResource will mark the resource as cancelled by cancelledBy if it is pending and its payment was
+	// not started after paymentStartedBefore, reporting whether it was cancelled
+	CancelPayableResource(customerCode, payableRef, cancelledBy string, paymentStartedBefore time.Time, requestId string) (bool, error)
+	// ExpirePayableResources will mark all the resources that ExpirePayableResource would expire as expired,
+	// returning how many were expired
+	ExpirePayableResources(createdBefore, paymentStartedBefore time.Time, requestId string) (int64, error)
 	// Shutdown can be called to clean up any open resources that the service may be holding on to.
 	Shutdown()
 }
@@ -31,6 +55,27 @@ func NewPayableResourcesDaoService(mongoClientProvider interfaces.MongoClientPro
 	}
 }
 
+// PayableResourceSearchDaoService interface declares how to search the persisted payable resources regardless
+// of underlying technology
+type PayableResourceSearchDaoService interface {
+	// SearchPayableResources will find the page of payable resources that match the query, newest first, and
+	// how many match it in total
+	SearchPayableResources(query PayableResourceQuery, requestId string) ([]PayableResourceRecordDao, int64, error)
+
+	// CreateIndexes will create the indexes used to search payable resources if they do not already exist
+	CreateIndexes(requestId string) error
+}
+
+// NewPayableResourceSearchDaoService will create a new instance of the PayableResourceSearchDaoService interface
+// for the payable resources collection
+func NewPayableResourceSearchDaoService(mongoClientProvider interfaces.MongoClientProvider, cfg *config.Config) PayableResourceSearchDaoService {
+	return &MongoPayableResourceService{
+		mongoClientProvider: mongoClientProvider,
+		db:                  &MongoDatabaseWrapper{db: mongoClientProvider.Database(cfg.Database)},
+		CollectionName:      cfg.PayableResourcesCollection,
+	}
+}
+
 // AccountPenaltiesDaoService interface declares how to interact with the persistence layer
 // regardless of underlying technology
 type AccountPenaltiesDaoService interface {
@@ -38,6 +83,10 @@ type AccountPenaltiesDaoService interface {
 	CreateAccountPenalties(dao *models.AccountPenaltiesDao, requestId string) error
 	// GetAccountPenalties will find the account penalties for a given customerCode and companyCode
 	GetAccountPenalties(customerCode string, companyCode string, requestId string) (*models.AccountPenaltiesDao, error)
+	// GetAccountPenaltiesByPenaltyRef will find the account penalties for a given companyCode that contain the penaltyRef
+	GetAccountPenaltiesByPenaltyRef(companyCode string, penaltyRef string, requestId string) (*models.AccountPenaltiesDao, error)
+	// CreateIndexes will create the indexes used to look up account penalties if they do not already exist
+	CreateIndexes(requestId string) error
 	// UpdateAccountPenaltyAsPaid will update a transactions as paid for a given customerCode, companyCode and penaltyRef
 	UpdateAccountPenaltyAsPaid(customerCode string, companyCode string, penaltyRef string, requestId string) error
 	// UpdateAccountPenalties will update the created_at, closed_at and data fields of an existing document
@@ -53,3 +102,50 @@ func NewAccountPenaltiesDaoService(mongoClientProvider interfaces.MongoClientPro
 		CollectionName:      cfg.AccountPenaltiesCollection,
 	}
 }
+
+// MaintenanceWindowDaoService interface declares how to interact with the persistence layer for
+// maintenance windows regardless of underlying technology
+type MaintenanceWindowDaoService interface {
+	// CreateMaintenanceWindow will persist a newly created maintenance window
+	CreateMaintenanceWindow(dao *MaintenanceWindowDao, requestId string) error
+	// GetMaintenanceWindows will find the maintenance windows that end after the given time, ordered by start time
+	GetMaintenanceWindows(endAfter time.Time, requestId string) ([]MaintenanceWindowDao, error)
+	// CancelMaintenanceWindow will mark a maintenance window that has not already been cancelled as cancelled
+	CancelMaintenanceWindow(id, cancelledBy string, requestId string) error
+}
+
+// NewMaintenanceWindowDaoService will create a new instance of the MaintenanceWindowDaoService interface.
+// All details about its implementation and the database driver will be hidden from outside of this package
+func NewMaintenanceWindowDaoService(mongoClientProvider interfaces.MongoClientProvider, cfg *config.Config) MaintenanceWindowDaoService {
+	return &MongoMaintenanceWindowService{
+		db:             &MongoDatabaseWrapper{db: mongoClientProvider.Database(cfg.Database)},
+		CollectionName: cfg.MaintenanceWindowsCollection,
+	}
+}
+
+// IdempotencyKeyDaoService interface declares how to interact with the persistence layer for idempotency
+// keys regardless of underlying technology
+type IdempotencyKeyDaoService interface {
+	// ReserveIdempotencyKey will persist a new idempotency key, or replace one that has expired. It returns
+	// ErrIdempotencyKeyInUse if the key has been used by the same user for the same customer and has not expired.
+	ReserveIdempotencyKey(dao *IdempotencyKeyDao, requestId string) error
+	// GetIdempotencyKey will find the idempotency key used by the user for the customer that has not expired
+	GetIdempotencyKey(key, customerCode, createdBy string, requestId string) (*IdempotencyKeyDao, error)
+	// CompleteIdempotencyKey will record the payable resource created by the request the key was sent with
+	CompleteIdempotencyKey(dao *IdempotencyKeyDao, requestId string) error
+	// ReleaseIdempotencyKey will delete an idempotency key whose request did not create a payable resource,
+	// so that the request can be retried with the same key
+	ReleaseIdempotencyKey(dao *IdempotencyKeyDao, requestId string) error
+	// CreateIndexes will create the unique and time to live indexes of the idempotency keys if they do not
+	// already exist
+	CreateIndexes(requestId string) error
+}
+
+// NewIdempotencyKeyDaoService will create a new instance of the IdempotencyKeyDaoService interface.
+// All details about its implementation and the database driver will be hidden from outside of this package
+func NewIdempotencyKeyDaoService(mongoClientProvider interfaces.MongoClientProvider, cfg *config.Config) IdempotencyKeyDaoService {
+	return &MongoIdempotencyKeyService{
+		db:             &MongoDatabaseWrapper{db: mongoClientProvider.Database(cfg.Database)},
+		CollectionName: cfg.IdempotencyKeysCollection,
+	}
+}
@@ -48,4 +48,32 @@ func TestUnitService(t *testing.T) {
 		apDaoService := NewAccountPenaltiesDaoService(mockMongoClientProvider, cfg)
 		So(apDaoService, ShouldNotBeNil)
 	})
+
+	Convey("successful creation of new maintenance window dao service", t, func() {
+		mockMongoClientProvider := mocks.NewMockMongoClientProvider(ctrl)
+		mockMongoClientProvider.EXPECT().Database("test").Return(mockDatabase)
+
+		cfg := &config.Config{
+			MongoDBURL:                   dbUrl,
+			Database:                     db,
+			MaintenanceWindowsCollection: "maintenance_windows",
+		}
+
+		mwDaoService := NewMaintenanceWindowDaoService(mockMongoClientProvider, cfg)
+		So(mwDaoService, ShouldNotBeNil)
+	})
+
+	Convey("successful creation of new idempotency key dao service", t, func() {
+		mockMongoClientProvider := mocks.NewMockMongoClientProvider(ctrl)
+		mockMongoClientProvider.EXPECT().Database("test").Return(mockDatabase)
+
+		cfg := &config.Config{
+			MongoDBURL:                dbUrl,
+			Database:                  db,
+			IdempotencyKeysCollection: "idempotency_keys",
+		}
+
+		ikDaoService := NewIdempotencyKeyDaoService(mockMongoClientProvider, cfg)
+		So(ikDaoService, ShouldNotBeNil)
+	})
 }
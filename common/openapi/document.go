@@ -0,0 +1,222 @@
+// Package openapi validates requests and responses against the OpenAPI document of the API
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Document is the part of an OpenAPI 3 document needed to validate requests and responses
+type Document struct {
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components Components           `yaml:"components"`
+}
+
+// PathItem holds the operations of a path
+type PathItem struct {
+	Get        *Operation   `yaml:"get"`
+	Post       *Operation   `yaml:"post"`
+	Put        *Operation   `yaml:"put"`
+	Patch      *Operation   `yaml:"patch"`
+	Delete     *Operation   `yaml:"delete"`
+	Parameters []*Parameter `yaml:"parameters"`
+}
+
+// Operation is a method of a path
+type Operation struct {
+	OperationID string               `yaml:"operationId"`
+	Parameters  []*Parameter         `yaml:"parameters"`
+	RequestBody *RequestBody         `yaml:"requestBody"`
+	Responses   map[string]*Response `yaml:"responses"`
+}
+
+// Parameter is a path, query or header parameter of an operation
+type Parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+}
+
+// RequestBody is the body of a request, by media type
+type RequestBody struct {
+	Required bool                  `yaml:"required"`
+	Content  map[string]*MediaType `yaml:"content"`
+}
+
+// Response is the body of a response, by media type
+type Response struct {
+	Content map[string]*MediaType `yaml:"content"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+// Components holds the schemas and parameters that can be referenced
+type Components struct {
+	Schemas    map[string]*Schema    `yaml:"schemas"`
+	Parameters map[string]*Parameter `yaml:"parameters"`
+}
+
+// Route is a method and path template of an operation
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Load parses an OpenAPI document, checking that every reference in it can be resolved
+func Load(data []byte) (*Document, error) {
+	var document Document
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("error parsing OpenAPI document: %v", err)
+	}
+	if len(document.Paths) == 0 {
+		return nil, fmt.Errorf("OpenAPI document has no paths")
+	}
+	if err := document.checkReferences(); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (p *PathItem) operations() map[string]*Operation {
+	return map[string]*Operation{
+		http.MethodGet:    p.Get,
+		http.MethodPost:   p.Post,
+		http.MethodPut:    p.Put,
+		http.MethodPatch:  p.Patch,
+		http.MethodDelete: p.Delete,
+	}
+}
+
+// Routes returns the method and path template of every operation, ordered by path then method
+func (d *Document) Routes() []Route {
+	var routes []Route
+	for path, pathItem := range d.Paths {
+		for method, operation := range pathItem.operations() {
+			if operation != nil {
+				routes = append(routes, Route{Method: method, Path: path})
+			}
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Operation returns the operation of the method and path template, which is the path a route is registered
+// with rather than the path of a request
+func (d *Document) Operation(method, pathTemplate string) (*Operation, bool) {
+	pathItem, ok := d.Paths[pathTemplate]
+	if !ok {
+		return nil, false
+	}
+	operation := pathItem.operations()[strings.ToUpper(method)]
+	return operation, operation != nil
+}
+
+// parameters returns the parameters of the operation with references resolved, operation parameters
+// override the path parameters of the same name
+func (d *Document) parameters(operation *Operation, pathParameters []*Parameter) []*Parameter {
+	var parameters []*Parameter
+	seen := map[string]bool{}
+	for _, parameter := range append(append([]*Parameter{}, operation.Parameters...), pathParameters...) {
+		parameter = d.resolveParameter(parameter)
+		if key := parameter.In + ":" + parameter.Name; !seen[key] {
+			seen[key] = true
+			parameters = append(parameters, parameter)
+		}
+	}
+	return parameters
+}
+
+func (d *Document) resolveParameter(parameter *Parameter) *Parameter {
+	if parameter.Ref == "" {
+		return parameter
+	}
+	return d.Components.Parameters[strings.TrimPrefix(parameter.Ref, "#/components/parameters/")]
+}
+
+func (d *Document) resolveSchema(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// checkReferences fails if a parameter or schema references a component that does not exist
+func (d *Document) checkReferences() error {
+	for _, parameter := range d.Components.Parameters {
+		if err := d.checkSchema(parameter.Schema); err != nil {
+			return err
+		}
+	}
+	for _, schema := range d.Components.Schemas {
+		if err := d.checkSchema(schema); err != nil {
+			return err
+		}
+	}
+
+	for path, pathItem := range d.Paths {
+		for method, operation := range pathItem.operations() {
+			if operation == nil {
+				continue
+			}
+			for _, parameter := range append(append([]*Parameter{}, operation.Parameters...), pathItem.Parameters...) {
+				if d.resolveParameter(parameter) == nil {
+					return fmt.Errorf("%s %s references parameter %s that does not exist", method, path, parameter.Ref)
+				}
+				if err := d.checkSchema(parameter.Schema); err != nil {
+					return err
+				}
+			}
+			if operation.RequestBody != nil {
+				for _, mediaType := range operation.RequestBody.Content {
+					if err := d.checkSchema(mediaType.Schema); err != nil {
+						return err
+					}
+				}
+			}
+			for _, response := range operation.Responses {
+				for _, mediaType := range response.Content {
+					if err := d.checkSchema(mediaType.Schema); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Document) checkSchema(schema *Schema) error {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		if d.resolveSchema(schema) == nil {
+			return fmt.Errorf("schema %s does not exist", schema.Ref)
+		}
+		return nil
+	}
+	for _, property := range schema.Properties {
+		if err := d.checkSchema(property); err != nil {
+			return err
+		}
+	}
+	if err := d.checkSchema(schema.Items); err != nil {
+		return err
+	}
+	return d.checkSchema(schema.AdditionalProperties.Schema)
+}
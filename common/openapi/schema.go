@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of an OpenAPI schema object that values are validated against. Formats other than
+// date and date-time are treated as annotations.
+type Schema struct {
+	Ref                  string               `yaml:"$ref"`
+	Type                 string               `yaml:"type"`
+	Format               string               `yaml:"format"`
+	Enum                 []interface{}        `yaml:"enum"`
+	Nullable             bool                 `yaml:"nullable"`
+	Required             []string             `yaml:"required"`
+	Properties           map[string]*Schema   `yaml:"properties"`
+	AdditionalProperties AdditionalProperties `yaml:"additionalProperties"`
+	Items                *Schema              `yaml:"items"`
+	MinItems             *int                 `yaml:"minItems"`
+	MaxItems             *int                 `yaml:"maxItems"`
+	MinLength            *int                 `yaml:"minLength"`
+	MaxLength            *int                 `yaml:"maxLength"`
+	Pattern              string               `yaml:"pattern"`
+	Minimum              *float64             `yaml:"minimum"`
+	Maximum              *float64             `yaml:"maximum"`
+}
+
+// AdditionalProperties is whether an object can have properties that are not listed in its schema, and the
+// schema of those properties. Unlike the OpenAPI default, unlisted properties are only allowed if an object
+// has no properties or additionalProperties allows them, so that unknown fields are rejected.
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalYAML reads additionalProperties as either a boolean or a schema
+func (a *AdditionalProperties) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var allowed bool
+	if err := unmarshal(&allowed); err == nil {
+		a.Allowed = allowed
+		return nil
+	}
+
+	var schema Schema
+	if err := unmarshal(&schema); err != nil {
+		return err
+	}
+	a.Allowed = true
+	a.Schema = &schema
+	return nil
+}
+
+// validateValue validates a value decoded from json against the schema, field is the path of the value in
+// the body, e.g. transactions[0].amount
+func (d *Document) validateValue(schema *Schema, value interface{}, field string) error {
+	schema = d.resolveSchema(schema)
+	if schema == nil {
+		return nil
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return invalid(field, "must not be null")
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(enumValue interface{}) bool {
+		return fmt.Sprint(enumValue) == fmt.Sprint(value)
+	}) {
+		allowed := make([]string, 0, len(schema.Enum))
+		for _, enumValue := range schema.Enum {
+			allowed = append(allowed, fmt.Sprint(enumValue))
+		}
+		return invalid(field, "must be one of "+strings.Join(allowed, ", "))
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return invalid(field, "must be an object")
+		}
+		return d.validateObject(schema, object, field)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return invalid(field, "must be an array")
+		}
+		return d.validateArray(schema, array, field)
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return invalid(field, "must be a string")
+		}
+		return validateString(schema, s, field)
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return invalid(field, "must be a whole number")
+		}
+		return validateNumber(schema, n, field)
+	case "number":
+		n, ok := value.(float64)
+		if !ok {
+			return invalid(field, "must be a number")
+		}
+		return validateNumber(schema, n, field)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid(field, "must be true or false")
+		}
+	}
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, object map[string]interface{}, field string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return invalid(childField(field, name), "is required")
+		}
+	}
+
+	// validate in name order so the same error is reported each time
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertySchema, ok := schema.Properties[name]
+		if !ok {
+			if schema.Properties != nil && !schema.AdditionalProperties.Allowed {
+				return invalid(childField(field, name), "is not a known field")
+			}
+			propertySchema = schema.AdditionalProperties.Schema
+		}
+		if err := d.validateValue(propertySchema, object[name], childField(field, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Document) validateArray(schema *Schema, array []interface{}, field string) error {
+	if schema.MinItems != nil && len(array) < *schema.MinItems {
+		return invalid(field, fmt.Sprintf("must have at least %d items", *schema.MinItems))
+	}
+	if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+		return invalid(field, fmt.Sprintf("must have at most %d items", *schema.MaxItems))
+	}
+	for i, item := range array {
+		if err := d.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateString(schema *Schema, s, field string) error {
+	length := len([]rune(s))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return invalid(field, fmt.Sprintf("must be at least %d characters", *schema.MinLength))
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return invalid(field, fmt.Sprintf("must be at most %d characters", *schema.MaxLength))
+	}
+	if schema.Pattern != "" {
+		if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(s) {
+			return invalid(field, "must match "+schema.Pattern)
+		}
+	}
+
+	switch schema.Format {
+	case "date":
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return invalid(field, "must be a date in the format YYYY-MM-DD")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return invalid(field, "must be an RFC 3339 date-time")
+		}
+	}
+	return nil
+}
+
+func validateNumber(schema *Schema, n float64, field string) error {
+	if schema.Minimum != nil && n < *schema.Minimum {
+		return invalid(field, fmt.Sprintf("must not be less than %v", *schema.Minimum))
+	}
+	if schema.Maximum != nil && n > *schema.Maximum {
+		return invalid(field, fmt.Sprintf("must not be more than %v", *schema.Maximum))
+	}
+	return nil
+}
+
+func childField(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
@@ -0,0 +1,198 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Where a validation error was found
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InBody   = "body"
+)
+
+// ValidationError is a value that does not match the OpenAPI document, Field is the name of the parameter
+// or the path of the value in the body
+type ValidationError struct {
+	In      string
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.In + " " + e.Message
+	}
+	return e.Field + " " + e.Message
+}
+
+// UnsupportedMediaTypeError is a request body sent with a media type that the operation does not accept
+type UnsupportedMediaTypeError struct {
+	ContentType string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("content type %q is not supported", e.ContentType)
+}
+
+func invalid(field, message string) *ValidationError {
+	return &ValidationError{In: InBody, Field: field, Message: message}
+}
+
+// ValidateParameters validates the path parameters, query parameters and headers of a request against the
+// parameters of the operation. Query parameters and headers that are not in the document are ignored.
+func (d *Document) ValidateParameters(operation *Operation, pathTemplate string, pathParameters map[string]string,
+	query url.Values, header http.Header) error {
+	var pathItemParameters []*Parameter
+	if pathItem, ok := d.Paths[pathTemplate]; ok {
+		pathItemParameters = pathItem.Parameters
+	}
+
+	for _, parameter := range d.parameters(operation, pathItemParameters) {
+		var value string
+		var present bool
+		switch parameter.In {
+		case InPath:
+			value, present = pathParameters[parameter.Name]
+		case InQuery:
+			present = query.Has(parameter.Name)
+			value = query.Get(parameter.Name)
+		case InHeader:
+			value = header.Get(parameter.Name)
+			present = value != ""
+		default:
+			continue
+		}
+
+		if !present {
+			if parameter.Required {
+				return &ValidationError{In: parameter.In, Field: parameter.Name, Message: "is required"}
+			}
+			continue
+		}
+
+		if err := d.validateParameter(parameter, value); err != nil {
+			err.In = parameter.In
+			err.Field = parameter.Name
+			return err
+		}
+	}
+	return nil
+}
+
+// validateParameter converts the parameter to the type of its schema before validating it
+func (d *Document) validateParameter(parameter *Parameter, value string) *ValidationError {
+	schema := d.resolveSchema(parameter.Schema)
+	if schema == nil {
+		return nil
+	}
+
+	var converted interface{} = value
+	switch schema.Type {
+	case "integer", "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return invalid(parameter.Name, "must be a number")
+		}
+		converted = n
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid(parameter.Name, "must be true or false")
+		}
+		converted = b
+	}
+
+	if err := d.validateValue(schema, converted, parameter.Name); err != nil {
+		return err.(*ValidationError)
+	}
+	return nil
+}
+
+// ValidateRequestBody validates a json request body against the request body of the operation. Bodies of
+// other media types are not validated.
+func (d *Document) ValidateRequestBody(operation *Operation, contentType string, body []byte) error {
+	if operation.RequestBody == nil {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if operation.RequestBody.Required {
+			return &ValidationError{In: InBody, Message: "is required"}
+		}
+		return nil
+	}
+
+	mediaType, err := requestMediaType(operation.RequestBody.Content, contentType)
+	if err != nil || mediaType == nil {
+		return err
+	}
+	return d.validateJSON(mediaType.Schema, body)
+}
+
+// ValidateResponse validates a json response body against the response of the operation for the status
+func (d *Document) ValidateResponse(operation *Operation, status int, contentType string, body []byte) error {
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		response, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return fmt.Errorf("status %d is not documented", status)
+	}
+	if len(response.Content) == 0 || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	mediaTypeName, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("content type %q is not valid", contentType)
+	}
+	mediaType, ok := response.Content[mediaTypeName]
+	if !ok {
+		return fmt.Errorf("content type %s is not documented for status %d", mediaTypeName, status)
+	}
+	if mediaTypeName != "application/json" {
+		return nil
+	}
+	return d.validateJSON(mediaType.Schema, body)
+}
+
+func (d *Document) validateJSON(schema *Schema, body []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return &ValidationError{In: InBody, Message: "is not valid json"}
+	}
+	if err := d.validateValue(schema, value, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// requestMediaType returns the json media type of the content to validate a request body against. A body
+// sent without a content type is treated as json. Any other media type the operation does not accept is an
+// UnsupportedMediaTypeError, and one it does accept that is not json is not validated.
+func requestMediaType(content map[string]*MediaType, contentType string) (*MediaType, error) {
+	if contentType == "" {
+		return content["application/json"], nil
+	}
+	mediaTypeName, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, &UnsupportedMediaTypeError{ContentType: contentType}
+	}
+	if mediaTypeName == "application/json" || strings.HasSuffix(mediaTypeName, "+json") {
+		if mediaType, ok := content["application/json"]; ok {
+			return mediaType, nil
+		}
+	}
+	if _, ok := content[mediaTypeName]; !ok {
+		return nil, &UnsupportedMediaTypeError{ContentType: mediaTypeName}
+	}
+	return nil, nil
+}
@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api/spec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testDocument = `
+openapi: 3.0.3
+paths:
+  /company/{customer_code}/penalties/{penalty_reference_type}:
+    get:
+      parameters:
+        - name: customer_code
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: penalty_reference_type
+          in: path
+          required: true
+          schema:
+            type: string
+            enum:
+              - LATE_FILING
+              - SANCTIONS
+        - $ref: '#/components/parameters/StartIndex'
+        - name: is_paid
+          in: query
+          schema:
+            type: boolean
+      responses:
+        "200":
+          description: The penalties
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Penalties'
+        "304":
+          description: Not modified
+  /company/{customer_code}/penalties/payable:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required:
+                - transactions
+              properties:
+                transactions:
+                  type: array
+                  minItems: 1
+                  items:
+                    type: object
+                    required:
+                      - penalty_ref
+                    properties:
+                      penalty_ref:
+                        type: string
+                      amount:
+                        type: number
+                      made_up_date:
+                        type: string
+                        format: date
+                metadata:
+                  type: object
+                  additionalProperties:
+                    type: string
+      responses:
+        "201":
+          description: Created
+components:
+  parameters:
+    StartIndex:
+      name: start_index
+      in: query
+      schema:
+        type: integer
+        minimum: 0
+  schemas:
+    Penalties:
+      type: object
+      properties:
+        total_results:
+          type: integer
+        items:
+          type: array
+          items:
+            type: object
+            properties:
+              id:
+                type: string
+`
+
+func TestUnitLoad(t *testing.T) {
+	Convey("The OpenAPI document of the API can be loaded", t, func() {
+		document, err := Load(spec.OpenAPI)
+		So(err, ShouldBeNil)
+		So(document.Routes(), ShouldNotBeEmpty)
+
+		_, ok := document.Operation(http.MethodPost, "/company/{customer_code}/penalties/payable")
+		So(ok, ShouldBeTrue)
+	})
+
+	Convey("A document with a reference that does not exist cannot be loaded", t, func() {
+		_, err := Load([]byte(`
+paths:
+  /healthcheck:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Missing'
+`))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "#/components/schemas/Missing")
+	})
+}
+
+func TestUnitValidateParameters(t *testing.T) {
+	document, err := Load([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pathTemplate := "/company/{customer_code}/penalties/{penalty_reference_type}"
+	operation, _ := document.Operation(http.MethodGet, pathTemplate)
+
+	validate := func(penaltyRefType, query string) *ValidationError {
+		values, _ := url.ParseQuery(query)
+		err := document.ValidateParameters(operation, pathTemplate,
+			map[string]string{"customer_code": "12345678", "penalty_reference_type": penaltyRefType}, values, http.Header{})
+		var validationErr *ValidationError
+		errors.As(err, &validationErr)
+		return validationErr
+	}
+
+	Convey("Valid parameters are accepted", t, func() {
+		So(validate("LATE_FILING", ""), ShouldBeNil)
+		So(validate("SANCTIONS", "start_index=10&is_paid=false&unknown=1"), ShouldBeNil)
+	})
+
+	Convey("Invalid parameters are rejected with the name of the parameter", t, func() {
+		err := validate("UNKNOWN", "")
+		So(err, ShouldNotBeNil)
+		So(err.In, ShouldEqual, InPath)
+		So(err.Field, ShouldEqual, "penalty_reference_type")
+		So(err.Error(), ShouldEqual, "penalty_reference_type must be one of LATE_FILING, SANCTIONS")
+
+		err = validate("LATE_FILING", "start_index=-1")
+		So(err, ShouldNotBeNil)
+		So(err.In, ShouldEqual, InQuery)
+		So(err.Field, ShouldEqual, "start_index")
+
+		err = validate("LATE_FILING", "start_index=1.5")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "start_index must be a whole number")
+
+		err = validate("LATE_FILING", "is_paid=maybe")
+		So(err, ShouldNotBeNil)
+		So(err.Field, ShouldEqual, "is_paid")
+	})
+}
+
+func TestUnitValidateRequestBody(t *testing.T) {
+	document, err := Load([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	operation, _ := document.Operation(http.MethodPost, "/company/{customer_code}/penalties/payable")
+
+	validate := func(body string) error {
+		return document.ValidateRequestBody(operation, "application/json", []byte(body))
+	}
+
+	Convey("A valid body is accepted", t, func() {
+		So(validate(`{"transactions":[{"penalty_ref":"A1234567","amount":150,"made_up_date":"2025-01-31"}]}`), ShouldBeNil)
+		So(validate(`{"transactions":[{"penalty_ref":"A1234567"}],"metadata":{"source":"web"}}`), ShouldBeNil)
+	})
+
+	Convey("An invalid body is rejected with the path of the field", t, func() {
+		testCases := []struct {
+			body    string
+			message string
+		}{
+			{body: ``, message: "body is required"},
+			{body: `{"transactions":`, message: "body is not valid json"},
+			{body: `[]`, message: "body must be an object"},
+			{body: `{}`, message: "transactions is required"},
+			{body: `{"transactions":[]}`, message: "transactions must have at least 1 items"},
+			{body: `{"transactions":[{"amount":150}]}`, message: "transactions[0].penalty_ref is required"},
+			{body: `{"transactions":[{"penalty_ref":"A1234567","amount":"150"}]}`, message: "transactions[0].amount must be a number"},
+			{body: `{"transactions":[{"penalty_ref":"A1234567","made_up_date":"31/01/2025"}]}`, message: "transactions[0].made_up_date must be a date in the format YYYY-MM-DD"},
+			{body: `{"transactions":[{"penalty_ref":"A1234567","is_dca":true}]}`, message: "transactions[0].is_dca is not a known field"},
+			{body: `{"transactions":[{"penalty_ref":"A1234567"}],"metadata":{"source":1}}`, message: "metadata.source must be a string"},
+		}
+
+		for _, tc := range testCases {
+			err := validate(tc.body)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, tc.message)
+		}
+	})
+
+	Convey("A json body without a content type is validated", t, func() {
+		So(document.ValidateRequestBody(operation, "", []byte(`{"transactions":[{"penalty_ref":"A1234567"}]}`)), ShouldBeNil)
+		So(document.ValidateRequestBody(operation, "", []byte(`{}`)), ShouldNotBeNil)
+	})
+
+	Convey("A body with a json suffixed content type is validated as json", t, func() {
+		So(document.ValidateRequestBody(operation, "application/merge-patch+json", []byte(`{}`)), ShouldNotBeNil)
+	})
+
+	Convey("A body with a media type the operation does not accept is rejected", t, func() {
+		for _, contentType := range []string{"text/plain", "application/x-www-form-urlencoded", "multipart/form-data; boundary=x", "invalid;;"} {
+			err := document.ValidateRequestBody(operation, contentType, []byte("transactions"))
+
+			var unsupportedErr *UnsupportedMediaTypeError
+			So(errors.As(err, &unsupportedErr), ShouldBeTrue)
+		}
+	})
+}
+
+func TestUnitValidateResponse(t *testing.T) {
+	document, err := Load([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	operation, _ := document.Operation(http.MethodGet, "/company/{customer_code}/penalties/{penalty_reference_type}")
+
+	Convey("A response matching the document is valid", t, func() {
+		So(document.ValidateResponse(operation, http.StatusOK, "application/json", []byte(`{"total_results":1,"items":[{"id":"A1234567"}]}`)), ShouldBeNil)
+		So(document.ValidateResponse(operation, http.StatusNotModified, "", nil), ShouldBeNil)
+	})
+
+	Convey("A response that does not match the document is reported", t, func() {
+		err := document.ValidateResponse(operation, http.StatusOK, "application/json", []byte(`{"total_results":1,"items":[{"id":1}]}`))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "items[0].id must be a string")
+
+		err = document.ValidateResponse(operation, http.StatusOK, "text/csv", []byte("id\nA1234567"))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "content type text/csv is not documented for status 200")
+
+		err = document.ValidateResponse(operation, http.StatusInternalServerError, "application/json", []byte(`{}`))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "status 500 is not documented")
+	})
+}
@@ -1,11 +1,14 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/constants"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api-core/validators"
 	"github.com/companieshouse/penalty-payment-api/common/dao"
@@ -18,6 +21,25 @@ var (
 	ErrAlreadyPaid = errors.New("the Penalty has already been paid")
 	// ErrPenaltyNotFound represents when the payable resource does not exist in the db
 	ErrPenaltyNotFound = errors.New("the Penalty does not exist")
+	// ErrPayableResourceExpired represents when the payable resource has expired
+	ErrPayableResourceExpired = errors.New("the payable resource has expired")
+	// ErrPaymentInProgress represents when the payments platform is taking payment for another payable resource
+	// of the penalty
+	ErrPaymentInProgress = errors.New("a payment for the Penalty is in progress")
+	// ErrInvalidPenaltyReference represents when a penalty reference does not match any penalty reference type
+	ErrInvalidPenaltyReference = errors.New("invalid penalty reference")
+)
+
+const (
+	// DefaultPendingPayableExpiry is how long a pending payable resource can be paid for if PENDING_PAYABLE_EXPIRY
+	// is not set
+	DefaultPendingPayableExpiry = 24 * time.Hour
+	// DefaultPendingPayableExpiryInterval is how often pending payable resources are expired if
+	// PENDING_PAYABLE_EXPIRY_INTERVAL is not set
+	DefaultPendingPayableExpiryInterval = 15 * time.Minute
+	// DefaultPaymentInProgressTimeout is how long the penalties of a payable resource are locked once the
+	// payments platform starts taking payment if PAYMENT_IN_PROGRESS_TIMEOUT is not set
+	DefaultPaymentInProgressTimeout = 90 * time.Minute
 )
 
 // PayableResourceService contains the DAO for db access
@@ -40,6 +62,13 @@ func (s *PayableResourceService) GetPayableResource(req *http.Request, customerC
 		return nil, NotFound, nil
 	}
 
+	// a pending payable resource is expired when it is read, so it is not paid before the expiry job has run
+	if err = s.expireIfPendingTooLong(payable, time.Now(), requestId); err != nil {
+		err = fmt.Errorf("error expiring payable resource in db: [%v]", err)
+		log.ErrorC(requestId, err)
+		return nil, Error, err
+	}
+
 	payableResource := transformers.PayableResourceDBToRequest(payable)
 	return payableResource, Success, nil
 }
@@ -74,3 +103,209 @@ func (s *PayableResourceService) UpdateAsPaid(resource models.PayableResource, p
 
 	return s.DAO.UpdatePaymentDetails(model, requestId)
 }
+
+// PendingPayableExpiry returns how long a pending payable resource can be paid for after it is created
+func (s *PayableResourceService) PendingPayableExpiry() time.Duration {
+	if s.Config == nil {
+		return DefaultPendingPayableExpiry
+	}
+	return parseDuration("PENDING_PAYABLE_EXPIRY", s.Config.PendingPayableExpiry, DefaultPendingPayableExpiry)
+}
+
+// PaymentInProgressTimeout returns how long the penalties of a payable resource are locked after the payments
+// platform starts taking payment for it
+func (s *PayableResourceService) PaymentInProgressTimeout() time.Duration {
+	if s.Config == nil {
+		return DefaultPaymentInProgressTimeout
+	}
+	return parseDuration("PAYMENT_IN_PROGRESS_TIMEOUT", s.Config.PaymentInProgressTimeout, DefaultPaymentInProgressTimeout)
+}
+
+// parseDuration parses the duration of the config value, the default is used if it is not set or is invalid
+func parseDuration(name, value string, defaultDuration time.Duration) time.Duration {
+	if value == "" {
+		return defaultDuration
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Error(fmt.Errorf("error parsing %s [%s], applying the default of %s: %v", name, value, defaultDuration, err))
+		return defaultDuration
+	}
+	return duration
+}
+
+// GetReusablePayableResource finds a pending payable resource that has not expired, created by the same user
+// for the same transactions of the customer, so that it is returned rather than another being created. Nil is
+// returned if there is none, after cancelling the pending payable resources of the user for other transactions of
+// the penalties, which the new payable resource replaces. ErrPaymentInProgress is returned if the payments platform
+// is taking payment for any of the penalties, or another user has a pending payable resource for any of them, as a
+// second payment could be taken.
+func (s *PayableResourceService) GetReusablePayableResource(request models.PayableRequest, penaltyDetailsMap *config.PenaltyDetailsMap,
+	now time.Time, requestId string) (*models.PayableResourceDao, error) {
+	var penaltyRefs []string
+	for _, transaction := range request.Transactions {
+		// the penalty references are used as field names in the queries, so only well formed references are used
+		if !penaltyDetailsMap.IsPenaltyReference(transaction.PenaltyRef) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPenaltyReference, transaction.PenaltyRef)
+		}
+		penaltyRefs = append(penaltyRefs, transaction.PenaltyRef)
+	}
+	logContext := log.Data{"customer_code": request.CustomerCode, "penalty_refs": penaltyRefs}
+
+	inPayment, err := s.DAO.GetPayableResourceInPayment(request.CustomerCode, penaltyRefs,
+		now.Add(-s.PaymentInProgressTimeout()), requestId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting payable resource in payment from db: [%v]", err)
+	}
+	if inPayment != nil {
+		log.InfoC(requestId, "payment in progress for payable resource", logContext, log.Data{"payable_ref": inPayment.PayableRef})
+		return nil, ErrPaymentInProgress
+	}
+
+	pending, err := s.DAO.GetPendingPayableResources(request.CustomerCode, penaltyRefs,
+		now.Add(-s.PendingPayableExpiry()), requestId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pending payable resources from db: [%v]", err)
+	}
+	for i := range pending {
+		if pending[i].Data.CreatedBy.ID != request.CreatedBy.ID {
+			log.InfoC(requestId, "penalty in pending payable resource of another user", logContext,
+				log.Data{"payable_ref": pending[i].PayableRef})
+			return nil, ErrPaymentInProgress
+		}
+	}
+	for i := range pending {
+		if hasSameTransactions(pending[i], request.Transactions) {
+			log.InfoC(requestId, "reusing pending payable resource", logContext, log.Data{"payable_ref": pending[i].PayableRef})
+			return &pending[i], nil
+		}
+	}
+	for _, replaced := range pending {
+		cancelled, err := s.DAO.CancelPayableResource(replaced.CustomerCode, replaced.PayableRef, request.CreatedBy.ID,
+			now.Add(-s.PaymentInProgressTimeout()), requestId)
+		if err != nil {
+			return nil, fmt.Errorf("error cancelling replaced payable resource in db: [%v]", err)
+		}
+		if !cancelled {
+			return nil, ErrPaymentInProgress
+		}
+		log.InfoC(requestId, "cancelled replaced pending payable resource", logContext, log.Data{"payable_ref": replaced.PayableRef})
+	}
+	return nil, nil
+}
+
+// CreatePayableResource stores the new payable resource. A penalty of the customer can only be in one pending
+// payable resource, so if it is in one that has expired but is not yet marked as expired, the expired pending
+// payable resources are marked as expired and it is stored again. ErrPaymentInProgress is returned if a penalty
+// is still in another pending payable resource.
+func (s *PayableResourceService) CreatePayableResource(payable *models.PayableResourceDao, now time.Time, requestId string) error {
+	err := s.DAO.CreatePayableResource(payable, requestId)
+	if !errors.Is(err, dao.ErrPenaltyInPendingPayableResource) {
+		return err
+	}
+
+	if _, err := s.ExpirePendingPayableResources(now, requestId); err != nil {
+		return fmt.Errorf("error expiring pending payable resources in db: [%v]", err)
+	}
+	err = s.DAO.CreatePayableResource(payable, requestId)
+	if errors.Is(err, dao.ErrPenaltyInPendingPayableResource) {
+		return ErrPaymentInProgress
+	}
+	return err
+}
+
+// hasSameTransactions reports whether the payable resource is for exactly the penalties and amounts of the transactions
+func hasSameTransactions(payable models.PayableResourceDao, transactions []models.TransactionItem) bool {
+	if len(payable.Data.Transactions) != len(transactions) {
+		return false
+	}
+	for _, transaction := range transactions {
+		payableTransaction, ok := payable.Data.Transactions[transaction.PenaltyRef]
+		if !ok || payableTransaction.Amount != transaction.Amount {
+			return false
+		}
+	}
+	return true
+}
+
+// RecordPaymentStarted records that the payments platform has started taking payment for the pending payable
+// resource, which locks its penalties for the payment in progress timeout
+func (s *PayableResourceService) RecordPaymentStarted(resource *models.PayableResource, now time.Time, requestId string) error {
+	if resource.Payment.Status != constants.Pending.String() {
+		return nil
+	}
+	return s.DAO.SetPaymentStarted(resource.CustomerCode, resource.PayableRef, now, requestId)
+}
+
+// expireIfPendingTooLong marks the payable resource as expired if it has been pending for longer than the pending
+// payable expiry, unless the payments platform is taking payment for it
+func (s *PayableResourceService) expireIfPendingTooLong(payable *models.PayableResourceDao, now time.Time, requestId string) error {
+	createdBefore := now.Add(-s.PendingPayableExpiry())
+	if payable.Data.Payment.Status != constants.Pending.String() || payable.Data.CreatedAt == nil ||
+		payable.Data.CreatedAt.After(createdBefore) {
+		return nil
+	}
+
+	expired, err := s.DAO.ExpirePayableResource(payable.CustomerCode, payable.PayableRef, createdBefore,
+		now.Add(-s.PaymentInProgressTimeout()), requestId)
+	if err != nil {
+		return err
+	}
+	if expired {
+		payable.Data.Payment.Status = dao.PaymentStatusExpired
+	}
+	return nil
+}
+
+// ExpirePendingPayableResources marks the payable resources that have been pending for longer than the pending
+// payable expiry as expired, except those the payments platform is taking payment for
+func (s *PayableResourceService) ExpirePendingPayableResources(now time.Time, requestId string) (int64, error) {
+	return s.DAO.ExpirePayableResources(now.Add(-s.PendingPayableExpiry()), now.Add(-s.PaymentInProgressTimeout()), requestId)
+}
+
+// WatchPendingPayableResources expires pending payable resources every interval, until the context is cancelled
+func (s *PayableResourceService) WatchPendingPayableResources(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := s.ExpirePendingPayableResources(now, "")
+			if err != nil {
+				log.Error(fmt.Errorf("error expiring pending payable resources: %v", err))
+				continue
+			}
+			if expired > 0 {
+				log.Info("pending payable resources expired", log.Data{"expired": expired})
+			}
+		}
+	}
+}
+
+// CancelPayableResource cancels the pending payable resource so that it can no longer be paid. Cancelling a
+// cancelled resource does nothing. ErrAlreadyPaid is returned for a paid resource, ErrPayableResourceExpired for
+// an expired one, and ErrPaymentInProgress if the payments platform is taking payment for it.
+func (s *PayableResourceService) CancelPayableResource(resource *models.PayableResource, cancelledBy string,
+	now time.Time, requestId string) error {
+	switch resource.Payment.Status {
+	case constants.Paid.String():
+		return ErrAlreadyPaid
+	case dao.PaymentStatusExpired:
+		return ErrPayableResourceExpired
+	case dao.PaymentStatusCancelled:
+		return nil
+	}
+
+	cancelled, err := s.DAO.CancelPayableResource(resource.CustomerCode, resource.PayableRef, cancelledBy,
+		now.Add(-s.PaymentInProgressTimeout()), requestId)
+	if err != nil {
+		return fmt.Errorf("error cancelling payable resource in db: [%v]", err)
+	}
+	if !cancelled {
+		return ErrPaymentInProgress
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,9 +10,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/penalty-payment-api-core/constants"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api-core/validators"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
 	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/mocks"
 	"github.com/golang/mock/gomock"
@@ -46,7 +49,7 @@ func createTestGetRequest(requestId string) *http.Request {
 
 func buildTestPayableResourceDao(size int, customerCode string, payableRef string, status string) *models.PayableResourceDao {
 	transactions := map[string]models.TransactionDao{
-		"abcd": {Amount: 5},
+		"A1234567": {Amount: 5},
 	}
 	totalAmount := 5
 	if size > 1 {
@@ -150,6 +153,89 @@ func TestUnitGetPayableResource(t *testing.T) {
 
 		testGetPayableResource(mockPayableResourceSvc, payableResourceDao, customerCode, validPayableRef, Success, requestId)
 	})
+
+	Convey("Get Payable resource - pending for longer than the expiry", t, func() {
+		createdAt := time.Now().Add(-DefaultPendingPayableExpiry - time.Minute)
+		req := createTestGetRequest(requestId)
+
+		Convey("is expired", func() {
+			payableResourceDao := buildTestPayableResourceDao(1, customerCode, validPayableRef, "pending")
+			payableResourceDao.Data.CreatedAt = &createdAt
+			mockPrDaoSvc.EXPECT().GetPayableResource(customerCode, validPayableRef, requestId).Return(payableResourceDao, nil)
+			mockPrDaoSvc.EXPECT().ExpirePayableResource(customerCode, validPayableRef, gomock.Any(), gomock.Any(), requestId).Return(true, nil)
+
+			payableResource, status, err := mockPayableResourceSvc.GetPayableResource(req, customerCode, validPayableRef)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, Success)
+			So(payableResource.Payment.Status, ShouldEqual, dao.PaymentStatusExpired)
+		})
+
+		Convey("is not expired while a payment is in progress", func() {
+			payableResourceDao := buildTestPayableResourceDao(1, customerCode, validPayableRef, "pending")
+			payableResourceDao.Data.CreatedAt = &createdAt
+			mockPrDaoSvc.EXPECT().GetPayableResource(customerCode, validPayableRef, requestId).Return(payableResourceDao, nil)
+			mockPrDaoSvc.EXPECT().ExpirePayableResource(customerCode, validPayableRef, gomock.Any(), gomock.Any(), requestId).Return(false, nil)
+
+			payableResource, status, err := mockPayableResourceSvc.GetPayableResource(req, customerCode, validPayableRef)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, Success)
+			So(payableResource.Payment.Status, ShouldEqual, "pending")
+		})
+
+		Convey("is an error if it cannot be expired", func() {
+			payableResourceDao := buildTestPayableResourceDao(1, customerCode, validPayableRef, "pending")
+			payableResourceDao.Data.CreatedAt = &createdAt
+			mockPrDaoSvc.EXPECT().GetPayableResource(customerCode, validPayableRef, requestId).Return(payableResourceDao, nil)
+			mockPrDaoSvc.EXPECT().ExpirePayableResource(customerCode, validPayableRef, gomock.Any(), gomock.Any(), requestId).
+				Return(false, errors.New("error"))
+
+			payableResource, status, err := mockPayableResourceSvc.GetPayableResource(req, customerCode, validPayableRef)
+
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, Error)
+			So(payableResource, ShouldBeNil)
+		})
+	})
+}
+
+func TestUnitPayableResourceService_ExpirePendingPayableResources(t *testing.T) {
+	Convey("PayableResourceService.ExpirePendingPayableResources", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		svc := &PayableResourceService{DAO: mockPrDaoSvc}
+		now := time.Now()
+
+		Convey("Payable resources pending for longer than the expiry are expired unless a payment is in progress", func() {
+			mockPrDaoSvc.EXPECT().ExpirePayableResources(now.Add(-DefaultPendingPayableExpiry),
+				now.Add(-DefaultPaymentInProgressTimeout), requestId).Return(int64(2), nil)
+
+			expired, err := svc.ExpirePendingPayableResources(now, requestId)
+
+			So(err, ShouldBeNil)
+			So(expired, ShouldEqual, int64(2))
+		})
+
+		Convey("Pending payable resources are expired by the watch until it is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			mockPrDaoSvc.EXPECT().ExpirePayableResources(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_, _ time.Time, _ string) (int64, error) {
+					cancel()
+					return 0, errors.New("error")
+				}).MinTimes(1)
+
+			done := make(chan struct{})
+			go func() {
+				svc.WatchPendingPayableResources(ctx, time.Millisecond)
+				close(done)
+			}()
+
+			<-done
+			So(ctx.Err(), ShouldNotBeNil)
+		})
+	})
 }
 
 func buildEmptyPayableResource() models.PayableResource {
@@ -213,3 +299,207 @@ func TestUnitPayableResourceService_UpdateAsPaid(t *testing.T) {
 		})
 	})
 }
+
+func TestUnitPayableResourceService_Durations(t *testing.T) {
+	Convey("PayableResourceService durations", t, func() {
+		Convey("Defaults are used when not configured", func() {
+			svc := &PayableResourceService{}
+
+			So(svc.PendingPayableExpiry(), ShouldEqual, DefaultPendingPayableExpiry)
+			So(svc.PaymentInProgressTimeout(), ShouldEqual, DefaultPaymentInProgressTimeout)
+		})
+
+		Convey("Configured durations are used", func() {
+			svc := &PayableResourceService{Config: &config.Config{PendingPayableExpiry: "2h", PaymentInProgressTimeout: "30m"}}
+
+			So(svc.PendingPayableExpiry(), ShouldEqual, 2*time.Hour)
+			So(svc.PaymentInProgressTimeout(), ShouldEqual, 30*time.Minute)
+		})
+
+		Convey("Defaults are used when the configured durations are invalid", func() {
+			svc := &PayableResourceService{Config: &config.Config{PendingPayableExpiry: "a day", PaymentInProgressTimeout: "-1m"}}
+
+			So(svc.PendingPayableExpiry(), ShouldEqual, DefaultPendingPayableExpiry)
+			So(svc.PaymentInProgressTimeout(), ShouldEqual, DefaultPaymentInProgressTimeout)
+		})
+	})
+}
+
+func TestUnitPayableResourceService_GetReusablePayableResource(t *testing.T) {
+	Convey("PayableResourceService.GetReusablePayableResource", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		svc := &PayableResourceService{DAO: mockPrDaoSvc}
+
+		now := time.Now()
+		startedAfter := now.Add(-DefaultPaymentInProgressTimeout)
+		createdAfter := now.Add(-DefaultPendingPayableExpiry)
+		penaltyRefs := []string{"A1234567"}
+		penaltyDetailsMap := &config.PenaltyDetailsMap{Details: map[string]config.PenaltyDetails{
+			"LATE_FILING": {CompanyCode: "LP", ReferencePrefix: "A"},
+		}}
+		request := models.PayableRequest{
+			CustomerCode: customerCode,
+			CreatedBy:    authentication.AuthUserDetails{ID: "identity"},
+			Transactions: []models.TransactionItem{{PenaltyRef: "A1234567", Amount: 5}},
+		}
+
+		Convey("A payment in progress for a penalty is an error", func() {
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, startedAfter, requestId).
+				Return(buildTestPayableResourceDao(1, customerCode, validPayableRef, "pending"), nil)
+
+			resource, err := svc.GetReusablePayableResource(request, penaltyDetailsMap, now, requestId)
+
+			So(resource, ShouldBeNil)
+			So(err, ShouldBeError, ErrPaymentInProgress)
+		})
+
+		Convey("The pending payable resource of the user for the same transactions is returned", func() {
+			other := buildTestPayableResourceDao(2, customerCode, "other", "pending")
+			pending := buildTestPayableResourceDao(1, customerCode, validPayableRef, "pending")
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, startedAfter, requestId).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, requestId).
+				Return([]models.PayableResourceDao{*other, *pending}, nil)
+
+			resource, err := svc.GetReusablePayableResource(request, penaltyDetailsMap, now, requestId)
+
+			So(err, ShouldBeNil)
+			So(resource.PayableRef, ShouldEqual, validPayableRef)
+		})
+
+		Convey("A pending payable resource of another user for a penalty is an error", func() {
+			otherUser := buildTestPayableResourceDao(1, customerCode, "other-user", "pending")
+			otherUser.Data.CreatedBy.ID = "other"
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, startedAfter, requestId).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, requestId).
+				Return([]models.PayableResourceDao{*otherUser}, nil)
+
+			resource, err := svc.GetReusablePayableResource(request, penaltyDetailsMap, now, requestId)
+
+			So(resource, ShouldBeNil)
+			So(err, ShouldBeError, ErrPaymentInProgress)
+		})
+
+		Convey("The pending payable resource of the user for another amount is cancelled and not returned", func() {
+			otherAmount := buildTestPayableResourceDao(1, customerCode, "other-amount", "pending")
+			otherAmount.Data.Transactions["A1234567"] = models.TransactionDao{Amount: 10}
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, startedAfter, requestId).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, requestId).
+				Return([]models.PayableResourceDao{*otherAmount}, nil)
+			mockPrDaoSvc.EXPECT().CancelPayableResource(customerCode, "other-amount", "identity", startedAfter, requestId).
+				Return(true, nil)
+
+			resource, err := svc.GetReusablePayableResource(request, penaltyDetailsMap, now, requestId)
+
+			So(err, ShouldBeNil)
+			So(resource, ShouldBeNil)
+		})
+
+		Convey("A replaced payable resource that cannot be cancelled is a payment in progress", func() {
+			otherAmount := buildTestPayableResourceDao(1, customerCode, "other-amount", "pending")
+			otherAmount.Data.Transactions["A1234567"] = models.TransactionDao{Amount: 10}
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, startedAfter, requestId).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, requestId).
+				Return([]models.PayableResourceDao{*otherAmount}, nil)
+			mockPrDaoSvc.EXPECT().CancelPayableResource(customerCode, "other-amount", "identity", startedAfter, requestId).
+				Return(false, nil)
+
+			resource, err := svc.GetReusablePayableResource(request, penaltyDetailsMap, now, requestId)
+
+			So(resource, ShouldBeNil)
+			So(err, ShouldBeError, ErrPaymentInProgress)
+		})
+
+		Convey("A penalty reference that does not match any penalty reference type is not looked up", func() {
+			for _, penaltyRef := range []string{"A123456", "B1234567", "A1234567.data", "$where"} {
+				request.Transactions = []models.TransactionItem{{PenaltyRef: penaltyRef, Amount: 5}}
+
+				resource, err := svc.GetReusablePayableResource(request, penaltyDetailsMap, now, requestId)
+
+				So(resource, ShouldBeNil)
+				So(errors.Is(err, ErrInvalidPenaltyReference), ShouldBeTrue)
+			}
+		})
+
+		Convey("An error getting pending payable resources is returned", func() {
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, startedAfter, requestId).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, requestId).
+				Return(nil, errors.New("error"))
+
+			resource, err := svc.GetReusablePayableResource(request, penaltyDetailsMap, now, requestId)
+
+			So(resource, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitPayableResourceService_CreatePayableResource(t *testing.T) {
+	Convey("PayableResourceService.CreatePayableResource", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		svc := &PayableResourceService{DAO: mockPrDaoSvc}
+
+		now := time.Now()
+		payable := buildTestPayableResourceDao(1, customerCode, validPayableRef, "pending")
+
+		Convey("The payable resource is stored", func() {
+			mockPrDaoSvc.EXPECT().CreatePayableResource(payable, requestId).Return(nil)
+
+			So(svc.CreatePayableResource(payable, now, requestId), ShouldBeNil)
+		})
+
+		Convey("The payable resource is stored again once the expired pending payable resources are expired", func() {
+			gomock.InOrder(
+				mockPrDaoSvc.EXPECT().CreatePayableResource(payable, requestId).Return(dao.ErrPenaltyInPendingPayableResource),
+				mockPrDaoSvc.EXPECT().ExpirePayableResources(now.Add(-DefaultPendingPayableExpiry),
+					now.Add(-DefaultPaymentInProgressTimeout), requestId).Return(int64(1), nil),
+				mockPrDaoSvc.EXPECT().CreatePayableResource(payable, requestId).Return(nil),
+			)
+
+			So(svc.CreatePayableResource(payable, now, requestId), ShouldBeNil)
+		})
+
+		Convey("A penalty still in another pending payable resource is a payment in progress", func() {
+			mockPrDaoSvc.EXPECT().CreatePayableResource(payable, requestId).Return(dao.ErrPenaltyInPendingPayableResource).Times(2)
+			mockPrDaoSvc.EXPECT().ExpirePayableResources(gomock.Any(), gomock.Any(), requestId).Return(int64(0), nil)
+
+			So(svc.CreatePayableResource(payable, now, requestId), ShouldBeError, ErrPaymentInProgress)
+		})
+
+		Convey("An error expiring the pending payable resources is returned", func() {
+			mockPrDaoSvc.EXPECT().CreatePayableResource(payable, requestId).Return(dao.ErrPenaltyInPendingPayableResource)
+			mockPrDaoSvc.EXPECT().ExpirePayableResources(gomock.Any(), gomock.Any(), requestId).Return(int64(0), errors.New("error"))
+
+			err := svc.CreatePayableResource(payable, now, requestId)
+
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, ErrPaymentInProgress), ShouldBeFalse)
+		})
+	})
+}
+
+func TestUnitPayableResourceService_RecordPaymentStarted(t *testing.T) {
+	Convey("PayableResourceService.RecordPaymentStarted", t, func() {
+		mockCtrl, mockPrDaoSvc, mockPayableResourceSvc := setup(t)
+		defer mockCtrl.Finish()
+		now := time.Now()
+
+		Convey("The payment started is recorded for a pending payable resource", func() {
+			resource := models.PayableResource{CustomerCode: customerCode, PayableRef: validPayableRef,
+				Payment: models.Payment{Status: constants.Pending.String()}}
+			mockPrDaoSvc.EXPECT().SetPaymentStarted(customerCode, validPayableRef, now, requestId).Return(nil)
+
+			So(mockPayableResourceSvc.RecordPaymentStarted(&resource, now, requestId), ShouldBeNil)
+		})
+
+		Convey("The payment started is not recorded for a paid payable resource", func() {
+			resource := models.PayableResource{CustomerCode: customerCode, PayableRef: validPayableRef,
+				Payment: models.Payment{Status: constants.Paid.String()}}
+
+			So(mockPayableResourceSvc.RecordPaymentStarted(&resource, now, requestId), ShouldBeNil)
+		})
+	})
+}
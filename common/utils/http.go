@@ -3,7 +3,10 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/companieshouse/chs.go/log"
 )
@@ -22,3 +25,83 @@ func WriteJSONWithStatus(w http.ResponseWriter, r *http.Request, data interface{
 		log.ErrorR(r, fmt.Errorf("error writing response: %v", err))
 	}
 }
+
+// WriteJSONWithEtag writes the interface as a json string with status of 200 and an ETag header. If the
+// etag matches the If-None-Match header of the request a 304 is written without a body.
+func WriteJSONWithEtag(w http.ResponseWriter, r *http.Request, data interface{}, etag string) {
+	quotedEtag := `"` + etag + `"`
+	w.Header().Set("ETag", quotedEtag)
+	if etagMatches(r.Header.Get("If-None-Match"), quotedEtag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	WriteJSON(w, r, data)
+}
+
+// etagMatches checks the etag against the comma separated etags of an If-None-Match header, using the
+// weak comparison that conditional GET requests use
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateMediaType returns the offered media type that an Accept header prefers. Each offer takes the
+// quality of the most specific media range that matches it, so a media range with q=0 excludes the offer
+// even if a wildcard would accept it. Offers of the same quality are preferred by the more specific media
+// range and then by the order they are offered. An empty string is returned if no offer is acceptable.
+func NegotiateMediaType(accept string, offers []string) string {
+	type mediaRange struct {
+		mediaType   string
+		quality     float64
+		specificity int
+	}
+
+	var mediaRanges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		specificity := 2
+		if mediaType == "*/*" {
+			specificity = 0
+		} else if strings.HasSuffix(mediaType, "/*") {
+			specificity = 1
+		}
+		mediaRanges = append(mediaRanges, mediaRange{mediaType: mediaType, quality: quality, specificity: specificity})
+	}
+
+	best, bestQuality, bestSpecificity := "", 0.0, -1
+	for _, offer := range offers {
+		offerType, _, _ := strings.Cut(offer, "/")
+		matched := mediaRange{specificity: -1}
+		for _, mediaRange := range mediaRanges {
+			if mediaRange.specificity <= matched.specificity {
+				continue
+			}
+			if mediaRange.mediaType == offer || mediaRange.mediaType == offerType+"/*" || mediaRange.mediaType == "*/*" {
+				matched = mediaRange
+			}
+		}
+		if matched.specificity < 0 || matched.quality <= 0 {
+			continue
+		}
+		if matched.quality > bestQuality || (matched.quality == bestQuality && matched.specificity > bestSpecificity) {
+			best, bestQuality, bestSpecificity = offer, matched.quality, matched.specificity
+		}
+	}
+	return best
+}
@@ -34,3 +34,71 @@ func TestUnitWriteJSON(t *testing.T) {
 		So(w.Body.String(), ShouldEqual, "{\"self\":\"\"}\n")
 	})
 }
+
+func TestUnitWriteJSONWithEtag(t *testing.T) {
+	Convey("Write json with etag", t, func() {
+		testCases := []struct {
+			ifNoneMatch string
+			wantStatus  int
+		}{
+			{ifNoneMatch: "", wantStatus: http.StatusOK},
+			{ifNoneMatch: `"other"`, wantStatus: http.StatusOK},
+			{ifNoneMatch: `"abc123"`, wantStatus: http.StatusNotModified},
+			{ifNoneMatch: `W/"abc123"`, wantStatus: http.StatusNotModified},
+			{ifNoneMatch: `"other", "abc123"`, wantStatus: http.StatusNotModified},
+			{ifNoneMatch: "*", wantStatus: http.StatusNotModified},
+		}
+
+		for _, tc := range testCases {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+
+			WriteJSONWithEtag(w, r, &models.CreatedPayableResourceLinks{}, "abc123")
+
+			So(w.Code, ShouldEqual, tc.wantStatus)
+			So(w.Header().Get("ETag"), ShouldEqual, `"abc123"`)
+			if tc.wantStatus == http.StatusNotModified {
+				So(w.Body.String(), ShouldEqual, "")
+			} else {
+				So(w.Body.String(), ShouldEqual, "{\"self\":\"\"}\n")
+			}
+		}
+	})
+}
+
+func TestUnitNegotiateMediaType(t *testing.T) {
+	Convey("Negotiate media type", t, func() {
+		offers := []string{"application/json", "text/csv"}
+
+		Convey("An offer that is accepted is returned", func() {
+			So(NegotiateMediaType("text/csv", offers), ShouldEqual, "text/csv")
+			So(NegotiateMediaType("application/*", offers), ShouldEqual, "application/json")
+		})
+
+		Convey("The offer with the highest quality is returned", func() {
+			So(NegotiateMediaType("application/json;q=0.5, text/csv", offers), ShouldEqual, "text/csv")
+			So(NegotiateMediaType("text/csv;q=0.2, */*;q=0.8", offers), ShouldEqual, "application/json")
+		})
+
+		Convey("The more specific media range is preferred when the quality is the same", func() {
+			So(NegotiateMediaType("*/*, text/csv", offers), ShouldEqual, "text/csv")
+		})
+
+		Convey("The first offer is preferred when the media ranges are the same", func() {
+			So(NegotiateMediaType("*/*", offers), ShouldEqual, "application/json")
+		})
+
+		Convey("A media range with q=0 excludes the offer even if a wildcard accepts it", func() {
+			So(NegotiateMediaType("application/json;q=0, */*", offers), ShouldEqual, "text/csv")
+			So(NegotiateMediaType("application/json;q=0, text/csv;q=0, */*", offers), ShouldEqual, "")
+		})
+
+		Convey("Nothing is returned when no offer is accepted", func() {
+			So(NegotiateMediaType("application/xml", offers), ShouldEqual, "")
+			So(NegotiateMediaType("invalid;;", offers), ShouldEqual, "")
+		})
+	})
+}
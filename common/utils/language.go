@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	EnglishLanguage = "en"
+	WelshLanguage   = "cy"
+)
+
+// SupportedLanguages are the languages that reasons and descriptions can be returned in
+var SupportedLanguages = []string{EnglishLanguage, WelshLanguage}
+
+// SetContentLanguage sets the language of the response. The response varies by the Accept-Language header
+// so that a shared cache does not serve it, or match its ETag, for a request in another language.
+func SetContentLanguage(w http.ResponseWriter, language string) {
+	w.Header().Set("Content-Language", language)
+	w.Header().Add("Vary", "Accept-Language")
+}
+
+// GetLanguage determines the language of the response from the lang query parameter or, if that is not
+// set, the Accept-Language header. English is used when neither asks for a supported language.
+func GetLanguage(req *http.Request) string {
+	if lang := normaliseLanguage(req.URL.Query().Get("lang")); slices.Contains(SupportedLanguages, lang) {
+		return lang
+	}
+
+	for _, lang := range parseAcceptLanguage(req.Header.Get("Accept-Language")) {
+		if slices.Contains(SupportedLanguages, lang) {
+			return lang
+		}
+	}
+
+	return EnglishLanguage
+}
+
+// parseAcceptLanguage returns the languages in an Accept-Language header ordered by preference
+func parseAcceptLanguage(acceptLanguage string) []string {
+	type weightedLanguage struct {
+		lang    string
+		quality float64
+	}
+
+	var weightedLanguages []weightedLanguage
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if lang = normaliseLanguage(lang); lang != "" && quality > 0 {
+			weightedLanguages = append(weightedLanguages, weightedLanguage{lang: lang, quality: quality})
+		}
+	}
+
+	sort.SliceStable(weightedLanguages, func(i, j int) bool {
+		return weightedLanguages[i].quality > weightedLanguages[j].quality
+	})
+
+	languages := make([]string, 0, len(weightedLanguages))
+	for _, weighted := range weightedLanguages {
+		languages = append(languages, weighted.lang)
+	}
+	return languages
+}
+
+// normaliseLanguage reduces a language tag such as cy-GB to its primary language subtag
+func normaliseLanguage(lang string) string {
+	primary, _, _ := strings.Cut(strings.TrimSpace(lang), "-")
+	return strings.ToLower(primary)
+}
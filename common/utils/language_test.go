@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitGetLanguage(t *testing.T) {
+	Convey("Get language", t, func() {
+		testCases := []struct {
+			name           string
+			target         string
+			acceptLanguage string
+			want           string
+		}{
+			{name: "Neither lang nor Accept-Language", target: "/", want: EnglishLanguage},
+			{name: "Welsh lang query parameter", target: "/?lang=cy", want: WelshLanguage},
+			{name: "English lang query parameter overrides Accept-Language", target: "/?lang=en", acceptLanguage: "cy", want: EnglishLanguage},
+			{name: "Unsupported lang query parameter falls back to Accept-Language", target: "/?lang=fr", acceptLanguage: "cy", want: WelshLanguage},
+			{name: "Welsh Accept-Language with region", target: "/", acceptLanguage: "cy-GB", want: WelshLanguage},
+			{name: "Accept-Language ordered by quality", target: "/", acceptLanguage: "en;q=0.5, cy;q=0.9", want: WelshLanguage},
+			{name: "Accept-Language skips unsupported languages", target: "/", acceptLanguage: "fr, de;q=0.9, cy;q=0.1", want: WelshLanguage},
+			{name: "Accept-Language excludes zero quality", target: "/", acceptLanguage: "cy;q=0", want: EnglishLanguage},
+			{name: "Accept-Language with only unsupported languages", target: "/", acceptLanguage: "fr", want: EnglishLanguage},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				req := httptest.NewRequest("GET", tc.target, nil)
+				if tc.acceptLanguage != "" {
+					req.Header.Set("Accept-Language", tc.acceptLanguage)
+				}
+
+				So(GetLanguage(req), ShouldEqual, tc.want)
+			})
+		}
+	})
+}
+
+func TestUnitSetContentLanguage(t *testing.T) {
+	Convey("The response has its language and varies by the Accept-Language header", t, func() {
+		w := httptest.NewRecorder()
+		w.Header().Set("Vary", "Accept")
+
+		SetContentLanguage(w, WelshLanguage)
+
+		So(w.Header().Get("Content-Language"), ShouldEqual, WelshLanguage)
+		So(w.Header().Values("Vary"), ShouldResemble, []string{"Accept", "Accept-Language"})
+	})
+}
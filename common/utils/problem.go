@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+)
+
+// ProblemContentType is the media type of an RFC 7807 problem details response
+const ProblemContentType = "application/problem+json"
+
+// Error codes identify why a request failed. They are part of the API contract so once published a code
+// must keep its meaning, clients should rely on the code rather than on the wording of the detail.
+const (
+	ErrorCodeInvalidRequestBody           = "INVALID_REQUEST_BODY"
+	ErrorCodeValidationFailed             = "VALIDATION_FAILED"
+	ErrorCodeInvalidQueryParameter        = "INVALID_QUERY_PARAMETER"
+	ErrorCodeInvalidPenaltyReferenceType  = "INVALID_PENALTY_REFERENCE_TYPE"
+	ErrorCodeInvalidPenaltyReference      = "INVALID_PENALTY_REFERENCE"
+	ErrorCodeCustomerCodeMissing          = "CUSTOMER_CODE_MISSING"
+	ErrorCodePayableRefMissing            = "PAYABLE_REF_MISSING"
+	ErrorCodeUnauthorised                 = "UNAUTHORISED"
+	ErrorCodeForbidden                    = "FORBIDDEN"
+	ErrorCodeUserDetailsMissing           = "USER_DETAILS_MISSING"
+	ErrorCodePayableResourceMissing       = "PAYABLE_RESOURCE_MISSING"
+	ErrorCodePayableResourceNotFound      = "PAYABLE_RESOURCE_NOT_FOUND"
+	ErrorCodePayableResourceExpired       = "PAYABLE_RESOURCE_EXPIRED"
+	ErrorCodePayableResourceCancelled     = "PAYABLE_RESOURCE_CANCELLED"
+	ErrorCodePayableResourceNotPaid       = "PAYABLE_RESOURCE_NOT_PAID"
+	ErrorCodePenaltyNotFound              = "PENALTY_NOT_FOUND"
+	ErrorCodePenaltyAlreadyPaid           = "PENALTY_ALREADY_PAID"
+	ErrorCodePaymentNotFound              = "PAYMENT_NOT_FOUND"
+	ErrorCodePaymentInvalid               = "PAYMENT_INVALID"
+	ErrorCodePaymentInProgress            = "PAYMENT_IN_PROGRESS"
+	ErrorCodePaymentsNotEnabled           = "PAYMENTS_NOT_ENABLED"
+	ErrorCodeIdempotencyKeyReused         = "IDEMPOTENCY_KEY_REUSED"
+	ErrorCodeIdempotencyKeyInUse          = "IDEMPOTENCY_KEY_IN_USE"
+	ErrorCodeMaintenanceWindowNotFound    = "MAINTENANCE_WINDOW_NOT_FOUND"
+	ErrorCodeFinanceSystemBadRequest      = "FINANCE_SYSTEM_BAD_REQUEST"
+	ErrorCodeFinanceSystemNotFound        = "FINANCE_SYSTEM_NOT_FOUND"
+	ErrorCodeFinanceSystemError           = "FINANCE_SYSTEM_ERROR"
+	ErrorCodeFinanceSystemInvalidResponse = "FINANCE_SYSTEM_INVALID_RESPONSE"
+	ErrorCodeRateLimited                  = "RATE_LIMITED"
+	ErrorCodeNotAcceptable                = "NOT_ACCEPTABLE"
+	ErrorCodeRequestBodyTooLarge          = "REQUEST_BODY_TOO_LARGE"
+	ErrorCodeUnsupportedMediaType         = "UNSUPPORTED_MEDIA_TYPE"
+	ErrorCodeInternalError                = "INTERNAL_ERROR"
+)
+
+// Problem is an RFC 7807 problem details response, extended with a stable error code, the request field
+// that caused the problem and the request id to quote when reporting it
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Code      string `json:"code"`
+	Field     string `json:"field,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// NewProblem returns a Problem with the status, error code and detail. The type is about:blank as the code
+// identifies the problem, so the title is the standard text of the status.
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithField returns a copy of the problem naming the request field that caused it
+func (p Problem) WithField(field string) Problem {
+	p.Field = field
+	return p
+}
+
+// WriteProblem writes the problem as application/problem+json if the request accepts it. Otherwise the
+// detail is written in the legacy message response so that existing clients are not affected.
+func WriteProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	if !acceptsProblem(r.Header.Get("Accept")) {
+		WriteJSONWithStatus(w, r, models.NewMessageResponse(problem.Detail), problem.Status)
+		return
+	}
+
+	if problem.RequestID == "" {
+		problem.RequestID = log.Context(r)
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	err := json.NewEncoder(w).Encode(problem)
+	if err != nil {
+		log.ErrorR(r, fmt.Errorf("error writing problem response: %v", err))
+	}
+}
+
+// acceptsProblem checks whether application/problem+json is one of the media types of an Accept header.
+// Wildcards are not enough, a client has to ask for problem details explicitly.
+func acceptsProblem(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || mediaType != ProblemContentType {
+			continue
+		}
+		if params["q"] != "0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitWriteProblem(t *testing.T) {
+	problem := NewProblem(http.StatusBadRequest, ErrorCodeValidationFailed, "invalid amount supplied").WithField("amount")
+
+	Convey("Problem details are written if the client accepts them", t, func() {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "application/json, application/problem+json")
+
+		WriteProblem(w, r, problem)
+
+		var written Problem
+		So(json.Unmarshal(w.Body.Bytes(), &written), ShouldBeNil)
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(w.Header().Get("Content-Type"), ShouldEqual, ProblemContentType)
+		So(written.Type, ShouldEqual, "about:blank")
+		So(written.Title, ShouldEqual, "Bad Request")
+		So(written.Status, ShouldEqual, http.StatusBadRequest)
+		So(written.Detail, ShouldEqual, "invalid amount supplied")
+		So(written.Code, ShouldEqual, ErrorCodeValidationFailed)
+		So(written.Field, ShouldEqual, "amount")
+	})
+
+	Convey("The legacy message response is written otherwise", t, func() {
+		for _, accept := range []string{"", "application/json", "*/*", "application/problem+json;q=0"} {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if accept != "" {
+				r.Header.Set("Accept", accept)
+			}
+
+			WriteProblem(w, r, problem)
+
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+			So(w.Body.String(), ShouldEqual, "{\"message\":\"invalid amount supplied\"}\n")
+		}
+	})
+}
@@ -3,15 +3,12 @@ package utils
 import (
 	"crypto/sha512"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/pkg/errors"
-
-	"github.com/companieshouse/penalty-payment-api-core/models"
 )
 
 // GenerateReferenceNumber produces a random reference number in the format of [A-Z]{2}[0-9]{8}
@@ -44,82 +41,29 @@ func GenerateEtag() (string, error) {
 	return sha1Hash, nil
 }
 
-// GetCustomerCodeFromVars returns the customer code from the supplied request vars.
-func GetCustomerCodeFromVars(vars map[string]string) (string, error) {
-	customerCode := vars["customer_code"]
-	if len(customerCode) == 0 {
-		return "", fmt.Errorf("customer code not supplied")
-	}
-
-	return strings.ToUpper(customerCode), nil
-}
-
-// GetCompanyCode gets the company code from the penalty reference type
-func GetCompanyCode(penaltyRefType string) (string, error) {
-	switch penaltyRefType {
-	case LateFilingPenaltyRefType:
-		return LateFilingPenaltyCompanyCode, nil
-	case SanctionsPenaltyRefType:
-		return SanctionsCompanyCode, nil
-	case SanctionsRoePenaltyRefType:
-		return SanctionsCompanyCode, nil
-	default:
-		return "", fmt.Errorf("invalid penalty reference type supplied")
-	}
-}
-
-// GetCompanyCodeFromTransaction determines the penalty type by the penaltyReference which is held in
-// the first element of the transactions under the property TransactionID that is pulled back
-func GetCompanyCodeFromTransaction(transactions []models.TransactionItem) (string, error) {
-	penaltyPrefix, err := getPrefix(transactions)
+// GenerateContentEtag generates an etag from a digest of the json encoded content, so that the etag only
+// changes when the content does
+func GenerateContentEtag(content interface{}) (string, error) {
+	encoded, err := json.Marshal(content)
 	if err != nil {
-		return "", err
-	}
-
-	switch penaltyPrefix {
-	case "A":
-		return LateFilingPenaltyCompanyCode, nil
-	case "P":
-		return SanctionsCompanyCode, nil
-	case "U":
-		return SanctionsCompanyCode, nil
-	default:
-		return "", fmt.Errorf("error converting penalty reference")
+		return "", fmt.Errorf("error encoding etag content: [%s]", err)
 	}
-}
-
-// GetPenaltyRefTypeFromTransaction determines the penalty reference type by the penaltyReference
-// which is held in the first element of the transactions under the property TransactionID that is pulled back
-func GetPenaltyRefTypeFromTransaction(transactions []models.TransactionItem) (string, error) {
-	penaltyPrefix, err := getPrefix(transactions)
+	shaDigest := sha512.New512_224()
+	_, err = shaDigest.Write(encoded)
 	if err != nil {
-		return "", err
-	}
-
-	switch penaltyPrefix {
-	case "A":
-		return LateFilingPenaltyRefType, nil
-	case "P":
-		return SanctionsPenaltyRefType, nil
-	case "U":
-		return SanctionsRoePenaltyRefType, nil
-	default:
-		return "", fmt.Errorf("error converting penalty reference")
+		return "", fmt.Errorf("error writing sha digest: [%s]", err)
 	}
+	return hex.EncodeToString(shaDigest.Sum(nil)), nil
 }
 
-func getPrefix(transactions []models.TransactionItem) (string, error) {
-	if len(transactions) == 0 {
-		return "", errors.New("no transactions found")
-	}
-
-	penaltyReference := transactions[0].PenaltyRef
-
-	if len(penaltyReference) == 0 {
-		return "", errors.New("no penalty reference found")
+// GetCustomerCodeFromVars returns the customer code from the supplied request vars.
+func GetCustomerCodeFromVars(vars map[string]string) (string, error) {
+	customerCode := vars["customer_code"]
+	if len(customerCode) == 0 {
+		return "", fmt.Errorf("customer code not supplied")
 	}
 
-	return penaltyReference[0:1], nil
+	return strings.ToUpper(customerCode), nil
 }
 
 const (
@@ -3,8 +3,6 @@ package utils
 import (
 	"testing"
 
-	"github.com/companieshouse/penalty-payment-api-core/models"
-
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -44,6 +42,29 @@ func TestUnitGenerateEtag(t *testing.T) {
 	})
 }
 
+func TestUnitGenerateContentEtag(t *testing.T) {
+	Convey("Generate content etag", t, func() {
+		etag, err := GenerateContentEtag(map[string]string{"payable_status": "OPEN"})
+		So(err, ShouldBeNil)
+		So(len(etag), ShouldEqual, 56)
+
+		Convey("The same content has the same etag", func() {
+			sameEtag, _ := GenerateContentEtag(map[string]string{"payable_status": "OPEN"})
+			So(sameEtag, ShouldEqual, etag)
+		})
+
+		Convey("Different content has a different etag", func() {
+			changedEtag, _ := GenerateContentEtag(map[string]string{"payable_status": "CLOSED"})
+			So(changedEtag, ShouldNotEqual, etag)
+		})
+
+		Convey("Content that cannot be encoded is an error", func() {
+			_, err := GenerateContentEtag(make(chan int))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
 func TestUnitGetCustomerCode(t *testing.T) {
 	Convey("Get customer code", t, func() {
 		testCases := []struct {
@@ -87,222 +108,3 @@ func TestUnitGetCustomerCode(t *testing.T) {
 		}
 	})
 }
-
-func TestUnitGetCompanyCode(t *testing.T) {
-	Convey("Get Company Code from penalty reference", t, func() {
-		testCases := []struct {
-			name          string
-			input         string
-			expectedCode  string
-			expectedError bool
-		}{
-			{
-				name:          "Late Filing",
-				input:         LateFilingPenaltyRefType,
-				expectedCode:  LateFilingPenaltyCompanyCode,
-				expectedError: false,
-			},
-			{
-				name:         "Sanctions",
-				input:        SanctionsPenaltyRefType,
-				expectedCode: SanctionsCompanyCode,
-			},
-			{
-				name:         "Sanctions ROE",
-				input:        SanctionsRoePenaltyRefType,
-				expectedCode: SanctionsCompanyCode,
-			},
-			{
-				name:          "Error invalid penalty reference",
-				input:         "R1234567",
-				expectedCode:  "",
-				expectedError: true,
-			},
-		}
-
-		for _, tc := range testCases {
-			Convey(tc.name, func() {
-				companyCode, err := GetCompanyCode(tc.input)
-				Convey(tc.expectedCode, func() {
-					if tc.expectedError {
-						So(err, ShouldNotBeNil)
-					} else {
-						So(err, ShouldBeNil)
-					}
-					So(companyCode, ShouldEqual, tc.expectedCode)
-				})
-			})
-		}
-	})
-}
-
-func TestUnitGetCompanyCodeFromTransaction(t *testing.T) {
-	Convey("Get Company Code from penalty ref", t, func() {
-		testCases := []struct {
-			name          string
-			input         []models.TransactionItem
-			expectedCode  string
-			expectedError bool
-		}{
-			{
-				name: "Late Filing",
-				input: []models.TransactionItem{
-					{
-						Amount:     5,
-						Type:       "penalty",
-						PenaltyRef: "A1000007",
-					},
-				},
-				expectedCode:  LateFilingPenaltyCompanyCode,
-				expectedError: false,
-			},
-			{
-				name: "Sanctions",
-				input: []models.TransactionItem{
-					{
-						Amount:     5,
-						Type:       "penalty",
-						PenaltyRef: "P1000007",
-					},
-				},
-				expectedCode: "C1",
-			},
-			{
-				name: "Sanctions ROE",
-				input: []models.TransactionItem{
-					{
-						Amount:     5,
-						Type:       "penalty",
-						PenaltyRef: "U1000007",
-					},
-				},
-				expectedCode: "C1",
-			},
-			{
-				name: "Error unknown penalty reference",
-				input: []models.TransactionItem{
-					{
-						Amount:     5,
-						Type:       "penalty",
-						PenaltyRef: "Q1000007",
-					},
-				},
-				expectedCode:  "",
-				expectedError: true,
-			},
-			{
-				name: "Error no penalty reference",
-				input: []models.TransactionItem{
-					{},
-				},
-				expectedCode:  "",
-				expectedError: true,
-			},
-			{
-				name:          "Error no transaction present",
-				input:         []models.TransactionItem{},
-				expectedCode:  "",
-				expectedError: true,
-			},
-		}
-
-		for _, tc := range testCases {
-			Convey(tc.name, func() {
-				companyCode, err := GetCompanyCodeFromTransaction(tc.input)
-				Convey(tc.expectedCode, func() {
-					if tc.expectedError {
-						So(err, ShouldNotBeNil)
-					} else {
-						So(err, ShouldBeNil)
-					}
-					So(companyCode, ShouldEqual, tc.expectedCode)
-				})
-			})
-		}
-	})
-}
-
-func TestUnitGetPenaltyRefTypeFromTransaction(t *testing.T) {
-	Convey("Get Company Code from penalty ref", t, func() {
-		testCases := []struct {
-			name                   string
-			input                  []models.TransactionItem
-			expectedPenaltyRefType string
-			expectedError          bool
-		}{
-			{
-				name: "Late Filing",
-				input: []models.TransactionItem{
-					{
-						Amount:     5,
-						Type:       "penalty",
-						PenaltyRef: "A1000007",
-					},
-				},
-				expectedPenaltyRefType: LateFilingPenaltyRefType,
-			},
-			{
-				name: "Sanctions",
-				input: []models.TransactionItem{
-					{
-						Amount:     5,
-						Type:       "penalty",
-						PenaltyRef: "P1000007",
-					},
-				},
-				expectedPenaltyRefType: SanctionsPenaltyRefType,
-			},
-			{
-				name: "Sanctions ROE",
-				input: []models.TransactionItem{
-					{
-						Amount:     5,
-						Type:       "penalty",
-						PenaltyRef: "U1000007",
-					},
-				},
-				expectedPenaltyRefType: SanctionsRoePenaltyRefType,
-			},
-			{
-				name: "Error unknown penalty reference",
-				input: []models.TransactionItem{
-					{
-						Amount:     5,
-						Type:       "penalty",
-						PenaltyRef: "Q1000007",
-					},
-				},
-				expectedPenaltyRefType: "",
-				expectedError:          true,
-			},
-			{
-				name: "Error no penalty reference",
-				input: []models.TransactionItem{
-					{},
-				},
-				expectedPenaltyRefType: "",
-				expectedError:          true,
-			},
-			{
-				name:                   "Error no transaction present",
-				input:                  []models.TransactionItem{},
-				expectedPenaltyRefType: "",
-				expectedError:          true,
-			},
-		}
-
-		for _, tc := range testCases {
-			Convey(tc.name, func() {
-				penaltyRefType, err := GetPenaltyRefTypeFromTransaction(tc.input)
-				Convey(tc.expectedPenaltyRefType, func() {
-					if tc.expectedError {
-						So(err, ShouldNotBeNil)
-					} else {
-						So(err, ShouldBeNil)
-					}
-					So(penaltyRefType, ShouldEqual, tc.expectedPenaltyRefType)
-				})
-			})
-		}
-	})
-}
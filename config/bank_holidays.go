@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	iCalendarDateLayout     = "20060102"
+	iCalendarDateTimeLayout = "20060102T150405"
+)
+
+// LoadBankHolidays reads the events of an iCalendar file, such as the UK bank holidays published on
+// GOV.UK, as maintenance windows. All-day events run from midnight to midnight Europe/London time.
+func LoadBankHolidays(fileName string) ([]MaintenanceWindow, error) {
+	iCalendarFile, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBankHolidays(iCalendarFile)
+}
+
+func parseBankHolidays(iCalendarFile []byte) ([]MaintenanceWindow, error) {
+	var bankHolidays []MaintenanceWindow
+	var event map[string]string
+
+	for i, line := range unfoldICalendarLines(string(iCalendarFile)) {
+		switch {
+		case line == "BEGIN:VEVENT":
+			event = map[string]string{}
+		case line == "END:VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", i+1)
+			}
+			bankHoliday, err := eventToMaintenanceWindow(event)
+			if err != nil {
+				return nil, err
+			}
+			bankHolidays = append(bankHolidays, bankHoliday)
+			event = nil
+		case event != nil:
+			name, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			// Property parameters such as DTSTART;VALUE=DATE are not needed to read the value
+			name, _, _ = strings.Cut(name, ";")
+			event[strings.ToUpper(name)] = value
+		}
+	}
+
+	if event != nil {
+		return nil, errors.New("calendar ends inside an event")
+	}
+
+	return bankHolidays, nil
+}
+
+// unfoldICalendarLines splits the calendar into lines, joining long lines that have been folded onto
+// lines beginning with a space or tab
+func unfoldICalendarLines(iCalendar string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(iCalendar, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	return lines
+}
+
+func eventToMaintenanceWindow(event map[string]string) (MaintenanceWindow, error) {
+	summary := strings.ReplaceAll(event["SUMMARY"], `\,`, ",")
+
+	start, allDay, err := parseICalendarTime(event["DTSTART"])
+	if err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("event %q has an invalid start: %v", summary, err)
+	}
+
+	var end time.Time
+	if event["DTEND"] == "" {
+		if !allDay {
+			return MaintenanceWindow{}, fmt.Errorf("event %q has no end", summary)
+		}
+		end = start.AddDate(0, 0, 1)
+	} else if end, _, err = parseICalendarTime(event["DTEND"]); err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("event %q has an invalid end: %v", summary, err)
+	}
+
+	if !end.After(start) {
+		return MaintenanceWindow{}, fmt.Errorf("event %q must end after it starts", summary)
+	}
+
+	return MaintenanceWindow{Name: summary, Start: start, End: end}, nil
+}
+
+// parseICalendarTime parses a DATE or DATE-TIME value, reporting whether it is a date. Times without a
+// trailing Z are in Europe/London time.
+func parseICalendarTime(value string) (t time.Time, allDay bool, err error) {
+	if t, err = time.ParseInLocation(iCalendarDateLayout, value, MaintenanceLocation); err == nil {
+		return t, true, nil
+	}
+	if utc, found := strings.CutSuffix(value, "Z"); found {
+		t, err = time.ParseInLocation(iCalendarDateTimeLayout, utc, time.UTC)
+	} else {
+		t, err = time.ParseInLocation(iCalendarDateTimeLayout, value, MaintenanceLocation)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+	}
+	return t.In(MaintenanceLocation), false, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const bankHolidaysICalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"METHOD:PUBLISH\r\n" +
+	"PRODID:-//uk.gov/GOVUK calendars//EN\r\n" +
+	"CALSCALE:GREGORIAN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTEND;VALUE=DATE:20250102\r\n" +
+	"DTSTART;VALUE=DATE:20250101\r\n" +
+	"SUMMARY:New Year’s Day\r\n" +
+	"UID:ca6af7456b0088abad9a69f9f620f5ac-0@gov.uk\r\n" +
+	"SEQUENCE:0\r\n" +
+	"DTSTAMP:20250101T000000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20250825\r\n" +
+	"SUMMARY:Summer bank\r\n" +
+	"  holiday\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20251225T090000\r\n" +
+	"DTEND:20251225T170000Z\r\n" +
+	"SUMMARY:Christmas Day\\, office hours\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestUnitLoadBankHolidays(t *testing.T) {
+	Convey("Load bank holidays", t, func() {
+		fileName := filepath.Join(t.TempDir(), "bank-holidays.ics")
+		So(os.WriteFile(fileName, []byte(bankHolidaysICalendar), 0o644), ShouldBeNil)
+
+		bankHolidays, err := LoadBankHolidays(fileName)
+
+		So(err, ShouldBeNil)
+		So(bankHolidays, ShouldHaveLength, 3)
+
+		Convey("All day events run from midnight to midnight UK time", func() {
+			So(bankHolidays[0].Name, ShouldEqual, "New Year’s Day")
+			So(bankHolidays[0].Start, ShouldEqual, londonTime(2025, time.January, 1, 0, 0))
+			So(bankHolidays[0].End, ShouldEqual, londonTime(2025, time.January, 2, 0, 0))
+		})
+
+		Convey("All day events without an end last one day", func() {
+			So(bankHolidays[1].Name, ShouldEqual, "Summer bank holiday")
+			So(bankHolidays[1].Start, ShouldEqual, londonTime(2025, time.August, 25, 0, 0))
+			So(bankHolidays[1].End, ShouldEqual, londonTime(2025, time.August, 26, 0, 0))
+		})
+
+		Convey("Events with times", func() {
+			So(bankHolidays[2].Name, ShouldEqual, "Christmas Day, office hours")
+			So(bankHolidays[2].Start, ShouldEqual, londonTime(2025, time.December, 25, 9, 0))
+			So(bankHolidays[2].End, ShouldEqual, londonTime(2025, time.December, 25, 17, 0))
+		})
+	})
+
+	Convey("Invalid bank holidays", t, func() {
+		testCases := []struct {
+			name      string
+			iCalendar string
+		}{
+			{name: "Invalid start", iCalendar: "BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n"},
+			{name: "Event with a time and no end", iCalendar: "BEGIN:VEVENT\nDTSTART:20251225T090000\nEND:VEVENT\n"},
+			{name: "Event ends before it starts", iCalendar: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20251225\nDTEND;VALUE=DATE:20251224\nEND:VEVENT\n"},
+			{name: "Event is not ended", iCalendar: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20251225\n"},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				bankHolidays, err := parseBankHolidays([]byte(tc.iCalendar))
+
+				So(bankHolidays, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+			})
+		}
+	})
+
+	Convey("Missing file", t, func() {
+		bankHolidays, err := LoadBankHolidays("missing.ics")
+
+		So(bankHolidays, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -3,6 +3,7 @@ package config
 
 import (
 	"os"
+	"regexp"
 	"sync"
 	"time"
 
@@ -24,6 +25,8 @@ type Config struct {
 	Database                               string       `env:"PPS_MONGODB_DATABASE"                         flag:"mongodb-database"                         flagDesc:"MongoDB database for data"`
 	PayableResourcesCollection             string       `env:"PPS_MONGODB_PAYABLE_RESOURCES_COLLECTION"     flag:"mongodb-payable-resources-collection"     flagDesc:"The name of the mongodb payable resources collection"`
 	AccountPenaltiesCollection             string       `env:"PPS_MONGODB_ACCOUNT_PENALTIES_COLLECTION"     flag:"mongodb-account-penalties-collection"     flagDesc:"The name of the mongodb account penalties collection"`
+	MaintenanceWindowsCollection           string       `env:"PPS_MONGODB_MAINTENANCE_WINDOWS_COLLECTION"   flag:"mongodb-maintenance-windows-collection"   flagDesc:"The name of the mongodb maintenance windows collection"`
+	IdempotencyKeysCollection              string       `env:"PPS_MONGODB_IDEMPOTENCY_KEYS_COLLECTION"      flag:"mongodb-idempotency-keys-collection"      flagDesc:"The name of the mongodb idempotency keys collection"`
 	AccountPenaltiesTTL                    string       `env:"PPS_ACCOUNT_PENALTIES_TTL"                    flag:"account-penalties-ttl"                    flagDesc:"The time to live for account penalties cache entry"`
 	BrokerAddr                             []string     `env:"KAFKA_BROKER_ADDR"                            flag:"broker-addr"                              flagDesc:"Kafka broker address"`
 	Kafka3BrokerAddr                       []string     `env:"KAFKA3_BROKER_ADDR"                           flag:"kafka3-broker-addr"                       flagDesc:"Kafka3 broker address"`
@@ -45,6 +48,19 @@ type Config struct {
 	WeeklyMaintenanceDay                   time.Weekday `env:"WEEKLY_MAINTENANCE_DAY"                       flag:"weekly-maintenance-day"                   flagDesc:"The day on which Weekly E5 maintenance takes place"`
 	PlannedMaintenanceStart                string       `env:"PLANNED_MAINTENANCE_START_TIME"               flag:"planned-maintenance-start-time"           flagDesc:"The time of the day at which Planned E5 maintenance starts"`
 	PlannedMaintenanceEnd                  string       `env:"PLANNED_MAINTENANCE_END_TIME"                 flag:"planned-maintenance-end-time"             flagDesc:"The time of the day at which Planned E5 maintenance ends"`
+	MaintenanceScheduleFile                string       `env:"MAINTENANCE_SCHEDULE_FILE"                    flag:"maintenance-schedule-file"                flagDesc:"Path to the yaml file of recurring and one-off E5 maintenance windows"`
+	MaintenanceBankHolidaysFile            string       `env:"MAINTENANCE_BANK_HOLIDAYS_FILE"               flag:"maintenance-bank-holidays-file"           flagDesc:"Path to an iCalendar file of bank holidays on which E5 is unavailable"`
+	MaintenanceWindowsCacheTTL             string       `env:"MAINTENANCE_WINDOWS_CACHE_TTL"                flag:"maintenance-windows-cache-ttl"            flagDesc:"How long maintenance windows read from mongodb are cached for"`
+	PendingPayableExpiry                   string       `env:"PENDING_PAYABLE_EXPIRY"                       flag:"pending-payable-expiry"                   flagDesc:"How long a pending payable resource can be paid for after it is created"`
+	PendingPayableExpiryInterval           string       `env:"PENDING_PAYABLE_EXPIRY_INTERVAL"              flag:"pending-payable-expiry-interval"          flagDesc:"How often pending payable resources that have expired are marked as expired"`
+	PaymentInProgressTimeout               string       `env:"PAYMENT_IN_PROGRESS_TIMEOUT"                  flag:"payment-in-progress-timeout"              flagDesc:"How long after the payments platform starts taking payment for a payable resource its penalties are locked"`
+	IdempotencyKeyTTL                      string       `env:"IDEMPOTENCY_KEY_TTL"                          flag:"idempotency-key-ttl"                      flagDesc:"How long an Idempotency-Key sent when creating a payable resource is kept for"`
+	PenaltyConfigReloadInterval            string       `env:"PENALTY_CONFIG_RELOAD_INTERVAL"               flag:"penalty-config-reload-interval"           flagDesc:"How often the penalty details, types and payable status rules files are checked for changes, reloading is disabled if not set"`
+	BulkLookupConcurrency                  int          `env:"BULK_LOOKUP_CONCURRENCY"                      flag:"bulk-lookup-concurrency"                  flagDesc:"How many customers the bulk penalty lookup requests from E5 at once"`
+	PenaltyLookupRateLimit                 int          `env:"PENALTY_LOOKUP_RATE_LIMIT"                    flag:"penalty-lookup-rate-limit"                flagDesc:"How many penalty lookups each client can make a minute"`
+	TrustedProxies                         []string     `env:"TRUSTED_PROXIES"                              flag:"trusted-proxies"                          flagDesc:"IP addresses or CIDR ranges of the proxies whose X-Forwarded-For header identifies the client of a penalty lookup"`
+	MaxRequestBodyBytes                    int          `env:"MAX_REQUEST_BODY_BYTES"                       flag:"max-request-body-bytes"                   flagDesc:"The largest request body accepted, in bytes"`
+	ValidateResponses                      bool         `env:"VALIDATE_RESPONSES"                           flag:"validate-responses"                       flagDesc:"If responses are validated against the OpenAPI document and mismatches logged, for non-production environments"`
 }
 
 // Namespace implements service.Config Namespace.
@@ -52,7 +68,8 @@ func (c *Config) Namespace() string {
 	return "penalty-payment-api"
 }
 
-// PenaltyDetailsMap defines the struct to hold the map of penalty details.
+// PenaltyDetailsMap defines the struct to hold the map of penalty details. It is the registry of penalty
+// reference types, mapping each one to its E5 company code and penalty reference prefix.
 type PenaltyDetailsMap struct {
 	Name    string                    `yaml:"name"`
 	Details map[string]PenaltyDetails `yaml:"details"`
@@ -60,13 +77,50 @@ type PenaltyDetailsMap struct {
 
 // PenaltyDetails defines the struct to hold the penalty details.
 type PenaltyDetails struct {
-	Description        string `yaml:"Description"`
-	DescriptionId      string `yaml:"DescriptionId"`
-	ClassOfPayment     string `yaml:"ClassOfPayment"`
-	ResourceKind       string `yaml:"ResourceKind"`
-	ProductType        string `yaml:"ProductType"`
-	EmailReceivedAppId string `yaml:"EmailReceivedAppId"`
-	EmailMsgType       string `yaml:"EmailMsgType"`
+	CompanyCode        string            `yaml:"CompanyCode"`
+	ReferencePrefix    string            `yaml:"ReferencePrefix"`
+	Description        string            `yaml:"Description"`
+	Descriptions       map[string]string `yaml:"Descriptions"`
+	DescriptionId      string            `yaml:"DescriptionId"`
+	ClassOfPayment     string            `yaml:"ClassOfPayment"`
+	ResourceKind       string            `yaml:"ResourceKind"`
+	ProductType        string            `yaml:"ProductType"`
+	EmailReceivedAppId string            `yaml:"EmailReceivedAppId"`
+	EmailMsgType       string            `yaml:"EmailMsgType"`
+	EnabledFrom        string            `yaml:"EnabledFrom"`
+	EnabledTo          string            `yaml:"EnabledTo"`
+}
+
+// GetDescription returns the description in the requested language, falling back to the English Description
+func (p PenaltyDetails) GetDescription(language string) string {
+	if description := p.Descriptions[language]; description != "" {
+		return description
+	}
+	return p.Description
+}
+
+// ReferencePattern returns the regular expression that the penalty references of the type match, the reference
+// prefix followed by seven digits
+func (p PenaltyDetails) ReferencePattern() string {
+	return "^" + regexp.QuoteMeta(p.ReferencePrefix) + "[0-9]{7}$"
+}
+
+// PaymentsEnabled reports whether penalties of this type can be paid at the supplied time. EnabledFrom and
+// EnabledTo are optional RFC3339 timestamps, an unset bound is treated as open-ended.
+func (p PenaltyDetails) PaymentsEnabled(now time.Time) bool {
+	return p.PaymentsDisabledReason(now) == ""
+}
+
+// PaymentsDisabledReason explains why penalties of this type cannot be paid at the supplied time, it is empty if
+// they can be paid
+func (p PenaltyDetails) PaymentsDisabledReason(now time.Time) string {
+	if enabledFrom, err := time.Parse(time.RFC3339, p.EnabledFrom); err == nil && now.Before(enabledFrom) {
+		return "payments are enabled from " + p.EnabledFrom
+	}
+	if enabledTo, err := time.Parse(time.RFC3339, p.EnabledTo); err == nil && !now.Before(enabledTo) {
+		return "payments were disabled at " + p.EnabledTo
+	}
+	return ""
 }
 
 // Get returns a pointer to a Config instance
@@ -95,9 +149,13 @@ func GetAllowedTransactions(fileName string) (*models.AllowedTransactionMap, err
 		return nil, err
 	}
 
+	return parseAllowedTransactions(yamlFile)
+}
+
+func parseAllowedTransactions(yamlFile []byte) (*models.AllowedTransactionMap, error) {
 	var allowedTransactions = models.AllowedTransactionMap{}
 
-	err = yaml.Unmarshal(yamlFile, &allowedTransactions)
+	err := yaml.Unmarshal(yamlFile, &allowedTransactions)
 	if err != nil {
 		return nil, err
 	}
@@ -111,9 +169,13 @@ func LoadPenaltyDetails(fileName string) (*PenaltyDetailsMap, error) {
 		return nil, err
 	}
 
+	return parsePenaltyDetails(yamlFile)
+}
+
+func parsePenaltyDetails(yamlFile []byte) (*PenaltyDetailsMap, error) {
 	var penaltyDetailsMap PenaltyDetailsMap
 
-	err = yaml.Unmarshal(yamlFile, &penaltyDetailsMap)
+	err := yaml.Unmarshal(yamlFile, &penaltyDetailsMap)
 	if err != nil {
 		return nil, err
 	}
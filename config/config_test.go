@@ -331,3 +331,46 @@ func TestUnitGet(t *testing.T) {
 		})
 	})
 }
+
+func TestUnitPaymentsEnabled(t *testing.T) {
+	Convey("Given penalty details with an enabled window", t, func() {
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		testCases := []struct {
+			name           string
+			enabledFrom    string
+			enabledTo      string
+			expected       bool
+			expectedReason string
+		}{
+			{name: "When no window is set", expected: true},
+			{name: "When now is after enabled from", enabledFrom: "2025-06-01T12:00:00Z", expected: true},
+			{name: "When now is before enabled from", enabledFrom: "2025-06-01T12:00:01Z", expected: false,
+				expectedReason: "payments are enabled from 2025-06-01T12:00:01Z"},
+			{name: "When now is before enabled to", enabledTo: "2025-06-01T12:00:01Z", expected: true},
+			{name: "When now is at enabled to", enabledTo: "2025-06-01T12:00:00Z", expected: false,
+				expectedReason: "payments were disabled at 2025-06-01T12:00:00Z"},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				details := PenaltyDetails{EnabledFrom: tc.enabledFrom, EnabledTo: tc.enabledTo}
+				So(details.PaymentsEnabled(now), ShouldEqual, tc.expected)
+				So(details.PaymentsDisabledReason(now), ShouldEqual, tc.expectedReason)
+			})
+		}
+	})
+}
+
+func TestUnitGetDescription(t *testing.T) {
+	Convey("Given penalty details with a Welsh description", t, func() {
+		details := PenaltyDetails{
+			Description:  "Late Filing Penalty",
+			Descriptions: map[string]string{"cy": "Cosb am Gyflwyno'n Hwyr"},
+		}
+
+		So(details.GetDescription("en"), ShouldEqual, "Late Filing Penalty")
+		So(details.GetDescription("cy"), ShouldEqual, "Cosb am Gyflwyno'n Hwyr")
+		So(PenaltyDetails{Description: "Late Filing Penalty"}.GetDescription("cy"), ShouldEqual, "Late Filing Penalty")
+	})
+}
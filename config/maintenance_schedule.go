@@ -0,0 +1,287 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // maintenance windows are scheduled in UK time wherever the service runs
+
+	"gopkg.in/yaml.v2"
+)
+
+// MaintenanceTimeZone is the time zone that maintenance windows are scheduled in
+const MaintenanceTimeZone = "Europe/London"
+
+const (
+	maintenanceClockLayout = "15:04"
+	maintenanceDateLayout  = "2006-01-02T15:04"
+
+	// maintenanceLookAhead is how far ahead recurring windows are searched for the next window, every
+	// weekly window occurs at least once in this period
+	maintenanceLookAhead = 8 * 24 * time.Hour
+)
+
+// MaintenanceLocation is the location of MaintenanceTimeZone
+var MaintenanceLocation, _ = time.LoadLocation(MaintenanceTimeZone)
+
+// MaintenanceSchedule holds the recurring and one-off windows during which E5 is unavailable. Times are
+// in Europe/London time. Bank holidays and the windows managed at runtime are added after loading.
+type MaintenanceSchedule struct {
+	Description  string                       `yaml:"description"`
+	Recurring    []RecurringMaintenanceWindow `yaml:"recurring"`
+	OneOff       []OneOffMaintenanceWindow    `yaml:"one_off"`
+	BankHolidays []MaintenanceWindow          `yaml:"-"`
+	Managed      []MaintenanceWindow          `yaml:"-"`
+}
+
+// RecurringMaintenanceWindow takes place every week on each of its days, or every day if no days are
+// set. A window that ends at or before the time it starts runs over midnight into the following day.
+type RecurringMaintenanceWindow struct {
+	Name  string   `yaml:"name"`
+	Days  []string `yaml:"days"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+// OneOffMaintenanceWindow takes place once. Start and End are Europe/London times such as
+// 2025-01-30T17:00, or timestamps in RFC3339 or RFC822 format.
+type OneOffMaintenanceWindow struct {
+	Name  string `yaml:"name"`
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+// MaintenanceWindow is a single period of maintenance
+type MaintenanceWindow struct {
+	Name  string    `json:"name,omitempty"`
+	Start time.Time `json:"start_time"`
+	End   time.Time `json:"end_time"`
+}
+
+// ActiveWindow returns the maintenance window in progress at the supplied time. Windows that overlap or
+// follow on from it without a break are merged into it, so the end of the returned window is when the
+// system is next available.
+func (s *MaintenanceSchedule) ActiveWindow(now time.Time) (MaintenanceWindow, bool) {
+	windows := s.windows(now.Add(-maintenanceLookAhead), now.Add(maintenanceLookAhead))
+
+	var active MaintenanceWindow
+	found := false
+	for _, window := range windows {
+		if window.contains(now) && (!found || window.End.After(active.End)) {
+			active = window
+			found = true
+		}
+	}
+	if !found {
+		return MaintenanceWindow{}, false
+	}
+
+	return mergeFollowingWindows(active, windows), true
+}
+
+// NextWindow returns the next maintenance window to start after the supplied time, ignoring any window
+// that is already in progress
+func (s *MaintenanceSchedule) NextWindow(now time.Time) (MaintenanceWindow, bool) {
+	from := now
+	if active, ok := s.ActiveWindow(now); ok {
+		from = active.End
+	}
+
+	windows := s.windows(from, from.Add(maintenanceLookAhead))
+	for _, window := range windows {
+		if window.Start.After(from) {
+			return mergeFollowingWindows(window, windows), true
+		}
+	}
+
+	return MaintenanceWindow{}, false
+}
+
+// windows returns every occurrence of the maintenance windows that overlaps the period from and to,
+// ordered by start time. One-off, bank holiday and managed windows are always included.
+func (s *MaintenanceSchedule) windows(from, to time.Time) []MaintenanceWindow {
+	var windows []MaintenanceWindow
+
+	for _, recurring := range s.Recurring {
+		windows = append(windows, recurring.occurrences(from, to)...)
+	}
+	for _, oneOff := range s.OneOff {
+		if window, err := oneOff.window(); err == nil {
+			windows = append(windows, window)
+		}
+	}
+	windows = append(windows, s.BankHolidays...)
+	windows = append(windows, s.Managed...)
+
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+
+	return windows
+}
+
+func (w MaintenanceWindow) contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+func mergeFollowingWindows(window MaintenanceWindow, windows []MaintenanceWindow) MaintenanceWindow {
+	for merged := true; merged; {
+		merged = false
+		for _, other := range windows {
+			if !other.Start.After(window.End) && other.End.After(window.End) {
+				window.End = other.End
+				merged = true
+			}
+		}
+	}
+	return window
+}
+
+// occurrences returns the occurrences of the window that overlap the period from and to. Occurrences
+// starting the day before from are included in case they run over midnight.
+func (r RecurringMaintenanceWindow) occurrences(from, to time.Time) []MaintenanceWindow {
+	startHour, startMinute, err := parseClockTime(r.Start)
+	if err != nil {
+		return nil
+	}
+	endHour, endMinute, err := parseClockTime(r.End)
+	if err != nil {
+		return nil
+	}
+	days, err := parseWeekdays(r.Days)
+	if err != nil {
+		return nil
+	}
+
+	var occurrences []MaintenanceWindow
+	first := from.In(MaintenanceLocation).AddDate(0, 0, -1)
+	last := to.In(MaintenanceLocation)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, MaintenanceLocation); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if len(days) > 0 && !days[day.Weekday()] {
+			continue
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), startHour, startMinute, 0, 0, MaintenanceLocation)
+		end := time.Date(day.Year(), day.Month(), day.Day(), endHour, endMinute, 0, 0, MaintenanceLocation)
+		if !end.After(start) {
+			end = time.Date(day.Year(), day.Month(), day.Day()+1, endHour, endMinute, 0, 0, MaintenanceLocation)
+		}
+
+		if end.After(from) && start.Before(to) {
+			occurrences = append(occurrences, MaintenanceWindow{Name: r.Name, Start: start, End: end})
+		}
+	}
+
+	return occurrences
+}
+
+func (o OneOffMaintenanceWindow) window() (MaintenanceWindow, error) {
+	start, err := ParseMaintenanceTime(o.Start)
+	if err != nil {
+		return MaintenanceWindow{}, err
+	}
+	end, err := ParseMaintenanceTime(o.End)
+	if err != nil {
+		return MaintenanceWindow{}, err
+	}
+	return MaintenanceWindow{Name: o.Name, Start: start, End: end}, nil
+}
+
+// ParseMaintenanceTime parses the start or end of a one-off maintenance window. Times without a zone
+// are in Europe/London time, RFC822 zone abbreviations such as BST are read as Europe/London offsets.
+func ParseMaintenanceTime(value string) (time.Time, error) {
+	for _, layout := range []string{maintenanceDateLayout, time.RFC3339, time.RFC822} {
+		if t, err := time.ParseInLocation(layout, value, MaintenanceLocation); err == nil {
+			return t.In(MaintenanceLocation), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid maintenance time %q, expected a time such as 2025-01-30T17:00", value)
+}
+
+func parseClockTime(value string) (hour, minute int, err error) {
+	t, err := time.Parse(maintenanceClockLayout, value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q, expected a time such as 19:00", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func parseWeekdays(names []string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool, len(names))
+	for _, name := range names {
+		day, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", name)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+func LoadMaintenanceSchedule(fileName string) (*MaintenanceSchedule, error) {
+	yamlFile, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMaintenanceSchedule(yamlFile)
+}
+
+func parseMaintenanceSchedule(yamlFile []byte) (*MaintenanceSchedule, error) {
+	var maintenanceSchedule MaintenanceSchedule
+
+	err := yaml.UnmarshalStrict(yamlFile, &maintenanceSchedule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &maintenanceSchedule, nil
+}
+
+// ValidateMaintenanceSchedule checks that every window has valid days and times and that one-off
+// windows end after they start
+func ValidateMaintenanceSchedule(maintenanceSchedule *MaintenanceSchedule) error {
+	if maintenanceSchedule == nil {
+		return errors.New("maintenance schedule must not be empty")
+	}
+
+	for i, recurring := range maintenanceSchedule.Recurring {
+		if _, err := parseWeekdays(recurring.Days); err != nil {
+			return fmt.Errorf("recurring maintenance window %d (%s): %v", i+1, recurring.Name, err)
+		}
+		if _, _, err := parseClockTime(recurring.Start); err != nil {
+			return fmt.Errorf("recurring maintenance window %d (%s): %v", i+1, recurring.Name, err)
+		}
+		if _, _, err := parseClockTime(recurring.End); err != nil {
+			return fmt.Errorf("recurring maintenance window %d (%s): %v", i+1, recurring.Name, err)
+		}
+		if recurring.Start == recurring.End {
+			return fmt.Errorf("recurring maintenance window %d (%s) must not start and end at the same time", i+1, recurring.Name)
+		}
+	}
+
+	for i, oneOff := range maintenanceSchedule.OneOff {
+		window, err := oneOff.window()
+		if err != nil {
+			return fmt.Errorf("one-off maintenance window %d (%s): %v", i+1, oneOff.Name, err)
+		}
+		if !window.End.After(window.Start) {
+			return fmt.Errorf("one-off maintenance window %d (%s) must end after it starts", i+1, oneOff.Name)
+		}
+	}
+
+	return nil
+}
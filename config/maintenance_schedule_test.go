@@ -0,0 +1,258 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func londonTime(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, MaintenanceLocation)
+}
+
+func TestUnitMaintenanceSchedule(t *testing.T) {
+	maintenanceSchedule := &MaintenanceSchedule{
+		Recurring: []RecurringMaintenanceWindow{
+			{Name: "Weekly", Days: []string{"Sunday"}, Start: "19:00", End: "19:30"},
+			{Name: "Overnight", Days: []string{"wednesday"}, Start: "23:00", End: "02:00"},
+		},
+		OneOff: []OneOffMaintenanceWindow{
+			{Name: "Upgrade", Start: "2025-03-13T01:30", End: "2025-03-13T04:00"},
+		},
+		BankHolidays: []MaintenanceWindow{
+			{Name: "Good Friday", Start: londonTime(2025, time.April, 18, 0, 0), End: londonTime(2025, time.April, 19, 0, 0)},
+		},
+	}
+
+	Convey("Active window", t, func() {
+		testCases := []struct {
+			name string
+			now  time.Time
+			want MaintenanceWindow
+		}{
+			{
+				name: "Weekly window",
+				now:  londonTime(2025, time.March, 16, 19, 10),
+				want: MaintenanceWindow{Name: "Weekly", Start: londonTime(2025, time.March, 16, 19, 0), End: londonTime(2025, time.March, 16, 19, 30)},
+			},
+			{
+				name: "Window is in UK time during British Summer Time",
+				now:  time.Date(2025, time.June, 15, 18, 10, 0, 0, time.UTC),
+				want: MaintenanceWindow{Name: "Weekly", Start: londonTime(2025, time.June, 15, 19, 0), End: londonTime(2025, time.June, 15, 19, 30)},
+			},
+			{
+				name: "Window that crosses midnight, after midnight",
+				now:  londonTime(2025, time.March, 6, 1, 0),
+				want: MaintenanceWindow{Name: "Overnight", Start: londonTime(2025, time.March, 5, 23, 0), End: londonTime(2025, time.March, 6, 2, 0)},
+			},
+			{
+				name: "Overlapping windows are merged",
+				now:  londonTime(2025, time.March, 12, 23, 30),
+				want: MaintenanceWindow{Name: "Overnight", Start: londonTime(2025, time.March, 12, 23, 0), End: londonTime(2025, time.March, 13, 4, 0)},
+			},
+			{
+				name: "Bank holiday",
+				now:  londonTime(2025, time.April, 18, 12, 0),
+				want: MaintenanceWindow{Name: "Good Friday", Start: londonTime(2025, time.April, 18, 0, 0), End: londonTime(2025, time.April, 19, 0, 0)},
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				got, ok := maintenanceSchedule.ActiveWindow(tc.now)
+
+				So(ok, ShouldBeTrue)
+				So(got.Name, ShouldEqual, tc.want.Name)
+				So(got.Start, ShouldEqual, tc.want.Start)
+				So(got.End, ShouldEqual, tc.want.End)
+			})
+		}
+
+		Convey("No window in progress", func() {
+			for _, now := range []time.Time{
+				londonTime(2025, time.March, 16, 18, 59),
+				londonTime(2025, time.March, 16, 19, 30),
+				londonTime(2025, time.March, 5, 22, 59),
+				londonTime(2025, time.March, 6, 2, 0),
+			} {
+				_, ok := maintenanceSchedule.ActiveWindow(now)
+				So(ok, ShouldBeFalse)
+			}
+		})
+	})
+
+	Convey("Next window", t, func() {
+		testCases := []struct {
+			name string
+			now  time.Time
+			want MaintenanceWindow
+		}{
+			{
+				name: "Next recurring window",
+				now:  londonTime(2025, time.March, 14, 9, 0),
+				want: MaintenanceWindow{Name: "Weekly", Start: londonTime(2025, time.March, 16, 19, 0), End: londonTime(2025, time.March, 16, 19, 30)},
+			},
+			{
+				name: "Next window after the window in progress",
+				now:  londonTime(2025, time.March, 16, 19, 10),
+				want: MaintenanceWindow{Name: "Overnight", Start: londonTime(2025, time.March, 19, 23, 0), End: londonTime(2025, time.March, 20, 2, 0)},
+			},
+			{
+				name: "Next window is merged with the windows that overlap it",
+				now:  londonTime(2025, time.March, 10, 9, 0),
+				want: MaintenanceWindow{Name: "Overnight", Start: londonTime(2025, time.March, 12, 23, 0), End: londonTime(2025, time.March, 13, 4, 0)},
+			},
+			{
+				name: "Next window is a bank holiday",
+				now:  londonTime(2025, time.April, 17, 9, 0),
+				want: MaintenanceWindow{Name: "Good Friday", Start: londonTime(2025, time.April, 18, 0, 0), End: londonTime(2025, time.April, 19, 0, 0)},
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				got, ok := maintenanceSchedule.NextWindow(tc.now)
+
+				So(ok, ShouldBeTrue)
+				So(got.Name, ShouldEqual, tc.want.Name)
+				So(got.Start, ShouldEqual, tc.want.Start)
+				So(got.End, ShouldEqual, tc.want.End)
+			})
+		}
+
+		Convey("Empty schedule", func() {
+			_, ok := (&MaintenanceSchedule{}).NextWindow(londonTime(2025, time.March, 13, 9, 0))
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestUnitParseMaintenanceTime(t *testing.T) {
+	Convey("Parse maintenance time", t, func() {
+		testCases := []struct {
+			name    string
+			value   string
+			want    time.Time
+			wantErr bool
+		}{
+			{name: "UK time", value: "2025-07-01T17:00", want: londonTime(2025, time.July, 1, 17, 0)},
+			{name: "RFC3339", value: "2025-07-01T16:00:00Z", want: londonTime(2025, time.July, 1, 17, 0)},
+			{name: "RFC822 in British Summer Time", value: "01 Jul 25 17:00 BST", want: londonTime(2025, time.July, 1, 17, 0)},
+			{name: "RFC822 in Greenwich Mean Time", value: "30 Jan 25 17:00 GMT", want: londonTime(2025, time.January, 30, 17, 0)},
+			{name: "Invalid", value: "invalid", wantErr: true},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				got, err := ParseMaintenanceTime(tc.value)
+
+				if tc.wantErr {
+					So(err, ShouldNotBeNil)
+				} else {
+					So(err, ShouldBeNil)
+					So(got, ShouldEqual, tc.want)
+				}
+			})
+		}
+	})
+}
+
+func TestUnitLoadMaintenanceSchedule(t *testing.T) {
+	Convey("Load maintenance schedule", t, func() {
+		fileName := filepath.Join(t.TempDir(), "maintenance_schedule.yml")
+		err := os.WriteFile(fileName, []byte(`
+description: test schedule
+recurring:
+  - name: Weekly
+    days: [sunday]
+    start: "19:00"
+    end: "19:30"
+one_off:
+  - name: Upgrade
+    start: "2025-03-12T01:30"
+    end: "2025-03-12T04:00"
+`), 0o644)
+		So(err, ShouldBeNil)
+
+		maintenanceSchedule, err := LoadMaintenanceSchedule(fileName)
+
+		So(err, ShouldBeNil)
+		So(ValidateMaintenanceSchedule(maintenanceSchedule), ShouldBeNil)
+		So(maintenanceSchedule.Recurring, ShouldHaveLength, 1)
+		So(maintenanceSchedule.OneOff, ShouldHaveLength, 1)
+	})
+
+	Convey("Unknown field", t, func() {
+		maintenanceSchedule, err := parseMaintenanceSchedule([]byte("weekly: []"))
+
+		So(maintenanceSchedule, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Missing file", t, func() {
+		maintenanceSchedule, err := LoadMaintenanceSchedule("missing.yml")
+
+		So(maintenanceSchedule, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestUnitValidateMaintenanceSchedule(t *testing.T) {
+	Convey("Validate maintenance schedule", t, func() {
+		testCases := []struct {
+			name                string
+			maintenanceSchedule *MaintenanceSchedule
+			wantErr             string
+		}{
+			{
+				name:                "Empty schedule",
+				maintenanceSchedule: &MaintenanceSchedule{},
+			},
+			{
+				name:                "No schedule",
+				maintenanceSchedule: nil,
+				wantErr:             "maintenance schedule must not be empty",
+			},
+			{
+				name:                "Invalid day",
+				maintenanceSchedule: &MaintenanceSchedule{Recurring: []RecurringMaintenanceWindow{{Name: "Weekly", Days: []string{"Funday"}, Start: "19:00", End: "19:30"}}},
+				wantErr:             `recurring maintenance window 1 (Weekly): invalid day "Funday"`,
+			},
+			{
+				name:                "Invalid time of day",
+				maintenanceSchedule: &MaintenanceSchedule{Recurring: []RecurringMaintenanceWindow{{Name: "Weekly", Start: "1900", End: "19:30"}}},
+				wantErr:             `recurring maintenance window 1 (Weekly): invalid time of day "1900", expected a time such as 19:00`,
+			},
+			{
+				name:                "Recurring window starts and ends at the same time",
+				maintenanceSchedule: &MaintenanceSchedule{Recurring: []RecurringMaintenanceWindow{{Name: "Weekly", Start: "19:00", End: "19:00"}}},
+				wantErr:             "recurring maintenance window 1 (Weekly) must not start and end at the same time",
+			},
+			{
+				name:                "Invalid one-off time",
+				maintenanceSchedule: &MaintenanceSchedule{OneOff: []OneOffMaintenanceWindow{{Name: "Upgrade", Start: "invalid", End: "2025-03-12T04:00"}}},
+				wantErr:             `one-off maintenance window 1 (Upgrade): invalid maintenance time "invalid", expected a time such as 2025-01-30T17:00`,
+			},
+			{
+				name:                "One-off window ends before it starts",
+				maintenanceSchedule: &MaintenanceSchedule{OneOff: []OneOffMaintenanceWindow{{Name: "Upgrade", Start: "2025-03-12T04:00", End: "2025-03-12T01:30"}}},
+				wantErr:             "one-off maintenance window 1 (Upgrade) must end after it starts",
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				err := ValidateMaintenanceSchedule(tc.maintenanceSchedule)
+
+				if tc.wantErr == "" {
+					So(err, ShouldBeNil)
+				} else {
+					So(err.Error(), ShouldEqual, tc.wantErr)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PayableStatusRules is an ordered list of rules used to decide the payable status of a penalty. The
+// status of the first rule whose conditions all match is used, otherwise the default status is used.
+type PayableStatusRules struct {
+	Description   string              `yaml:"description"`
+	DefaultStatus string              `yaml:"default_status"`
+	Rules         []PayableStatusRule `yaml:"rules"`
+}
+
+// PayableStatusRule gives a payable status to a penalty that matches all of its conditions
+type PayableStatusRule struct {
+	Name   string                 `yaml:"name"`
+	Status string                 `yaml:"status"`
+	When   PayableStatusCondition `yaml:"when"`
+}
+
+// PayableStatusCondition holds the conditions of a rule. Conditions that are not set are not checked.
+type PayableStatusCondition struct {
+	SubTypeDisabled    *bool                        `yaml:"sub_type_disabled"`
+	Paid               *bool                        `yaml:"paid"`
+	PaidToday          *bool                        `yaml:"paid_today"`
+	PaymentAllocated   *bool                        `yaml:"payment_allocated"`
+	Outstanding        *bool                        `yaml:"outstanding"`
+	UnpaidCosts        *bool                        `yaml:"unpaid_costs"`
+	CompanyCodes       []string                     `yaml:"company_codes"`
+	DunningStatuses    []string                     `yaml:"dunning_statuses"`
+	AccountStatuses    []string                     `yaml:"account_statuses"`
+	RelatedTransaction *RelatedTransactionCondition `yaml:"related_transaction"`
+}
+
+// RelatedTransactionCondition matches when another transaction with the same made up date as the
+// penalty has the given transaction type and subtype
+type RelatedTransactionCondition struct {
+	TransactionType    string `yaml:"transaction_type"`
+	TransactionSubType string `yaml:"transaction_sub_type"`
+}
+
+// PayableStatuses are the payable statuses that a rule can give a penalty
+var PayableStatuses = []string{
+	"OPEN",
+	"DISABLED",
+	"CLOSED",
+	"CLOSED_PENDING_ALLOCATION",
+	"CLOSED_INSTALMENT_PLAN",
+	"CLOSED_PEN_STRATEGY_EXHAUSTED",
+}
+
+func LoadPayableStatusRules(fileName string) (*PayableStatusRules, error) {
+	yamlFile, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePayableStatusRules(yamlFile)
+}
+
+func parsePayableStatusRules(yamlFile []byte) (*PayableStatusRules, error) {
+	var payableStatusRules PayableStatusRules
+
+	err := yaml.UnmarshalStrict(yamlFile, &payableStatusRules)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payableStatusRules, nil
+}
+
+// ValidatePayableStatusRules checks that the payable status rules have a known default status and that every
+// rule has a known status and at least one condition
+func ValidatePayableStatusRules(payableStatusRules *PayableStatusRules) error {
+	if payableStatusRules == nil || payableStatusRules.DefaultStatus == "" {
+		return errors.New("payable status rules must have a default status")
+	}
+	if !slices.Contains(PayableStatuses, payableStatusRules.DefaultStatus) {
+		return fmt.Errorf("payable status rules default status %s is not a payable status", payableStatusRules.DefaultStatus)
+	}
+
+	for i, rule := range payableStatusRules.Rules {
+		if rule.Status == "" {
+			return fmt.Errorf("payable status rule %d (%s) is missing a status", i+1, rule.Name)
+		}
+		if !slices.Contains(PayableStatuses, rule.Status) {
+			return fmt.Errorf("payable status rule %d (%s) status %s is not a payable status", i+1, rule.Name, rule.Status)
+		}
+		if !rule.When.hasConditions() {
+			return fmt.Errorf("payable status rule %d (%s) must have at least one condition", i+1, rule.Name)
+		}
+		if related := rule.When.RelatedTransaction; related != nil && related.TransactionType == "" {
+			return fmt.Errorf("payable status rule %d (%s) related transaction is missing a transaction type", i+1, rule.Name)
+		}
+	}
+
+	return nil
+}
+
+func (c PayableStatusCondition) hasConditions() bool {
+	return c.SubTypeDisabled != nil || c.Paid != nil || c.PaidToday != nil || c.PaymentAllocated != nil ||
+		c.Outstanding != nil || c.UnpaidCosts != nil || len(c.CompanyCodes) > 0 || len(c.DunningStatuses) > 0 ||
+		len(c.AccountStatuses) > 0 || c.RelatedTransaction != nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitLoadPayableStatusRules(t *testing.T) {
+	Convey("Load payable status rules", t, func() {
+		Convey("The payable status rules asset is valid", func() {
+			payableStatusRules, err := LoadPayableStatusRules("../assets/payable_status_rules.yml")
+
+			So(err, ShouldBeNil)
+			So(payableStatusRules.DefaultStatus, ShouldEqual, "CLOSED")
+			So(payableStatusRules.Rules, ShouldNotBeEmpty)
+			So(ValidatePayableStatusRules(payableStatusRules), ShouldBeNil)
+		})
+
+		Convey("Missing file", func() {
+			payableStatusRules, err := LoadPayableStatusRules("missing.yml")
+
+			So(payableStatusRules, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Unknown condition", func() {
+			payableStatusRules, err := parsePayableStatusRules([]byte(`
+default_status: CLOSED
+rules:
+  - name: typo
+    status: OPEN
+    when:
+      company_code: [ "LP" ]
+`))
+
+			So(payableStatusRules, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitValidatePayableStatusRules(t *testing.T) {
+	Convey("Validate payable status rules", t, func() {
+		paid := true
+
+		testCases := []struct {
+			name               string
+			payableStatusRules *PayableStatusRules
+			wantErr            string
+		}{
+			{
+				name: "Valid rules",
+				payableStatusRules: &PayableStatusRules{
+					DefaultStatus: "CLOSED",
+					Rules:         []PayableStatusRule{{Name: "paid", Status: "CLOSED", When: PayableStatusCondition{Paid: &paid}}},
+				},
+			},
+			{
+				name:               "Missing default status",
+				payableStatusRules: &PayableStatusRules{},
+				wantErr:            "payable status rules must have a default status",
+			},
+			{
+				name: "Missing status",
+				payableStatusRules: &PayableStatusRules{
+					DefaultStatus: "CLOSED",
+					Rules:         []PayableStatusRule{{Name: "paid", When: PayableStatusCondition{Paid: &paid}}},
+				},
+				wantErr: "payable status rule 1 (paid) is missing a status",
+			},
+			{
+				name:               "Unknown default status",
+				payableStatusRules: &PayableStatusRules{DefaultStatus: "SHUT"},
+				wantErr:            "payable status rules default status SHUT is not a payable status",
+			},
+			{
+				name: "Unknown status",
+				payableStatusRules: &PayableStatusRules{
+					DefaultStatus: "CLOSED",
+					Rules:         []PayableStatusRule{{Name: "paid", Status: "PAID", When: PayableStatusCondition{Paid: &paid}}},
+				},
+				wantErr: "payable status rule 1 (paid) status PAID is not a payable status",
+			},
+			{
+				name: "No conditions",
+				payableStatusRules: &PayableStatusRules{
+					DefaultStatus: "CLOSED",
+					Rules:         []PayableStatusRule{{Name: "everything", Status: "OPEN"}},
+				},
+				wantErr: "payable status rule 1 (everything) must have at least one condition",
+			},
+			{
+				name: "Related transaction without a transaction type",
+				payableStatusRules: &PayableStatusRules{
+					DefaultStatus: "CLOSED",
+					Rules: []PayableStatusRule{{Name: "instalment plan", Status: "CLOSED_INSTALMENT_PLAN",
+						When: PayableStatusCondition{RelatedTransaction: &RelatedTransactionCondition{TransactionSubType: "00"}}}},
+				},
+				wantErr: "payable status rule 1 (instalment plan) related transaction is missing a transaction type",
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				err := ValidatePayableStatusRules(tc.payableStatusRules)
+
+				if tc.wantErr == "" {
+					So(err, ShouldBeNil)
+				} else {
+					So(err.Error(), ShouldEqual, tc.wantErr)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+)
+
+// PenaltyConfig is a validated, immutable version of the penalty details, penalty types, payable status
+// rules and reasons catalogue files.
+type PenaltyConfig struct {
+	PenaltyDetails      *PenaltyDetailsMap
+	AllowedTransactions *models.AllowedTransactionMap
+	PayableStatusRules  *PayableStatusRules
+	ReasonsCatalogue    *ReasonsCatalogue
+	Version             int
+	Hash                string
+	LoadedAt            time.Time
+}
+
+// PenaltyConfigFiles are the paths of the files that make up the penalty config
+type PenaltyConfigFiles struct {
+	PenaltyDetails      string
+	AllowedTransactions string
+	PayableStatusRules  string
+	ReasonsCatalogue    string
+}
+
+// PenaltyConfigProvider holds the active PenaltyConfig and reloads it when the underlying files change.
+// A new version is only swapped in once it has been parsed and validated, otherwise the last good
+// version continues to be served.
+type PenaltyConfigProvider struct {
+	files     PenaltyConfigFiles
+	current   atomic.Pointer[PenaltyConfig]
+	reloadMtx sync.Mutex
+}
+
+// NewPenaltyConfigProvider loads the initial version of the penalty config, returning an error if the
+// files cannot be read or are invalid.
+func NewPenaltyConfigProvider(files PenaltyConfigFiles) (*PenaltyConfigProvider, error) {
+	provider := &PenaltyConfigProvider{files: files}
+
+	if _, _, err := provider.Reload(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// Get returns the active penalty config. The returned value must not be modified.
+func (p *PenaltyConfigProvider) Get() *PenaltyConfig {
+	return p.current.Load()
+}
+
+// Reload reads the files and swaps in a new version if their contents have changed and are valid.
+// It returns the active config and whether a new version was swapped in.
+func (p *PenaltyConfigProvider) Reload() (*PenaltyConfig, bool, error) {
+	p.reloadMtx.Lock()
+	defer p.reloadMtx.Unlock()
+
+	active := p.current.Load()
+
+	penaltyDetailsFile, err := os.ReadFile(p.files.PenaltyDetails)
+	if err != nil {
+		return active, false, err
+	}
+	allowedTransactionsFile, err := os.ReadFile(p.files.AllowedTransactions)
+	if err != nil {
+		return active, false, err
+	}
+	payableStatusRulesFile, err := os.ReadFile(p.files.PayableStatusRules)
+	if err != nil {
+		return active, false, err
+	}
+	reasonsCatalogueFile, err := os.ReadFile(p.files.ReasonsCatalogue)
+	if err != nil {
+		return active, false, err
+	}
+
+	hash := penaltyConfigHash(penaltyDetailsFile, allowedTransactionsFile, payableStatusRulesFile, reasonsCatalogueFile)
+	if active != nil && active.Hash == hash {
+		return active, false, nil
+	}
+
+	penaltyDetails, err := parsePenaltyDetails(penaltyDetailsFile)
+	if err != nil {
+		return active, false, fmt.Errorf("error parsing %s: %v", p.files.PenaltyDetails, err)
+	}
+	allowedTransactions, err := parseAllowedTransactions(allowedTransactionsFile)
+	if err != nil {
+		return active, false, fmt.Errorf("error parsing %s: %v", p.files.AllowedTransactions, err)
+	}
+	payableStatusRules, err := parsePayableStatusRules(payableStatusRulesFile)
+	if err != nil {
+		return active, false, fmt.Errorf("error parsing %s: %v", p.files.PayableStatusRules, err)
+	}
+	reasonsCatalogue, err := parseReasonsCatalogue(reasonsCatalogueFile)
+	if err != nil {
+		return active, false, fmt.Errorf("error parsing %s: %v", p.files.ReasonsCatalogue, err)
+	}
+	if err = ValidatePenaltyConfig(penaltyDetails, allowedTransactions); err != nil {
+		return active, false, err
+	}
+	if err = ValidatePayableStatusRules(payableStatusRules); err != nil {
+		return active, false, err
+	}
+	if err = ValidateReasonsCatalogue(reasonsCatalogue); err != nil {
+		return active, false, err
+	}
+
+	version := 1
+	if active != nil {
+		version = active.Version + 1
+	}
+
+	next := &PenaltyConfig{
+		PenaltyDetails:      penaltyDetails,
+		AllowedTransactions: allowedTransactions,
+		PayableStatusRules:  payableStatusRules,
+		ReasonsCatalogue:    reasonsCatalogue,
+		Version:             version,
+		Hash:                hash,
+		LoadedAt:            time.Now(),
+	}
+	p.current.Store(next)
+
+	return next, true, nil
+}
+
+// Watch polls the penalty config files every interval and reloads them when they change, until the
+// context is cancelled.
+func (p *PenaltyConfigProvider) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			active, reloaded, err := p.Reload()
+			if err != nil {
+				log.Error(fmt.Errorf("error reloading penalty config, keeping version %d: %v", active.Version, err))
+				continue
+			}
+			if reloaded {
+				log.Info("penalty config reloaded", log.Data{"version": active.Version, "hash": active.Hash})
+			}
+		}
+	}
+}
+
+// ValidatePenaltyConfig checks that the penalty details and allowed transactions are complete enough
+// to be served.
+func ValidatePenaltyConfig(penaltyDetails *PenaltyDetailsMap, allowedTransactions *models.AllowedTransactionMap) error {
+	if penaltyDetails == nil || len(penaltyDetails.Details) == 0 {
+		return errors.New("penalty details must contain at least one penalty reference type")
+	}
+
+	penaltyRefTypes := make([]string, 0, len(penaltyDetails.Details))
+	for penaltyRefType := range penaltyDetails.Details {
+		penaltyRefTypes = append(penaltyRefTypes, penaltyRefType)
+	}
+	sort.Strings(penaltyRefTypes)
+
+	for _, penaltyRefType := range penaltyRefTypes {
+		details := penaltyDetails.Details[penaltyRefType]
+		required := []struct{ name, value string }{
+			{"Description", details.Description},
+			{"DescriptionId", details.DescriptionId},
+			{"ClassOfPayment", details.ClassOfPayment},
+			{"ResourceKind", details.ResourceKind},
+			{"ProductType", details.ProductType},
+			{"EmailReceivedAppId", details.EmailReceivedAppId},
+			{"EmailMsgType", details.EmailMsgType},
+		}
+		for _, field := range required {
+			if field.value == "" {
+				return fmt.Errorf("penalty details for %s is missing %s", penaltyRefType, field.name)
+			}
+		}
+		for _, field := range []struct{ name, value string }{
+			{"EnabledFrom", details.EnabledFrom},
+			{"EnabledTo", details.EnabledTo},
+		} {
+			if _, err := time.Parse(time.RFC3339, field.value); field.value != "" && err != nil {
+				return fmt.Errorf("penalty details for %s has an invalid %s: %v", penaltyRefType, field.name, err)
+			}
+		}
+		for language := range details.Descriptions {
+			if !slices.Contains(utils.SupportedLanguages, language) {
+				return fmt.Errorf("penalty details for %s has a description in unsupported language %s", penaltyRefType, language)
+			}
+		}
+	}
+
+	if err := penaltyDetails.validateRegistry(); err != nil {
+		return err
+	}
+
+	if allowedTransactions == nil || len(allowedTransactions.Types) == 0 {
+		return errors.New("allowed transactions must contain at least one transaction type")
+	}
+	for transactionType, subTypes := range allowedTransactions.Types {
+		if len(subTypes) == 0 {
+			return fmt.Errorf("allowed transactions for type %s must contain at least one subtype", transactionType)
+		}
+	}
+
+	return nil
+}
+
+func penaltyConfigHash(files ...[]byte) string {
+	hash := sha256.New()
+	for _, file := range files {
+		hash.Write(file)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const validPenaltyDetailsYaml = `
+name: penalty details
+details:
+  LATE_FILING:
+    CompanyCode: "LP"
+    ReferencePrefix: "A"
+    Description: "Late Filing Penalty"
+    DescriptionId: "late-filing-penalty"
+    ClassOfPayment: "penalty-lfp"
+    ResourceKind: "late-filing-penalty#late-filing-penalty"
+    ProductType: "late-filing-penalty"
+    EmailMsgType: "penalty_payment_received_email"
+    EmailReceivedAppId: "penalty-payment-api.penalty_payment_received_email"
+`
+
+const validPenaltyTypesYaml = `
+description: transaction types and subtypes of allowed penalties
+allowed_transactions:
+  1:
+    EJ:
+      true
+`
+
+const validPayableStatusRulesYaml = `
+default_status: CLOSED
+rules:
+  - name: late filing penalty
+    status: OPEN
+    when:
+      company_codes: [ "LP" ]
+`
+
+const validReasonsYaml = `
+reasons:
+  - transaction_type: "1"
+    text:
+      en: "Penalty"
+      cy: "Cosb"
+`
+
+func writePenaltyConfigFile(t *testing.T, dir, name, contents string) string {
+	fileName := filepath.Join(dir, name)
+	if err := os.WriteFile(fileName, []byte(contents), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return fileName
+}
+
+func writeValidPenaltyConfigFiles(t *testing.T, dir string) PenaltyConfigFiles {
+	return PenaltyConfigFiles{
+		PenaltyDetails:      writePenaltyConfigFile(t, dir, "penalty_details.yml", validPenaltyDetailsYaml),
+		AllowedTransactions: writePenaltyConfigFile(t, dir, "penalty_types.yml", validPenaltyTypesYaml),
+		PayableStatusRules:  writePenaltyConfigFile(t, dir, "payable_status_rules.yml", validPayableStatusRulesYaml),
+		ReasonsCatalogue:    writePenaltyConfigFile(t, dir, "reasons.yml", validReasonsYaml),
+	}
+}
+
+func TestUnitNewPenaltyConfigProvider(t *testing.T) {
+	Convey("Given the penalty config provider is created", t, func() {
+		dir := t.TempDir()
+		files := writeValidPenaltyConfigFiles(t, dir)
+
+		Convey("When the files are valid", func() {
+			provider, err := NewPenaltyConfigProvider(files)
+
+			Convey("Then the first version of the config should be active", func() {
+				So(err, ShouldBeNil)
+				penaltyConfig := provider.Get()
+				So(penaltyConfig.Version, ShouldEqual, 1)
+				So(penaltyConfig.Hash, ShouldHaveLength, 64)
+				So(penaltyConfig.PenaltyDetails.Details["LATE_FILING"].Description, ShouldEqual, "Late Filing Penalty")
+				So(penaltyConfig.AllowedTransactions.Types["1"]["EJ"], ShouldBeTrue)
+				So(penaltyConfig.PayableStatusRules.DefaultStatus, ShouldEqual, "CLOSED")
+				So(penaltyConfig.ReasonsCatalogue.Reasons, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a file does not exist", func() {
+			files.AllowedTransactions = filepath.Join(dir, "missing.yml")
+
+			provider, err := NewPenaltyConfigProvider(files)
+
+			Convey("Then an error should be returned", func() {
+				So(provider, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the penalty details are invalid", func() {
+			writePenaltyConfigFile(t, dir, "penalty_details.yml", `
+name: penalty details
+details:
+  LATE_FILING:
+    Description: "Late Filing Penalty"
+`)
+
+			provider, err := NewPenaltyConfigProvider(files)
+
+			Convey("Then a validation error should be returned", func() {
+				So(provider, ShouldBeNil)
+				So(err.Error(), ShouldEqual, "penalty details for LATE_FILING is missing DescriptionId")
+			})
+		})
+
+		Convey("When the payable status rules are invalid", func() {
+			writePenaltyConfigFile(t, dir, "payable_status_rules.yml", `
+default_status: CLOSED
+rules:
+  - name: late filing penalty
+    status: OPEN
+`)
+
+			provider, err := NewPenaltyConfigProvider(files)
+
+			Convey("Then a validation error should be returned", func() {
+				So(provider, ShouldBeNil)
+				So(err.Error(), ShouldEqual, "payable status rule 1 (late filing penalty) must have at least one condition")
+			})
+		})
+
+		Convey("When the reasons catalogue is invalid", func() {
+			writePenaltyConfigFile(t, dir, "reasons.yml", `
+reasons:
+  - transaction_type: "1"
+    text:
+      cy: "Cosb"
+`)
+
+			provider, err := NewPenaltyConfigProvider(files)
+
+			Convey("Then a validation error should be returned", func() {
+				So(provider, ShouldBeNil)
+				So(err.Error(), ShouldEqual, "reason 1 is missing en text")
+			})
+		})
+	})
+}
+
+func TestUnitPenaltyConfigProviderReload(t *testing.T) {
+	Convey("Given an active penalty config", t, func() {
+		dir := t.TempDir()
+		provider, err := NewPenaltyConfigProvider(writeValidPenaltyConfigFiles(t, dir))
+		So(err, ShouldBeNil)
+		original := provider.Get()
+
+		Convey("When the files have not changed", func() {
+			active, reloaded, err := provider.Reload()
+
+			Convey("Then the active version should be kept", func() {
+				So(err, ShouldBeNil)
+				So(reloaded, ShouldBeFalse)
+				So(active, ShouldEqual, original)
+			})
+		})
+
+		Convey("When a valid change is made", func() {
+			writePenaltyConfigFile(t, dir, "penalty_types.yml", validPenaltyTypesYaml+`
+    EK:
+      true
+`)
+			active, reloaded, err := provider.Reload()
+
+			Convey("Then the new version should be swapped in", func() {
+				So(err, ShouldBeNil)
+				So(reloaded, ShouldBeTrue)
+				So(active.Version, ShouldEqual, 2)
+				So(active.Hash, ShouldNotEqual, original.Hash)
+				So(provider.Get(), ShouldEqual, active)
+				So(provider.Get().AllowedTransactions.Types["1"]["EK"], ShouldBeTrue)
+			})
+		})
+
+		Convey("When an invalid change is made", func() {
+			writePenaltyConfigFile(t, dir, "penalty_types.yml", `
+description: transaction types and subtypes of allowed penalties
+allowed_transactions:
+`)
+			active, reloaded, err := provider.Reload()
+
+			Convey("Then the last good version should continue to be served", func() {
+				So(err.Error(), ShouldEqual, "allowed transactions must contain at least one transaction type")
+				So(reloaded, ShouldBeFalse)
+				So(active, ShouldEqual, original)
+				So(provider.Get(), ShouldEqual, original)
+			})
+		})
+
+		Convey("When the files are being watched and a valid change is made", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go provider.Watch(ctx, 10*time.Millisecond)
+
+			writePenaltyConfigFile(t, dir, "penalty_types.yml", validPenaltyTypesYaml+`
+    EL:
+      true
+`)
+
+			Convey("Then the new version should be picked up", func() {
+				deadline := time.Now().Add(2 * time.Second)
+				for provider.Get().Version == original.Version && time.Now().Before(deadline) {
+					time.Sleep(10 * time.Millisecond)
+				}
+				So(provider.Get().Version, ShouldEqual, 2)
+				So(provider.Get().AllowedTransactions.Types["1"]["EL"], ShouldBeTrue)
+			})
+		})
+	})
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+)
+
+// GetCompanyCode gets the company code from the penalty reference type
+func (m *PenaltyDetailsMap) GetCompanyCode(penaltyRefType string) (string, error) {
+	details, ok := m.Details[penaltyRefType]
+	if !ok || details.CompanyCode == "" {
+		return "", fmt.Errorf("invalid penalty reference type supplied")
+	}
+
+	return details.CompanyCode, nil
+}
+
+// GetCompanyCodeFromTransaction determines the company code by the penaltyReference which is held in
+// the first element of the transactions
+func (m *PenaltyDetailsMap) GetCompanyCodeFromTransaction(transactions []models.TransactionItem) (string, error) {
+	penaltyRefType, err := m.GetPenaltyRefTypeFromTransaction(transactions)
+	if err != nil {
+		return "", err
+	}
+
+	return m.Details[penaltyRefType].CompanyCode, nil
+}
+
+// GetPenaltyRefTypeFromTransaction determines the penalty reference type by the penaltyReference
+// which is held in the first element of the transactions
+func (m *PenaltyDetailsMap) GetPenaltyRefTypeFromTransaction(transactions []models.TransactionItem) (string, error) {
+	if len(transactions) == 0 {
+		return "", errors.New("no transactions found")
+	}
+
+	penaltyReference := transactions[0].PenaltyRef
+
+	if len(penaltyReference) == 0 {
+		return "", errors.New("no penalty reference found")
+	}
+
+	for penaltyRefType, details := range m.Details {
+		if details.ReferencePrefix != "" && strings.HasPrefix(penaltyReference, details.ReferencePrefix) {
+			return penaltyRefType, nil
+		}
+	}
+
+	return "", fmt.Errorf("error converting penalty reference")
+}
+
+// IsPenaltyReference reports whether the penalty reference matches the reference pattern of any penalty
+// reference type
+func (m *PenaltyDetailsMap) IsPenaltyReference(penaltyReference string) bool {
+	for _, details := range m.Details {
+		if details.ReferencePrefix == "" {
+			continue
+		}
+		if matched, err := regexp.MatchString(details.ReferencePattern(), penaltyReference); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRegistry checks that every penalty reference type has a company code and a reference
+// prefix, and that no penalty reference could match more than one penalty reference type
+func (m *PenaltyDetailsMap) validateRegistry() error {
+	penaltyRefTypes := make([]string, 0, len(m.Details))
+	for penaltyRefType := range m.Details {
+		penaltyRefTypes = append(penaltyRefTypes, penaltyRefType)
+	}
+	sort.Strings(penaltyRefTypes)
+
+	for i, penaltyRefType := range penaltyRefTypes {
+		details := m.Details[penaltyRefType]
+		if details.CompanyCode == "" {
+			return fmt.Errorf("penalty details for %s is missing CompanyCode", penaltyRefType)
+		}
+		if details.ReferencePrefix == "" {
+			return fmt.Errorf("penalty details for %s is missing ReferencePrefix", penaltyRefType)
+		}
+
+		for _, other := range penaltyRefTypes[i+1:] {
+			otherPrefix := m.Details[other].ReferencePrefix
+			if strings.HasPrefix(details.ReferencePrefix, otherPrefix) || strings.HasPrefix(otherPrefix, details.ReferencePrefix) {
+				return fmt.Errorf("reference prefix %s of %s clashes with reference prefix %s of %s",
+					details.ReferencePrefix, penaltyRefType, otherPrefix, other)
+			}
+		}
+	}
+
+	return nil
+}
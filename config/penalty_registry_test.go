@@ -0,0 +1,313 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func loadTestPenaltyRegistry(t *testing.T) *PenaltyDetailsMap {
+	penaltyDetailsMap, err := LoadPenaltyDetails("../assets/penalty_details.yml")
+	if err != nil {
+		t.Fatalf("Failed to load penalty details: %v", err)
+	}
+	return penaltyDetailsMap
+}
+
+func TestUnitGetCompanyCode(t *testing.T) {
+	Convey("Get Company Code from penalty reference type", t, func() {
+		penaltyDetailsMap := loadTestPenaltyRegistry(t)
+
+		testCases := []struct {
+			name          string
+			input         string
+			expectedCode  string
+			expectedError bool
+		}{
+			{
+				name:          "Late Filing",
+				input:         utils.LateFilingPenaltyRefType,
+				expectedCode:  utils.LateFilingPenaltyCompanyCode,
+				expectedError: false,
+			},
+			{
+				name:         "Sanctions",
+				input:        utils.SanctionsPenaltyRefType,
+				expectedCode: utils.SanctionsCompanyCode,
+			},
+			{
+				name:         "Sanctions ROE",
+				input:        utils.SanctionsRoePenaltyRefType,
+				expectedCode: utils.SanctionsCompanyCode,
+			},
+			{
+				name:          "Error invalid penalty reference",
+				input:         "R1234567",
+				expectedCode:  "",
+				expectedError: true,
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				companyCode, err := penaltyDetailsMap.GetCompanyCode(tc.input)
+				Convey(tc.expectedCode, func() {
+					if tc.expectedError {
+						So(err, ShouldNotBeNil)
+					} else {
+						So(err, ShouldBeNil)
+					}
+					So(companyCode, ShouldEqual, tc.expectedCode)
+				})
+			})
+		}
+	})
+}
+
+func TestUnitGetCompanyCodeFromTransaction(t *testing.T) {
+	Convey("Get Company Code from penalty ref", t, func() {
+		penaltyDetailsMap := loadTestPenaltyRegistry(t)
+
+		testCases := []struct {
+			name          string
+			input         []models.TransactionItem
+			expectedCode  string
+			expectedError bool
+		}{
+			{
+				name: "Late Filing",
+				input: []models.TransactionItem{
+					{
+						Amount:     5,
+						Type:       "penalty",
+						PenaltyRef: "A1000007",
+					},
+				},
+				expectedCode:  utils.LateFilingPenaltyCompanyCode,
+				expectedError: false,
+			},
+			{
+				name: "Sanctions",
+				input: []models.TransactionItem{
+					{
+						Amount:     5,
+						Type:       "penalty",
+						PenaltyRef: "P1000007",
+					},
+				},
+				expectedCode: "C1",
+			},
+			{
+				name: "Sanctions ROE",
+				input: []models.TransactionItem{
+					{
+						Amount:     5,
+						Type:       "penalty",
+						PenaltyRef: "U1000007",
+					},
+				},
+				expectedCode: "C1",
+			},
+			{
+				name: "Error unknown penalty reference",
+				input: []models.TransactionItem{
+					{
+						Amount:     5,
+						Type:       "penalty",
+						PenaltyRef: "Q1000007",
+					},
+				},
+				expectedCode:  "",
+				expectedError: true,
+			},
+			{
+				name: "Error no penalty reference",
+				input: []models.TransactionItem{
+					{},
+				},
+				expectedCode:  "",
+				expectedError: true,
+			},
+			{
+				name:          "Error no transaction present",
+				input:         []models.TransactionItem{},
+				expectedCode:  "",
+				expectedError: true,
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				companyCode, err := penaltyDetailsMap.GetCompanyCodeFromTransaction(tc.input)
+				Convey(tc.expectedCode, func() {
+					if tc.expectedError {
+						So(err, ShouldNotBeNil)
+					} else {
+						So(err, ShouldBeNil)
+					}
+					So(companyCode, ShouldEqual, tc.expectedCode)
+				})
+			})
+		}
+	})
+}
+
+func TestUnitGetPenaltyRefTypeFromTransaction(t *testing.T) {
+	Convey("Get penalty reference type from penalty ref", t, func() {
+		penaltyDetailsMap := loadTestPenaltyRegistry(t)
+
+		testCases := []struct {
+			name                   string
+			input                  []models.TransactionItem
+			expectedPenaltyRefType string
+			expectedError          bool
+		}{
+			{
+				name: "Late Filing",
+				input: []models.TransactionItem{
+					{
+						Amount:     5,
+						Type:       "penalty",
+						PenaltyRef: "A1000007",
+					},
+				},
+				expectedPenaltyRefType: utils.LateFilingPenaltyRefType,
+			},
+			{
+				name: "Sanctions",
+				input: []models.TransactionItem{
+					{
+						Amount:     5,
+						Type:       "penalty",
+						PenaltyRef: "P1000007",
+					},
+				},
+				expectedPenaltyRefType: utils.SanctionsPenaltyRefType,
+			},
+			{
+				name: "Sanctions ROE",
+				input: []models.TransactionItem{
+					{
+						Amount:     5,
+						Type:       "penalty",
+						PenaltyRef: "U1000007",
+					},
+				},
+				expectedPenaltyRefType: utils.SanctionsRoePenaltyRefType,
+			},
+			{
+				name: "Error unknown penalty reference",
+				input: []models.TransactionItem{
+					{
+						Amount:     5,
+						Type:       "penalty",
+						PenaltyRef: "Q1000007",
+					},
+				},
+				expectedPenaltyRefType: "",
+				expectedError:          true,
+			},
+			{
+				name: "Error no penalty reference",
+				input: []models.TransactionItem{
+					{},
+				},
+				expectedPenaltyRefType: "",
+				expectedError:          true,
+			},
+			{
+				name:                   "Error no transaction present",
+				input:                  []models.TransactionItem{},
+				expectedPenaltyRefType: "",
+				expectedError:          true,
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				penaltyRefType, err := penaltyDetailsMap.GetPenaltyRefTypeFromTransaction(tc.input)
+				Convey(tc.expectedPenaltyRefType, func() {
+					if tc.expectedError {
+						So(err, ShouldNotBeNil)
+					} else {
+						So(err, ShouldBeNil)
+					}
+					So(penaltyRefType, ShouldEqual, tc.expectedPenaltyRefType)
+				})
+			})
+		}
+	})
+}
+
+func TestUnitValidateRegistry(t *testing.T) {
+	Convey("Validate the penalty reference type registry", t, func() {
+		Convey("The bundled penalty details are valid", func() {
+			So(loadTestPenaltyRegistry(t).validateRegistry(), ShouldBeNil)
+		})
+
+		testCases := []struct {
+			name          string
+			details       map[string]PenaltyDetails
+			expectedError string
+		}{
+			{
+				name: "Error missing company code",
+				details: map[string]PenaltyDetails{
+					utils.LateFilingPenaltyRefType: {ReferencePrefix: "A"},
+				},
+				expectedError: "penalty details for LATE_FILING is missing CompanyCode",
+			},
+			{
+				name: "Error missing reference prefix",
+				details: map[string]PenaltyDetails{
+					utils.LateFilingPenaltyRefType: {CompanyCode: utils.LateFilingPenaltyCompanyCode},
+				},
+				expectedError: "penalty details for LATE_FILING is missing ReferencePrefix",
+			},
+			{
+				name: "Error duplicate reference prefix",
+				details: map[string]PenaltyDetails{
+					utils.SanctionsPenaltyRefType:    {CompanyCode: utils.SanctionsCompanyCode, ReferencePrefix: "P"},
+					utils.SanctionsRoePenaltyRefType: {CompanyCode: utils.SanctionsCompanyCode, ReferencePrefix: "P"},
+				},
+				expectedError: "reference prefix P of SANCTIONS clashes with reference prefix P of SANCTIONS_ROE",
+			},
+			{
+				name: "Error overlapping reference prefix",
+				details: map[string]PenaltyDetails{
+					utils.LateFilingPenaltyRefType: {CompanyCode: utils.LateFilingPenaltyCompanyCode, ReferencePrefix: "AB"},
+					utils.SanctionsPenaltyRefType:  {CompanyCode: utils.SanctionsCompanyCode, ReferencePrefix: "A"},
+				},
+				expectedError: "reference prefix AB of LATE_FILING clashes with reference prefix A of SANCTIONS",
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				penaltyDetailsMap := &PenaltyDetailsMap{Details: tc.details}
+				So(penaltyDetailsMap.validateRegistry(), ShouldBeError, tc.expectedError)
+			})
+		}
+	})
+}
+
+func TestUnitIsPenaltyReference(t *testing.T) {
+	Convey("Is penalty reference", t, func() {
+		penaltyDetailsMap := loadTestPenaltyRegistry(t)
+
+		Convey("A reference prefix followed by seven digits is a penalty reference", func() {
+			for _, penaltyRef := range []string{"A1234567", "P1234567", "U1234567"} {
+				So(penaltyDetailsMap.IsPenaltyReference(penaltyRef), ShouldBeTrue)
+			}
+		})
+
+		Convey("Any other reference is not a penalty reference", func() {
+			for _, penaltyRef := range []string{"", "A123456", "A12345678", "R1234567", "a1234567", "A1234567.data", "$where"} {
+				So(penaltyDetailsMap.IsPenaltyReference(penaltyRef), ShouldBeFalse)
+			}
+		})
+	})
+}
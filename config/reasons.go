@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+)
+
+// ReasonsCatalogue holds the localised reasons given for penalty transactions
+type ReasonsCatalogue struct {
+	Description string   `yaml:"description"`
+	Reasons     []Reason `yaml:"reasons"`
+}
+
+// Reason is the localised text given for transactions matching its company code, transaction type and
+// transaction subtype. Fields that are not set match any value.
+type Reason struct {
+	CompanyCode        string            `yaml:"company_code"`
+	TransactionType    string            `yaml:"transaction_type"`
+	TransactionSubType string            `yaml:"transaction_sub_type"`
+	Text               map[string]string `yaml:"text"`
+}
+
+// GetReason returns the text of the first reason that matches the transaction in the requested language,
+// falling back to English. An empty string is returned if no reason matches.
+func (c *ReasonsCatalogue) GetReason(companyCode, transactionType, transactionSubType, language string) string {
+	for _, reason := range c.Reasons {
+		if reason.matches(companyCode, transactionType, transactionSubType) {
+			return localise(reason.Text, language)
+		}
+	}
+	return ""
+}
+
+// Translate returns the requested language version of English reason text, such as the reason stored
+// against a payable resource. Text that is not in the catalogue is returned unchanged.
+func (c *ReasonsCatalogue) Translate(text, language string) string {
+	for _, reason := range c.Reasons {
+		if reason.Text[utils.EnglishLanguage] == text {
+			return localise(reason.Text, language)
+		}
+	}
+	return text
+}
+
+func (r Reason) matches(companyCode, transactionType, transactionSubType string) bool {
+	return (r.CompanyCode == "" || r.CompanyCode == companyCode) &&
+		(r.TransactionType == "" || r.TransactionType == transactionType) &&
+		(r.TransactionSubType == "" || r.TransactionSubType == transactionSubType)
+}
+
+func localise(text map[string]string, language string) string {
+	if localised := text[language]; localised != "" {
+		return localised
+	}
+	return text[utils.EnglishLanguage]
+}
+
+func LoadReasonsCatalogue(fileName string) (*ReasonsCatalogue, error) {
+	yamlFile, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReasonsCatalogue(yamlFile)
+}
+
+func parseReasonsCatalogue(yamlFile []byte) (*ReasonsCatalogue, error) {
+	var reasonsCatalogue ReasonsCatalogue
+
+	err := yaml.UnmarshalStrict(yamlFile, &reasonsCatalogue)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reasonsCatalogue, nil
+}
+
+// ValidateReasonsCatalogue checks that the catalogue has at least one reason, that every reason has
+// English text and that it is only translated into supported languages
+func ValidateReasonsCatalogue(reasonsCatalogue *ReasonsCatalogue) error {
+	if reasonsCatalogue == nil || len(reasonsCatalogue.Reasons) == 0 {
+		return errors.New("reasons catalogue must contain at least one reason")
+	}
+
+	for i, reason := range reasonsCatalogue.Reasons {
+		if reason.Text[utils.EnglishLanguage] == "" {
+			return fmt.Errorf("reason %d is missing %s text", i+1, utils.EnglishLanguage)
+		}
+		for language := range reason.Text {
+			if !slices.Contains(utils.SupportedLanguages, language) {
+				return fmt.Errorf("reason %d has text in unsupported language %s", i+1, language)
+			}
+		}
+	}
+
+	return nil
+}
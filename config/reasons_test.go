@@ -0,0 +1,108 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitReasonsCatalogue(t *testing.T) {
+	reasonsCatalogue := &ReasonsCatalogue{
+		Reasons: []Reason{
+			{CompanyCode: "LP", TransactionType: "1", Text: map[string]string{"en": "Late filing of accounts", "cy": "Cyflwyno cyfrifon yn hwyr"}},
+			{CompanyCode: "C1", TransactionType: "1", TransactionSubType: "S1", Text: map[string]string{"en": "Failure to file a confirmation statement"}},
+			{TransactionType: "1", Text: map[string]string{"en": "Penalty", "cy": "Cosb"}},
+		},
+	}
+
+	Convey("Get reason", t, func() {
+		testCases := []struct {
+			name               string
+			companyCode        string
+			transactionType    string
+			transactionSubType string
+			language           string
+			want               string
+		}{
+			{name: "English", companyCode: "LP", transactionType: "1", transactionSubType: "EU", language: utils.EnglishLanguage, want: "Late filing of accounts"},
+			{name: "Welsh", companyCode: "LP", transactionType: "1", transactionSubType: "EU", language: utils.WelshLanguage, want: "Cyflwyno cyfrifon yn hwyr"},
+			{name: "Welsh falls back to English", companyCode: "C1", transactionType: "1", transactionSubType: "S1", language: utils.WelshLanguage, want: "Failure to file a confirmation statement"},
+			{name: "First matching reason is used", companyCode: "C1", transactionType: "1", transactionSubType: "S2", language: utils.WelshLanguage, want: "Cosb"},
+			{name: "No matching reason", companyCode: "C1", transactionType: "5", transactionSubType: "02", language: utils.EnglishLanguage, want: ""},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				So(reasonsCatalogue.GetReason(tc.companyCode, tc.transactionType, tc.transactionSubType, tc.language), ShouldEqual, tc.want)
+			})
+		}
+	})
+
+	Convey("Translate reason", t, func() {
+		So(reasonsCatalogue.Translate("Late filing of accounts", utils.WelshLanguage), ShouldEqual, "Cyflwyno cyfrifon yn hwyr")
+		So(reasonsCatalogue.Translate("Late filing of accounts", utils.EnglishLanguage), ShouldEqual, "Late filing of accounts")
+		So(reasonsCatalogue.Translate("Not in the catalogue", utils.WelshLanguage), ShouldEqual, "Not in the catalogue")
+	})
+}
+
+func TestUnitLoadReasonsCatalogue(t *testing.T) {
+	Convey("The reasons catalogue asset is valid", t, func() {
+		reasonsCatalogue, err := LoadReasonsCatalogue("../assets/reasons.yml")
+
+		So(err, ShouldBeNil)
+		So(ValidateReasonsCatalogue(reasonsCatalogue), ShouldBeNil)
+		for _, reason := range reasonsCatalogue.Reasons {
+			So(reason.Text[utils.WelshLanguage], ShouldNotBeEmpty)
+		}
+	})
+
+	Convey("Missing file", t, func() {
+		reasonsCatalogue, err := LoadReasonsCatalogue("missing.yml")
+
+		So(reasonsCatalogue, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestUnitValidateReasonsCatalogue(t *testing.T) {
+	Convey("Validate reasons catalogue", t, func() {
+		testCases := []struct {
+			name             string
+			reasonsCatalogue *ReasonsCatalogue
+			wantErr          string
+		}{
+			{
+				name:             "Valid catalogue",
+				reasonsCatalogue: &ReasonsCatalogue{Reasons: []Reason{{Text: map[string]string{"en": "Penalty", "cy": "Cosb"}}}},
+			},
+			{
+				name:             "No reasons",
+				reasonsCatalogue: &ReasonsCatalogue{},
+				wantErr:          "reasons catalogue must contain at least one reason",
+			},
+			{
+				name:             "Missing English text",
+				reasonsCatalogue: &ReasonsCatalogue{Reasons: []Reason{{Text: map[string]string{"cy": "Cosb"}}}},
+				wantErr:          "reason 1 is missing en text",
+			},
+			{
+				name:             "Unsupported language",
+				reasonsCatalogue: &ReasonsCatalogue{Reasons: []Reason{{Text: map[string]string{"en": "Penalty", "fr": "Pénalité"}}}},
+				wantErr:          "reason 1 has text in unsupported language fr",
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				err := ValidateReasonsCatalogue(tc.reasonsCatalogue)
+
+				if tc.wantErr == "" {
+					So(err, ShouldBeNil)
+				} else {
+					So(err.Error(), ShouldEqual, tc.wantErr)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+)
+
+const (
+	// DefaultBulkLookupConcurrency is how many customers are requested from E5 at once if it is not configured
+	DefaultBulkLookupConcurrency = 5
+	maxBulkLookupCustomerCodes   = 100
+)
+
+// BulkPenaltiesRequest is the body of a request for the penalties of several customers
+type BulkPenaltiesRequest struct {
+	CustomerCodes        []string `json:"customer_codes"`
+	PenaltyReferenceType string   `json:"penalty_reference_type"`
+}
+
+// BulkPenaltiesResponse is the penalties of each customer in the order they were requested
+type BulkPenaltiesResponse struct {
+	PenaltyReferenceType string                `json:"penalty_reference_type"`
+	Results              []BulkPenaltiesResult `json:"results"`
+}
+
+// BulkPenaltiesResult is the penalties of one customer, with the status that GET penalties would have
+// responded with for the customer
+type BulkPenaltiesResult struct {
+	CustomerCode string                          `json:"customer_code"`
+	Status       int                             `json:"status"`
+	Penalties    *models.TransactionListResponse `json:"penalties,omitempty"`
+	Error        string                          `json:"error,omitempty"`
+}
+
+// HandleBulkGetPenalties retrieves the penalties of every customer code in the request. The customers are
+// requested through AccountPenalties with at most concurrency requests in progress at once, the failure of
+// one customer does not fail the request.
+func HandleBulkGetPenalties(apDaoSvc dao.AccountPenaltiesDaoService, penaltyDetailsMap *config.PenaltyDetailsMap,
+	allowedTransactionsMap *models.AllowedTransactionMap, payableStatusRules *config.PayableStatusRules,
+	reasonsCatalogue *config.ReasonsCatalogue, concurrency int) http.HandlerFunc {
+	if concurrency <= 0 {
+		concurrency = DefaultBulkLookupConcurrency
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestId := log.Context(req)
+		log.InfoC(requestId, "start POST bulk penalties request")
+
+		var request BulkPenaltiesRequest
+		err := json.NewDecoder(req.Body).Decode(&request)
+		if err != nil {
+			message := "failed to read request body"
+			log.ErrorC(requestId, fmt.Errorf(message+": %v", err))
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidRequestBody, message)
+			return
+		}
+
+		customerCodes, err := validateBulkCustomerCodes(request.CustomerCodes)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("invalid bulk penalties request: %v", err))
+			writeErrorProblem(w, req, http.StatusBadRequest, err, utils.ErrorCodeValidationFailed, err.Error())
+			return
+		}
+
+		penaltyRefType := GetPenaltyRefType(request.PenaltyReferenceType)
+		companyCode, err := getCompanyCode(penaltyDetailsMap, penaltyRefType)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReferenceType, "invalid penalty reference type supplied")
+			return
+		}
+
+		language := utils.GetLanguage(req)
+		results := make([]BulkPenaltiesResult, len(customerCodes))
+		semaphore := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i, customerCode := range customerCodes {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func() {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+				params := types.AccountPenaltiesParams{
+					PenaltyRefType:             penaltyRefType,
+					CustomerCode:               customerCode,
+					CompanyCode:                companyCode,
+					PenaltyDetailsMap:          penaltyDetailsMap,
+					AllowedTransactionsMap:     allowedTransactionsMap,
+					PayableStatusRules:         payableStatusRules,
+					ReasonsCatalogue:           reasonsCatalogue,
+					Language:                   language,
+					AccountPenaltiesDaoService: apDaoSvc,
+					RequestId:                  requestId,
+				}
+				results[i] = getBulkPenaltiesResult(params)
+			}()
+		}
+		wg.Wait()
+
+		utils.SetContentLanguage(w, language)
+		utils.WriteJSON(w, req, BulkPenaltiesResponse{
+			PenaltyReferenceType: penaltyRefType,
+			Results:              results,
+		})
+
+		log.InfoC(requestId, "POST bulk penalties request completed successfully", log.Data{
+			"customer_codes": len(customerCodes),
+		})
+	}
+}
+
+// validateBulkCustomerCodes removes blank and repeated customer codes, so that each customer is only
+// requested once, and checks that the number of customers is within the limit
+func validateBulkCustomerCodes(customerCodes []string) ([]string, error) {
+	seen := map[string]bool{}
+	validCustomerCodes := make([]string, 0, len(customerCodes))
+	for _, customerCode := range customerCodes {
+		customerCode = strings.TrimSpace(customerCode)
+		if customerCode == "" || seen[customerCode] {
+			continue
+		}
+		seen[customerCode] = true
+		validCustomerCodes = append(validCustomerCodes, customerCode)
+	}
+
+	if len(validCustomerCodes) == 0 {
+		return nil, newFieldError("customer_codes", "customer_codes must contain at least one customer code")
+	}
+	if len(validCustomerCodes) > maxBulkLookupCustomerCodes {
+		return nil, newFieldError("customer_codes", "customer_codes must not contain more than %d customer codes", maxBulkLookupCustomerCodes)
+	}
+	return validCustomerCodes, nil
+}
+
+func getBulkPenaltiesResult(params types.AccountPenaltiesParams) BulkPenaltiesResult {
+	result := BulkPenaltiesResult{CustomerCode: params.CustomerCode}
+
+	transactionListResponse, responseType, err := accountPenalties(params)
+	if err == nil && responseType == services.Success {
+		result.Status = http.StatusOK
+		result.Penalties = transactionListResponse
+		return result
+	}
+
+	log.ErrorC(params.RequestId, fmt.Errorf("error calling e5 to get transactions: %v", err), log.Data{
+		"customer_code": params.CustomerCode,
+	})
+	switch responseType {
+	case services.InvalidData:
+		result.Status = http.StatusBadRequest
+		result.Error = "failed to read finance transactions"
+	default:
+		result.Status = http.StatusInternalServerError
+		result.Error = "there was a problem communicating with the finance backend"
+	}
+	return result
+}
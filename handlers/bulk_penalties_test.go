@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serveBulkGetPenalties(body string, concurrency int) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/penalty-payment-api/admin/penalties", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	HandleBulkGetPenalties(nil, &config.PenaltyDetailsMap{}, &models.AllowedTransactionMap{}, nil, nil, concurrency).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestUnitHandleBulkGetPenalties(t *testing.T) {
+	getCompanyCode = func(_ *config.PenaltyDetailsMap, penaltyRefType string) (string, error) {
+		if penaltyRefType != utils.LateFilingPenaltyRefType {
+			return "", errors.New("invalid penalty reference type supplied")
+		}
+		return utils.LateFilingPenaltyCompanyCode, nil
+	}
+	defer func() {
+		getCompanyCode = (*config.PenaltyDetailsMap).GetCompanyCode
+		accountPenalties = api.AccountPenalties
+	}()
+
+	Convey("Given some customers can be retrieved and some cannot", t, func() {
+		var mutex sync.Mutex
+		var requestedCustomerCodes []string
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			mutex.Lock()
+			requestedCustomerCodes = append(requestedCustomerCodes, params.CustomerCode)
+			mutex.Unlock()
+			switch params.CustomerCode {
+			case "22222222":
+				return nil, services.InvalidData, errors.New("invalid transactions")
+			case "33333333":
+				return nil, services.Error, errors.New("e5 unavailable")
+			}
+			return &models.TransactionListResponse{Items: []models.TransactionListItem{{ID: "A1234567"}}}, services.Success, nil
+		}
+
+		rr := serveBulkGetPenalties(`{"customer_codes":["11111111","22222222","33333333","11111111"," "],"penalty_reference_type":"LATE_FILING"}`, 2)
+		var response BulkPenaltiesResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &response)
+
+		Convey("Then each customer has their own status in the order requested", func() {
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(requestedCustomerCodes, ShouldHaveLength, 3)
+			So(response.PenaltyReferenceType, ShouldEqual, utils.LateFilingPenaltyRefType)
+			So(response.Results, ShouldHaveLength, 3)
+
+			So(response.Results[0].CustomerCode, ShouldEqual, "11111111")
+			So(response.Results[0].Status, ShouldEqual, http.StatusOK)
+			So(response.Results[0].Penalties.Items[0].ID, ShouldEqual, "A1234567")
+
+			So(response.Results[1].CustomerCode, ShouldEqual, "22222222")
+			So(response.Results[1].Status, ShouldEqual, http.StatusBadRequest)
+			So(response.Results[1].Penalties, ShouldBeNil)
+
+			So(response.Results[2].CustomerCode, ShouldEqual, "33333333")
+			So(response.Results[2].Status, ShouldEqual, http.StatusInternalServerError)
+			So(response.Results[2].Error, ShouldEqual, "there was a problem communicating with the finance backend")
+		})
+	})
+
+	Convey("Given many customers", t, func() {
+		var mutex sync.Mutex
+		inProgress, maxInProgress := 0, 0
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			mutex.Lock()
+			inProgress++
+			maxInProgress = max(maxInProgress, inProgress)
+			mutex.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mutex.Lock()
+			inProgress--
+			mutex.Unlock()
+			return &models.TransactionListResponse{}, services.Success, nil
+		}
+
+		customerCodes := make([]string, 0, 20)
+		for i := range 20 {
+			customerCodes = append(customerCodes, fmt.Sprintf(`"%08d"`, i))
+		}
+		rr := serveBulkGetPenalties(`{"customer_codes":[`+strings.Join(customerCodes, ",")+`]}`, 3)
+
+		Convey("Then no more than the concurrency limit are requested at once", func() {
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(maxInProgress, ShouldBeGreaterThan, 0)
+			So(maxInProgress <= 3, ShouldBeTrue)
+		})
+	})
+
+	Convey("Given an invalid request", t, func() {
+		So(serveBulkGetPenalties(`not json`, 0).Code, ShouldEqual, http.StatusBadRequest)
+		So(serveBulkGetPenalties(`{"customer_codes":[]}`, 0).Code, ShouldEqual, http.StatusBadRequest)
+		So(serveBulkGetPenalties(`{"customer_codes":["11111111"],"penalty_reference_type":"UNKNOWN"}`, 0).Code, ShouldEqual, http.StatusBadRequest)
+
+		tooMany := make([]string, 0, maxBulkLookupCustomerCodes+1)
+		for i := range maxBulkLookupCustomerCodes + 1 {
+			tooMany = append(tooMany, fmt.Sprintf(`"%08d"`, i))
+		}
+		So(serveBulkGetPenalties(`{"customer_codes":[`+strings.Join(tooMany, ",")+`]}`, 0).Code, ShouldEqual, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+)
+
+// HandleCancelPayableResource cancels the pending payable resource in the request context, so that the user can
+// choose a different way to pay. The resource is kept with the cancelled status, which the payments platform
+// reads from its payment details. Authorisation is handled by the PayableAuthenticationInterceptor.
+func HandleCancelPayableResource(payableResourceService *services.PayableResourceService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestId := log.Context(r)
+		log.InfoC(requestId, "start DELETE payable resource request")
+
+		resource, ok := r.Context().Value(config.PayableResource).(*models.PayableResource)
+		if !ok {
+			log.ErrorC(requestId, fmt.Errorf("invalid PayableResource in request context"))
+			writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodePayableResourceMissing, "the payable resource is not present in the request context")
+			return
+		}
+		logContext := log.Data{"customer_code": resource.CustomerCode, "payable_ref": resource.PayableRef}
+
+		err := payableResourceService.CancelPayableResource(resource, requestIdentity(r), timeNow(), requestId)
+		switch {
+		case errors.Is(err, services.ErrAlreadyPaid):
+			writeProblem(w, r, http.StatusConflict, utils.ErrorCodePenaltyAlreadyPaid, "the payable resource has already been paid")
+			return
+		case errors.Is(err, services.ErrPayableResourceExpired):
+			writeProblem(w, r, http.StatusGone, utils.ErrorCodePayableResourceExpired, "the payable resource has expired")
+			return
+		case errors.Is(err, services.ErrPaymentInProgress):
+			writeProblem(w, r, http.StatusConflict, utils.ErrorCodePaymentInProgress, "a payment for the payable resource is in progress")
+			return
+		case err != nil:
+			log.ErrorC(requestId, err, logContext)
+			writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+		log.InfoC(requestId, "DELETE payable resource request completed successfully", logContext)
+	}
+}
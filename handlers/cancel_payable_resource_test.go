@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitHandleCancelPayableResource(t *testing.T) {
+	Convey("Given a payable resource is cancelled", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		payableResourceService := &services.PayableResourceService{DAO: mockPrDaoSvc}
+		now := time.Now()
+		timeNow = func() time.Time { return now }
+		defer func() { timeNow = time.Now }()
+
+		serve := func(payable *models.PayableResource) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodDelete, "/company/12345678/penalties/payable/abcdef", nil)
+			req.Header.Set("Accept", utils.ProblemContentType)
+			req.Header.Set("ERIC-Identity", "uz3r1D_H3r3")
+			if payable != nil {
+				req = req.WithContext(context.WithValue(req.Context(), config.PayableResource, payable))
+			}
+			res := httptest.NewRecorder()
+			HandleCancelPayableResource(payableResourceService).ServeHTTP(res, req)
+			return res
+		}
+		payable := generateTestPayableResource(true, "A1234567")
+		paymentStartedBefore := now.Add(-services.DefaultPaymentInProgressTimeout)
+
+		Convey("Then a pending payable resource is cancelled by the user", func() {
+			mockPrDaoSvc.EXPECT().CancelPayableResource("12345678", "abcdef", "uz3r1D_H3r3", paymentStartedBefore, gomock.Any()).
+				Return(true, nil)
+
+			So(serve(&payable).Code, ShouldEqual, http.StatusNoContent)
+		})
+
+		Convey("Then a cancelled payable resource is not cancelled again", func() {
+			payable.Payment.Status = dao.PaymentStatusCancelled
+
+			So(serve(&payable).Code, ShouldEqual, http.StatusNoContent)
+		})
+
+		Convey("Then a paid payable resource cannot be cancelled", func() {
+			payable.Payment.Status = "paid"
+
+			res := serve(&payable)
+
+			So(res.Code, ShouldEqual, http.StatusConflict)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodePenaltyAlreadyPaid)
+		})
+
+		Convey("Then an expired payable resource cannot be cancelled", func() {
+			payable.Payment.Status = dao.PaymentStatusExpired
+
+			res := serve(&payable)
+
+			So(res.Code, ShouldEqual, http.StatusGone)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodePayableResourceExpired)
+		})
+
+		Convey("Then a payable resource cannot be cancelled while a payment is in progress", func() {
+			mockPrDaoSvc.EXPECT().CancelPayableResource("12345678", "abcdef", "uz3r1D_H3r3", paymentStartedBefore, gomock.Any()).
+				Return(false, nil)
+
+			res := serve(&payable)
+
+			So(res.Code, ShouldEqual, http.StatusConflict)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodePaymentInProgress)
+		})
+
+		Convey("Then an error cancelling the payable resource is an internal error", func() {
+			mockPrDaoSvc.EXPECT().CancelPayableResource("12345678", "abcdef", "uz3r1D_H3r3", paymentStartedBefore, gomock.Any()).
+				Return(false, errors.New("error"))
+
+			So(serve(&payable).Code, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Convey("Then the payable resource must be in the request context", func() {
+			So(serve(nil).Code, ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+}
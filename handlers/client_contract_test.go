@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/client"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/openapi"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"github.com/companieshouse/penalty-payment-api/middleware"
+	"github.com/companieshouse/penalty-payment-api/mocks"
+	"github.com/companieshouse/penalty-payment-api/spec"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// checkResponses records the responses of the handlers that do not match the OpenAPI document
+func checkResponses(document *openapi.Document, mismatches *[]string) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pathTemplate, _ := mux.CurrentRoute(r).GetPathTemplate()
+			operation, ok := document.Operation(r.Method, pathTemplate)
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, r)
+			if ok {
+				err := document.ValidateResponse(operation, rr.Code, rr.Header().Get("Content-Type"), rr.Body.Bytes())
+				if err != nil {
+					*mismatches = append(*mismatches, r.Method+" "+pathTemplate+": "+err.Error())
+				}
+			}
+
+			for name, values := range rr.Header() {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(rr.Code)
+			_, _ = w.Write(rr.Body.Bytes())
+		})
+	}
+}
+
+// TestUnitClientContract calls the real router with the client, checking that the requests of the client
+// are accepted and that the responses of the handlers match the OpenAPI document and decode into the client
+func TestUnitClientContract(t *testing.T) {
+	document, err := openapi.Load(spec.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Given the router is served with the client contract checks", t, func() {
+		getCompanyCode = (*config.PenaltyDetailsMap).GetCompanyCode
+		getCompanyCodeFromTransaction = (*config.PenaltyDetailsMap).GetCompanyCodeFromTransaction
+		getPenaltyRefTypeFromTransaction = (*config.PenaltyDetailsMap).GetPenaltyRefTypeFromTransaction
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			return &models.TransactionListResponse{
+				TotalResults: 1,
+				Items: []models.TransactionListItem{{
+					ID:              "A1234567",
+					Kind:            "late-filing-penalty#late-filing-penalty",
+					DueDate:         "2025-03-01",
+					MadeUpDate:      "2024-06-30",
+					TransactionDate: "2025-02-01",
+					OriginalAmount:  150,
+					Outstanding:     150,
+					Type:            types.Penalty.String(),
+					Reason:          "Late filing of accounts",
+					PayableStatus:   private.OpenPayableStatus,
+				}},
+			}, services.Success, nil
+		}
+		penaltyPayability = func(params types.AccountPenaltiesParams, penaltyRef string, amount *float64) (*api.PenaltyPayabilityReport, services.ResponseType, error) {
+			return &api.PenaltyPayabilityReport{
+				Penalty: models.TransactionListItem{ID: penaltyRef, PayableStatus: private.OpenPayableStatus},
+				E5Transaction: models.AccountPenaltiesDataDao{
+					Amount:            150,
+					OutstandingAmount: 150,
+				},
+			}, services.Success, nil
+		}
+		payablePenalty = func(params types.PayablePenaltyParams) (*models.TransactionItem, error) {
+			transaction := params.Transaction
+			transaction.Type = types.Penalty.String()
+			transaction.MadeUpDate = "2024-06-30"
+			transaction.Reason = "Late filing of accounts"
+			return &transaction, nil
+		}
+		defer func() {
+			accountPenalties = api.AccountPenalties
+			penaltyPayability = api.PenaltyPayability
+			payablePenalty = api.PayablePenalty
+		}()
+
+		penaltyConfigProvider, err := newTestPenaltyConfigProvider()
+		So(err, ShouldBeNil)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(mockCtrl)
+
+		var mismatches []string
+		router := mux.NewRouter()
+		maintenanceWindowsCache := api.NewMaintenanceWindowsCache(&fakeMaintenanceWindowDaoService{}, time.Minute)
+		So(api.LoadMaintenanceSchedule(&config.Config{}), ShouldBeNil)
+		idempotencyKeys := NewIdempotencyKeys(&fakeIdempotencyKeyDaoService{}, time.Hour)
+		prSearchDaoSvc := &fakePayableResourceSearchDaoService{}
+		Register(router, &config.Config{}, mockPrDaoSvc, mockApDaoSvc, prSearchDaoSvc, penaltyConfigProvider,
+			maintenanceWindowsCache, idempotencyKeys, middleware.NewRequestValidator(document, middleware.DefaultMaxRequestBodyBytes, false))
+		router.Use(checkResponses(document, &mismatches))
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		newClient := func(authenticator client.Authenticator) *client.Client {
+			c := client.NewClient(server.URL, authenticator)
+			c.HTTPClient = server.Client()
+			c.MaxAttempts = 1
+			return c
+		}
+		user := newClient(client.OAuth2User("user-id", "demo@ch.gov.uk", "Demo", "User"))
+		apiKey := newClient(client.ElevatedAPIKey("api-key"))
+		ctx := context.Background()
+
+		Convey("Then the health checks respond", func() {
+			healthcheck, err := apiKey.Healthcheck(ctx)
+			So(err, ShouldBeNil)
+			So(healthcheck.Message, ShouldEqual, "HEALTHY")
+
+			financeHealthcheck, err := apiKey.FinanceHealthcheck(ctx)
+			So(err, ShouldBeNil)
+			So(financeHealthcheck.Message, ShouldEqual, "HEALTHY")
+
+			penaltyRefTypes, err := apiKey.GetPenaltyReferenceTypes(ctx)
+			So(err, ShouldBeNil)
+			So(penaltyRefTypes, ShouldNotBeEmpty)
+			So(mismatches, ShouldBeEmpty)
+		})
+
+		Convey("Then maintenance windows can be created, listed and cancelled", func() {
+			start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+			created, err := apiKey.CreateMaintenanceWindow(ctx, "E5 upgrade", start, start.Add(time.Hour))
+			So(err, ShouldBeNil)
+			So(created.ID, ShouldNotBeEmpty)
+
+			maintenanceWindows, err := apiKey.GetMaintenanceWindows(ctx)
+			So(err, ShouldBeNil)
+			So(maintenanceWindows, ShouldHaveLength, 1)
+			So(maintenanceWindows[0].Name, ShouldEqual, "E5 upgrade")
+
+			So(apiKey.CancelMaintenanceWindow(ctx, created.ID), ShouldBeNil)
+			So(mismatches, ShouldBeEmpty)
+		})
+
+		Convey("Then the penalties of a customer can be read", func() {
+			penalties, err := user.GetPenalties(ctx, "12345678", utils.LateFilingPenaltyRefType, &client.PenaltiesQuery{
+				PayableStatus: []string{private.OpenPayableStatus},
+				Sort:          "-due_date",
+			})
+			So(err, ShouldBeNil)
+			So(penalties.Items, ShouldHaveLength, 1)
+			So(penalties.Items[0].ID, ShouldEqual, "A1234567")
+
+			legacyPenalties, err := user.GetLateFilingPenalties(ctx, "12345678")
+			So(err, ShouldBeNil)
+			So(legacyPenalties.Items, ShouldHaveLength, 1)
+
+			statement, err := user.GetPenaltyStatement(ctx, "12345678")
+			So(err, ShouldBeNil)
+			So(statement.Sections, ShouldNotBeEmpty)
+
+			payability, err := user.GetPenaltyPayability(ctx, "12345678", utils.LateFilingPenaltyRefType, "A1234567", 150)
+			So(err, ShouldBeNil)
+			So(payability.PenaltyRef, ShouldEqual, "A1234567")
+
+			bulkPenalties, err := apiKey.BulkGetPenalties(ctx, []string{"12345678"}, "")
+			So(err, ShouldBeNil)
+			So(bulkPenalties.Results, ShouldHaveLength, 1)
+			So(bulkPenalties.Results[0].Status, ShouldEqual, http.StatusOK)
+			So(mismatches, ShouldBeEmpty)
+		})
+
+		Convey("Then a penalty can be looked up by its reference", func() {
+			mockApDaoSvc.EXPECT().GetAccountPenaltiesByPenaltyRef(utils.LateFilingPenaltyCompanyCode, "A1234567", gomock.Any()).
+				Return(&models.AccountPenaltiesDao{CustomerCode: "12345678", CompanyCode: utils.LateFilingPenaltyCompanyCode}, nil)
+
+			penaltyLookup, err := user.LookupPenalty(ctx, client.PenaltyLookupRequest{PenaltyRef: "A1234567", CompanyNumber: "12345678"})
+			So(err, ShouldBeNil)
+			So(penaltyLookup.CustomerCode, ShouldEqual, "12345678")
+			So(penaltyLookup.Penalty.ID, ShouldEqual, "A1234567")
+			So(mismatches, ShouldBeEmpty)
+		})
+
+		Convey("Then the penalty ledger of a customer can be exported", func() {
+			mockPrDaoSvc.EXPECT().GetPaidPayableResources("12345678", gomock.Any()).Return(nil, nil).Times(2)
+
+			export, err := user.ExportPenalties(ctx, "12345678", utils.LateFilingPenaltyRefType)
+			So(err, ShouldBeNil)
+			So(export.Items, ShouldHaveLength, 1)
+
+			csv, err := user.ExportPenaltiesCSV(ctx, "12345678", utils.LateFilingPenaltyRefType)
+			So(err, ShouldBeNil)
+			So(string(csv), ShouldStartWith, "penalty_ref,")
+			So(mismatches, ShouldBeEmpty)
+		})
+
+		Convey("Then a payable resource can be created, read and its payment details read", func() {
+			var created *models.PayableResourceDao
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment("12345678", []string{"A1234567"}, gomock.Any(), gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources("12345678", []string{"A1234567"}, gomock.Any(), gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().CreatePayableResource(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(dao *models.PayableResourceDao, _ string) error {
+					created = dao
+					return nil
+				})
+			mockPrDaoSvc.EXPECT().GetPayableResource("12345678", gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_, _, _ string) (*models.PayableResourceDao, error) {
+					return created, nil
+				}).AnyTimes()
+
+			createdResource, err := user.CreatePayable(ctx, "12345678", []models.TransactionItem{
+				{PenaltyRef: "A1234567", Amount: 150},
+			})
+			So(err, ShouldBeNil)
+			So(createdResource.PayableRef, ShouldEqual, created.PayableRef)
+			So(createdResource.Links.Self, ShouldEqual, "/company/12345678/penalties/payable/"+created.PayableRef)
+
+			payableResource, err := user.GetPayable(ctx, "12345678", createdResource.PayableRef)
+			So(err, ShouldBeNil)
+			So(payableResource.CreatedBy.ID, ShouldEqual, "user-id")
+			So(payableResource.Transactions, ShouldHaveLength, 1)
+			So(payableResource.Transactions[0].PenaltyRef, ShouldEqual, "A1234567")
+
+			paymentDetails, err := user.GetPaymentDetails(ctx, "12345678", createdResource.PayableRef)
+			So(err, ShouldBeNil)
+			So(paymentDetails.Status, ShouldEqual, "pending")
+			So(paymentDetails.Items, ShouldHaveLength, 1)
+			So(mismatches, ShouldBeEmpty)
+
+			Convey("And the user can cancel it so that its payment details are cancelled", func() {
+				mockPrDaoSvc.EXPECT().CancelPayableResource("12345678", created.PayableRef, "user-id", gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_, _, _ string, _ time.Time, _ string) (bool, error) {
+						created.Data.Payment.Status = dao.PaymentStatusCancelled
+						return true, nil
+					})
+
+				So(user.CancelPayable(ctx, "12345678", createdResource.PayableRef), ShouldBeNil)
+
+				paymentDetails, err := user.GetPaymentDetails(ctx, "12345678", createdResource.PayableRef)
+				So(err, ShouldBeNil)
+				So(paymentDetails.Status, ShouldEqual, dao.PaymentStatusCancelled)
+				So(mismatches, ShouldBeEmpty)
+			})
+
+			Convey("And it is listed in the payable resources of the user", func() {
+				prSearchDaoSvc.resources = []dao.PayableResourceRecordDao{{PayableResourceDao: *created}}
+
+				payables, err := user.ListPayables(ctx, "12345678", &client.PayablesQuery{Status: []string{"pending"}})
+				So(err, ShouldBeNil)
+				So(payables.TotalResults, ShouldEqual, 1)
+				So(payables.Items[0].PayableRef, ShouldEqual, createdResource.PayableRef)
+				So(payables.Items[0].E5ProcessingState, ShouldEqual, E5ProcessingStateNotPaid)
+				So(mismatches, ShouldBeEmpty)
+			})
+
+			Convey("And support can find it by the email of the user", func() {
+				prSearchDaoSvc.resources = []dao.PayableResourceRecordDao{{PayableResourceDao: *created}}
+
+				payables, err := apiKey.SearchPayables(ctx, &client.PayableSearchQuery{Email: "demo@ch.gov.uk"})
+				So(err, ShouldBeNil)
+				So(payables.TotalResults, ShouldEqual, 1)
+				So(payables.Items[0].CustomerCode, ShouldEqual, "12345678")
+				So(mismatches, ShouldBeEmpty)
+			})
+
+			Convey("And another user cannot mark it as paid", func() {
+				otherUser := newClient(client.OAuth2User("other-user-id", "other@ch.gov.uk", "Other", "User"))
+
+				err := otherUser.MarkAsPaid(ctx, "12345678", createdResource.PayableRef, "payment-ref")
+
+				var apiErr *client.APIError
+				So(errors.As(err, &apiErr), ShouldBeTrue)
+				So(apiErr.StatusCode, ShouldEqual, http.StatusUnauthorized)
+			})
+		})
+
+		Convey("Then a payable resource created with an idempotency key is created once", func() {
+			var created []*models.PayableResourceDao
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment("12345678", []string{"A1234567"}, gomock.Any(), gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources("12345678", []string{"A1234567"}, gomock.Any(), gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().CreatePayableResource(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(dao *models.PayableResourceDao, _ string) error {
+					created = append(created, dao)
+					return nil
+				})
+			mockPrDaoSvc.EXPECT().GetPayableResource("12345678", gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_, _, _ string) (*models.PayableResourceDao, error) {
+					return created[0], nil
+				})
+			transactions := []models.TransactionItem{{PenaltyRef: "A1234567", Amount: 150}}
+
+			first, err := user.CreatePayableWithIdempotencyKey(ctx, "12345678", "key-1", transactions)
+			So(err, ShouldBeNil)
+			repeated, err := user.CreatePayableWithIdempotencyKey(ctx, "12345678", "key-1", transactions)
+			So(err, ShouldBeNil)
+			So(repeated, ShouldResemble, first)
+			So(created, ShouldHaveLength, 1)
+
+			_, err = user.CreatePayableWithIdempotencyKey(ctx, "12345678", "key-1",
+				[]models.TransactionItem{{PenaltyRef: "A1234567", Amount: 100}})
+			var apiErr *client.APIError
+			So(errors.As(err, &apiErr), ShouldBeTrue)
+			So(apiErr.StatusCode, ShouldEqual, http.StatusUnprocessableEntity)
+			So(apiErr.Code, ShouldEqual, utils.ErrorCodeIdempotencyKeyReused)
+		})
+
+		Convey("Then a request that does not match the document is rejected with a problem", func() {
+			_, err := user.CreatePayable(ctx, "12345678", nil)
+
+			var apiErr *client.APIError
+			So(errors.As(err, &apiErr), ShouldBeTrue)
+			So(apiErr.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(apiErr.Code, ShouldEqual, utils.ErrorCodeValidationFailed)
+			So(apiErr.Field, ShouldEqual, "transactions")
+		})
+	})
+}
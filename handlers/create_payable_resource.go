@@ -11,6 +11,7 @@ import (
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
 	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
@@ -20,33 +21,64 @@ import (
 
 var payablePenalty = api.PayablePenalty
 
-// CreatePayableResourceHandler takes a http requests and creates a new payable resource
+// CreatePayableResourceHandler takes a http requests and creates a new payable resource. Payable resources
+// are not created while the finance system is in a maintenance window, as they could not be paid. A request
+// repeated with the same Idempotency-Key gets the payable resource created by the first request, and a pending
+// payable resource of the user for the same transactions is returned rather than another being created.
 func CreatePayableResourceHandler(prDaoSvc dao.PayableResourceDaoService, apDaoSvc dao.AccountPenaltiesDaoService,
-	penaltyDetailsMap *config.PenaltyDetailsMap, allowedTransactionMap *models.AllowedTransactionMap) http.Handler {
+	penaltyDetailsMap *config.PenaltyDetailsMap, allowedTransactionMap *models.AllowedTransactionMap,
+	payableStatusRules *config.PayableStatusRules, reasonsCatalogue *config.ReasonsCatalogue,
+	maintenanceWindowsCache *api.MaintenanceWindowsCache, idempotencyKeys *IdempotencyKeys,
+	payableResourceService *services.PayableResourceService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestId := log.Context(r)
 		log.InfoC(requestId, "start POST payable resource request")
 
+		if writeMaintenanceResponse(w, r, maintenanceWindowsCache) {
+			return
+		}
+
 		request, err := decodeRequest(r)
 		if err != nil {
 			message := "failed to read request body"
 			log.ErrorR(r, fmt.Errorf(message+": %v", err))
-			utils.WriteJSONWithStatus(w, r, models.NewMessageResponse(message), http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodeInvalidRequestBody, message)
 			return
 		}
 
-		authUserDetails, companyCode, penaltyRefType, failedValidation := extractRequestData(w, r, request)
+		authUserDetails, companyCode, penaltyRefType, failedValidation := extractRequestData(w, r, request, penaltyDetailsMap)
 		if failedValidation {
 			log.ErrorC(requestId, errors.New("error extracting request data"))
 			return
 		}
 
+		// penalties of a type whose payments are not enabled cannot be paid, so no payable resource is created
+		if reason := penaltyDetailsMap.Details[penaltyRefType].PaymentsDisabledReason(timeNow()); reason != "" {
+			log.InfoC(requestId, "payments not enabled for penalty reference type", log.Data{
+				"penalty_reference_type": penaltyRefType, "reason": reason})
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodePaymentsNotEnabled, reason)
+			return
+		}
+
 		customerCode := r.Context().Value(config.CustomerCode).(string)
 
 		request.CustomerCode = strings.ToUpper(customerCode)
 		request.CreatedBy = authUserDetails
 		log.DebugC(requestId, "successfully extracted request data", log.Data{"request": request})
 
+		idempotencyKey, done := idempotencyKeys.reserve(w, r, prDaoSvc, request, authUserDetails.ID)
+		if done {
+			return
+		}
+		if idempotencyKey != nil {
+			// the key is released if the payable resource is not created so the request can be retried
+			defer func() {
+				if !idempotencyKey.IsComplete() {
+					idempotencyKeys.release(idempotencyKey, requestId)
+				}
+			}()
+		}
+
 		// Create validation context
 		validationCtx := validationContext{
 			PenaltyRefType:         penaltyRefType,
@@ -56,12 +88,15 @@ func CreatePayableResourceHandler(prDaoSvc dao.PayableResourceDaoService, apDaoS
 			AccountPenaltiesDao:    apDaoSvc,
 			PenaltyDetailsMap:      penaltyDetailsMap,
 			AllowedTransactionsMap: allowedTransactionMap,
+			PayableStatusRules:     payableStatusRules,
+			ReasonsCatalogue:       reasonsCatalogue,
 		}
 
 		payablePenalties, err := validateTransactions(request.Transactions, validationCtx)
 		if err != nil {
-			log.ErrorC(requestId, errors.New("invalid request - failed matching against e5"))
-			utils.WriteJSONWithStatus(w, r, models.NewMessageResponse("one or more of the transactions you want to pay for do not exist or are not payable at this time"), http.StatusBadRequest)
+			log.ErrorC(requestId, fmt.Errorf("invalid request - failed matching against e5: %v", err))
+			writeErrorProblem(w, r, http.StatusBadRequest, err, utils.ErrorCodeValidationFailed,
+				"one or more of the transactions you want to pay for do not exist or are not payable at this time")
 			return
 		}
 
@@ -72,18 +107,50 @@ func CreatePayableResourceHandler(prDaoSvc dao.PayableResourceDaoService, apDaoS
 
 		if err != nil {
 			log.ErrorC(requestId, errors.New("invalid request - failed validation"))
-			utils.WriteJSONWithStatus(w, r, models.NewMessageResponse("invalid request body"), http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodeValidationFailed, "invalid request body")
 			return
 		}
 
-		log.DebugC(requestId, "request transactions validated, creating payable resource", log.Data{"request": request})
+		// a pending payable resource of the penalties is reused so that the penalties cannot be paid twice
+		var model *models.PayableResourceDao
+		if payableResourceService != nil {
+			model, err = payableResourceService.GetReusablePayableResource(request, penaltyDetailsMap, timeNow(), requestId)
+			if errors.Is(err, services.ErrPaymentInProgress) {
+				writeProblem(w, r, http.StatusConflict, utils.ErrorCodePaymentInProgress,
+					"a payment for one or more of the transactions you want to pay for is already in progress")
+				return
+			}
+			if err != nil {
+				log.ErrorC(requestId, err)
+				writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+				return
+			}
+		}
 
-		model := transformers.PayableResourceRequestToDB(&request, requestId)
+		if model == nil {
+			log.DebugC(requestId, "request transactions validated, creating payable resource", log.Data{"request": request})
+
+			model = transformers.PayableResourceRequestToDB(&request, requestId)
+
+			if payableResourceService != nil {
+				err = payableResourceService.CreatePayableResource(model, timeNow(), requestId)
+			} else {
+				err = prDaoSvc.CreatePayableResource(model, requestId)
+			}
+			if errors.Is(err, services.ErrPaymentInProgress) {
+				writeProblem(w, r, http.StatusConflict, utils.ErrorCodePaymentInProgress,
+					"a payment for one or more of the transactions you want to pay for is already in progress")
+				return
+			}
+			if err != nil {
+				log.ErrorC(requestId, errors.New("failed to create payable request in database"))
+				writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+				return
+			}
+		}
 
-		if err := prDaoSvc.CreatePayableResource(model, requestId); err != nil {
-			log.ErrorC(requestId, errors.New("failed to create payable request in database"))
-			utils.WriteJSONWithStatus(w, r, models.NewMessageResponse("there was a problem handling your request"), http.StatusInternalServerError)
-			return
+		if idempotencyKey != nil {
+			idempotencyKeys.complete(idempotencyKey, model.PayableRef, requestId)
 		}
 
 		payableResource := transformers.PayableResourceDaoToCreatedResponse(model)
@@ -103,27 +170,28 @@ func decodeRequest(r *http.Request) (models.PayableRequest, error) {
 }
 
 // extractRequestData extracts auth details, company code and penalty ref type from the request/context
-func extractRequestData(w http.ResponseWriter, r *http.Request, request models.PayableRequest) (authentication.AuthUserDetails, string, string, bool) {
+func extractRequestData(w http.ResponseWriter, r *http.Request, request models.PayableRequest,
+	penaltyDetailsMap *config.PenaltyDetailsMap) (authentication.AuthUserDetails, string, string, bool) {
 	var authUserDetails authentication.AuthUserDetails
 
 	userDetailsValue := r.Context().Value(authentication.ContextKeyUserDetails)
 	if userDetailsValue == nil {
 		log.ErrorR(r, errors.New("user details not in context"))
-		utils.WriteJSONWithStatus(w, r, models.NewMessageResponse("user details not in request context"), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodeUserDetailsMissing, "user details not in request context")
 		return authUserDetails, "", "", true
 	}
 
-	companyCode, err := getCompanyCodeFromTransaction(request.Transactions)
+	companyCode, err := getCompanyCodeFromTransaction(penaltyDetailsMap, request.Transactions)
 	if err != nil {
 		log.ErrorR(r, errors.New("company code cannot be resolved"))
-		utils.WriteJSONWithStatus(w, r, models.NewMessageResponse("company code cannot be resolved"), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReference, "company code cannot be resolved")
 		return authUserDetails, "", "", true
 	}
 
-	penaltyRefType, err := getPenaltyRefTypeFromTransaction(request.Transactions)
+	penaltyRefType, err := getPenaltyRefTypeFromTransaction(penaltyDetailsMap, request.Transactions)
 	if err != nil {
 		log.ErrorR(r, errors.New("penalty reference type cannot be resolved"))
-		utils.WriteJSONWithStatus(w, r, models.NewMessageResponse("penalty reference type cannot be resolved"), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReference, "penalty reference type cannot be resolved")
 		return authUserDetails, "", "", true
 	}
 
@@ -140,12 +208,15 @@ type validationContext struct {
 	AccountPenaltiesDao    dao.AccountPenaltiesDaoService
 	PenaltyDetailsMap      *config.PenaltyDetailsMap
 	AllowedTransactionsMap *models.AllowedTransactionMap
+	PayableStatusRules     *config.PayableStatusRules
+	ReasonsCatalogue       *config.ReasonsCatalogue
 }
 
-// validateTransactions ensures the transactions are valid payable penalties that exist in E5
+// validateTransactions ensures the transactions are valid payable penalties that exist in E5. The error
+// names the first transaction that cannot be paid.
 func validateTransactions(transactions []models.TransactionItem, validationCtx validationContext) ([]models.TransactionItem, error) {
 	var payablePenalties []models.TransactionItem
-	for _, transaction := range transactions {
+	for i, transaction := range transactions {
 		params := types.PayablePenaltyParams{
 			PenaltyRefType:             validationCtx.PenaltyRefType,
 			CustomerCode:               validationCtx.CustomerCode,
@@ -153,12 +224,14 @@ func validateTransactions(transactions []models.TransactionItem, validationCtx v
 			PenaltyDetailsMap:          validationCtx.PenaltyDetailsMap,
 			Transaction:                transaction,
 			AllowedTransactionsMap:     validationCtx.AllowedTransactionsMap,
+			PayableStatusRules:         validationCtx.PayableStatusRules,
+			ReasonsCatalogue:           validationCtx.ReasonsCatalogue,
 			AccountPenaltiesDaoService: validationCtx.AccountPenaltiesDao,
 			RequestId:                  validationCtx.RequestID,
 		}
 		payablePenalty, err := payablePenalty(params)
 		if err != nil {
-			return nil, err
+			return nil, fieldError{field: fmt.Sprintf("transactions[%d]", i), err: err}
 		}
 		payablePenalties = append(payablePenalties, *payablePenalty)
 	}
@@ -8,13 +8,17 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
 	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
 	"github.com/companieshouse/penalty-payment-api/mocks"
 	"github.com/golang/mock/gomock"
@@ -48,7 +52,7 @@ func serveCreatePayableResourceHandler(body []byte, payableResourceService dao.P
 	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
 	res := httptest.NewRecorder()
 
-	handler := CreatePayableResourceHandler(payableResourceService, apDaoSvc, penaltyDetailsMap, allowedTransactionsMap)
+	handler := CreatePayableResourceHandler(payableResourceService, apDaoSvc, penaltyDetailsMap, allowedTransactionsMap, nil, nil, nil, nil, nil)
 	handler.ServeHTTP(res, req.WithContext(testContext(withAuthUserDetails, customerCode)))
 
 	return res
@@ -271,7 +275,7 @@ func TestUnitCreatePayableResourceHandler(t *testing.T) {
 	})
 
 	Convey("Error when company code cannot be resolved", t, func() {
-		getCompanyCodeFromTransaction = func(transactions []models.TransactionItem) (string, error) {
+		getCompanyCodeFromTransaction = func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 			return "", errors.New("no penalty reference found")
 		}
 
@@ -409,9 +413,192 @@ func TestUnitCreatePayableResourceHandler_MockedPayablePenalty(t *testing.T) {
 	})
 }
 
+func TestUnitValidateTransactions(t *testing.T) {
+	Convey("The payable status rules and reasons catalogue are used to validate the transactions", t, func() {
+		var gotParams []types.PayablePenaltyParams
+		payablePenalty = func(params types.PayablePenaltyParams) (*models.TransactionItem, error) {
+			gotParams = append(gotParams, params)
+			return &params.Transaction, nil
+		}
+		defer func() { payablePenalty = api.PayablePenalty }()
+
+		validationCtx := validationContext{
+			PayableStatusRules: &config.PayableStatusRules{DefaultStatus: "CLOSED"},
+			ReasonsCatalogue:   &config.ReasonsCatalogue{},
+		}
+		transactions := []models.TransactionItem{{PenaltyRef: penaltyRef1}, {PenaltyRef: penaltyRef2}}
+
+		payablePenalties, err := validateTransactions(transactions, validationCtx)
+
+		So(err, ShouldBeNil)
+		So(payablePenalties, ShouldResemble, transactions)
+		So(gotParams, ShouldHaveLength, 2)
+		for _, params := range gotParams {
+			So(params.PayableStatusRules, ShouldEqual, validationCtx.PayableStatusRules)
+			So(params.ReasonsCatalogue, ShouldEqual, validationCtx.ReasonsCatalogue)
+		}
+	})
+}
+
 func setGetCompanyCodeFromTransactionMock(companyCode string) {
-	mockedGetCompanyCodeFromTransaction := func(transactions []models.TransactionItem) (string, error) {
+	mockedGetCompanyCodeFromTransaction := func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 		return companyCode, nil
 	}
 	getCompanyCodeFromTransaction = mockedGetCompanyCodeFromTransaction
 }
+
+func TestUnitCreatePayableResourceHandler_Maintenance(t *testing.T) {
+	Convey("Given the finance system is in a maintenance window", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		now := time.Date(2025, time.March, 16, 19, 10, 0, 0, config.MaintenanceLocation)
+		timeNow = func() time.Time {
+			return now
+		}
+		currentMaintenanceWindow = func(_ string, _ time.Time, _ *api.MaintenanceWindowsCache) *config.MaintenanceWindow {
+			return &config.MaintenanceWindow{
+				Name:  "Weekly maintenance",
+				Start: now.Add(-10 * time.Minute),
+				End:   now.Add(20 * time.Minute),
+			}
+		}
+		defer func() {
+			timeNow = time.Now
+			currentMaintenanceWindow = api.CurrentMaintenanceWindow
+		}()
+
+		Convey("When a payable resource is created", func() {
+			body := buildRequestBody(customerCode, false, false, []string{penaltyRef1})
+			res := serveCreatePayableResourceHandler(body, mocks.NewMockPayableResourceDaoService(mockCtrl),
+				mocks.NewMockAccountPenaltiesDaoService(mockCtrl), true, customerCode)
+
+			Convey("Then the service is unavailable until the end of the maintenance window", func() {
+				So(res.Code, ShouldEqual, http.StatusServiceUnavailable)
+				So(res.Header().Get("Retry-After"), ShouldEqual, "1200")
+				So(res.Body.String(), ShouldContainSubstring, `"available_from":"2025-03-16T19:30:00Z"`)
+			})
+		})
+	})
+}
+
+func TestUnitCreatePayableResourceHandler_PaymentsNotEnabled(t *testing.T) {
+	getCompanyCodeFromTransaction = (*config.PenaltyDetailsMap).GetCompanyCodeFromTransaction
+	getPenaltyRefTypeFromTransaction = (*config.PenaltyDetailsMap).GetPenaltyRefTypeFromTransaction
+
+	Convey("Given payments of late filing penalties are not enabled yet", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		details := penaltyDetailsMap.Details[utils.LateFilingPenaltyRefType]
+		details.EnabledFrom = "2999-01-01T00:00:00Z"
+		disabledPenaltyDetailsMap := &config.PenaltyDetailsMap{
+			Name:    penaltyDetailsMap.Name,
+			Details: map[string]config.PenaltyDetails{utils.LateFilingPenaltyRefType: details},
+		}
+
+		Convey("Then a payable resource is not created for a late filing penalty", func() {
+			body := `{"transactions":[{"penalty_ref":"A1234567","amount":150}]}`
+			req := httptest.NewRequest(http.MethodPost, "/company/"+customerCode+"/penalties/payable", strings.NewReader(body))
+			req.Header.Set("Accept", utils.ProblemContentType)
+			res := httptest.NewRecorder()
+			handler := CreatePayableResourceHandler(mocks.NewMockPayableResourceDaoService(mockCtrl),
+				mocks.NewMockAccountPenaltiesDaoService(mockCtrl), disabledPenaltyDetailsMap, allowedTransactionsMap,
+				nil, nil, nil, nil, nil)
+			handler.ServeHTTP(res, req.WithContext(testContext(true, customerCode)))
+
+			So(res.Code, ShouldEqual, http.StatusBadRequest)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodePaymentsNotEnabled)
+			So(res.Body.String(), ShouldContainSubstring, "payments are enabled from 2999-01-01T00:00:00Z")
+		})
+	})
+}
+
+func TestUnitCreatePayableResourceHandler_PendingPayableResource(t *testing.T) {
+	getCompanyCodeFromTransaction = (*config.PenaltyDetailsMap).GetCompanyCodeFromTransaction
+	getPenaltyRefTypeFromTransaction = (*config.PenaltyDetailsMap).GetPenaltyRefTypeFromTransaction
+	payablePenalty = func(params types.PayablePenaltyParams) (*models.TransactionItem, error) {
+		transaction := params.Transaction
+		transaction.Type = types.Penalty.String()
+		return &transaction, nil
+	}
+	defer func() { payablePenalty = api.PayablePenalty }()
+
+	Convey("Given a payable resource is created for a penalty", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		payableResourceService := &services.PayableResourceService{DAO: mockPrDaoSvc}
+		now := time.Now()
+		timeNow = func() time.Time { return now }
+		defer func() { timeNow = time.Now }()
+
+		serve := func() *httptest.ResponseRecorder {
+			body := `{"transactions":[{"penalty_ref":"A1234567","amount":150}]}`
+			req := httptest.NewRequest(http.MethodPost, "/company/"+customerCode+"/penalties/payable", strings.NewReader(body))
+			req.Header.Set("Accept", utils.ProblemContentType)
+			res := httptest.NewRecorder()
+			handler := CreatePayableResourceHandler(mockPrDaoSvc, mocks.NewMockAccountPenaltiesDaoService(mockCtrl),
+				penaltyDetailsMap, allowedTransactionsMap, nil, nil, nil, nil, payableResourceService)
+			handler.ServeHTTP(res, req.WithContext(testContext(true, customerCode)))
+			return res
+		}
+		penaltyRefs := []string{"A1234567"}
+		paymentStartedAfter := now.Add(-services.DefaultPaymentInProgressTimeout)
+		createdAfter := now.Add(-services.DefaultPendingPayableExpiry)
+
+		Convey("Then the pending payable resource of the user for the same transactions is returned", func() {
+			pending := models.PayableResourceDao{
+				CustomerCode: customerCode,
+				PayableRef:   "PR_PENDING",
+				Data: models.PayableResourceDataDao{
+					Transactions: map[string]models.TransactionDao{"A1234567": {Amount: 150}},
+				},
+			}
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, paymentStartedAfter, gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, gomock.Any()).
+				Return([]models.PayableResourceDao{pending}, nil)
+
+			res := serve()
+
+			So(res.Code, ShouldEqual, http.StatusCreated)
+			So(res.Body.String(), ShouldContainSubstring, `"PR_PENDING"`)
+		})
+
+		Convey("Then a payable resource is created if there is no pending payable resource", func() {
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, paymentStartedAfter, gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().CreatePayableResource(gomock.Any(), gomock.Any()).Return(nil)
+
+			So(serve().Code, ShouldEqual, http.StatusCreated)
+		})
+
+		Convey("Then the request conflicts if the penalty is in another pending payable resource when it is created", func() {
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, paymentStartedAfter, gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, gomock.Any()).Return(nil, nil)
+			mockPrDaoSvc.EXPECT().CreatePayableResource(gomock.Any(), gomock.Any()).Return(dao.ErrPenaltyInPendingPayableResource).Times(2)
+			mockPrDaoSvc.EXPECT().ExpirePayableResources(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil)
+
+			res := serve()
+
+			So(res.Code, ShouldEqual, http.StatusConflict)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodePaymentInProgress)
+		})
+
+		Convey("Then the request conflicts if a payment for the penalty is in progress", func() {
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, paymentStartedAfter, gomock.Any()).
+				Return(&models.PayableResourceDao{PayableRef: "PR_IN_PAYMENT"}, nil)
+
+			res := serve()
+
+			So(res.Code, ShouldEqual, http.StatusConflict)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodePaymentInProgress)
+		})
+
+		Convey("Then an error finding pending payable resources is an internal error", func() {
+			mockPrDaoSvc.EXPECT().GetPayableResourceInPayment(customerCode, penaltyRefs, paymentStartedAfter, gomock.Any()).
+				Return(nil, errors.New("error"))
+
+			So(serve().Code, ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+)
+
+// responseFormat is a format a resource can be provided in, with the media type it is negotiated by
+type responseFormat struct {
+	name      string
+	mediaType string
+}
+
+// negotiateFormat returns the name of the format in the format query parameter or, if it is not supplied, the
+// format the Accept header prefers. The first format is the default when there is no Accept header. The status
+// to respond with is returned with any error.
+func negotiateFormat(req *http.Request, resource string, formats []responseFormat) (string, int, error) {
+	names := make([]string, 0, len(formats))
+	mediaTypes := make([]string, 0, len(formats))
+	for _, format := range formats {
+		names = append(names, format.name)
+		mediaTypes = append(mediaTypes, format.mediaType)
+	}
+
+	if name := strings.ToLower(req.URL.Query().Get("format")); name != "" {
+		for _, format := range formats {
+			if format.name == name {
+				return name, http.StatusOK, nil
+			}
+		}
+		return "", http.StatusBadRequest, newFieldError("format", "format must be %s", strings.Join(names, " or "))
+	}
+
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return formats[0].name, http.StatusOK, nil
+	}
+	mediaType := utils.NegotiateMediaType(accept, mediaTypes)
+	for _, format := range formats {
+		if format.mediaType == mediaType {
+			return format.name, http.StatusOK, nil
+		}
+	}
+	return "", http.StatusNotAcceptable, fmt.Errorf("the %s can only be provided as %s", resource, strings.Join(mediaTypes, " or "))
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+)
+
+type healthCheckResponse struct {
+	Message       string                    `json:"message"`
+	PenaltyConfig penaltyConfigHealthStatus `json:"penalty_config"`
+}
+
+type penaltyConfigHealthStatus struct {
+	Version  int       `json:"version"`
+	Hash     string    `json:"hash"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// HandleHealthCheck reports that the service is up along with the version of the penalty config it is serving
+func HandleHealthCheck(penaltyConfigProvider *config.PenaltyConfigProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		penaltyConfig := penaltyConfigProvider.Get()
+
+		utils.WriteJSON(w, r, healthCheckResponse{
+			Message: "HEALTHY",
+			PenaltyConfig: penaltyConfigHealthStatus{
+				Version:  penaltyConfig.Version,
+				Hash:     penaltyConfig.Hash,
+				LoadedAt: penaltyConfig.LoadedAt,
+			},
+		})
+	}
+}
@@ -3,33 +3,57 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/companieshouse/chs.go/log"
-	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 )
 
+var checkScheduledMaintenance = api.CheckScheduledMaintenance
+
+// FinanceHealthcheckResponse is the health of the e5 system with the current and next maintenance windows
+type FinanceHealthcheckResponse struct {
+	Message            string                    `json:"message"`
+	MaintenanceEndTime *time.Time                `json:"maintenance_end_time,omitempty"`
+	CurrentMaintenance *config.MaintenanceWindow `json:"current_maintenance,omitempty"`
+	NextMaintenance    *config.MaintenanceWindow `json:"next_maintenance,omitempty"`
+}
+
 // HandleHealthCheckFinanceSystem checks whether the e5 system is available to take requests
-func HandleHealthCheckFinanceSystem(w http.ResponseWriter, r *http.Request) {
+func HandleHealthCheckFinanceSystem(maintenanceWindowsCache *api.MaintenanceWindowsCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleHealthCheckFinanceSystem(w, r, maintenanceWindowsCache)
+	}
+}
+
+func handleHealthCheckFinanceSystem(w http.ResponseWriter, r *http.Request, maintenanceWindowsCache *api.MaintenanceWindowsCache) {
 	requestId := log.Context(r)
 
-	systemAvailableTime, systemUnavailable, parseError := api.CheckScheduledMaintenance(requestId)
+	maintenanceStatus, err := checkScheduledMaintenance(requestId, timeNow(), maintenanceWindowsCache)
 
-	if parseError {
-		log.ErrorC(requestId, fmt.Errorf("parseError from CheckScheduledMaintenance: [%v]", parseError))
-		m := models.NewMessageResponse("failed to check scheduled maintenance")
-		utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+	if err != nil {
+		log.ErrorC(requestId, fmt.Errorf("error from CheckScheduledMaintenance: [%v]", err))
+		writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "failed to check scheduled maintenance")
 		return
 	}
 
-	if systemUnavailable {
-		m := models.NewMessageTimeResponse("UNHEALTHY - PLANNED MAINTENANCE", systemAvailableTime)
+	if maintenanceStatus.Current != nil {
+		m := FinanceHealthcheckResponse{
+			Message:            "UNHEALTHY - PLANNED MAINTENANCE",
+			MaintenanceEndTime: &maintenanceStatus.Current.End,
+			CurrentMaintenance: maintenanceStatus.Current,
+			NextMaintenance:    maintenanceStatus.Next,
+		}
 		utils.WriteJSONWithStatus(w, r, m, http.StatusServiceUnavailable)
 		log.TraceC(requestId, "Planned maintenance")
 		return
 	}
 
-	m := models.NewMessageResponse("HEALTHY")
+	m := FinanceHealthcheckResponse{
+		Message:         "HEALTHY",
+		NextMaintenance: maintenanceStatus.Next,
+	}
 	utils.WriteJSON(w, r, m)
 }
@@ -1,64 +1,61 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/companieshouse/penalty-payment-api/common/dao"
 	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
 func TestUnitHandleHealthCheckFinance(t *testing.T) {
 
 	cfg, _ := config.Get()
-	now := time.Now()
+	// 19:10 UK time on Sunday 16 March 2025
+	timeNow = func() time.Time {
+		return time.Date(2025, time.March, 16, 19, 10, 0, 0, config.MaintenanceLocation)
+	}
+	defer func() {
+		timeNow = time.Now
+		cfg.WeeklyMaintenanceStartTime = ""
+		cfg.WeeklyMaintenanceEndTime = ""
+		cfg.PlannedMaintenanceStart = ""
+		cfg.PlannedMaintenanceEnd = ""
+	}()
 
 	testCases := []struct {
-		when                           string
-		then                           string
-		status                         int
-		bodyStartsWith                 string
-		weeklyDowntime                 bool
-		plannedDowntime                bool
-		plannedMaintenanceStartInvalid bool
-		plannedMaintenanceEndInvalid   bool
+		when            string
+		then            string
+		status          int
+		body            string
+		weeklyDowntime  bool
+		plannedDowntime bool
 	}{
 		{
 			when:           "When the system is healthy",
 			then:           "Then the status should return 'OK'",
 			status:         http.StatusOK,
-			bodyStartsWith: `{"message":"HEALTHY"`,
-			weeklyDowntime: false, plannedDowntime: false, plannedMaintenanceStartInvalid: false, plannedMaintenanceEndInvalid: false,
+			body:           `{"message":"HEALTHY","next_maintenance":{"name":"Weekly maintenance","start_time":"2025-03-16T20:00:00Z","end_time":"2025-03-16T20:30:00Z"}}`,
+			weeklyDowntime: false, plannedDowntime: false,
 		},
 		{
 			when:           "When the system is not healthy due to weekly downtime",
 			then:           "Then the status should return 'Service Unavailable'",
 			status:         http.StatusServiceUnavailable,
-			bodyStartsWith: `{"message":"UNHEALTHY - PLANNED MAINTENANCE","maintenance_end_time":`,
-			weeklyDowntime: true, plannedDowntime: false, plannedMaintenanceStartInvalid: false, plannedMaintenanceEndInvalid: false,
+			body:           `{"message":"UNHEALTHY - PLANNED MAINTENANCE","maintenance_end_time":"2025-03-16T19:30:00Z","current_maintenance":{"name":"Weekly maintenance","start_time":"2025-03-16T19:00:00Z","end_time":"2025-03-16T19:30:00Z"},"next_maintenance":{"name":"Weekly maintenance","start_time":"2025-03-23T19:00:00Z","end_time":"2025-03-23T19:30:00Z"}}`,
+			weeklyDowntime: true, plannedDowntime: false,
 		},
 		{
 			when:           "When the system is not healthy due to planned maintenance",
 			then:           "Then the status should return 'Service Unavailable'",
 			status:         http.StatusServiceUnavailable,
-			bodyStartsWith: `{"message":"UNHEALTHY - PLANNED MAINTENANCE","maintenance_end_time":`,
-			weeklyDowntime: false, plannedDowntime: true, plannedMaintenanceStartInvalid: false, plannedMaintenanceEndInvalid: false,
-		},
-		{
-			when:           "When the Planned Maintenance Start Config value is invalid",
-			then:           "Then the status should be 'Internal Server Error'",
-			status:         http.StatusInternalServerError,
-			weeklyDowntime: false, plannedDowntime: false, plannedMaintenanceStartInvalid: true, plannedMaintenanceEndInvalid: false,
-		},
-		{
-			when:           "When the Planned Maintenance End Config value is invalid",
-			then:           "Then the status should be 'Internal Server Error'",
-			status:         http.StatusInternalServerError,
-			bodyStartsWith: "",
-			weeklyDowntime: false, plannedDowntime: false, plannedMaintenanceStartInvalid: false, plannedMaintenanceEndInvalid: true,
+			body:           `{"message":"UNHEALTHY - PLANNED MAINTENANCE","maintenance_end_time":"2025-03-17T12:00:00Z","current_maintenance":{"name":"Planned maintenance","start_time":"2025-03-15T12:00:00Z","end_time":"2025-03-17T12:00:00Z"}`,
+			weeklyDowntime: false, plannedDowntime: true,
 		},
 	}
 
@@ -66,42 +63,93 @@ func TestUnitHandleHealthCheckFinance(t *testing.T) {
 
 		for _, tc := range testCases {
 			Convey(tc.when, func() {
-				healthCheckFinanceTestConfigSetup(cfg, now, tc.weeklyDowntime, tc.plannedDowntime, tc.plannedMaintenanceStartInvalid, tc.plannedMaintenanceEndInvalid)
+				healthCheckFinanceTestConfigSetup(cfg, tc.weeklyDowntime, tc.plannedDowntime)
 				req, _ := http.NewRequest("GET", "/penalty-payment-api/healthcheck/finance-system", nil)
 				w := httptest.NewRecorder()
-				HandleHealthCheckFinanceSystem(w, req)
+				HandleHealthCheckFinanceSystem(nil)(w, req)
 
 				Convey(tc.then, func() {
 					So(w.Code, ShouldEqual, tc.status)
 
-					if tc.bodyStartsWith != "" {
+					if tc.body != "" {
 						Convey("And the body of the message should be correct", func() {
-							So(w.Body.String(), ShouldStartWith, tc.bodyStartsWith)
+							So(w.Body.String(), ShouldStartWith, tc.body)
 						})
 					}
 				})
 			})
 		}
+
+		Convey("When the maintenance schedule cannot be checked", func() {
+			checkScheduledMaintenance = func(requestId string, now time.Time, maintenanceWindowsCache *api.MaintenanceWindowsCache) (api.MaintenanceStatus, error) {
+				return api.MaintenanceStatus{}, api.ErrMaintenanceScheduleNotLoaded
+			}
+			defer func() { checkScheduledMaintenance = api.CheckScheduledMaintenance }()
+
+			w := httptest.NewRecorder()
+			HandleHealthCheckFinanceSystem(nil)(w, httptest.NewRequest("GET", "/penalty-payment-api/healthcheck/finance-system", nil))
+
+			Convey("Then the status should be 'Internal Server Error'", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
 	})
 }
 
-func healthCheckFinanceTestConfigSetup(cfg *config.Config, now time.Time, weeklyDowntime, plannedDowntime, plannedMaintenanceStartInvalid, plannedMaintenanceEndInvalid bool) {
-	cfg.WeeklyMaintenanceStartTime = fmt.Sprintf("%02d00", now.Hour())
-	cfg.WeeklyMaintenanceDay = now.Weekday()
+func TestUnitHandleHealthCheckFinanceWithMaintenanceWindows(t *testing.T) {
+	cfg, _ := config.Get()
+	timeNow = func() time.Time {
+		return time.Date(2025, time.March, 16, 19, 10, 0, 0, config.MaintenanceLocation)
+	}
+	defer func() {
+		timeNow = time.Now
+		cfg.WeeklyMaintenanceStartTime = ""
+		cfg.WeeklyMaintenanceEndTime = ""
+	}()
+
+	Convey("Given a maintenance window is stored in mongo", t, func() {
+		healthCheckFinanceTestConfigSetup(cfg, false, false)
+		maintenanceWindowDaoService := &fakeMaintenanceWindowDaoService{
+			windows: []dao.MaintenanceWindowDao{
+				{Name: "E5 upgrade", StartTime: time.Date(2025, time.March, 16, 19, 0, 0, 0, time.UTC), EndTime: time.Date(2025, time.March, 16, 22, 0, 0, 0, time.UTC)},
+			},
+		}
+		maintenanceWindowsCache := api.NewMaintenanceWindowsCache(maintenanceWindowDaoService, time.Minute)
+
+		Convey("Then the finance system is unavailable until the end of the window", func() {
+			w := httptest.NewRecorder()
+			HandleHealthCheckFinanceSystem(maintenanceWindowsCache)(w, httptest.NewRequest("GET", "/penalty-payment-api/healthcheck/finance-system", nil))
+
+			So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+			So(w.Body.String(), ShouldStartWith, `{"message":"UNHEALTHY - PLANNED MAINTENANCE","maintenance_end_time":"2025-03-16T22:00:00Z"`)
+		})
+
+		Convey("When mongo is unavailable then the config maintenance windows are used", func() {
+			maintenanceWindowDaoService.err = errors.New("mongo unavailable")
+
+			w := httptest.NewRecorder()
+			HandleHealthCheckFinanceSystem(maintenanceWindowsCache)(w, httptest.NewRequest("GET", "/penalty-payment-api/healthcheck/finance-system", nil))
+
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldStartWith, `{"message":"HEALTHY","next_maintenance":{"name":"Weekly maintenance"`)
+		})
+	})
+}
+
+func healthCheckFinanceTestConfigSetup(cfg *config.Config, weeklyDowntime, plannedDowntime bool) {
+	cfg.WeeklyMaintenanceDay = time.Sunday
+	cfg.WeeklyMaintenanceEndTime = "2030"
 	if weeklyDowntime {
-		cfg.WeeklyMaintenanceEndTime = fmt.Sprintf("%02d00", now.Hour()+100)
+		cfg.WeeklyMaintenanceStartTime = "1900"
+		cfg.WeeklyMaintenanceEndTime = "1930"
 	} else {
-		cfg.WeeklyMaintenanceEndTime = fmt.Sprintf("%02d00", now.Hour()-100)
+		cfg.WeeklyMaintenanceStartTime = "2000"
 	}
+	cfg.PlannedMaintenanceStart = ""
+	cfg.PlannedMaintenanceEnd = ""
 	if plannedDowntime {
-		cfg.PlannedMaintenanceStart = (now.AddDate(0, 0, -1)).Format("02 Jan 06 15:04 MST")
-		cfg.PlannedMaintenanceEnd = (now.AddDate(0, 0, 1)).Format("02 Jan 06 15:04 MST")
-	}
-	if plannedMaintenanceStartInvalid {
-		cfg.PlannedMaintenanceStart = "invalid"
-	}
-	if plannedMaintenanceEndInvalid {
-		cfg.PlannedMaintenanceStart = (now).Format("02 Jan 06 15:04 MST")
-		cfg.PlannedMaintenanceEnd = "invalid"
+		cfg.PlannedMaintenanceStart = "15 Mar 25 12:00 GMT"
+		cfg.PlannedMaintenanceEnd = "17 Mar 25 12:00 GMT"
 	}
+	So(api.LoadMaintenanceSchedule(cfg), ShouldBeNil)
 }
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/penalty_payments/transformers"
+)
+
+const (
+	// IdempotencyKeyHeader is the header a client sends so that retrying a create payable request does not
+	// create another payable resource
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on a response that was replayed for a repeated Idempotency-Key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// DefaultIdempotencyKeyTTL is how long an idempotency key is kept for if IDEMPOTENCY_KEY_TTL is not set
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+)
+
+// IdempotencyKeys stores the Idempotency-Key of each create payable request for the TTL, with a hash of the
+// request and the payable resource it created
+type IdempotencyKeys struct {
+	DAO dao.IdempotencyKeyDaoService
+	TTL time.Duration
+}
+
+// NewIdempotencyKeys returns the IdempotencyKeys stored with the DAO, using the default TTL if ttl is not positive
+func NewIdempotencyKeys(idempotencyKeyDaoSvc dao.IdempotencyKeyDaoService, ttl time.Duration) *IdempotencyKeys {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+	return &IdempotencyKeys{DAO: idempotencyKeyDaoSvc, TTL: ttl}
+}
+
+// reserve reserves the Idempotency-Key of the request for the user, nil is returned if the request does not
+// have one or idempotency keys are not stored. If the key has been used before the response is written and
+// done is true: the original payable resource if the request is the same, otherwise a conflict.
+func (i *IdempotencyKeys) reserve(w http.ResponseWriter, r *http.Request, prDaoSvc dao.PayableResourceDaoService,
+	request models.PayableRequest, createdBy string) (idempotencyKey *dao.IdempotencyKeyDao, done bool) {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if i == nil || key == "" {
+		return nil, false
+	}
+
+	requestId := log.Context(r)
+	requestHash, err := hashPayableRequest(request)
+	if err != nil {
+		log.ErrorC(requestId, fmt.Errorf("error hashing request for idempotency key: %v", err))
+		writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+		return nil, true
+	}
+
+	now := timeNow().Truncate(time.Millisecond)
+	idempotencyKey = &dao.IdempotencyKeyDao{
+		Key:          key,
+		CustomerCode: request.CustomerCode,
+		CreatedBy:    createdBy,
+		RequestHash:  requestHash,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(i.TTL),
+	}
+
+	err = i.DAO.ReserveIdempotencyKey(idempotencyKey, requestId)
+	if err == nil {
+		return idempotencyKey, false
+	}
+	if !errors.Is(err, dao.ErrIdempotencyKeyInUse) {
+		log.ErrorC(requestId, fmt.Errorf("error reserving idempotency key: %v", err))
+		writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+		return nil, true
+	}
+
+	existing, err := i.DAO.GetIdempotencyKey(key, request.CustomerCode, createdBy, requestId)
+	if err != nil {
+		log.ErrorC(requestId, fmt.Errorf("error getting idempotency key: %v", err))
+		writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+		return nil, true
+	}
+
+	switch {
+	case existing != nil && existing.RequestHash != requestHash:
+		log.InfoC(requestId, "idempotency key reused with a different request", log.Data{"customer_code": request.CustomerCode})
+		writeProblem(w, r, http.StatusUnprocessableEntity, utils.ErrorCodeIdempotencyKeyReused,
+			"the idempotency key has already been used for a different request")
+	case existing == nil || !existing.IsComplete():
+		log.InfoC(requestId, "idempotency key in use by a request that has not completed", log.Data{"customer_code": request.CustomerCode})
+		writeProblem(w, r, http.StatusConflict, utils.ErrorCodeIdempotencyKeyInUse,
+			"a request with the idempotency key is still being processed")
+	default:
+		replayCreatedPayableResource(w, r, prDaoSvc, existing)
+	}
+	return nil, true
+}
+
+// complete records the payable resource created for the idempotency key. The payable resource has already
+// been created so an error is only logged, repeating the request then conflicts until the key expires.
+func (i *IdempotencyKeys) complete(idempotencyKey *dao.IdempotencyKeyDao, payableRef, requestId string) {
+	idempotencyKey.PayableRef = payableRef
+	if err := i.DAO.CompleteIdempotencyKey(idempotencyKey, requestId); err != nil {
+		log.ErrorC(requestId, fmt.Errorf("error completing idempotency key: %v", err), log.Data{"payable_ref": payableRef})
+	}
+}
+
+// release deletes an idempotency key whose request failed so that the request can be retried with the same key
+func (i *IdempotencyKeys) release(idempotencyKey *dao.IdempotencyKeyDao, requestId string) {
+	if err := i.DAO.ReleaseIdempotencyKey(idempotencyKey, requestId); err != nil {
+		log.ErrorC(requestId, fmt.Errorf("error releasing idempotency key: %v", err))
+	}
+}
+
+// replayCreatedPayableResource writes the 201 response of the payable resource created for the idempotency key
+func replayCreatedPayableResource(w http.ResponseWriter, r *http.Request, prDaoSvc dao.PayableResourceDaoService,
+	idempotencyKey *dao.IdempotencyKeyDao) {
+	requestId := log.Context(r)
+	model, err := prDaoSvc.GetPayableResource(idempotencyKey.CustomerCode, idempotencyKey.PayableRef, requestId)
+	if err != nil {
+		log.ErrorC(requestId, fmt.Errorf("error getting payable resource of idempotency key: %v", err),
+			log.Data{"payable_ref": idempotencyKey.PayableRef})
+		writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+		return
+	}
+
+	log.InfoC(requestId, "replaying payable resource created for idempotency key", log.Data{"payable_ref": model.PayableRef})
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	utils.WriteJSONWithStatus(w, r, transformers.PayableResourceDaoToCreatedResponse(model), http.StatusCreated)
+}
+
+// hashPayableRequest hashes the transactions of the request, so that a repeated request matches however its
+// json was formatted
+func hashPayableRequest(request models.PayableRequest) (string, error) {
+	transactions, err := json.Marshal(request.Transactions)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(transactions)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"github.com/companieshouse/penalty-payment-api/mocks"
+	"github.com/golang/mock/gomock"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeIdempotencyKeyDaoService is an in memory IdempotencyKeyDaoService
+type fakeIdempotencyKeyDaoService struct {
+	keys map[string]dao.IdempotencyKeyDao
+	err  error
+}
+
+func (f *fakeIdempotencyKeyDaoService) id(key, customerCode, createdBy string) string {
+	return key + "/" + customerCode + "/" + createdBy
+}
+
+func (f *fakeIdempotencyKeyDaoService) ReserveIdempotencyKey(idempotencyKey *dao.IdempotencyKeyDao, _ string) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.keys == nil {
+		f.keys = map[string]dao.IdempotencyKeyDao{}
+	}
+	id := f.id(idempotencyKey.Key, idempotencyKey.CustomerCode, idempotencyKey.CreatedBy)
+	if existing, ok := f.keys[id]; ok && existing.ExpiresAt.After(idempotencyKey.CreatedAt) {
+		return dao.ErrIdempotencyKeyInUse
+	}
+	f.keys[id] = *idempotencyKey
+	return nil
+}
+
+func (f *fakeIdempotencyKeyDaoService) GetIdempotencyKey(key, customerCode, createdBy string, _ string) (*dao.IdempotencyKeyDao, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	existing, ok := f.keys[f.id(key, customerCode, createdBy)]
+	if !ok {
+		return nil, nil
+	}
+	return &existing, nil
+}
+
+func (f *fakeIdempotencyKeyDaoService) CompleteIdempotencyKey(idempotencyKey *dao.IdempotencyKeyDao, _ string) error {
+	f.keys[f.id(idempotencyKey.Key, idempotencyKey.CustomerCode, idempotencyKey.CreatedBy)] = *idempotencyKey
+	return nil
+}
+
+func (f *fakeIdempotencyKeyDaoService) ReleaseIdempotencyKey(idempotencyKey *dao.IdempotencyKeyDao, _ string) error {
+	delete(f.keys, f.id(idempotencyKey.Key, idempotencyKey.CustomerCode, idempotencyKey.CreatedBy))
+	return nil
+}
+
+func (f *fakeIdempotencyKeyDaoService) CreateIndexes(_ string) error {
+	return nil
+}
+
+func TestUnitCreatePayableResourceHandler_IdempotencyKey(t *testing.T) {
+	getCompanyCodeFromTransaction = (*config.PenaltyDetailsMap).GetCompanyCodeFromTransaction
+	getPenaltyRefTypeFromTransaction = (*config.PenaltyDetailsMap).GetPenaltyRefTypeFromTransaction
+	payablePenalty = func(params types.PayablePenaltyParams) (*models.TransactionItem, error) {
+		if params.Transaction.Amount > 1000 {
+			return nil, errors.New("amount does not match the outstanding amount")
+		}
+		transaction := params.Transaction
+		transaction.Type = types.Penalty.String()
+		transaction.MadeUpDate = "2024-06-30"
+		transaction.Reason = "Late filing of accounts"
+		return &transaction, nil
+	}
+	defer func() { payablePenalty = api.PayablePenalty }()
+
+	Convey("Given payable resources are created with an Idempotency-Key", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(mockCtrl)
+		idempotencyKeyDaoService := &fakeIdempotencyKeyDaoService{}
+		idempotencyKeys := NewIdempotencyKeys(idempotencyKeyDaoService, time.Hour)
+
+		var created []*models.PayableResourceDao
+		mockPrDaoSvc.EXPECT().CreatePayableResource(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(payableResource *models.PayableResourceDao, _ string) error {
+				created = append(created, payableResource)
+				return nil
+			}).AnyTimes()
+		mockPrDaoSvc.EXPECT().GetPayableResource(customerCode, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_, _, _ string) (*models.PayableResourceDao, error) {
+				return created[0], nil
+			}).AnyTimes()
+
+		serve := func(key, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/company/"+customerCode+"/penalties/payable", strings.NewReader(body))
+			req.Header.Set("Accept", utils.ProblemContentType)
+			if key != "" {
+				req.Header.Set(IdempotencyKeyHeader, key)
+			}
+			res := httptest.NewRecorder()
+			handler := CreatePayableResourceHandler(mockPrDaoSvc, mockApDaoSvc, penaltyDetailsMap, allowedTransactionsMap,
+				nil, nil, nil, idempotencyKeys, nil)
+			handler.ServeHTTP(res, req.WithContext(testContext(true, customerCode)))
+			return res
+		}
+		body := `{"transactions":[{"penalty_ref":"A1234567","amount":150}]}`
+
+		Convey("Then a repeated request gets the payable resource created by the first request", func() {
+			first := serve("key-1", body)
+			repeated := serve("key-1", `{"transactions": [{"amount": 150, "penalty_ref": "A1234567"}]}`)
+
+			So(first.Code, ShouldEqual, http.StatusCreated)
+			So(repeated.Code, ShouldEqual, http.StatusCreated)
+			So(repeated.Body.String(), ShouldEqual, first.Body.String())
+			So(repeated.Header().Get(IdempotentReplayedHeader), ShouldEqual, "true")
+			So(created, ShouldHaveLength, 1)
+		})
+
+		Convey("Then a key reused with a different request is rejected", func() {
+			first := serve("key-1", body)
+			reused := serve("key-1", `{"transactions":[{"penalty_ref":"A1234567","amount":200}]}`)
+
+			So(first.Code, ShouldEqual, http.StatusCreated)
+			So(reused.Code, ShouldEqual, http.StatusUnprocessableEntity)
+			So(reused.Body.String(), ShouldContainSubstring, utils.ErrorCodeIdempotencyKeyReused)
+			So(created, ShouldHaveLength, 1)
+		})
+
+		Convey("Then a request whose key is still being processed conflicts", func() {
+			hash, err := hashPayableRequest(models.PayableRequest{
+				Transactions: []models.TransactionItem{{PenaltyRef: "A1234567", Amount: 150}},
+			})
+			So(err, ShouldBeNil)
+			now := time.Now()
+			idempotencyKeyDaoService.keys = map[string]dao.IdempotencyKeyDao{
+				idempotencyKeyDaoService.id("key-1", customerCode, ""): {
+					Key: "key-1", CustomerCode: customerCode, RequestHash: hash, CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+				},
+			}
+
+			res := serve("key-1", body)
+
+			So(res.Code, ShouldEqual, http.StatusConflict)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodeIdempotencyKeyInUse)
+			So(created, ShouldBeEmpty)
+		})
+
+		Convey("Then the key of a request that fails is released so the request can be retried", func() {
+			failed := serve("key-1", `{"transactions":[{"penalty_ref":"A1234567","amount":2000}]}`)
+
+			So(failed.Code, ShouldEqual, http.StatusBadRequest)
+			So(idempotencyKeyDaoService.keys, ShouldBeEmpty)
+		})
+
+		Convey("Then different keys create different payable resources", func() {
+			So(serve("key-1", body).Code, ShouldEqual, http.StatusCreated)
+			So(serve("key-2", body).Code, ShouldEqual, http.StatusCreated)
+			So(serve("", body).Code, ShouldEqual, http.StatusCreated)
+			So(created, ShouldHaveLength, 3)
+		})
+
+		Convey("Then an error storing the key is an internal error", func() {
+			idempotencyKeyDaoService.err = errors.New("error reserving idempotency key")
+
+			res := serve("key-1", body)
+
+			So(res.Code, ShouldEqual, http.StatusInternalServerError)
+			So(created, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/constants"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/penalty_payments/transformers"
+)
+
+const (
+	// DefaultPayableResourcesPerPage is the number of payable resources listed if items_per_page is not requested
+	DefaultPayableResourcesPerPage = 25
+	// MaxPayableResourcesPerPage is the most payable resources that can be listed in one page
+	MaxPayableResourcesPerPage = 100
+)
+
+// The E5 processing states of a listed payable resource
+const (
+	E5ProcessingStateNotPaid   = "not_paid"
+	E5ProcessingStatePending   = "pending"
+	E5ProcessingStateProcessed = "processed"
+	E5ProcessingStateFailed    = "failed"
+)
+
+var payableResourceStatuses = []string{constants.Pending.String(), constants.Paid.String(),
+	dao.PaymentStatusExpired, dao.PaymentStatusCancelled}
+
+// PayableResourceListResponse is a page of the payable resources of a company
+type PayableResourceListResponse struct {
+	ItemsPerPage int                       `json:"items_per_page"`
+	StartIndex   int                       `json:"start_index"`
+	TotalResults int                       `json:"total_results"`
+	Items        []PayableResourceListItem `json:"items"`
+}
+
+// PayableResourceListItem is a payable resource with whether its payment has been processed in E5
+type PayableResourceListItem struct {
+	models.PayableResource
+	E5ProcessingState string     `json:"e5_processing_state"`
+	E5CommandError    string     `json:"e5_command_error,omitempty"`
+	E5ProcessedAt     *time.Time `json:"e5_processed_at,omitempty"`
+}
+
+// HandleListPayableResources lists the payable resources of the customer code in the request context, newest
+// first, filtered and paginated by the query parameters. Oauth2 users only see the payable resources they
+// created unless they have the admin penalty lookup role, elevated API keys see all of them.
+func HandleListPayableResources(prSearchDaoSvc dao.PayableResourceSearchDaoService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestId := log.Context(r)
+		log.InfoC(requestId, "start GET payable resources request")
+
+		customerCode := r.Context().Value(config.CustomerCode).(string)
+
+		query, err := parsePayableResourceQuery(r)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			writeErrorProblem(w, r, http.StatusBadRequest, err, utils.ErrorCodeInvalidQueryParameter, "invalid query parameter: "+err.Error())
+			return
+		}
+		query.CustomerCode = customerCode
+
+		if authentication.GetAuthorisedIdentityType(r) == authentication.Oauth2IdentityType &&
+			!authentication.IsRoleAuthorised(r, utils.AdminPenaltyLookupRole) {
+			userDetails, ok := r.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
+			if !ok || userDetails.ID == "" {
+				log.InfoC(requestId, "unauthorised: no authorised identity to list payable resources")
+				writeProblem(w, r, http.StatusUnauthorized, utils.ErrorCodeUserDetailsMissing, "user details not in request context")
+				return
+			}
+			query.CreatedByID = userDetails.ID
+		}
+		logContext := log.Data{"customer_code": customerCode, "created_by_id": query.CreatedByID}
+
+		resources, total, err := prSearchDaoSvc.SearchPayableResources(query, requestId)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error searching payable resources: %v", err), logContext)
+			writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+			return
+		}
+
+		response := PayableResourceListResponse{
+			ItemsPerPage: query.ItemsPerPage,
+			StartIndex:   query.StartIndex,
+			TotalResults: int(total),
+			Items:        make([]PayableResourceListItem, 0, len(resources)),
+		}
+		for _, resource := range resources {
+			response.Items = append(response.Items, payableResourceListItem(resource))
+		}
+
+		utils.WriteJSON(w, r, response)
+
+		log.InfoC(requestId, "GET payable resources request completed successfully", logContext)
+	}
+}
+
+// parsePayableResourceQuery reads the query parameters of a GET payable resources request, returning an error
+// naming the parameter if one is invalid. The created_to date is inclusive.
+func parsePayableResourceQuery(r *http.Request) (dao.PayableResourceQuery, error) {
+	values := r.URL.Query()
+	query := dao.PayableResourceQuery{ItemsPerPage: DefaultPayableResourcesPerPage}
+
+	if statuses := values.Get("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			status = strings.ToLower(strings.TrimSpace(status))
+			if !slices.Contains(payableResourceStatuses, status) {
+				return query, newFieldError("status", "status must be one or more of %s", strings.Join(payableResourceStatuses, ", "))
+			}
+			query.Statuses = append(query.Statuses, status)
+		}
+	}
+
+	var err error
+	if query.CreatedFrom, err = parseCreatedDate(values.Get("created_from")); err != nil {
+		return query, newFieldError("created_from", "created_from %v", err)
+	}
+	if query.CreatedBefore, err = parseCreatedDate(values.Get("created_to")); err != nil {
+		return query, newFieldError("created_to", "created_to %v", err)
+	}
+	if !query.CreatedBefore.IsZero() {
+		query.CreatedBefore = query.CreatedBefore.AddDate(0, 0, 1)
+	}
+
+	if query.StartIndex, err = parseNonNegativeInt(values.Get("start_index")); err != nil {
+		return query, newFieldError("start_index", "start_index %v", err)
+	}
+	if itemsPerPage := values.Get("items_per_page"); itemsPerPage != "" {
+		if query.ItemsPerPage, err = parseNonNegativeInt(itemsPerPage); err != nil || query.ItemsPerPage == 0 ||
+			query.ItemsPerPage > MaxPayableResourcesPerPage {
+			return query, newFieldError("items_per_page", "items_per_page must be a whole number from 1 to %d", MaxPayableResourcesPerPage)
+		}
+	}
+
+	return query, nil
+}
+
+func parseCreatedDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(madeUpDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be a date in the format YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+// payableResourceListItem converts the payable resource record to a list item. A paid payable resource is pending
+// until the E5 commands, which can run after it is marked as paid, are recorded as processed or failed.
+func payableResourceListItem(resource dao.PayableResourceRecordDao) PayableResourceListItem {
+	item := PayableResourceListItem{
+		PayableResource:   *transformers.PayableResourceDBToRequest(&resource.PayableResourceDao),
+		E5ProcessingState: E5ProcessingStateNotPaid,
+		E5CommandError:    resource.E5CommandError,
+		E5ProcessedAt:     resource.E5ProcessedAt,
+	}
+	switch {
+	case resource.E5CommandError != "":
+		item.E5ProcessingState = E5ProcessingStateFailed
+	case resource.E5ProcessedAt != nil:
+		item.E5ProcessingState = E5ProcessingStateProcessed
+	case resource.IsPaid():
+		item.E5ProcessingState = E5ProcessingStatePending
+	}
+	return item
+}
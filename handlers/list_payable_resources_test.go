@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakePayableResourceSearchDaoService is an in memory PayableResourceSearchDaoService that records the last query
+type fakePayableResourceSearchDaoService struct {
+	resources []dao.PayableResourceRecordDao
+	query     dao.PayableResourceQuery
+	err       error
+}
+
+func (f *fakePayableResourceSearchDaoService) SearchPayableResources(query dao.PayableResourceQuery,
+	_ string) ([]dao.PayableResourceRecordDao, int64, error) {
+	f.query = query
+	if f.err != nil {
+		return nil, 0, f.err
+	}
+	var matches []dao.PayableResourceRecordDao
+	for _, resource := range f.resources {
+		if (query.CustomerCode != "" && resource.CustomerCode != query.CustomerCode) ||
+			(query.CreatedByID != "" && resource.Data.CreatedBy.ID != query.CreatedByID) ||
+			(query.CreatedByEmail != "" && !strings.EqualFold(resource.Data.CreatedBy.Email, query.CreatedByEmail)) ||
+			(query.PaymentReference != "" && resource.Data.Payment.Reference != query.PaymentReference) ||
+			(query.PenaltyRef != "" && !hasTransaction(resource, query.PenaltyRef)) ||
+			(len(query.Statuses) > 0 && !slices.Contains(query.Statuses, resource.Data.Payment.Status)) {
+			continue
+		}
+		matches = append(matches, resource)
+	}
+	start := min(query.StartIndex, len(matches))
+	end := min(start+query.ItemsPerPage, len(matches))
+	return matches[start:end], int64(len(matches)), nil
+}
+
+func (f *fakePayableResourceSearchDaoService) CreateIndexes(_ string) error {
+	return nil
+}
+
+func hasTransaction(resource dao.PayableResourceRecordDao, penaltyRef string) bool {
+	_, ok := resource.Data.Transactions[penaltyRef]
+	return ok
+}
+
+func payableResourceRecord(payableRef, createdBy, status, e5CommandError string) dao.PayableResourceRecordDao {
+	createdAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	record := dao.PayableResourceRecordDao{
+		PayableResourceDao: models.PayableResourceDao{
+			CustomerCode: customerCode,
+			PayableRef:   payableRef,
+			Data: models.PayableResourceDataDao{
+				Transactions: map[string]models.TransactionDao{"A1234567": {Amount: 150}},
+				Payment:      models.PaymentDao{Amount: "150", Status: status},
+				CreatedAt:    &createdAt,
+				CreatedBy:    models.CreatedByDao{ID: createdBy, Email: createdBy + "@ch.gov.uk"},
+			},
+		},
+		E5CommandError: e5CommandError,
+	}
+	if status == "paid" {
+		paidAt := createdAt.Add(time.Minute)
+		record.Data.Payment.Reference = "payment-" + payableRef
+		record.Data.Payment.PaidAt = &paidAt
+	}
+	return record
+}
+
+func TestUnitHandleListPayableResources(t *testing.T) {
+	Convey("Given the payable resources of a company created by two users", t, func() {
+		e5ProcessedAt := time.Date(2025, 3, 1, 10, 2, 0, 0, time.UTC)
+		processed := payableResourceRecord("PR_1", "user-1", "paid", "")
+		processed.E5ProcessedAt = &e5ProcessedAt
+		prSearchDaoSvc := &fakePayableResourceSearchDaoService{resources: []dao.PayableResourceRecordDao{
+			processed,
+			payableResourceRecord("PR_2", "user-1", "paid", "create_payment"),
+			payableResourceRecord("PR_3", "user-2", "pending", ""),
+		}}
+
+		serve := func(query string, identityType string, userID string, roles string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/company/"+customerCode+"/penalties/payable"+query, nil)
+			req.Header.Set("Accept", utils.ProblemContentType)
+			req.Header.Set("ERIC-Identity-Type", identityType)
+			if roles != "" {
+				req.Header.Set("ERIC-Authorised-Roles", roles)
+			}
+			ctx := context.WithValue(context.Background(), config.CustomerCode, customerCode)
+			ctx = context.WithValue(ctx, authentication.ContextKeyUserDetails, authentication.AuthUserDetails{ID: userID})
+			res := httptest.NewRecorder()
+			HandleListPayableResources(prSearchDaoSvc).ServeHTTP(res, req.WithContext(ctx))
+			return res
+		}
+		decodeList := func(res *httptest.ResponseRecorder) PayableResourceListResponse {
+			var list PayableResourceListResponse
+			So(json.Unmarshal(res.Body.Bytes(), &list), ShouldBeNil)
+			return list
+		}
+
+		Convey("Then a user only sees the payable resources they created", func() {
+			res := serve("", authentication.Oauth2IdentityType, "user-1", "")
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			list := decodeList(res)
+			So(list.TotalResults, ShouldEqual, 2)
+			So(list.ItemsPerPage, ShouldEqual, DefaultPayableResourcesPerPage)
+			So(list.Items, ShouldHaveLength, 2)
+			So(prSearchDaoSvc.query.CreatedByID, ShouldEqual, "user-1")
+		})
+
+		Convey("Then the payment and E5 processing state of each payable resource are returned", func() {
+			list := decodeList(serve("", authentication.Oauth2IdentityType, "user-1", ""))
+
+			So(list.Items[0].PayableRef, ShouldEqual, "PR_1")
+			So(list.Items[0].Payment.Reference, ShouldEqual, "payment-PR_1")
+			So(list.Items[0].Payment.PaidAt, ShouldNotBeNil)
+			So(list.Items[0].Transactions, ShouldHaveLength, 1)
+			So(list.Items[0].E5ProcessingState, ShouldEqual, E5ProcessingStateProcessed)
+			So(*list.Items[0].E5ProcessedAt, ShouldEqual, e5ProcessedAt)
+			So(list.Items[1].E5ProcessingState, ShouldEqual, E5ProcessingStateFailed)
+			So(list.Items[1].E5CommandError, ShouldEqual, "create_payment")
+		})
+
+		Convey("Then a paid payable resource is pending until it is recorded as processed in E5", func() {
+			prSearchDaoSvc.resources[0].E5ProcessedAt = nil
+
+			list := decodeList(serve("", authentication.Oauth2IdentityType, "user-1", ""))
+
+			So(list.Items[0].E5ProcessingState, ShouldEqual, E5ProcessingStatePending)
+			So(list.Items[0].E5ProcessedAt, ShouldBeNil)
+		})
+
+		Convey("Then a user with the admin penalty lookup role sees all of them", func() {
+			list := decodeList(serve("", authentication.Oauth2IdentityType, "admin", utils.AdminPenaltyLookupRole))
+
+			So(list.TotalResults, ShouldEqual, 3)
+			So(list.Items[2].E5ProcessingState, ShouldEqual, E5ProcessingStateNotPaid)
+			So(prSearchDaoSvc.query.CreatedByID, ShouldBeEmpty)
+		})
+
+		Convey("Then an API key sees all of them", func() {
+			list := decodeList(serve("", authentication.APIKeyIdentityType, "", ""))
+
+			So(list.TotalResults, ShouldEqual, 3)
+		})
+
+		Convey("Then a user without an identity is unauthorised", func() {
+			res := serve("", authentication.Oauth2IdentityType, "", "")
+
+			So(res.Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("Then the query is filtered and paginated", func() {
+			res := serve("?status=PAID,pending&created_from=2025-03-01&created_to=2025-03-31&start_index=1&items_per_page=1",
+				authentication.APIKeyIdentityType, "", "")
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			list := decodeList(res)
+			So(list.TotalResults, ShouldEqual, 3)
+			So(list.StartIndex, ShouldEqual, 1)
+			So(list.Items, ShouldHaveLength, 1)
+			So(list.Items[0].PayableRef, ShouldEqual, "PR_2")
+			So(prSearchDaoSvc.query, ShouldResemble, dao.PayableResourceQuery{
+				CustomerCode:  customerCode,
+				Statuses:      []string{"paid", "pending"},
+				CreatedFrom:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+				CreatedBefore: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+				StartIndex:    1,
+				ItemsPerPage:  1,
+			})
+		})
+
+		Convey("Then an invalid query parameter is a bad request naming the parameter", func() {
+			for query, field := range map[string]string{
+				"?status=unknown":       "status",
+				"?created_from=2025-13": "created_from",
+				"?created_to=tomorrow":  "created_to",
+				"?start_index=-1":       "start_index",
+				"?items_per_page=0":     "items_per_page",
+				"?items_per_page=101":   "items_per_page",
+			} {
+				res := serve(query, authentication.APIKeyIdentityType, "", "")
+
+				So(res.Code, ShouldEqual, http.StatusBadRequest)
+				So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodeInvalidQueryParameter)
+				So(res.Body.String(), ShouldContainSubstring, `"field":"`+field+`"`)
+			}
+		})
+
+		Convey("Then an error searching the payable resources is an internal error", func() {
+			prSearchDaoSvc.err = errors.New("error searching")
+
+			res := serve("", authentication.APIKeyIdentityType, "", "")
+
+			So(res.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+}
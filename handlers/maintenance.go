@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+)
+
+var currentMaintenanceWindow = api.CurrentMaintenanceWindow
+
+// MaintenanceResponse is returned when a request cannot be handled because the finance system is in a
+// maintenance window
+type MaintenanceResponse struct {
+	Message       string    `json:"message"`
+	AvailableFrom time.Time `json:"available_from"`
+}
+
+// writeMaintenanceResponse writes a 503 response with the time the finance system is available from if
+// a maintenance window is in progress. It returns true if the response has been written.
+func writeMaintenanceResponse(w http.ResponseWriter, r *http.Request, maintenanceWindowsCache *api.MaintenanceWindowsCache) bool {
+	requestId := log.Context(r)
+	now := timeNow()
+
+	maintenanceWindow := currentMaintenanceWindow(requestId, now, maintenanceWindowsCache)
+	if maintenanceWindow == nil {
+		return false
+	}
+
+	log.InfoC(requestId, "request rejected during planned maintenance", log.Data{"maintenance_window": maintenanceWindow})
+	w.Header().Set("Retry-After", retryAfter(now, maintenanceWindow))
+	m := MaintenanceResponse{
+		Message:       "the finance system is unavailable due to planned maintenance",
+		AvailableFrom: maintenanceWindow.End,
+	}
+	utils.WriteJSONWithStatus(w, r, m, http.StatusServiceUnavailable)
+	return true
+}
+
+// retryAfter returns the number of seconds until the end of the maintenance window for the Retry-After header
+func retryAfter(now time.Time, maintenanceWindow *config.MaintenanceWindow) string {
+	seconds := math.Ceil(maintenanceWindow.End.Sub(now).Seconds())
+	return strconv.Itoa(int(max(seconds, 0)))
+}
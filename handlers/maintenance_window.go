@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/gorilla/mux"
+)
+
+// MaintenanceWindowRequest is the body of a request to create a maintenance window
+type MaintenanceWindowRequest struct {
+	Name      string    `json:"name"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// MaintenanceWindowResponse is a maintenance window managed through the admin endpoints
+type MaintenanceWindowResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CreatedBy   string     `json:"created_by"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy string     `json:"cancelled_by,omitempty"`
+}
+
+// HandleCreateMaintenanceWindow adds a maintenance window during which E5 is unavailable
+func HandleCreateMaintenanceWindow(maintenanceWindowsCache *api.MaintenanceWindowsCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestId := log.Context(r)
+		log.InfoC(requestId, "start POST maintenance window request")
+
+		var request MaintenanceWindowRequest
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			message := "failed to read request body"
+			log.ErrorR(r, fmt.Errorf(message+": %v", err))
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodeInvalidRequestBody, message)
+			return
+		}
+
+		if err = validateMaintenanceWindowRequest(request, timeNow()); err != nil {
+			log.ErrorC(requestId, fmt.Errorf("invalid maintenance window request: %v", err))
+			writeErrorProblem(w, r, http.StatusBadRequest, err, utils.ErrorCodeValidationFailed, err.Error())
+			return
+		}
+
+		maintenanceWindow := &dao.MaintenanceWindowDao{
+			Name:      strings.TrimSpace(request.Name),
+			StartTime: request.StartTime.UTC(),
+			EndTime:   request.EndTime.UTC(),
+			CreatedAt: timeNow().UTC().Truncate(time.Millisecond),
+			CreatedBy: requestIdentity(r),
+		}
+
+		err = maintenanceWindowsCache.DAO.CreateMaintenanceWindow(maintenanceWindow, requestId)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error creating maintenance window: %v", err))
+			writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "failed to create maintenance window")
+			return
+		}
+		maintenanceWindowsCache.Invalidate()
+
+		utils.WriteJSONWithStatus(w, r, toMaintenanceWindowResponse(*maintenanceWindow), http.StatusCreated)
+
+		log.InfoC(requestId, "POST maintenance window request completed successfully")
+	}
+}
+
+// HandleGetMaintenanceWindows lists the maintenance windows that are in progress or have not started,
+// including cancelled windows
+func HandleGetMaintenanceWindows(maintenanceWindowsCache *api.MaintenanceWindowsCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestId := log.Context(r)
+		log.InfoC(requestId, "start GET maintenance windows request")
+
+		maintenanceWindows, err := maintenanceWindowsCache.DAO.GetMaintenanceWindows(timeNow(), requestId)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error getting maintenance windows: %v", err))
+			writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "failed to get maintenance windows")
+			return
+		}
+
+		response := make([]MaintenanceWindowResponse, 0, len(maintenanceWindows))
+		for _, maintenanceWindow := range maintenanceWindows {
+			response = append(response, toMaintenanceWindowResponse(maintenanceWindow))
+		}
+
+		utils.WriteJSON(w, r, response)
+
+		log.InfoC(requestId, "GET maintenance windows request completed successfully")
+	}
+}
+
+// HandleCancelMaintenanceWindow cancels a maintenance window, the window is kept so that it can be audited
+func HandleCancelMaintenanceWindow(maintenanceWindowsCache *api.MaintenanceWindowsCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestId := log.Context(r)
+		id := mux.Vars(r)["maintenance_window_id"]
+		log.InfoC(requestId, "start DELETE maintenance window request", log.Data{"maintenance_window_id": id})
+
+		err := maintenanceWindowsCache.DAO.CancelMaintenanceWindow(id, requestIdentity(r), requestId)
+		if errors.Is(err, dao.ErrMaintenanceWindowNotFound) {
+			writeErrorProblem(w, r, http.StatusNotFound, err, utils.ErrorCodeMaintenanceWindowNotFound, "maintenance window not found")
+			return
+		}
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error cancelling maintenance window: %v", err), log.Data{"maintenance_window_id": id})
+			writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "failed to cancel maintenance window")
+			return
+		}
+		maintenanceWindowsCache.Invalidate()
+
+		w.WriteHeader(http.StatusNoContent)
+
+		log.InfoC(requestId, "DELETE maintenance window request completed successfully", log.Data{"maintenance_window_id": id})
+	}
+}
+
+func validateMaintenanceWindowRequest(request MaintenanceWindowRequest, now time.Time) error {
+	switch {
+	case strings.TrimSpace(request.Name) == "":
+		return newFieldError("name", "name is required")
+	case request.StartTime.IsZero() || request.EndTime.IsZero():
+		return newFieldError("start_time", "start_time and end_time are required")
+	case !request.EndTime.After(request.StartTime):
+		return newFieldError("end_time", "end_time must be after start_time")
+	case !request.EndTime.After(now):
+		return newFieldError("end_time", "end_time must be in the future")
+	}
+	return nil
+}
+
+func toMaintenanceWindowResponse(maintenanceWindow dao.MaintenanceWindowDao) MaintenanceWindowResponse {
+	response := MaintenanceWindowResponse{
+		ID:          maintenanceWindow.ID.Hex(),
+		Name:        maintenanceWindow.Name,
+		StartTime:   maintenanceWindow.StartTime.In(config.MaintenanceLocation),
+		EndTime:     maintenanceWindow.EndTime.In(config.MaintenanceLocation),
+		CreatedAt:   maintenanceWindow.CreatedAt,
+		CreatedBy:   maintenanceWindow.CreatedBy,
+		CancelledBy: maintenanceWindow.CancelledBy,
+	}
+	if maintenanceWindow.CancelledAt != nil {
+		cancelledAt := *maintenanceWindow.CancelledAt
+		response.CancelledAt = &cancelledAt
+	}
+	return response
+}
+
+// requestIdentity returns the identity of the user or API key that made the request
+func requestIdentity(r *http.Request) string {
+	return r.Header.Get("ERIC-Identity")
+}
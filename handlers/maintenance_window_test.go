@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeMaintenanceWindowDaoService is an in memory MaintenanceWindowDaoService
+type fakeMaintenanceWindowDaoService struct {
+	windows []dao.MaintenanceWindowDao
+	err     error
+}
+
+func (f *fakeMaintenanceWindowDaoService) CreateMaintenanceWindow(maintenanceWindow *dao.MaintenanceWindowDao, _ string) error {
+	if f.err != nil {
+		return f.err
+	}
+	maintenanceWindow.ID = primitive.NewObjectID()
+	f.windows = append(f.windows, *maintenanceWindow)
+	return nil
+}
+
+func (f *fakeMaintenanceWindowDaoService) GetMaintenanceWindows(endAfter time.Time, _ string) ([]dao.MaintenanceWindowDao, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var windows []dao.MaintenanceWindowDao
+	for _, window := range f.windows {
+		if window.EndTime.After(endAfter) {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+func (f *fakeMaintenanceWindowDaoService) CancelMaintenanceWindow(id, cancelledBy string, _ string) error {
+	if f.err != nil {
+		return f.err
+	}
+	for i, window := range f.windows {
+		if window.ID.Hex() == id && !window.IsCancelled() {
+			cancelledAt := time.Now()
+			f.windows[i].CancelledAt = &cancelledAt
+			f.windows[i].CancelledBy = cancelledBy
+			return nil
+		}
+	}
+	return dao.ErrMaintenanceWindowNotFound
+}
+
+func TestUnitHandleCreateMaintenanceWindow(t *testing.T) {
+	timeNow = func() time.Time {
+		return time.Date(2025, time.March, 16, 12, 0, 0, 0, time.UTC)
+	}
+	defer func() { timeNow = time.Now }()
+
+	Convey("Create maintenance window", t, func() {
+		maintenanceWindowDaoService := &fakeMaintenanceWindowDaoService{}
+		maintenanceWindowsCache := api.NewMaintenanceWindowsCache(maintenanceWindowDaoService, time.Minute)
+
+		testCases := []struct {
+			name       string
+			body       string
+			daoErr     error
+			wantStatus int
+			wantBody   string
+		}{
+			{
+				name:       "Valid maintenance window",
+				body:       `{"name":"E5 upgrade","start_time":"2025-03-20T18:00:00Z","end_time":"2025-03-20T22:00:00Z"}`,
+				wantStatus: http.StatusCreated,
+			},
+			{
+				name:       "Invalid body",
+				body:       `{"name":`,
+				wantStatus: http.StatusBadRequest,
+				wantBody:   `{"message":"failed to read request body"}`,
+			},
+			{
+				name:       "Missing name",
+				body:       `{"start_time":"2025-03-20T18:00:00Z","end_time":"2025-03-20T22:00:00Z"}`,
+				wantStatus: http.StatusBadRequest,
+				wantBody:   `{"message":"name is required"}`,
+			},
+			{
+				name:       "Missing end time",
+				body:       `{"name":"E5 upgrade","start_time":"2025-03-20T18:00:00Z"}`,
+				wantStatus: http.StatusBadRequest,
+				wantBody:   `{"message":"start_time and end_time are required"}`,
+			},
+			{
+				name:       "Ends before it starts",
+				body:       `{"name":"E5 upgrade","start_time":"2025-03-20T22:00:00Z","end_time":"2025-03-20T18:00:00Z"}`,
+				wantStatus: http.StatusBadRequest,
+				wantBody:   `{"message":"end_time must be after start_time"}`,
+			},
+			{
+				name:       "Ended in the past",
+				body:       `{"name":"E5 upgrade","start_time":"2025-03-10T18:00:00Z","end_time":"2025-03-10T22:00:00Z"}`,
+				wantStatus: http.StatusBadRequest,
+				wantBody:   `{"message":"end_time must be in the future"}`,
+			},
+			{
+				name:       "Error storing the maintenance window",
+				body:       `{"name":"E5 upgrade","start_time":"2025-03-20T18:00:00Z","end_time":"2025-03-20T22:00:00Z"}`,
+				daoErr:     errors.New("mongo unavailable"),
+				wantStatus: http.StatusInternalServerError,
+				wantBody:   `{"message":"failed to create maintenance window"}`,
+			},
+		}
+
+		for _, tc := range testCases {
+			Convey(tc.name, func() {
+				maintenanceWindowDaoService.err = tc.daoErr
+				req := httptest.NewRequest(http.MethodPost, "/penalty-payment-api/admin/maintenance-windows", strings.NewReader(tc.body))
+				req.Header.Set("ERIC-Identity", "admin-key")
+				w := httptest.NewRecorder()
+
+				HandleCreateMaintenanceWindow(maintenanceWindowsCache)(w, req)
+
+				So(w.Code, ShouldEqual, tc.wantStatus)
+				if tc.wantBody != "" {
+					So(strings.TrimSpace(w.Body.String()), ShouldEqual, tc.wantBody)
+				}
+			})
+		}
+
+		Convey("The created window is returned and used by the next maintenance check", func() {
+			windows, err := maintenanceWindowsCache.Get("", timeNow())
+			So(err, ShouldBeNil)
+			So(windows, ShouldBeEmpty)
+
+			req := httptest.NewRequest(http.MethodPost, "/penalty-payment-api/admin/maintenance-windows",
+				strings.NewReader(`{"name":"E5 upgrade","start_time":"2025-03-20T18:00:00Z","end_time":"2025-03-20T22:00:00Z"}`))
+			req.Header.Set("ERIC-Identity", "admin-key")
+			w := httptest.NewRecorder()
+			HandleCreateMaintenanceWindow(maintenanceWindowsCache)(w, req)
+
+			var response MaintenanceWindowResponse
+			So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+			So(response.ID, ShouldNotBeEmpty)
+			So(response.Name, ShouldEqual, "E5 upgrade")
+			So(response.CreatedBy, ShouldEqual, "admin-key")
+			So(response.StartTime.Equal(time.Date(2025, time.March, 20, 18, 0, 0, 0, time.UTC)), ShouldBeTrue)
+
+			windows, err = maintenanceWindowsCache.Get("", timeNow())
+			So(err, ShouldBeNil)
+			So(windows, ShouldHaveLength, 1)
+		})
+	})
+}
+
+func TestUnitHandleGetMaintenanceWindows(t *testing.T) {
+	timeNow = func() time.Time {
+		return time.Date(2025, time.March, 16, 12, 0, 0, 0, time.UTC)
+	}
+	defer func() { timeNow = time.Now }()
+
+	Convey("Get maintenance windows", t, func() {
+		cancelledAt := time.Date(2025, time.March, 15, 9, 0, 0, 0, time.UTC)
+		maintenanceWindowDaoService := &fakeMaintenanceWindowDaoService{
+			windows: []dao.MaintenanceWindowDao{
+				{ID: primitive.NewObjectID(), Name: "Finished", StartTime: time.Date(2025, time.March, 10, 18, 0, 0, 0, time.UTC), EndTime: time.Date(2025, time.March, 10, 22, 0, 0, 0, time.UTC)},
+				{ID: primitive.NewObjectID(), Name: "Upcoming", StartTime: time.Date(2025, time.March, 20, 18, 0, 0, 0, time.UTC), EndTime: time.Date(2025, time.March, 20, 22, 0, 0, 0, time.UTC)},
+				{ID: primitive.NewObjectID(), Name: "Cancelled", StartTime: time.Date(2025, time.March, 21, 18, 0, 0, 0, time.UTC), EndTime: time.Date(2025, time.March, 21, 22, 0, 0, 0, time.UTC),
+					CancelledAt: &cancelledAt, CancelledBy: "admin-key"},
+			},
+		}
+		maintenanceWindowsCache := api.NewMaintenanceWindowsCache(maintenanceWindowDaoService, time.Minute)
+
+		Convey("Windows that have not finished are listed, including cancelled windows", func() {
+			w := httptest.NewRecorder()
+			HandleGetMaintenanceWindows(maintenanceWindowsCache)(w, httptest.NewRequest(http.MethodGet, "/penalty-payment-api/admin/maintenance-windows", nil))
+
+			var response []MaintenanceWindowResponse
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+			So(response, ShouldHaveLength, 2)
+			So(response[0].Name, ShouldEqual, "Upcoming")
+			So(response[0].CancelledAt, ShouldBeNil)
+			So(response[1].Name, ShouldEqual, "Cancelled")
+			So(response[1].CancelledAt.Equal(cancelledAt), ShouldBeTrue)
+			So(response[1].CancelledBy, ShouldEqual, "admin-key")
+		})
+
+		Convey("Error getting windows", func() {
+			maintenanceWindowDaoService.err = errors.New("mongo unavailable")
+
+			w := httptest.NewRecorder()
+			HandleGetMaintenanceWindows(maintenanceWindowsCache)(w, httptest.NewRequest(http.MethodGet, "/penalty-payment-api/admin/maintenance-windows", nil))
+
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+}
+
+func TestUnitHandleCancelMaintenanceWindow(t *testing.T) {
+	Convey("Cancel maintenance window", t, func() {
+		id := primitive.NewObjectID()
+		maintenanceWindowDaoService := &fakeMaintenanceWindowDaoService{
+			windows: []dao.MaintenanceWindowDao{
+				{ID: id, Name: "Upcoming", StartTime: time.Now().Add(time.Hour), EndTime: time.Now().Add(2 * time.Hour)},
+			},
+		}
+		maintenanceWindowsCache := api.NewMaintenanceWindowsCache(maintenanceWindowDaoService, time.Minute)
+
+		cancel := func(id string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodDelete, "/penalty-payment-api/admin/maintenance-windows/"+id, nil)
+			req = mux.SetURLVars(req, map[string]string{"maintenance_window_id": id})
+			req.Header.Set("ERIC-Identity", "admin-key")
+			w := httptest.NewRecorder()
+			HandleCancelMaintenanceWindow(maintenanceWindowsCache)(w, req)
+			return w
+		}
+
+		Convey("The window is cancelled and no longer used by maintenance checks", func() {
+			windows, _ := maintenanceWindowsCache.Get("", time.Now())
+			So(windows, ShouldHaveLength, 1)
+
+			w := cancel(id.Hex())
+
+			So(w.Code, ShouldEqual, http.StatusNoContent)
+			So(maintenanceWindowDaoService.windows[0].CancelledBy, ShouldEqual, "admin-key")
+			windows, _ = maintenanceWindowsCache.Get("", time.Now())
+			So(windows, ShouldBeEmpty)
+
+			Convey("And cancelling it again is not found", func() {
+				So(cancel(id.Hex()).Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("Unknown window", func() {
+			So(cancel(primitive.NewObjectID().Hex()).Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("Error cancelling the window", func() {
+			maintenanceWindowDaoService.err = errors.New("mongo unavailable")
+
+			So(cancel(id.Hex()).Code, ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+}
@@ -26,9 +26,11 @@ var (
 )
 
 // PayResourceHandler will update the resource to mark it as paid and also tell the finance system that the
-// transaction(s) associated with it are paid.
+// transaction(s) associated with it are paid. The payment has already been taken, so during a maintenance window
+// the finance system is not called and the update is recorded as an E5 command error to be processed later.
 func PayResourceHandler(payableResourceService *services.PayableResourceService, e5Client e5.ClientInterface, penaltyPaymentDetails *config.PenaltyDetailsMap,
-	allowedTransactionsMap *models.AllowedTransactionMap, apDaoSvc dao.AccountPenaltiesDaoService) http.Handler {
+	allowedTransactionsMap *models.AllowedTransactionMap, apDaoSvc dao.AccountPenaltiesDaoService,
+	maintenanceWindowsCache *api.MaintenanceWindowsCache) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestId := log.Context(r)
 		log.InfoC(requestId, "start PATCH payable resource request")
@@ -37,14 +39,26 @@ func PayResourceHandler(payableResourceService *services.PayableResourceService,
 		if i == nil {
 			err := fmt.Errorf("no payable resource in context. check PayableAuthenticationInterceptor is installed")
 			log.ErrorC(requestId, err)
-			m := models.NewMessageResponse("no payable request present in request context")
-			utils.WriteJSONWithStatus(w, r, m, http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodePayableResourceMissing, "no payable request present in request context")
 			return
 		}
 		resource := i.(*models.PayableResource)
 		logContext := log.Data{"payable_resource": resource}
 		log.DebugC(requestId, "got payable resource from context", logContext)
 
+		// the payments platform cannot start taking payment for an expired or cancelled resource, as its payment
+		// details show its status, so it cannot be paid
+		if resource.Payment.Status == dao.PaymentStatusExpired {
+			log.InfoC(requestId, "payable resource has expired", log.Data{"customer_code": resource.CustomerCode, "payable_ref": resource.PayableRef})
+			writeProblem(w, r, http.StatusGone, utils.ErrorCodePayableResourceExpired, "the payable resource has expired")
+			return
+		}
+		if resource.Payment.Status == dao.PaymentStatusCancelled {
+			log.InfoC(requestId, "payable resource has been cancelled", log.Data{"customer_code": resource.CustomerCode, "payable_ref": resource.PayableRef})
+			writeProblem(w, r, http.StatusGone, utils.ErrorCodePayableResourceCancelled, "the payable resource has been cancelled")
+			return
+		}
+
 		// 2. validate the request and check the payment reference against the payment api to validate that it has
 		// actually been paid
 		log.InfoC(requestId, "validating request", logContext)
@@ -52,8 +66,7 @@ func PayResourceHandler(payableResourceService *services.PayableResourceService,
 		err := json.NewDecoder(r.Body).Decode(&request)
 		if err != nil {
 			log.ErrorC(requestId, err)
-			m := models.NewMessageResponse("there was a problem reading the request body")
-			utils.WriteJSONWithStatus(w, r, m, http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodeInvalidRequestBody, "there was a problem reading the request body")
 			return
 		}
 
@@ -61,8 +74,7 @@ func PayResourceHandler(payableResourceService *services.PayableResourceService,
 
 		if err != nil {
 			log.ErrorC(requestId, err)
-			m := models.NewMessageResponse("the request contained insufficient data and/or failed validation")
-			utils.WriteJSONWithStatus(w, r, m, http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodeValidationFailed, "the request contained insufficient data and/or failed validation")
 			return
 		}
 		log.DebugC(requestId, "request is valid", log.Data{"request": request})
@@ -71,8 +83,7 @@ func PayResourceHandler(payableResourceService *services.PayableResourceService,
 		payment, err := service.GetPaymentInformation(request.Reference, r)
 		if err != nil {
 			log.ErrorC(requestId, err)
-			m := models.NewMessageResponse("the payable resource does not exist")
-			utils.WriteJSONWithStatus(w, r, m, http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodePaymentNotFound, "the payable resource does not exist")
 			return
 		}
 
@@ -92,8 +103,7 @@ func PayResourceHandler(payableResourceService *services.PayableResourceService,
 		err = validators.New().ValidateForPayment(*resource, *payment)
 		if err != nil {
 			log.ErrorC(requestId, err)
-			m := models.NewMessageResponse("there was a problem validating this payment")
-			utils.WriteJSONWithStatus(w, r, m, http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, utils.ErrorCodePaymentInvalid, "there was a problem validating this payment")
 			return
 		}
 		log.DebugC(requestId, "payment is valid", log.Data{"payment": payment})
@@ -107,11 +117,16 @@ func PayResourceHandler(payableResourceService *services.PayableResourceService,
 
 		if paymentsProcessingEnabled(requestId) {
 			log.InfoC(requestId, "payments processing feature enabled")
-			go addPaymentsProcessingMsgToTopic(resource, payment, requestId, w)
+			go addPaymentsProcessingMsgToTopic(resource, payment, penaltyPaymentDetails, requestId, w)
+		} else if maintenanceWindow := currentMaintenanceWindow(requestId, timeNow(), maintenanceWindowsCache); maintenanceWindow != nil {
+			log.InfoC(requestId, "payments processing feature disabled")
+			log.InfoC(requestId, "deferring update of penalty as paid in E5 during planned maintenance", log.Data{
+				"customer_code": resource.CustomerCode, "payable_ref": resource.PayableRef, "maintenance_window": maintenanceWindow})
+			go deferIssuerUpdate(payableResourceService, resource, requestId, w)
 		} else {
 			log.InfoC(requestId, "payments processing feature disabled")
 			log.InfoC(requestId, "updating penalty as paid in E5", log.Data{"customer_code": resource.CustomerCode, "payable_ref": resource.PayableRef})
-			go updateIssuer(payableResourceService, e5Client, resource, payment, requestId, w)
+			go updateIssuer(payableResourceService, e5Client, resource, payment, penaltyPaymentDetails, requestId, w)
 		}
 
 		wg.Wait()
@@ -119,7 +134,7 @@ func PayResourceHandler(payableResourceService *services.PayableResourceService,
 		// need to wait to mark the penalty as paid until the go routines above execute as the email
 		// sender relies on the state of the penalty in the DB i.e. not paid yet
 		log.InfoC(requestId, "updating account penalty cache record as paid", log.Data{"customer_code": resource.CustomerCode, "payable_ref": resource.PayableRef})
-		updateAccountPenaltyAsPaid(resource, apDaoSvc, requestId)
+		updateAccountPenaltyAsPaid(resource, apDaoSvc, penaltyPaymentDetails, requestId)
 
 		log.InfoC(requestId, "PATCH payable resource request completed successfully", log.Data{"customer_code": resource.CustomerCode})
 		w.Header().Set("Content-Type", "application/json")
@@ -177,10 +192,10 @@ func updateAsPaidInDatabase(resource *models.PayableResource, payment *validator
 }
 
 func updateIssuer(payableResourceService *services.PayableResourceService, e5Client e5.ClientInterface, resource *models.PayableResource,
-	payment *validators.PaymentInformation, requestId string, w http.ResponseWriter) {
+	payment *validators.PaymentInformation, penaltyPaymentDetails *config.PenaltyDetailsMap, requestId string, w http.ResponseWriter) {
 	// Mark the resource as paid in e5
 	defer wg.Done()
-	err := api.UpdateIssuerAccountWithPenaltyPaid(payableResourceService, e5Client, *resource, *payment, requestId)
+	err := api.UpdateIssuerAccountWithPenaltyPaid(payableResourceService, e5Client, *resource, *payment, penaltyPaymentDetails, requestId)
 	if err != nil {
 		log.ErrorC(requestId, err, log.Data{
 			"payable_ref":   resource.PayableRef,
@@ -196,8 +211,29 @@ func updateIssuer(payableResourceService *services.PayableResourceService, e5Cli
 	})
 }
 
-func addPaymentsProcessingMsgToTopic(payableResource *models.PayableResource,
-	payment *validators.PaymentInformation, requestId string, w http.ResponseWriter) {
+// deferIssuerUpdate records that the payment has not been created in E5, as the finance system is in a maintenance
+// window, so that the penalty is updated as paid in E5 with the payable resources whose E5 commands failed
+func deferIssuerUpdate(payableResourceService *services.PayableResourceService, resource *models.PayableResource,
+	requestId string, w http.ResponseWriter) {
+	defer wg.Done()
+	err := api.RecordIssuerCommandError(payableResourceService, *resource, e5.CreateAction, requestId)
+	if err != nil {
+		log.ErrorC(requestId, err, log.Data{
+			"payable_ref":   resource.PayableRef,
+			"customer_code": resource.CustomerCode,
+		})
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	log.InfoC(requestId, "deferred update of payment in E5 until after planned maintenance", log.Data{
+		"payable_ref":   resource.PayableRef,
+		"customer_code": resource.CustomerCode,
+	})
+}
+
+func addPaymentsProcessingMsgToTopic(payableResource *models.PayableResource, payment *validators.PaymentInformation,
+	penaltyPaymentDetails *config.PenaltyDetailsMap, requestId string, w http.ResponseWriter) {
 	defer wg.Done()
 
 	logContext := log.Data{
@@ -208,7 +244,7 @@ func addPaymentsProcessingMsgToTopic(payableResource *models.PayableResource,
 	}
 	log.DebugC(requestId, "adding payments processing message to topic", logContext)
 	// send the kafka message to the producer
-	err := handlePaymentProcessingKafkaMessage(*payableResource, payment, requestId)
+	err := handlePaymentProcessingKafkaMessage(*payableResource, payment, penaltyPaymentDetails, requestId)
 	if err != nil {
 		log.ErrorC(requestId, err, logContext)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -217,8 +253,9 @@ func addPaymentsProcessingMsgToTopic(payableResource *models.PayableResource,
 	log.InfoC(requestId, "Payment processing kafka message sent", logContext)
 }
 
-func updateAccountPenaltyAsPaid(resource *models.PayableResource, svc dao.AccountPenaltiesDaoService, requestId string) {
-	companyCode, err := getCompanyCodeFromTransaction(resource.Transactions)
+func updateAccountPenaltyAsPaid(resource *models.PayableResource, svc dao.AccountPenaltiesDaoService,
+	penaltyPaymentDetails *config.PenaltyDetailsMap, requestId string) {
+	companyCode, err := getCompanyCodeFromTransaction(penaltyPaymentDetails, resource.Transactions)
 	if err != nil {
 		log.ErrorC(requestId, fmt.Errorf("error updating account penalties collection as paid because company code cannot be resolved: [%v]", err),
 			log.Data{"customer_code": resource.CustomerCode, "payable_ref": resource.PayableRef})
@@ -8,7 +8,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/companieshouse/api-sdk-go/companieshouseapi"
 	"github.com/companieshouse/go-session-handler/httpsession"
@@ -21,6 +23,7 @@ import (
 	"github.com/companieshouse/penalty-payment-api/common/services"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
 	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 	"github.com/companieshouse/penalty-payment-api/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/jarcoal/httpmock"
@@ -43,7 +46,7 @@ var e5ValidationError = `
   } ]
 }
 `
-var penaltyDetailsMap = &config.PenaltyDetailsMap{}
+var penaltyDetailsMap, _ = config.LoadPenaltyDetails(filepath.Join(assetsDir, "penalty_details.yml"))
 var allowedTransactionsMap = &models.AllowedTransactionMap{
 	Types: map[string]map[string]bool{
 		"1": {
@@ -77,7 +80,7 @@ func dispatchPayResourceHandler(ctx context.Context, t *testing.T, reqBody *mode
 	ctx = context.WithValue(ctx, httpsession.ContextKeySession, &session.Session{})
 
 	h := PayResourceHandler(payableResourceService, e5.NewClient("foo", "e5api"),
-		penaltyDetailsMap, allowedTransactionsMap, apDaoSvc)
+		penaltyDetailsMap, allowedTransactionsMap, apDaoSvc, nil)
 	req := httptest.NewRequest(http.MethodPost, "/", body).WithContext(ctx)
 	res := httptest.NewRecorder()
 
@@ -109,20 +112,22 @@ func mockSendEmailKafkaMessage(_ models.PayableResource, _ *http.Request,
 }
 
 // Mock function for erroring when preparing and sending kafka message
-func mockPaymentsProcessingKafkaMessageError(_ models.PayableResource, _ *validators.PaymentInformation, _ string) error {
+func mockPaymentsProcessingKafkaMessageError(_ models.PayableResource, _ *validators.PaymentInformation,
+	_ *config.PenaltyDetailsMap, _ string) error {
 	return errors.New("error")
 }
 
 // Mock function for successful preparing and sending of kafka message
-func mockPaymentsProcessingKafkaMessage(_ models.PayableResource, _ *validators.PaymentInformation, _ string) error {
+func mockPaymentsProcessingKafkaMessage(_ models.PayableResource, _ *validators.PaymentInformation,
+	_ *config.PenaltyDetailsMap, _ string) error {
 	return nil
 }
 
-func mockedGetCompanyCodeFromTransaction(_ []models.TransactionItem) (string, error) {
+func mockedGetCompanyCodeFromTransaction(_ *config.PenaltyDetailsMap, _ []models.TransactionItem) (string, error) {
 	return utils.LateFilingPenaltyCompanyCode, nil
 }
 
-func mockedGetCompanyCodeFromTransactionError(_ []models.TransactionItem) (string, error) {
+func mockedGetCompanyCodeFromTransactionError(_ *config.PenaltyDetailsMap, _ []models.TransactionItem) (string, error) {
 	return "", errors.New("no penalty reference found")
 }
 
@@ -173,6 +178,26 @@ func TestUnitPayResourceHandler(t *testing.T) {
 			So(body.Message, ShouldEqual, "the request contained insufficient data and/or failed validation")
 		})
 
+		Convey("payable resource must not have expired", func() {
+			model := buildMockedPayableResource(true, 150)
+			model.Payment.Status = dao.PaymentStatusExpired
+			ctx := context.WithValue(context.Background(), config.PayableResource, model)
+			res, body := dispatchPayResourceHandler(ctx, t, &models.PatchResourceRequest{Reference: "123"}, nil, nil)
+
+			So(res.Code, ShouldEqual, http.StatusGone)
+			So(body.Message, ShouldEqual, "the payable resource has expired")
+		})
+
+		Convey("payable resource must not have been cancelled", func() {
+			model := buildMockedPayableResource(true, 150)
+			model.Payment.Status = dao.PaymentStatusCancelled
+			ctx := context.WithValue(context.Background(), config.PayableResource, model)
+			res, body := dispatchPayResourceHandler(ctx, t, &models.PatchResourceRequest{Reference: "123"}, nil, nil)
+
+			So(res.Code, ShouldEqual, http.StatusGone)
+			So(body.Message, ShouldEqual, "the payable resource has been cancelled")
+		})
+
 		Convey("error decoding request body json", func() {
 			defer httpmock.Reset()
 
@@ -190,7 +215,7 @@ func TestUnitPayResourceHandler(t *testing.T) {
 			ctx = context.WithValue(ctx, httpsession.ContextKeySession, &session.Session{})
 
 			h := PayResourceHandler(payableResourceService, e5.NewClient("foo", "e5api"),
-				penaltyDetailsMap, allowedTransactionsMap, nil)
+				penaltyDetailsMap, allowedTransactionsMap, nil, nil)
 			req := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(ctx)
 			res := httptest.NewRecorder()
 
@@ -277,7 +302,7 @@ func TestUnitPayResourceHandler(t *testing.T) {
 		})
 
 		Convey("problem with sending confirmation email", func() {
-			mockedGetCompanyCode := func(penaltyReference string) (string, error) {
+			mockedGetCompanyCode := func(_ *config.PenaltyDetailsMap, penaltyReference string) (string, error) {
 				return utils.LateFilingPenaltyCompanyCode, nil
 			}
 
@@ -327,7 +352,7 @@ func TestUnitPayResourceHandler(t *testing.T) {
 		})
 
 		Convey("problem with adding payments message to topic", func() {
-			mockedGetCompanyCode := func(penaltyReference string) (string, error) {
+			mockedGetCompanyCode := func(_ *config.PenaltyDetailsMap, penaltyReference string) (string, error) {
 				return utils.LateFilingPenaltyCompanyCode, nil
 			}
 
@@ -377,7 +402,7 @@ func TestUnitPayResourceHandler(t *testing.T) {
 		})
 
 		Convey("Penalty has already been paid", func() {
-			mockedGetCompanyCode := func(penaltyReference string) (string, error) {
+			mockedGetCompanyCode := func(_ *config.PenaltyDetailsMap, penaltyReference string) (string, error) {
 				return utils.LateFilingPenaltyCompanyCode, nil
 			}
 
@@ -512,6 +537,7 @@ func TestUnitPayResourceHandler(t *testing.T) {
 			mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
 			mockPrDaoSvc.EXPECT().GetPayableResource(gomock.Any(), gomock.Any(), "").Return(dataModel, nil)
 			mockPrDaoSvc.EXPECT().UpdatePaymentDetails(dataModel, "").Times(1)
+			mockPrDaoSvc.EXPECT().SaveE5Processed(gomock.Any(), gomock.Any(), gomock.Any(), "").Times(1)
 
 			// the payable resource in the request context
 			model := buildMockedPayableResource(true, 150)
@@ -561,6 +587,7 @@ func TestUnitPayResourceHandler(t *testing.T) {
 			mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
 			mockPrDaoSvc.EXPECT().GetPayableResource(gomock.Any(), gomock.Any(), "").Return(dataModel, nil)
 			mockPrDaoSvc.EXPECT().UpdatePaymentDetails(dataModel, "").Times(1)
+			mockPrDaoSvc.EXPECT().SaveE5Processed(gomock.Any(), gomock.Any(), gomock.Any(), "").Times(1)
 			mockApDaoSvc.EXPECT().UpdateAccountPenaltyAsPaid(gomock.Any(), gomock.Any(), gomock.Any(), "").Return(errors.New("error"))
 
 			// the payable resource in the request context
@@ -611,6 +638,7 @@ func TestUnitPayResourceHandler(t *testing.T) {
 			mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
 			mockPrDaoSvc.EXPECT().GetPayableResource(gomock.Any(), gomock.Any(), "").Return(dataModel, nil)
 			mockPrDaoSvc.EXPECT().UpdatePaymentDetails(dataModel, "").Times(1)
+			mockPrDaoSvc.EXPECT().SaveE5Processed(gomock.Any(), gomock.Any(), gomock.Any(), "").Times(1)
 			mockApDaoSvc.EXPECT().UpdateAccountPenaltyAsPaid(gomock.Any(), gomock.Any(), gomock.Any(), "").Return(nil)
 
 			// the payable resource in the request context
@@ -666,6 +694,8 @@ func TestUnitPayResourceHandler(t *testing.T) {
 			mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
 			mockPrDaoSvc.EXPECT().GetPayableResource(gomock.Any(), gomock.Any(), "").Return(dataModel, nil)
 			mockPrDaoSvc.EXPECT().UpdatePaymentDetails(dataModel, "").Times(1)
+			// E5 is only updated, and recorded as processed, when payments processing is disabled
+			mockPrDaoSvc.EXPECT().SaveE5Processed(gomock.Any(), gomock.Any(), gomock.Any(), "").MaxTimes(1)
 			mockApDaoSvc.EXPECT().UpdateAccountPenaltyAsPaid(gomock.Any(), gomock.Any(), gomock.Any(), "").Return(nil)
 
 			// the payable resource in the request context
@@ -725,4 +755,74 @@ func TestUnitPayResourceHandler(t *testing.T) {
 
 		})
 	})
+
+	Convey("E5 is not updated during planned maintenance", t, func() {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		currentMaintenanceWindow = func(_ string, _ time.Time, _ *api.MaintenanceWindowsCache) *config.MaintenanceWindow {
+			return &config.MaintenanceWindow{End: time.Now().Add(time.Hour)}
+		}
+		getConfig = func() (*config.Config, error) {
+			return &config.Config{FeatureFlagPaymentsProcessingEnabled: false}, nil
+		}
+		defer func() {
+			currentMaintenanceWindow = api.CurrentMaintenanceWindow
+			getConfig = config.Get
+		}()
+
+		// stub the response from the payments api
+		p := buildMockedPaymentResource("paid", "150")
+		responder, _ := httpmock.NewJsonResponder(http.StatusOK, p)
+		httpmock.RegisterResponder(http.MethodGet, companieshouseapi.PaymentsBasePath+"/payments/123", responder)
+		httpmock.RegisterResponder(http.MethodGet, companieshouseapi.PaymentsBasePath+"/private/payments/123/payment-details",
+			httpmock.NewStringResponder(http.StatusOK, "{}"))
+
+		// count the calls to the e5 api
+		e5Calls := 0
+		httpmock.RegisterResponder(http.MethodPost, "e5api/arTransactions/payment", func(*http.Request) (*http.Response, error) {
+			e5Calls++
+			return httpmock.NewBytesResponse(http.StatusOK, nil), nil
+		})
+
+		// stub the mongo lookup
+		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(mockCtrl)
+		dataModel := &models.PayableResourceDao{}
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		mockPrDaoSvc.EXPECT().GetPayableResource(gomock.Any(), gomock.Any(), "").Return(dataModel, nil)
+		mockPrDaoSvc.EXPECT().UpdatePaymentDetails(dataModel, "").Times(1)
+		mockApDaoSvc.EXPECT().UpdateAccountPenaltyAsPaid(gomock.Any(), gomock.Any(), gomock.Any(), "").Return(nil)
+
+		// the payable resource in the request context
+		model := buildMockedPayableResource(true, 150)
+		ctx := context.WithValue(context.Background(), config.PayableResource, model)
+
+		// stub kafka message
+		handleSendEmailKafkaMessage = mockSendEmailKafkaMessage
+		getCompanyCodeFromTransaction = mockedGetCompanyCodeFromTransaction
+
+		reqBody := &models.PatchResourceRequest{Reference: "123"}
+
+		Convey("the update is recorded as an E5 command error to be processed later", func() {
+			mockPrDaoSvc.EXPECT().SaveE5Error(customerCode, "123", "", e5.CreateAction).Return(nil)
+
+			res, body := dispatchPayResourceHandler(ctx, t, reqBody, mockPrDaoSvc, mockApDaoSvc)
+
+			So(res.Code, ShouldEqual, http.StatusNoContent)
+			So(body, ShouldBeNil)
+			So(e5Calls, ShouldEqual, 0)
+		})
+
+		Convey("an error recording the E5 command error is an internal error", func() {
+			mockPrDaoSvc.EXPECT().SaveE5Error(customerCode, "123", "", e5.CreateAction).Return(errors.New("error"))
+
+			res, _ := dispatchPayResourceHandler(ctx, t, reqBody, mockPrDaoSvc, mockApDaoSvc)
+
+			So(res.Code, ShouldEqual, http.StatusInternalServerError)
+			So(e5Calls, ShouldEqual, 0)
+		})
+	})
 }
@@ -10,22 +10,41 @@ import (
 	"github.com/companieshouse/penalty-payment-api/config"
 )
 
-// HandleGetPayableResource retrieves the payable resource from request context
-func HandleGetPayableResource(w http.ResponseWriter, req *http.Request) {
-	requestId := log.Context(req)
-	log.InfoC(requestId, "start GET payable resource request")
-
-	// get payable resource from context, put there by PayableResourceAuthenticationInterceptor
-	payableResource, ok := req.Context().Value(config.PayableResource).(*models.PayableResource)
-
-	if !ok {
-		log.ErrorC(requestId, fmt.Errorf("invalid PayableResource in request context"))
-		m := models.NewMessageResponse("the payable resource is not present in the request context")
-		utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
-		return
-	}
-	log.DebugC(requestId, "got payable resource", log.Data{"payable_resource": payableResource})
-	utils.WriteJSON(w, req, payableResource)
+// HandleGetPayableResource retrieves the payable resource from request context, translating the reasons of
+// its transactions into the requested language. The ETag is generated from the resource, so that a client
+// with the current version of it gets a 304.
+func HandleGetPayableResource(reasonsCatalogue *config.ReasonsCatalogue) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestId := log.Context(req)
+		log.InfoC(requestId, "start GET payable resource request")
+
+		// get payable resource from context, put there by PayableResourceAuthenticationInterceptor
+		payableResource, ok := req.Context().Value(config.PayableResource).(*models.PayableResource)
+
+		if !ok {
+			log.ErrorC(requestId, fmt.Errorf("invalid PayableResource in request context"))
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodePayableResourceMissing, "the payable resource is not present in the request context")
+			return
+		}
+		log.DebugC(requestId, "got payable resource", log.Data{"payable_resource": payableResource})
 
-	log.InfoC(requestId, "GET payable resource request completed successfully")
+		language := utils.GetLanguage(req)
+		localisedPayableResource := *payableResource
+		localisedPayableResource.Transactions = make([]models.TransactionItem, len(payableResource.Transactions))
+		for i, transaction := range payableResource.Transactions {
+			transaction.Reason = reasonsCatalogue.Translate(transaction.Reason, language)
+			localisedPayableResource.Transactions[i] = transaction
+		}
+
+		utils.SetContentLanguage(w, language)
+		etag, err := utils.GenerateContentEtag(localisedPayableResource)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error generating etag: %v", err))
+			utils.WriteJSON(w, req, localisedPayableResource)
+		} else {
+			utils.WriteJSONWithEtag(w, req, localisedPayableResource, etag)
+		}
+
+		log.InfoC(requestId, "GET payable resource request completed successfully")
+	}
 }
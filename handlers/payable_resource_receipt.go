@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/constants"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/penalty_payments/receipt"
+)
+
+const (
+	htmlReceiptFormat = "html"
+	pdfReceiptFormat  = "pdf"
+)
+
+// HandleGetPayableResourceReceipt writes the receipt of the paid payable resource in the request context as an
+// HTML page or a PDF download, with the name of the company and the reasons of its penalties in the requested
+// language. The format is chosen by the format query parameter, or by the Accept header if it is not supplied.
+// Authorisation is handled by the PayableAuthenticationInterceptor.
+func HandleGetPayableResourceReceipt(reasonsCatalogue *config.ReasonsCatalogue) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestId := log.Context(req)
+		log.InfoC(requestId, "start GET payable resource receipt request")
+
+		payableResource, ok := req.Context().Value(config.PayableResource).(*models.PayableResource)
+		if !ok {
+			log.ErrorC(requestId, fmt.Errorf("invalid PayableResource in request context"))
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodePayableResourceMissing, "the payable resource is not present in the request context")
+			return
+		}
+		logContext := log.Data{"customer_code": payableResource.CustomerCode, "payable_ref": payableResource.PayableRef}
+
+		if payableResource.Payment.Status != constants.Paid.String() || payableResource.Payment.PaidAt == nil {
+			log.InfoC(requestId, "receipt requested for a payable resource that has not been paid", logContext)
+			writeProblem(w, req, http.StatusConflict, utils.ErrorCodePayableResourceNotPaid, "the payable resource has not been paid")
+			return
+		}
+
+		format, status, err := getReceiptFormat(req)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			code := utils.ErrorCodeNotAcceptable
+			if status == http.StatusBadRequest {
+				code = utils.ErrorCodeInvalidQueryParameter
+			}
+			writeErrorProblem(w, req, status, err, code, err.Error())
+			return
+		}
+
+		companyName, err := getCompanyName(payableResource.CustomerCode, req)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error getting company name: %v", err), logContext)
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem getting the company name")
+			return
+		}
+
+		language := utils.GetLanguage(req)
+		localisedPayableResource := *payableResource
+		localisedPayableResource.Transactions = make([]models.TransactionItem, len(payableResource.Transactions))
+		for i, transaction := range payableResource.Transactions {
+			transaction.Reason = reasonsCatalogue.Translate(transaction.Reason, language)
+			localisedPayableResource.Transactions[i] = transaction
+		}
+
+		rendered, renderedLanguage, err := renderReceipt(&localisedPayableResource, companyName, language, format)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error rendering receipt: %v", err), logContext)
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+			return
+		}
+
+		filename := fmt.Sprintf("receipt-%s.%s", payableResource.PayableRef, format)
+		if format == pdfReceiptFormat {
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+		}
+		utils.SetContentLanguage(w, renderedLanguage)
+		if _, err = rendered.WriteTo(w); err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error writing receipt: %v", err), logContext)
+			return
+		}
+
+		log.InfoC(requestId, "GET payable resource receipt request completed successfully", logContext, log.Data{"format": format})
+	}
+}
+
+// renderReceipt renders the receipt of the payable resource before it is written, so that an error can still be
+// responded with. The language the receipt was rendered in is returned with it.
+func renderReceipt(payableResource *models.PayableResource, companyName, language, format string) (*bytes.Buffer, string, error) {
+	paymentReceipt, err := receipt.New(payableResource, companyName, language)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rendered bytes.Buffer
+	if format == pdfReceiptFormat {
+		err = paymentReceipt.WritePDF(&rendered)
+	} else {
+		err = paymentReceipt.WriteHTML(&rendered)
+	}
+	return &rendered, paymentReceipt.Language, err
+}
+
+// getReceiptFormat returns the format in the format query parameter or, if it is not supplied, the format
+// the Accept header prefers. The status to respond with is returned with any error.
+func getReceiptFormat(req *http.Request) (string, int, error) {
+	return negotiateFormat(req, "receipt", []responseFormat{
+		{name: htmlReceiptFormat, mediaType: "text/html"},
+		{name: pdfReceiptFormat, mediaType: "application/pdf"},
+	})
+}
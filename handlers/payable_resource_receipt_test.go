@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/penalty_payments/service"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitHandleGetPayableResourceReceipt(t *testing.T) {
+	Convey("Given the receipt of a payable resource is requested", t, func() {
+		getCompanyName = func(companyNumber string, req *http.Request) (string, error) {
+			return "ACME LIMITED", nil
+		}
+		defer func() { getCompanyName = service.GetCompanyName }()
+
+		reasonsCatalogue := &config.ReasonsCatalogue{
+			Reasons: []config.Reason{
+				{CompanyCode: "LP", Text: map[string]string{"en": "Late filing of accounts", "cy": "Cyflwyno cyfrifon yn hwyr"}},
+			},
+		}
+		paidAt := time.Date(2025, time.March, 16, 19, 10, 0, 0, time.UTC)
+		payable := &models.PayableResource{
+			CustomerCode: "12345678",
+			PayableRef:   "abcdef",
+			Transactions: []models.TransactionItem{
+				{Amount: 150, Type: "penalty", PenaltyRef: "A1234567", Reason: "Late filing of accounts"},
+			},
+			Payment: models.Payment{Amount: "150", Status: "paid", Reference: "payment-ref", PaidAt: &paidAt},
+		}
+
+		serve := func(target, accept string, payable *models.PayableResource) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Header.Set("Accept", accept)
+			if payable != nil {
+				req = req.WithContext(context.WithValue(req.Context(), config.PayableResource, payable))
+			}
+			res := httptest.NewRecorder()
+			HandleGetPayableResourceReceipt(reasonsCatalogue).ServeHTTP(res, req)
+			return res
+		}
+
+		Convey("Then the receipt is an HTML page by default", func() {
+			res := serve("/receipt", "", payable)
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			So(res.Header().Get("Content-Type"), ShouldEqual, "text/html; charset=utf-8")
+			So(res.Header().Get("Content-Disposition"), ShouldEqual, `inline; filename="receipt-abcdef.html"`)
+			So(res.Body.String(), ShouldContainSubstring, "ACME LIMITED")
+			So(res.Body.String(), ShouldContainSubstring, "Late filing of accounts")
+			So(res.Body.String(), ShouldContainSubstring, "payment-ref")
+		})
+
+		Convey("Then the receipt is in the requested language", func() {
+			res := serve("/receipt?lang=cy", "text/html", payable)
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			So(res.Header().Get("Content-Language"), ShouldEqual, "cy")
+			So(res.Header().Get("Vary"), ShouldEqual, "Accept-Language")
+			So(res.Body.String(), ShouldContainSubstring, "Cyflwyno cyfrifon yn hwyr")
+			So(res.Body.String(), ShouldContainSubstring, `<html lang="cy">`)
+			So(res.Body.String(), ShouldContainSubstring, "<h1>Derbynneb talu cosb</h1>")
+			So(payable.Transactions[0].Reason, ShouldEqual, "Late filing of accounts")
+		})
+
+		Convey("Then the receipt is a PDF download when the format is pdf", func() {
+			res := serve("/receipt?format=pdf", "application/json", payable)
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			So(res.Header().Get("Content-Type"), ShouldEqual, "application/pdf")
+			So(res.Header().Get("Content-Disposition"), ShouldEqual, `attachment; filename="receipt-abcdef.pdf"`)
+			So(res.Body.String(), ShouldStartWith, "%PDF-")
+		})
+
+		Convey("Then the receipt is a PDF download when a PDF is accepted", func() {
+			res := serve("/receipt", "application/pdf", payable)
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			So(res.Header().Get("Content-Type"), ShouldEqual, "application/pdf")
+		})
+
+		Convey("Then the receipt is in the format the Accept header prefers", func() {
+			So(serve("/receipt", "text/html;q=0.5, application/pdf", payable).Header().Get("Content-Type"), ShouldEqual, "application/pdf")
+			So(serve("/receipt", "application/pdf;q=0.5, */*", payable).Header().Get("Content-Type"), ShouldEqual, "text/html; charset=utf-8")
+			So(serve("/receipt", "text/html;q=0, application/pdf;q=0.1", payable).Header().Get("Content-Type"), ShouldEqual, "application/pdf")
+		})
+
+		Convey("Then the receipt cannot be provided in a format that is not accepted", func() {
+			So(serve("/receipt", "application/json", payable).Code, ShouldEqual, http.StatusNotAcceptable)
+
+			res := serve("/receipt", "application/json, "+utils.ProblemContentType, payable)
+
+			So(res.Code, ShouldEqual, http.StatusNotAcceptable)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodeNotAcceptable)
+		})
+
+		Convey("Then an unknown format is a bad request", func() {
+			res := serve("/receipt?format=doc", utils.ProblemContentType, payable)
+
+			So(res.Code, ShouldEqual, http.StatusBadRequest)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodeInvalidQueryParameter)
+			So(res.Body.String(), ShouldContainSubstring, `"field":"format"`)
+		})
+
+		Convey("Then a payable resource that has not been paid has no receipt", func() {
+			payable.Payment.Status = "pending"
+			payable.Payment.PaidAt = nil
+
+			res := serve("/receipt", utils.ProblemContentType, payable)
+
+			So(res.Code, ShouldEqual, http.StatusConflict)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodePayableResourceNotPaid)
+		})
+
+		Convey("Then an error getting the company name is an internal server error", func() {
+			getCompanyName = func(companyNumber string, req *http.Request) (string, error) {
+				return "", errors.New("error")
+			}
+
+			So(serve("/receipt", "text/html", payable).Code, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Convey("Then a payable resource missing from the context is an internal server error", func() {
+			res := serve("/receipt", utils.ProblemContentType, nil)
+
+			So(res.Code, ShouldEqual, http.StatusInternalServerError)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodePayableResourceMissing)
+		})
+	})
+}
@@ -16,7 +16,7 @@ func TestUnitHandleGetPayableResource(t *testing.T) {
 	Convey("Invalid PayableResourceRest", t, func() {
 		req := httptest.NewRequest("GET", "/test", nil)
 		w := httptest.NewRecorder()
-		HandleGetPayableResource(w, req)
+		HandleGetPayableResource(&config.ReasonsCatalogue{}).ServeHTTP(w, req)
 		So(w.Code, ShouldEqual, 500)
 	})
 	Convey("Valid PayableResource", t, func() {
@@ -51,7 +51,7 @@ func TestUnitHandleGetPayableResource(t *testing.T) {
 		ctx := context.WithValue(req.Context(), config.PayableResource, &payable)
 		w := httptest.NewRecorder()
 
-		HandleGetPayableResource(w, req.WithContext(ctx))
+		HandleGetPayableResource(&config.ReasonsCatalogue{}).ServeHTTP(w, req.WithContext(ctx))
 
 		So(w.Code, ShouldEqual, 200)
 		So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
@@ -74,4 +74,61 @@ func TestUnitHandleGetPayableResource(t *testing.T) {
 		So(resultPayable.Transactions[0].PenaltyRef, ShouldEqual, payable.Transactions[0].PenaltyRef)
 
 	})
+	Convey("PayableResource not modified", t, func() {
+		payable := models.PayableResource{
+			CustomerCode: "12345678",
+			PayableRef:   "abcdef",
+			Payment:      models.Payment{Amount: "5", Status: "pending"},
+		}
+		getPayable := func(ifNoneMatch string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/test", nil)
+			if ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", ifNoneMatch)
+			}
+			ctx := context.WithValue(req.Context(), config.PayableResource, &payable)
+			w := httptest.NewRecorder()
+			HandleGetPayableResource(&config.ReasonsCatalogue{}).ServeHTTP(w, req.WithContext(ctx))
+			return w
+		}
+
+		etag := getPayable("").Header().Get("ETag")
+		So(etag, ShouldNotBeEmpty)
+		So(getPayable(etag).Code, ShouldEqual, 304)
+
+		payable.Payment.Status = "paid"
+		w := getPayable(etag)
+		So(w.Code, ShouldEqual, 200)
+		So(w.Header().Get("ETag"), ShouldNotEqual, etag)
+	})
+	Convey("Valid PayableResource in Welsh", t, func() {
+		reasonsCatalogue := &config.ReasonsCatalogue{
+			Reasons: []config.Reason{
+				{CompanyCode: "LP", Text: map[string]string{"en": "Late filing of accounts", "cy": "Cyflwyno cyfrifon yn hwyr"}},
+			},
+		}
+		payable := models.PayableResource{
+			CustomerCode: "12345678",
+			PayableRef:   "abcdef",
+			Transactions: []models.TransactionItem{
+				{Amount: 5, Type: "penalty", PenaltyRef: "A1234567", Reason: "Late filing of accounts"},
+				{Amount: 5, Type: "penalty", PenaltyRef: "A7654321", Reason: "Not in the catalogue"},
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/test?lang=cy", nil)
+		ctx := context.WithValue(req.Context(), config.PayableResource, &payable)
+		w := httptest.NewRecorder()
+
+		HandleGetPayableResource(reasonsCatalogue).ServeHTTP(w, req.WithContext(ctx))
+
+		So(w.Code, ShouldEqual, 200)
+		So(w.Header().Get("Content-Language"), ShouldEqual, "cy")
+		So(w.Header().Get("Vary"), ShouldEqual, "Accept-Language")
+
+		resultPayable := &models.PayableResource{}
+		So(json.NewDecoder(w.Body).Decode(&resultPayable), ShouldBeNil)
+		So(resultPayable.Transactions[0].Reason, ShouldEqual, "Cyflwyno cyfrifon yn hwyr")
+		So(resultPayable.Transactions[1].Reason, ShouldEqual, "Not in the catalogue")
+		So(payable.Transactions[0].Reason, ShouldEqual, "Late filing of accounts")
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
@@ -21,21 +22,21 @@ func HandleGetPaymentDetails(penaltyDetailsMap *config.PenaltyDetailsMap) http.H
 		payableResource, ok := req.Context().Value(config.PayableResource).(*models.PayableResource)
 		if !ok {
 			log.ErrorC(requestId, fmt.Errorf("invalid PayableResource in request context"))
-			m := models.NewMessageResponse("the payable resource is not present in the request context")
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodePayableResourceMissing, "the payable resource is not present in the request context")
 			return
 		}
 		log.DebugC(requestId, "got payable resource", log.Data{"payableResource": payableResource})
 
-		penaltyRefType, err := getPenaltyRefTypeFromTransaction(payableResource.Transactions)
+		penaltyRefType, err := getPenaltyRefTypeFromTransaction(penaltyDetailsMap, payableResource.Transactions)
 		if err != nil {
 			log.ErrorC(requestId, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReference, err.Error())
 			return
 		}
 
+		language := utils.GetLanguage(req)
 		penaltyDetails := penaltyDetailsMap.Details[penaltyRefType]
+		penaltyDetails.Description = penaltyDetails.GetDescription(language)
 		log.DebugC(requestId, "penalty details", log.Data{"penaltyDetails": penaltyDetails})
 
 		// Get the payment details from the payable resource
@@ -46,11 +47,21 @@ func HandleGetPaymentDetails(penaltyDetailsMap *config.PenaltyDetailsMap) http.H
 		// can only return either an InvalidData or Success response type
 		if err != nil {
 			log.DebugC(requestId, fmt.Sprintf("invalid data getting payment details from payable resource so returning not found [%s]", err.Error()), logContext)
-			m := models.NewMessageResponse("payable resource does not exist or has insufficient data")
-			utils.WriteJSONWithStatus(w, req, m, http.StatusNotFound)
+			writeProblem(w, req, http.StatusNotFound, utils.ErrorCodePayableResourceNotFound, "payable resource does not exist or has insufficient data")
 			return
 		}
 		log.DebugC(requestId, "got payment details", log.Data{"paymentDetails": paymentDetails})
+
+		// the payments platform gets the payment details when it starts taking payment, which locks the penalties
+		// so that another payable resource cannot be created and paid for them at the same time
+		if authentication.GetAuthorisedIdentityType(req) == authentication.APIKeyIdentityType &&
+			paymentDetailsService != nil && paymentDetailsService.PayableResourceService != nil {
+			err = paymentDetailsService.PayableResourceService.RecordPaymentStarted(payableResource, timeNow(), requestId)
+			if err != nil {
+				log.ErrorC(requestId, fmt.Errorf("error recording payment started: %v", err), logContext)
+			}
+		}
+		utils.SetContentLanguage(w, language)
 		utils.WriteJSON(w, req, paymentDetails)
 
 		log.InfoC(requestId, "GET payment details request completed successfully")
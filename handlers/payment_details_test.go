@@ -2,15 +2,22 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
 	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/mocks"
+	"github.com/companieshouse/penalty-payment-api/penalty_payments/service"
+	"github.com/golang/mock/gomock"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -31,7 +38,7 @@ func serveGetPaymentDetailsHandler(payableResource *models.PayableResource) *htt
 }
 
 func setGetPenaltyRefTypeFromTransactionMock(penaltyRefType string) {
-	mockedGetPenaltyRefTypeFromTransaction := func(transactions []models.TransactionItem) (string, error) {
+	mockedGetPenaltyRefTypeFromTransaction := func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 		return penaltyRefType, nil
 	}
 	getPenaltyRefTypeFromTransaction = mockedGetPenaltyRefTypeFromTransaction
@@ -79,7 +86,7 @@ func TestUnitHandleGetPaymentDetails(t *testing.T) {
 
 	Convey("Cannot determine penalty ref type from transaction ID", t, func() {
 
-		getPenaltyRefTypeFromTransaction = func(transactions []models.TransactionItem) (string, error) {
+		getPenaltyRefTypeFromTransaction = func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 			return "", errors.New("cannot determine penalty ref type")
 		}
 
@@ -136,4 +143,81 @@ func TestUnitHandleGetPaymentDetails(t *testing.T) {
 			})
 		}
 	})
+	Convey("Payment PenaltyDetails description in Welsh", t, func() {
+		setGetPenaltyRefTypeFromTransactionMock(utils.LateFilingPenaltyRefType)
+		penaltyDetailsMap := &config.PenaltyDetailsMap{
+			Details: map[string]config.PenaltyDetails{
+				utils.LateFilingPenaltyRefType: {
+					Description:  "Late Filing Penalty",
+					Descriptions: map[string]string{utils.WelshLanguage: "Cosb am Gyflwyno'n Hwyr"},
+				},
+			},
+		}
+		payable := generateTestPayableResource(true, "A1234567")
+
+		req := httptest.NewRequest(http.MethodGet, "/company/12345/penalties/payable/321/payment?lang=cy", nil)
+		req = req.WithContext(context.WithValue(req.Context(), config.PayableResource, &payable))
+		res := httptest.NewRecorder()
+
+		HandleGetPaymentDetails(penaltyDetailsMap).ServeHTTP(res, req)
+
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Header().Get("Content-Language"), ShouldEqual, utils.WelshLanguage)
+		So(res.Header().Get("Vary"), ShouldEqual, "Accept-Language")
+		var paymentDetails models.PaymentDetails
+		So(json.NewDecoder(res.Body).Decode(&paymentDetails), ShouldBeNil)
+		So(paymentDetails.Description, ShouldEqual, "Cosb am Gyflwyno'n Hwyr")
+		So(paymentDetails.Items[0].Description, ShouldEqual, "Cosb am Gyflwyno'n Hwyr")
+	})
+
+	Convey("Payment started is recorded when the payments platform gets the payment details", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		paymentDetailsService = &service.PaymentDetailsService{
+			PayableResourceService: &services.PayableResourceService{DAO: mockPrDaoSvc},
+		}
+		defer func() { paymentDetailsService = nil }()
+		now := time.Now()
+		timeNow = func() time.Time { return now }
+		defer func() { timeNow = time.Now }()
+		setGetPenaltyRefTypeFromTransactionMock(utils.LateFilingPenaltyRefType)
+		payable := generateTestPayableResource(true, "A1234567")
+
+		serve := func(identityType string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/company/12345678/penalties/payable/abcdef/payment", nil)
+			req.Header.Set("ERIC-Identity-Type", identityType)
+			req = req.WithContext(context.WithValue(req.Context(), config.PayableResource, &payable))
+			res := httptest.NewRecorder()
+			HandleGetPaymentDetails(&config.PenaltyDetailsMap{}).ServeHTTP(res, req)
+			return res
+		}
+
+		Convey("Then an API key request records the payment started", func() {
+			mockPrDaoSvc.EXPECT().SetPaymentStarted("12345678", "abcdef", now, gomock.Any()).Return(nil)
+
+			So(serve(authentication.APIKeyIdentityType).Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Then an error recording the payment started is not returned", func() {
+			mockPrDaoSvc.EXPECT().SetPaymentStarted("12345678", "abcdef", now, gomock.Any()).Return(errors.New("error"))
+
+			So(serve(authentication.APIKeyIdentityType).Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Then an expired payable resource has the expired status and its payment is not started", func() {
+			payable.Payment.Status = dao.PaymentStatusExpired
+
+			res := serve(authentication.APIKeyIdentityType)
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			var paymentDetails models.PaymentDetails
+			So(json.NewDecoder(res.Body).Decode(&paymentDetails), ShouldBeNil)
+			So(paymentDetails.Status, ShouldEqual, dao.PaymentStatusExpired)
+		})
+
+		Convey("Then a user request does not record the payment started", func() {
+			So(serve(authentication.Oauth2IdentityType).Code, ShouldEqual, http.StatusOK)
+		})
+	})
 }
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -18,9 +17,11 @@ import (
 
 var accountPenalties = api.AccountPenalties
 
-// HandleGetPenalties retrieves the penalty details for the supplied customer code from e5
+// HandleGetPenalties retrieves the penalty details for the supplied customer code from e5, filtered, sorted
+// and paginated by the query parameters
 func HandleGetPenalties(apDaoSvc dao.AccountPenaltiesDaoService, penaltyDetailsMap *config.PenaltyDetailsMap,
-	allowedTransactionsMap *models.AllowedTransactionMap) http.HandlerFunc {
+	allowedTransactionsMap *models.AllowedTransactionMap, payableStatusRules *config.PayableStatusRules,
+	reasonsCatalogue *config.ReasonsCatalogue) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		requestId := log.Context(req)
 		log.InfoC(requestId, "start GET penalties request")
@@ -32,15 +33,23 @@ func HandleGetPenalties(apDaoSvc dao.AccountPenaltiesDaoService, penaltyDetailsM
 		// the penalty reference type is needed further on in the generate_transaction_list to get
 		// the ResourceKind from the penalty_details.yaml
 		penaltyRefType := GetPenaltyRefType(vars["penalty_reference_type"])
-		companyCode, err := getCompanyCode(penaltyRefType)
+		companyCode, err := getCompanyCode(penaltyDetailsMap, penaltyRefType)
 
 		if err != nil {
 			log.ErrorC(requestId, err)
-			m := models.NewMessageResponse("invalid penalty reference type supplied")
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReferenceType, "invalid penalty reference type supplied")
 			return
 		}
 
+		query, err := parsePenaltiesQuery(req)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			writeErrorProblem(w, req, http.StatusBadRequest, err, utils.ErrorCodeInvalidQueryParameter, "invalid query parameter: "+err.Error())
+			return
+		}
+
+		language := utils.GetLanguage(req)
+
 		// Call service layer to handle request to E5
 		params := types.AccountPenaltiesParams{
 			PenaltyRefType:             penaltyRefType,
@@ -48,6 +57,9 @@ func HandleGetPenalties(apDaoSvc dao.AccountPenaltiesDaoService, penaltyDetailsM
 			CompanyCode:                companyCode,
 			PenaltyDetailsMap:          penaltyDetailsMap,
 			AllowedTransactionsMap:     allowedTransactionsMap,
+			PayableStatusRules:         payableStatusRules,
+			ReasonsCatalogue:           reasonsCatalogue,
+			Language:                   language,
 			AccountPenaltiesDaoService: apDaoSvc,
 			RequestId:                  requestId,
 		}
@@ -57,24 +69,25 @@ func HandleGetPenalties(apDaoSvc dao.AccountPenaltiesDaoService, penaltyDetailsM
 			log.ErrorC(requestId, fmt.Errorf("error calling e5 to get transactions: %v", err))
 			switch responseType {
 			case services.InvalidData:
-				m := models.NewMessageResponse("failed to read finance transactions")
-				utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+				writeErrorProblem(w, req, http.StatusBadRequest, err, utils.ErrorCodeFinanceSystemInvalidResponse, "failed to read finance transactions")
 				return
 			default:
-				m := models.NewMessageResponse("there was a problem communicating with the finance backend")
-				utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+				writeErrorProblem(w, req, http.StatusInternalServerError, err, utils.ErrorCodeFinanceSystemError, "there was a problem communicating with the finance backend")
 				return
 			}
 		}
-		// response body contains fully decorated REST model
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-
-		err = json.NewEncoder(w).Encode(transactionListResponse)
+		transactionListResponse, err = query.apply(transactionListResponse)
 		if err != nil {
-			log.ErrorC(requestId, fmt.Errorf("error writing response: %v", err))
+			log.ErrorC(requestId, fmt.Errorf("error filtering penalties: %v", err))
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
 			return
 		}
+
+		// response body contains fully decorated REST model, a 304 is returned if the client has the
+		// current version of it
+		utils.SetContentLanguage(w, language)
+		utils.WriteJSONWithEtag(w, req, transactionListResponse, transactionListResponse.Etag)
+
 		log.InfoC(requestId, "GET penalties request completed successfully", log.Data{"customer_code": customerCode})
 	}
 }
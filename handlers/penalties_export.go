@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"github.com/gorilla/mux"
+)
+
+const (
+	csvExportFormat  = "csv"
+	jsonExportFormat = "json"
+)
+
+var penaltyExportCSVHeader = []string{
+	"penalty_ref", "type", "reason", "made_up_date", "transaction_date", "due_date", "original_amount",
+	"outstanding", "is_paid", "is_dca", "payable_status", "paid_date", "payment_reference",
+}
+
+// PenaltyExportItem is a transaction in the penalty ledger export with the date it was paid through this service
+type PenaltyExportItem struct {
+	PenaltyRef       string  `json:"penalty_ref"`
+	Type             string  `json:"type"`
+	Reason           string  `json:"reason"`
+	MadeUpDate       string  `json:"made_up_date"`
+	TransactionDate  string  `json:"transaction_date"`
+	DueDate          string  `json:"due_date"`
+	OriginalAmount   float64 `json:"original_amount"`
+	Outstanding      float64 `json:"outstanding"`
+	IsPaid           bool    `json:"is_paid"`
+	IsDCA            bool    `json:"is_dca"`
+	PayableStatus    string  `json:"payable_status"`
+	PaidDate         string  `json:"paid_date,omitempty"`
+	PaymentReference string  `json:"payment_reference,omitempty"`
+}
+
+// PenaltyExportResponse is the penalty ledger export in JSON
+type PenaltyExportResponse struct {
+	CustomerCode         string              `json:"customer_code"`
+	PenaltyReferenceType string              `json:"penalty_reference_type"`
+	Items                []PenaltyExportItem `json:"items"`
+}
+
+type penaltyPayment struct {
+	paidDate  string
+	reference string
+}
+
+// HandleExportPenalties writes the penalty ledger of the supplied customer code as a CSV or JSON download.
+// The format is chosen by the format query parameter, or by the Accept header if it is not supplied.
+func HandleExportPenalties(prDaoSvc dao.PayableResourceDaoService, apDaoSvc dao.AccountPenaltiesDaoService,
+	penaltyDetailsMap *config.PenaltyDetailsMap, allowedTransactionsMap *models.AllowedTransactionMap,
+	payableStatusRules *config.PayableStatusRules, reasonsCatalogue *config.ReasonsCatalogue) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestId := log.Context(req)
+		log.InfoC(requestId, "start GET penalties export request")
+
+		customerCode := req.Context().Value(config.CustomerCode).(string)
+
+		penaltyRefType := GetPenaltyRefType(mux.Vars(req)["penalty_reference_type"])
+		companyCode, err := getCompanyCode(penaltyDetailsMap, penaltyRefType)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReferenceType, "invalid penalty reference type supplied")
+			return
+		}
+
+		format, status, err := getExportFormat(req)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			code := utils.ErrorCodeNotAcceptable
+			if status == http.StatusBadRequest {
+				code = utils.ErrorCodeInvalidQueryParameter
+			}
+			writeErrorProblem(w, req, status, err, code, err.Error())
+			return
+		}
+
+		language := utils.GetLanguage(req)
+		params := types.AccountPenaltiesParams{
+			PenaltyRefType:             penaltyRefType,
+			CustomerCode:               customerCode,
+			CompanyCode:                companyCode,
+			PenaltyDetailsMap:          penaltyDetailsMap,
+			AllowedTransactionsMap:     allowedTransactionsMap,
+			PayableStatusRules:         payableStatusRules,
+			ReasonsCatalogue:           reasonsCatalogue,
+			Language:                   language,
+			AccountPenaltiesDaoService: apDaoSvc,
+			RequestId:                  requestId,
+		}
+		transactionListResponse, responseType, err := accountPenalties(params)
+		if err != nil || responseType != services.Success {
+			log.ErrorC(requestId, fmt.Errorf("error calling e5 to get transactions: %v", err))
+			writeErrorProblem(w, req, http.StatusInternalServerError, err, utils.ErrorCodeFinanceSystemError, "there was a problem communicating with the finance backend")
+			return
+		}
+
+		paidPayableResources, err := prDaoSvc.GetPaidPayableResources(customerCode, requestId)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error getting paid payable resources: %v", err))
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+			return
+		}
+
+		items := buildPenaltyExportItems(transactionListResponse.Items, paidPayableResources)
+		filename := fmt.Sprintf("penalties-%s-%s.%s", customerCode, strings.ToLower(penaltyRefType), format)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		utils.SetContentLanguage(w, language)
+
+		if format == csvExportFormat {
+			err = writePenaltyExportCSV(w, items)
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(PenaltyExportResponse{
+				CustomerCode:         customerCode,
+				PenaltyReferenceType: penaltyRefType,
+				Items:                items,
+			})
+		}
+		if err != nil {
+			// the status has already been written so the export can only be logged as incomplete
+			log.ErrorC(requestId, fmt.Errorf("error writing penalties export: %v", err))
+			return
+		}
+
+		log.InfoC(requestId, "GET penalties export request completed successfully", log.Data{
+			"customer_code": customerCode,
+			"format":        format,
+		})
+	}
+}
+
+// getExportFormat returns the format in the format query parameter or, if it is not supplied, the format
+// the Accept header prefers. The status to respond with is returned with any error.
+func getExportFormat(req *http.Request) (string, int, error) {
+	return negotiateFormat(req, "export", []responseFormat{
+		{name: jsonExportFormat, mediaType: "application/json"},
+		{name: csvExportFormat, mediaType: "text/csv"},
+	})
+}
+
+// buildPenaltyExportItems adds the date and reference of the payment to each transaction that was paid
+// through this service. Transactions paid in other ways do not have a paid date.
+func buildPenaltyExportItems(transactions []models.TransactionListItem, paidPayableResources []models.PayableResourceDao) []PenaltyExportItem {
+	payments := map[string]penaltyPayment{}
+	for _, payableResource := range paidPayableResources {
+		payment := penaltyPayment{reference: payableResource.Data.Payment.Reference}
+		if payableResource.Data.Payment.PaidAt != nil {
+			payment.paidDate = payableResource.Data.Payment.PaidAt.Format(time.DateOnly)
+		}
+		for penaltyRef := range payableResource.Data.Transactions {
+			payments[penaltyRef] = payment
+		}
+	}
+
+	items := make([]PenaltyExportItem, 0, len(transactions))
+	for _, transaction := range transactions {
+		payment := payments[transaction.ID]
+		items = append(items, PenaltyExportItem{
+			PenaltyRef:       transaction.ID,
+			Type:             transaction.Type,
+			Reason:           transaction.Reason,
+			MadeUpDate:       transaction.MadeUpDate,
+			TransactionDate:  transaction.TransactionDate,
+			DueDate:          transaction.DueDate,
+			OriginalAmount:   transaction.OriginalAmount,
+			Outstanding:      transaction.Outstanding,
+			IsPaid:           transaction.IsPaid,
+			IsDCA:            transaction.IsDCA,
+			PayableStatus:    transaction.PayableStatus,
+			PaidDate:         payment.paidDate,
+			PaymentReference: payment.reference,
+		})
+	}
+	return items
+}
+
+func writePenaltyExportCSV(w http.ResponseWriter, items []PenaltyExportItem) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writer := csv.NewWriter(w)
+	if err := writer.Write(penaltyExportCSVHeader); err != nil {
+		return err
+	}
+	for _, item := range items {
+		err := writer.Write([]string{
+			item.PenaltyRef,
+			item.Type,
+			item.Reason,
+			item.MadeUpDate,
+			item.TransactionDate,
+			item.DueDate,
+			strconv.FormatFloat(item.OriginalAmount, 'f', 2, 64),
+			strconv.FormatFloat(item.Outstanding, 'f', 2, 64),
+			strconv.FormatBool(item.IsPaid),
+			strconv.FormatBool(item.IsDCA),
+			item.PayableStatus,
+			item.PaidDate,
+			item.PaymentReference,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"github.com/companieshouse/penalty-payment-api/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serveExportPenalties(target, accept string, prDaoSvc dao.PayableResourceDaoService) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	req = req.WithContext(context.WithValue(req.Context(), config.CustomerCode, "12345678"))
+	req = mux.SetURLVars(req, map[string]string{"penalty_reference_type": utils.LateFilingPenaltyRefType})
+	rr := httptest.NewRecorder()
+
+	HandleExportPenalties(prDaoSvc, nil, &config.PenaltyDetailsMap{}, &models.AllowedTransactionMap{}, nil, nil).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestUnitHandleExportPenalties(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	getCompanyCode = func(_ *config.PenaltyDetailsMap, penaltyRefType string) (string, error) {
+		return utils.LateFilingPenaltyCompanyCode, nil
+	}
+	accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+		return &models.TransactionListResponse{Items: []models.TransactionListItem{
+			{ID: "A1234567", Type: types.Penalty.String(), Reason: "Late filing of accounts", MadeUpDate: "2024-03-31",
+				TransactionDate: "2025-01-07", DueDate: "2025-02-06", OriginalAmount: 150, Outstanding: 0, IsPaid: true,
+				PayableStatus: private.ClosedPayableStatus},
+			{ID: "A7654321", Type: types.Penalty.String(), Reason: "Late filing of accounts", MadeUpDate: "2025-03-31",
+				TransactionDate: "2026-01-07", DueDate: "2026-02-06", OriginalAmount: 250.5, Outstanding: 250.5,
+				PayableStatus: private.OpenPayableStatus},
+		}}, services.Success, nil
+	}
+	defer func() {
+		getCompanyCode = (*config.PenaltyDetailsMap).GetCompanyCode
+		accountPenalties = api.AccountPenalties
+	}()
+
+	paidAt := time.Date(2025, time.January, 20, 10, 0, 0, 0, time.UTC)
+	paidPayableResources := []models.PayableResourceDao{{
+		CustomerCode: "12345678",
+		PayableRef:   "PR1234",
+		Data: models.PayableResourceDataDao{
+			Transactions: map[string]models.TransactionDao{"A1234567": {Amount: 150}},
+			Payment:      models.PaymentDao{Reference: "pay_123", Status: "paid", PaidAt: &paidAt},
+		},
+	}}
+
+	Convey("Given CSV is requested in the Accept header", t, func() {
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		mockPrDaoSvc.EXPECT().GetPaidPayableResources("12345678", gomock.Any()).Return(paidPayableResources, nil)
+
+		rr := serveExportPenalties("/company/12345678/penalties/LATE_FILING/export", "text/csv", mockPrDaoSvc)
+
+		Convey("Then the ledger is written as CSV with the paid date", func() {
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Header().Get("Content-Type"), ShouldEqual, "text/csv; charset=utf-8")
+			So(rr.Header().Get("Content-Disposition"), ShouldEqual, `attachment; filename="penalties-12345678-late_filing.csv"`)
+			So(rr.Body.String(), ShouldEqual,
+				"penalty_ref,type,reason,made_up_date,transaction_date,due_date,original_amount,outstanding,is_paid,is_dca,payable_status,paid_date,payment_reference\n"+
+					"A1234567,penalty,Late filing of accounts,2024-03-31,2025-01-07,2025-02-06,150.00,0.00,true,false,CLOSED,2025-01-20,pay_123\n"+
+					"A7654321,penalty,Late filing of accounts,2025-03-31,2026-01-07,2026-02-06,250.50,250.50,false,false,OPEN,,\n")
+		})
+	})
+
+	Convey("Given JSON is requested in the format parameter", t, func() {
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		mockPrDaoSvc.EXPECT().GetPaidPayableResources("12345678", gomock.Any()).Return(paidPayableResources, nil)
+
+		rr := serveExportPenalties("/company/12345678/penalties/LATE_FILING/export?format=json", "text/csv", mockPrDaoSvc)
+
+		Convey("Then the format parameter takes precedence over the Accept header", func() {
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Header().Get("Content-Type"), ShouldEqual, "application/json")
+			So(rr.Body.String(), ShouldContainSubstring, `"customer_code":"12345678","penalty_reference_type":"LATE_FILING"`)
+			So(rr.Body.String(), ShouldContainSubstring, `"payable_status":"CLOSED","paid_date":"2025-01-20","payment_reference":"pay_123"}`)
+			So(rr.Body.String(), ShouldContainSubstring, `"payable_status":"OPEN"}`)
+		})
+	})
+
+	Convey("Given CSV is preferred by quality in the Accept header", t, func() {
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		mockPrDaoSvc.EXPECT().GetPaidPayableResources("12345678", gomock.Any()).Return(paidPayableResources, nil)
+
+		rr := serveExportPenalties("/company/12345678/penalties/LATE_FILING/export", "application/json;q=0.5, text/csv", mockPrDaoSvc)
+
+		Convey("Then the ledger is written as CSV", func() {
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Header().Get("Content-Type"), ShouldEqual, "text/csv; charset=utf-8")
+		})
+	})
+
+	Convey("Given JSON is excluded with q=0 in the Accept header", t, func() {
+		So(serveExportPenalties("/company/12345678/penalties/LATE_FILING/export", "application/json;q=0, text/csv;q=0, */*", nil).Code,
+			ShouldEqual, http.StatusNotAcceptable)
+	})
+
+	Convey("Given an unsupported format", t, func() {
+		So(serveExportPenalties("/company/12345678/penalties/LATE_FILING/export?format=xml", "", nil).Code, ShouldEqual, http.StatusBadRequest)
+		So(serveExportPenalties("/company/12345678/penalties/LATE_FILING/export", "application/xml", nil).Code, ShouldEqual, http.StatusNotAcceptable)
+
+		rr := serveExportPenalties("/company/12345678/penalties/LATE_FILING/export?format=xml", utils.ProblemContentType, nil)
+		So(rr.Body.String(), ShouldContainSubstring, utils.ErrorCodeInvalidQueryParameter)
+		rr = serveExportPenalties("/company/12345678/penalties/LATE_FILING/export", "application/xml, "+utils.ProblemContentType, nil)
+		So(rr.Body.String(), ShouldContainSubstring, utils.ErrorCodeNotAcceptable)
+	})
+
+	Convey("Given the paid payable resources cannot be retrieved", t, func() {
+		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
+		mockPrDaoSvc.EXPECT().GetPaidPayableResources("12345678", gomock.Any()).Return(nil, errors.New("mongo unavailable"))
+
+		So(serveExportPenalties("/company/12345678/penalties/LATE_FILING/export", "", mockPrDaoSvc).Code, ShouldEqual, http.StatusInternalServerError)
+	})
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+)
+
+const madeUpDateLayout = "2006-01-02"
+
+// penaltiesQuery is the filtering, sorting and pagination requested in the query parameters of GET penalties
+type penaltiesQuery struct {
+	Type            string
+	PayableStatuses []string
+	IsPaid          *bool
+	MadeUpDateFrom  string
+	MadeUpDateTo    string
+	Sort            string
+	Descending      bool
+	StartIndex      int
+	ItemsPerPage    int
+}
+
+// parsePenaltiesQuery reads the query parameters of a GET penalties request, returning an error naming the
+// parameter if one is invalid
+func parsePenaltiesQuery(req *http.Request) (penaltiesQuery, error) {
+	values := req.URL.Query()
+	var query penaltiesQuery
+
+	if transactionType := values.Get("type"); transactionType != "" {
+		if transactionType != types.Penalty.String() && transactionType != types.Other.String() {
+			return query, newFieldError("type", "type must be %s or %s", types.Penalty, types.Other)
+		}
+		query.Type = transactionType
+	}
+
+	if payableStatuses := values.Get("payable_status"); payableStatuses != "" {
+		for _, payableStatus := range strings.Split(payableStatuses, ",") {
+			query.PayableStatuses = append(query.PayableStatuses, strings.ToUpper(strings.TrimSpace(payableStatus)))
+		}
+	}
+
+	if isPaid := values.Get("is_paid"); isPaid != "" {
+		parsedIsPaid, err := strconv.ParseBool(isPaid)
+		if err != nil {
+			return query, newFieldError("is_paid", "is_paid must be true or false")
+		}
+		query.IsPaid = &parsedIsPaid
+	}
+
+	var err error
+	if query.MadeUpDateFrom, err = parseMadeUpDate(values.Get("made_up_date_from")); err != nil {
+		return query, newFieldError("made_up_date_from", "made_up_date_from %v", err)
+	}
+	if query.MadeUpDateTo, err = parseMadeUpDate(values.Get("made_up_date_to")); err != nil {
+		return query, newFieldError("made_up_date_to", "made_up_date_to %v", err)
+	}
+
+	if sort := values.Get("sort"); sort != "" {
+		query.Descending = strings.HasPrefix(sort, "-")
+		query.Sort = strings.TrimPrefix(sort, "-")
+		if query.Sort != "due_date" && query.Sort != "amount" {
+			return query, newFieldError("sort", "sort must be due_date or amount, prefixed with - for descending order")
+		}
+	}
+
+	if query.StartIndex, err = parseNonNegativeInt(values.Get("start_index")); err != nil {
+		return query, newFieldError("start_index", "start_index %v", err)
+	}
+	if query.ItemsPerPage, err = parseNonNegativeInt(values.Get("items_per_page")); err != nil {
+		return query, newFieldError("items_per_page", "items_per_page %v", err)
+	}
+
+	return query, nil
+}
+
+func parseMadeUpDate(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if _, err := time.Parse(madeUpDateLayout, value); err != nil {
+		return "", fmt.Errorf("must be a date in the format YYYY-MM-DD")
+	}
+	return value, nil
+}
+
+func parseNonNegativeInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("must be a whole number that is not negative")
+	}
+	return parsed, nil
+}
+
+// isEmpty is true if no filtering, sorting or pagination was requested
+func (query penaltiesQuery) isEmpty() bool {
+	return query.Type == "" && len(query.PayableStatuses) == 0 && query.IsPaid == nil &&
+		query.MadeUpDateFrom == "" && query.MadeUpDateTo == "" && query.Sort == "" &&
+		query.StartIndex == 0 && query.ItemsPerPage == 0
+}
+
+// apply returns the page of the filtered and sorted penalties, with the total results set to the number of
+// penalties that match the filters. If items per page is not requested the rest of the penalties after
+// the start index are returned. The etag is generated from the page that is returned.
+func (query penaltiesQuery) apply(transactionList *models.TransactionListResponse) (*models.TransactionListResponse, error) {
+	if query.isEmpty() {
+		return transactionList, nil
+	}
+
+	items := make([]models.TransactionListItem, 0, len(transactionList.Items))
+	for _, item := range transactionList.Items {
+		if query.matches(item) {
+			items = append(items, item)
+		}
+	}
+
+	if query.Sort != "" {
+		slices.SortStableFunc(items, func(a, b models.TransactionListItem) int {
+			var order int
+			if query.Sort == "due_date" {
+				order = cmp.Compare(a.DueDate, b.DueDate)
+			} else {
+				// the amount is the outstanding amount, which is what is left to pay
+				order = cmp.Compare(a.Outstanding, b.Outstanding)
+			}
+			if query.Descending {
+				return -order
+			}
+			return order
+		})
+	}
+
+	page := models.TransactionListResponse{
+		StartIndex:   query.StartIndex,
+		TotalResults: len(items),
+	}
+	start := min(query.StartIndex, len(items))
+	end := len(items)
+	if query.ItemsPerPage > 0 {
+		end = min(start+query.ItemsPerPage, len(items))
+	}
+	page.Items = items[start:end]
+	page.ItemsPerPage = query.ItemsPerPage
+	if page.ItemsPerPage == 0 {
+		page.ItemsPerPage = len(page.Items)
+	}
+
+	etag, err := utils.GenerateContentEtag(page)
+	if err != nil {
+		return nil, err
+	}
+	page.Etag = etag
+
+	return &page, nil
+}
+
+func (query penaltiesQuery) matches(item models.TransactionListItem) bool {
+	if query.Type != "" && item.Type != query.Type {
+		return false
+	}
+	if len(query.PayableStatuses) > 0 && !slices.Contains(query.PayableStatuses, item.PayableStatus) {
+		return false
+	}
+	if query.IsPaid != nil && item.IsPaid != *query.IsPaid {
+		return false
+	}
+	if query.MadeUpDateFrom != "" && item.MadeUpDate < query.MadeUpDateFrom {
+		return false
+	}
+	if query.MadeUpDateTo != "" && item.MadeUpDate > query.MadeUpDateTo {
+		return false
+	}
+	return true
+}
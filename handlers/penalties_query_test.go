@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func penaltiesQueryTestList() *models.TransactionListResponse {
+	return &models.TransactionListResponse{
+		Etag:         "unfiltered",
+		TotalResults: 4,
+		Items: []models.TransactionListItem{
+			{ID: "A0000001", Type: "penalty", PayableStatus: "CLOSED", IsPaid: true, MadeUpDate: "2019-06-30", DueDate: "2020-07-21", Outstanding: 0},
+			{ID: "CF1", Type: "other", PayableStatus: "CLOSED", MadeUpDate: "2019-06-30", DueDate: "2021-04-09", Outstanding: 105},
+			{ID: "A0000003", Type: "penalty", PayableStatus: "OPEN", MadeUpDate: "2020-06-26", DueDate: "2021-12-15", Outstanding: 1500},
+			{ID: "A0000004", Type: "penalty", PayableStatus: "OPEN", MadeUpDate: "2021-06-26", DueDate: "2022-06-06", Outstanding: 750},
+		},
+	}
+}
+
+func penaltiesQueryTestIDs(transactionList *models.TransactionListResponse) []string {
+	ids := make([]string, 0, len(transactionList.Items))
+	for _, item := range transactionList.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestUnitParsePenaltiesQuery(t *testing.T) {
+	Convey("Parse penalties query", t, func() {
+		Convey("Valid query parameters are parsed", func() {
+			req := httptest.NewRequest("GET", "/penalties?type=penalty&payable_status=open,closed&is_paid=false"+
+				"&made_up_date_from=2020-01-01&made_up_date_to=2021-12-31&sort=-amount&start_index=10&items_per_page=5", nil)
+
+			query, err := parsePenaltiesQuery(req)
+
+			So(err, ShouldBeNil)
+			So(query.Type, ShouldEqual, "penalty")
+			So(query.PayableStatuses, ShouldResemble, []string{"OPEN", "CLOSED"})
+			So(*query.IsPaid, ShouldBeFalse)
+			So(query.MadeUpDateFrom, ShouldEqual, "2020-01-01")
+			So(query.MadeUpDateTo, ShouldEqual, "2021-12-31")
+			So(query.Sort, ShouldEqual, "amount")
+			So(query.Descending, ShouldBeTrue)
+			So(query.StartIndex, ShouldEqual, 10)
+			So(query.ItemsPerPage, ShouldEqual, 5)
+		})
+
+		Convey("No query parameters is an empty query", func() {
+			query, err := parsePenaltiesQuery(httptest.NewRequest("GET", "/penalties", nil))
+
+			So(err, ShouldBeNil)
+			So(query.isEmpty(), ShouldBeTrue)
+		})
+
+		invalidQueries := map[string]string{
+			"type=fee":                     "type must be penalty or other",
+			"is_paid=maybe":                "is_paid must be true or false",
+			"made_up_date_from=30/06/2020": "made_up_date_from must be a date in the format YYYY-MM-DD",
+			"made_up_date_to=2020-13-01":   "made_up_date_to must be a date in the format YYYY-MM-DD",
+			"sort=reason":                  "sort must be due_date or amount, prefixed with - for descending order",
+			"start_index=-1":               "start_index must be a whole number that is not negative",
+			"items_per_page=ten":           "items_per_page must be a whole number that is not negative",
+		}
+		for rawQuery, wantErr := range invalidQueries {
+			Convey("Invalid query "+rawQuery, func() {
+				_, err := parsePenaltiesQuery(httptest.NewRequest("GET", "/penalties?"+rawQuery, nil))
+
+				So(err.Error(), ShouldEqual, wantErr)
+			})
+		}
+	})
+}
+
+func TestUnitApplyPenaltiesQuery(t *testing.T) {
+	Convey("Apply penalties query", t, func() {
+		Convey("The penalties are unchanged by an empty query", func() {
+			transactionList := penaltiesQueryTestList()
+
+			got, err := penaltiesQuery{}.apply(transactionList)
+
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, transactionList)
+		})
+
+		Convey("The penalties are filtered and the total results is the filtered count", func() {
+			got, err := penaltiesQuery{Type: "penalty", PayableStatuses: []string{"OPEN"}}.apply(penaltiesQueryTestList())
+
+			So(err, ShouldBeNil)
+			So(penaltiesQueryTestIDs(got), ShouldResemble, []string{"A0000003", "A0000004"})
+			So(got.TotalResults, ShouldEqual, 2)
+			So(got.Etag, ShouldNotEqual, "unfiltered")
+		})
+
+		Convey("The penalties are filtered by paid and made up date", func() {
+			isPaid := false
+			got, err := penaltiesQuery{IsPaid: &isPaid, MadeUpDateFrom: "2019-06-30", MadeUpDateTo: "2020-06-26"}.apply(penaltiesQueryTestList())
+
+			So(err, ShouldBeNil)
+			So(penaltiesQueryTestIDs(got), ShouldResemble, []string{"CF1", "A0000003"})
+		})
+
+		Convey("The penalties are sorted", func() {
+			byAmount, _ := penaltiesQuery{Sort: "amount", Descending: true}.apply(penaltiesQueryTestList())
+			byDueDate, _ := penaltiesQuery{Sort: "due_date"}.apply(penaltiesQueryTestList())
+
+			So(penaltiesQueryTestIDs(byAmount), ShouldResemble, []string{"A0000003", "A0000004", "CF1", "A0000001"})
+			So(penaltiesQueryTestIDs(byDueDate), ShouldResemble, []string{"A0000001", "CF1", "A0000003", "A0000004"})
+		})
+
+		Convey("The penalties are sorted by the outstanding amount rather than the original amount", func() {
+			transactionList := penaltiesQueryTestList()
+			transactionList.Items[0].OriginalAmount = 3000
+			transactionList.Items[2].OriginalAmount = 1500
+			transactionList.Items[3].OriginalAmount = 1000
+
+			byAmount, _ := penaltiesQuery{Sort: "amount"}.apply(transactionList)
+
+			So(penaltiesQueryTestIDs(byAmount), ShouldResemble, []string{"A0000001", "CF1", "A0000004", "A0000003"})
+		})
+
+		Convey("The penalties are paginated", func() {
+			got, err := penaltiesQuery{StartIndex: 1, ItemsPerPage: 2}.apply(penaltiesQueryTestList())
+
+			So(err, ShouldBeNil)
+			So(penaltiesQueryTestIDs(got), ShouldResemble, []string{"CF1", "A0000003"})
+			So(got.StartIndex, ShouldEqual, 1)
+			So(got.ItemsPerPage, ShouldEqual, 2)
+			So(got.TotalResults, ShouldEqual, 4)
+		})
+
+		Convey("A start index after the last penalty is an empty page", func() {
+			got, err := penaltiesQuery{StartIndex: 10}.apply(penaltiesQueryTestList())
+
+			So(err, ShouldBeNil)
+			So(got.Items, ShouldBeEmpty)
+			So(got.TotalResults, ShouldEqual, 4)
+		})
+	})
+}
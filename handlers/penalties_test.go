@@ -39,10 +39,10 @@ func TestUnitHandleGetPenalties(t *testing.T) {
 			if customerCode == "INTERNAL_SERVER_ERROR" {
 				return nil, services.NotFound, errors.New("error getting penalties")
 			}
-			return nil, services.Success, nil
+			return &models.TransactionListResponse{}, services.Success, nil
 		}
 
-		mockedGetCompanyCode := func(penaltyRefType string) (string, error) {
+		mockedGetCompanyCode := func(_ *config.PenaltyDetailsMap, penaltyRefType string) (string, error) {
 			return utils.LateFilingPenaltyCompanyCode, nil
 		}
 
@@ -63,25 +63,112 @@ func TestUnitHandleGetPenalties(t *testing.T) {
 			req := buildGetPenaltiesRequest(tc.companyCode)
 			rr := httptest.NewRecorder()
 
-			handler := HandleGetPenalties(nil, penaltyDetailsMap, allowedTransactionsMap)
+			handler := HandleGetPenalties(nil, penaltyDetailsMap, allowedTransactionsMap, nil, nil)
 			handler.ServeHTTP(rr, req)
 
 			So(rr.Code, ShouldEqual, tc.response)
 		}
 	})
 	Convey("Given a request to get penalties when company code cannot be determined", t, func() {
-		getCompanyCode = func(penaltyRefType string) (string, error) {
+		getCompanyCode = func(_ *config.PenaltyDetailsMap, penaltyRefType string) (string, error) {
 			return "", errors.New("cannot determine company code")
 		}
 
 		rr := httptest.NewRecorder()
 		req := buildGetPenaltiesRequest("NI123546")
 
-		handler := HandleGetPenalties(nil, penaltyDetailsMap, allowedTransactionsMap)
+		handler := HandleGetPenalties(nil, penaltyDetailsMap, allowedTransactionsMap, nil, nil)
 		handler.ServeHTTP(rr, req)
 
 		So(rr.Code, ShouldEqual, http.StatusBadRequest)
 	})
+	Convey("Given a request to get penalties in Welsh", t, func() {
+		getCompanyCode = func(_ *config.PenaltyDetailsMap, penaltyRefType string) (string, error) {
+			return utils.LateFilingPenaltyCompanyCode, nil
+		}
+		reasonsCatalogue := &config.ReasonsCatalogue{}
+		var gotParams types.AccountPenaltiesParams
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			gotParams = params
+			return &models.TransactionListResponse{}, services.Success, nil
+		}
+
+		rr := httptest.NewRecorder()
+		req := buildGetPenaltiesRequest("NI123546")
+		req.Header.Set("Accept-Language", "cy-GB,en;q=0.8")
+
+		handler := HandleGetPenalties(nil, penaltyDetailsMap, allowedTransactionsMap, nil, reasonsCatalogue)
+		handler.ServeHTTP(rr, req)
+
+		So(rr.Code, ShouldEqual, http.StatusOK)
+		So(rr.Header().Get("Content-Language"), ShouldEqual, utils.WelshLanguage)
+		So(rr.Header().Get("Vary"), ShouldEqual, "Accept-Language")
+		So(gotParams.Language, ShouldEqual, utils.WelshLanguage)
+		So(gotParams.ReasonsCatalogue, ShouldEqual, reasonsCatalogue)
+	})
+}
+
+func TestUnitHandleGetPenaltiesConditionalRequest(t *testing.T) {
+	Convey("Given a request to get penalties that the client has the current version of", t, func() {
+		getCompanyCode = func(_ *config.PenaltyDetailsMap, penaltyRefType string) (string, error) {
+			return utils.LateFilingPenaltyCompanyCode, nil
+		}
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			return &models.TransactionListResponse{Etag: "abc123"}, services.Success, nil
+		}
+		handler := HandleGetPenalties(nil, &config.PenaltyDetailsMap{}, &models.AllowedTransactionMap{}, nil, nil)
+
+		Convey("Then the penalties are returned with their etag", func() {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, buildGetPenaltiesRequest("NI123546"))
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Header().Get("ETag"), ShouldEqual, `"abc123"`)
+		})
+
+		Convey("Then not modified is returned when the etag matches", func() {
+			rr := httptest.NewRecorder()
+			req := buildGetPenaltiesRequest("NI123546")
+			req.Header.Set("If-None-Match", `"abc123"`)
+			handler.ServeHTTP(rr, req)
+
+			So(rr.Code, ShouldEqual, http.StatusNotModified)
+			So(rr.Body.String(), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestUnitHandleGetPenaltiesQuery(t *testing.T) {
+	Convey("Given a request to get penalties with query parameters", t, func() {
+		getCompanyCode = func(_ *config.PenaltyDetailsMap, penaltyRefType string) (string, error) {
+			return utils.LateFilingPenaltyCompanyCode, nil
+		}
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			return penaltiesQueryTestList(), services.Success, nil
+		}
+		handler := HandleGetPenalties(nil, &config.PenaltyDetailsMap{}, &models.AllowedTransactionMap{}, nil, nil)
+
+		Convey("Then the filtered penalties are returned", func() {
+			req := buildGetPenaltiesRequest("NI123546")
+			req.URL.RawQuery = "type=penalty&is_paid=false&sort=due_date&items_per_page=1"
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Body.String(), ShouldContainSubstring, `"items_per_page":1,"start_index":0,"total_results":2`)
+			So(rr.Body.String(), ShouldContainSubstring, `"id":"A0000003"`)
+			So(rr.Body.String(), ShouldNotContainSubstring, `"id":"A0000004"`)
+		})
+
+		Convey("Then an invalid query parameter is a bad request", func() {
+			req := buildGetPenaltiesRequest("NI123546")
+			req.URL.RawQuery = "sort=reason"
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
 }
 
 func TestUnitHandleGetPenaltyRefType(t *testing.T) {
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// DefaultPenaltyLookupRateLimit is how many penalty lookups each client can make a minute if it is not configured
+	DefaultPenaltyLookupRateLimit = 10
+	penaltyLookupNotFoundMessage  = "no penalty found matching the details supplied"
+)
+
+// PenaltyLookupRequest is the body of a request to find a penalty from its reference. The company number or
+// the postcode of the registered office must be supplied to show that the penalty belongs to the customer.
+type PenaltyLookupRequest struct {
+	PenaltyRef    string `json:"penalty_ref"`
+	CompanyNumber string `json:"company_number"`
+	Postcode      string `json:"postcode"`
+}
+
+// PenaltyLookupResponse is the customer that a penalty belongs to with the details of the penalty
+type PenaltyLookupResponse struct {
+	CustomerCode         string                     `json:"customer_code"`
+	PenaltyReferenceType string                     `json:"penalty_reference_type"`
+	Penalty              models.TransactionListItem `json:"penalty"`
+}
+
+// HandleLookupPenalty finds the customer that a penalty belongs to from the penalty reference alone. The
+// prefix of the reference gives the company code and the account penalties cache is used as a reverse index
+// from penalty to customer, the penalty details are then refreshed from E5. The same response is given
+// whether the penalty does not exist or the company number and postcode do not match, so that the lookup
+// cannot be used to find out which penalties exist.
+func HandleLookupPenalty(apDaoSvc dao.AccountPenaltiesDaoService, penaltyDetailsMap *config.PenaltyDetailsMap,
+	allowedTransactionsMap *models.AllowedTransactionMap, payableStatusRules *config.PayableStatusRules,
+	reasonsCatalogue *config.ReasonsCatalogue) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestId := log.Context(req)
+		log.InfoC(requestId, "start POST penalty lookup request")
+
+		var request PenaltyLookupRequest
+		err := json.NewDecoder(req.Body).Decode(&request)
+		if err != nil {
+			message := "failed to read request body"
+			log.ErrorC(requestId, fmt.Errorf(message+": %v", err))
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidRequestBody, message)
+			return
+		}
+
+		penaltyRef := strings.ToUpper(strings.TrimSpace(request.PenaltyRef))
+		if penaltyRef == "" || (strings.TrimSpace(request.CompanyNumber) == "" && strings.TrimSpace(request.Postcode) == "") {
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodeValidationFailed, "penalty_ref and either company_number or postcode must be supplied")
+			return
+		}
+
+		penaltyRefType, err := getPenaltyRefTypeFromTransaction(penaltyDetailsMap, []models.TransactionItem{{PenaltyRef: penaltyRef}})
+		if err != nil {
+			log.ErrorC(requestId, err)
+			writeFieldProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReference, "invalid penalty reference supplied", "penalty_ref")
+			return
+		}
+		companyCode, err := getCompanyCode(penaltyDetailsMap, penaltyRefType)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			writeFieldProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReference, "invalid penalty reference supplied", "penalty_ref")
+			return
+		}
+
+		accountPenaltiesDao, err := apDaoSvc.GetAccountPenaltiesByPenaltyRef(companyCode, penaltyRef, requestId)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			writeProblem(w, req, http.StatusNotFound, utils.ErrorCodePenaltyNotFound, penaltyLookupNotFoundMessage)
+			return
+		}
+		if err != nil {
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+			return
+		}
+
+		customerCode := accountPenaltiesDao.CustomerCode
+		if !penaltyLookupVerified(request, customerCode, req) {
+			log.InfoC(requestId, "penalty lookup details do not match the customer", log.Data{"penalty_ref": penaltyRef})
+			writeProblem(w, req, http.StatusNotFound, utils.ErrorCodePenaltyNotFound, penaltyLookupNotFoundMessage)
+			return
+		}
+
+		params := types.AccountPenaltiesParams{
+			PenaltyRefType:             penaltyRefType,
+			CustomerCode:               customerCode,
+			CompanyCode:                companyCode,
+			PenaltyDetailsMap:          penaltyDetailsMap,
+			AllowedTransactionsMap:     allowedTransactionsMap,
+			PayableStatusRules:         payableStatusRules,
+			ReasonsCatalogue:           reasonsCatalogue,
+			Language:                   utils.GetLanguage(req),
+			AccountPenaltiesDaoService: apDaoSvc,
+			RequestId:                  requestId,
+		}
+		transactionListResponse, responseType, err := accountPenalties(params)
+		if err != nil || responseType != services.Success {
+			log.ErrorC(requestId, fmt.Errorf("error calling e5 to get transactions: %v", err))
+			writeErrorProblem(w, req, http.StatusInternalServerError, err, utils.ErrorCodeFinanceSystemError, "there was a problem communicating with the finance backend")
+			return
+		}
+
+		for _, transaction := range transactionListResponse.Items {
+			if transaction.ID == penaltyRef {
+				utils.WriteJSON(w, req, PenaltyLookupResponse{
+					CustomerCode:         customerCode,
+					PenaltyReferenceType: penaltyRefType,
+					Penalty:              transaction,
+				})
+				log.InfoC(requestId, "POST penalty lookup request completed successfully", log.Data{
+					"customer_code": customerCode,
+					"penalty_ref":   penaltyRef,
+				})
+				return
+			}
+		}
+
+		// the penalty was in the cache but is no longer in E5
+		log.InfoC(requestId, "penalty not found in E5 transactions", log.Data{"customer_code": customerCode, "penalty_ref": penaltyRef})
+		writeProblem(w, req, http.StatusNotFound, utils.ErrorCodePenaltyNotFound, penaltyLookupNotFoundMessage)
+	}
+}
+
+// penaltyLookupVerified checks that the company number matches the customer code, or that the postcode
+// matches the registered office address of the customer
+func penaltyLookupVerified(request PenaltyLookupRequest, customerCode string, req *http.Request) bool {
+	if companyNumber := strings.ToUpper(strings.TrimSpace(request.CompanyNumber)); companyNumber != "" && companyNumber == customerCode {
+		return true
+	}
+
+	postcode := normalisePostcode(request.Postcode)
+	if postcode == "" {
+		return false
+	}
+	registeredPostcode, err := getCompanyPostcode(customerCode, req)
+	if err != nil {
+		return false
+	}
+	return postcode == normalisePostcode(registeredPostcode)
+}
+
+func normalisePostcode(postcode string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(postcode), ""))
+}
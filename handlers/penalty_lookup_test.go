@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"github.com/companieshouse/penalty-payment-api/mocks"
+	"github.com/companieshouse/penalty-payment-api/penalty_payments/service"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func serveLookupPenalty(body string, apDaoSvc dao.AccountPenaltiesDaoService) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/penalty-payment-api/penalty-lookup", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	HandleLookupPenalty(apDaoSvc, statementPenaltyDetailsMap, &models.AllowedTransactionMap{}, nil, nil).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestUnitHandleLookupPenalty(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	getPenaltyRefTypeFromTransaction = (*config.PenaltyDetailsMap).GetPenaltyRefTypeFromTransaction
+	getCompanyCode = (*config.PenaltyDetailsMap).GetCompanyCode
+	var requestedPostcodes int
+	getCompanyPostcode = func(companyNumber string, req *http.Request) (string, error) {
+		requestedPostcodes++
+		if companyNumber != "12345678" {
+			return "", errors.New("company not found")
+		}
+		return "SW1P 1JP", nil
+	}
+	accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+		return &models.TransactionListResponse{Items: []models.TransactionListItem{
+			{ID: "A1234567", Type: types.Penalty.String(), Outstanding: 150},
+		}}, services.Success, nil
+	}
+	defer func() {
+		getCompanyPostcode = service.GetCompanyPostcode
+		accountPenalties = api.AccountPenalties
+	}()
+
+	Convey("Given the penalty reference is in the reverse index", t, func() {
+		requestedPostcodes = 0
+		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(mockCtrl)
+		mockApDaoSvc.EXPECT().GetAccountPenaltiesByPenaltyRef(utils.LateFilingPenaltyCompanyCode, "A1234567", gomock.Any()).
+			Return(&models.AccountPenaltiesDao{CustomerCode: "12345678", CompanyCode: utils.LateFilingPenaltyCompanyCode}, nil)
+
+		Convey("Then the penalty is returned if the company number matches", func() {
+			rr := serveLookupPenalty(`{"penalty_ref":"a1234567","company_number":"12345678"}`, mockApDaoSvc)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Body.String(), ShouldContainSubstring, `"customer_code":"12345678","penalty_reference_type":"LATE_FILING"`)
+			So(rr.Body.String(), ShouldContainSubstring, `"id":"A1234567"`)
+			So(requestedPostcodes, ShouldEqual, 0)
+		})
+
+		Convey("Then the penalty is returned if the postcode matches", func() {
+			rr := serveLookupPenalty(`{"penalty_ref":"A1234567","postcode":"sw1p1jp"}`, mockApDaoSvc)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(requestedPostcodes, ShouldEqual, 1)
+		})
+
+		Convey("Then the penalty is not found if neither matches", func() {
+			rr := serveLookupPenalty(`{"penalty_ref":"A1234567","company_number":"87654321","postcode":"EC1A 1BB"}`, mockApDaoSvc)
+
+			So(rr.Code, ShouldEqual, http.StatusNotFound)
+			So(rr.Body.String(), ShouldContainSubstring, penaltyLookupNotFoundMessage)
+		})
+	})
+
+	Convey("Given the penalty reference is not in the reverse index", t, func() {
+		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(mockCtrl)
+		mockApDaoSvc.EXPECT().GetAccountPenaltiesByPenaltyRef(utils.LateFilingPenaltyCompanyCode, "A7654321", gomock.Any()).
+			Return(nil, mongo.ErrNoDocuments)
+
+		rr := serveLookupPenalty(`{"penalty_ref":"A7654321","company_number":"12345678"}`, mockApDaoSvc)
+
+		So(rr.Code, ShouldEqual, http.StatusNotFound)
+		So(rr.Body.String(), ShouldContainSubstring, penaltyLookupNotFoundMessage)
+	})
+
+	Convey("Given an invalid request", t, func() {
+		So(serveLookupPenalty(`not json`, nil).Code, ShouldEqual, http.StatusBadRequest)
+		So(serveLookupPenalty(`{"penalty_ref":"A1234567"}`, nil).Code, ShouldEqual, http.StatusBadRequest)
+		So(serveLookupPenalty(`{"penalty_ref":"Z1234567","company_number":"12345678"}`, nil).Code, ShouldEqual, http.StatusBadRequest)
+	})
+}
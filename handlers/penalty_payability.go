@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"github.com/gorilla/mux"
+)
+
+var penaltyPayability = api.PenaltyPayability
+
+// paymentsNotEnabledRule is the rule broken by a penalty of a type whose payments are not enabled
+const paymentsNotEnabledRule = "PAYMENTS_NOT_ENABLED"
+
+// PenaltyPayabilityResponse explains why a penalty can or cannot be paid
+type PenaltyPayabilityResponse struct {
+	PenaltyRef    string               `json:"penalty_ref"`
+	Payable       bool                 `json:"payable"`
+	PayableStatus string               `json:"payable_status"`
+	FailedRules   []FailedRule         `json:"failed_rules"`
+	E5Transaction E5TransactionDetails `json:"e5_transaction"`
+}
+
+// FailedRule is a rule that stops a penalty being paid. An advisory rule is reported but does not stop a
+// payable resource being created for the penalty.
+type FailedRule struct {
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	Advisory bool   `json:"advisory,omitempty"`
+}
+
+// E5TransactionDetails are the fields of the E5 transaction that the payable rules are checked against
+type E5TransactionDetails struct {
+	TransactionType    string  `json:"transaction_type"`
+	TransactionSubType string  `json:"transaction_sub_type"`
+	DunningStatus      string  `json:"dunning_status"`
+	AccountStatus      string  `json:"account_status"`
+	OriginalAmount     float64 `json:"original_amount"`
+	OutstandingAmount  float64 `json:"outstanding_amount"`
+	IsPaid             bool    `json:"is_paid"`
+}
+
+// HandleGetPenaltyPayability reports whether the penalty can be paid and every rule that stops it being paid,
+// so that the reason can be shown to the customer rather than a generic error
+func HandleGetPenaltyPayability(apDaoSvc dao.AccountPenaltiesDaoService, penaltyDetailsMap *config.PenaltyDetailsMap,
+	allowedTransactionsMap *models.AllowedTransactionMap, payableStatusRules *config.PayableStatusRules,
+	reasonsCatalogue *config.ReasonsCatalogue) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestId := log.Context(req)
+		log.InfoC(requestId, "start GET penalty payability request")
+
+		customerCode := req.Context().Value(config.CustomerCode).(string)
+
+		vars := mux.Vars(req)
+		penaltyRef := vars["penalty_ref"]
+		penaltyRefType := GetPenaltyRefType(vars["penalty_reference_type"])
+		companyCode, err := getCompanyCode(penaltyDetailsMap, penaltyRefType)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			writeProblem(w, req, http.StatusBadRequest, utils.ErrorCodeInvalidPenaltyReferenceType, "invalid penalty reference type supplied")
+			return
+		}
+
+		var amount *float64
+		if amountParam := req.URL.Query().Get("amount"); amountParam != "" {
+			parsedAmount, err := strconv.ParseFloat(amountParam, 64)
+			if err != nil {
+				log.ErrorC(requestId, fmt.Errorf("invalid amount: %v", err))
+				writeFieldProblem(w, req, http.StatusBadRequest, utils.ErrorCodeValidationFailed, "invalid amount supplied", "amount")
+				return
+			}
+			amount = &parsedAmount
+		}
+
+		params := types.AccountPenaltiesParams{
+			PenaltyRefType:             penaltyRefType,
+			CustomerCode:               customerCode,
+			CompanyCode:                companyCode,
+			PenaltyDetailsMap:          penaltyDetailsMap,
+			AllowedTransactionsMap:     allowedTransactionsMap,
+			PayableStatusRules:         payableStatusRules,
+			ReasonsCatalogue:           reasonsCatalogue,
+			Language:                   utils.GetLanguage(req),
+			AccountPenaltiesDaoService: apDaoSvc,
+			RequestId:                  requestId,
+		}
+		report, responseType, err := penaltyPayability(params, penaltyRef, amount)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error checking penalty payability: %v", err))
+			switch responseType {
+			case services.NotFound:
+				writeProblem(w, req, http.StatusNotFound, utils.ErrorCodePenaltyNotFound, "penalty not found")
+			default:
+				writeErrorProblem(w, req, http.StatusInternalServerError, err, utils.ErrorCodeFinanceSystemError, "there was a problem communicating with the finance backend")
+			}
+			return
+		}
+
+		payable := true
+		failedRules := make([]FailedRule, 0, len(report.FailedRules)+1)
+		if reason := penaltyDetailsMap.Details[penaltyRefType].PaymentsDisabledReason(timeNow()); reason != "" {
+			payable = false
+			failedRules = append(failedRules, FailedRule{Rule: paymentsNotEnabledRule, Message: reason})
+		}
+		for _, failedRule := range report.FailedRules {
+			advisory := private.IsAdvisoryRule(failedRule)
+			payable = payable && advisory
+			failedRules = append(failedRules, FailedRule{
+				Rule:     private.PenaltyRule(failedRule),
+				Message:  failedRule.Error(),
+				Advisory: advisory,
+			})
+		}
+
+		utils.WriteJSON(w, req, PenaltyPayabilityResponse{
+			PenaltyRef:    penaltyRef,
+			Payable:       payable,
+			PayableStatus: report.Penalty.PayableStatus,
+			FailedRules:   failedRules,
+			E5Transaction: E5TransactionDetails{
+				TransactionType:    report.E5Transaction.TransactionType,
+				TransactionSubType: report.E5Transaction.TransactionSubType,
+				DunningStatus:      report.E5Transaction.DunningStatus,
+				AccountStatus:      report.E5Transaction.AccountStatus,
+				OriginalAmount:     report.E5Transaction.Amount,
+				OutstandingAmount:  report.E5Transaction.OutstandingAmount,
+				IsPaid:             report.E5Transaction.IsPaid,
+			},
+		})
+
+		log.InfoC(requestId, "GET penalty payability request completed successfully", log.Data{
+			"customer_code": customerCode,
+			"penalty_ref":   penaltyRef,
+		})
+	}
+}
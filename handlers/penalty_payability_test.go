@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func servePenaltyPayability(target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req = req.WithContext(context.WithValue(req.Context(), config.CustomerCode, "12345678"))
+	req = mux.SetURLVars(req, map[string]string{"penalty_reference_type": utils.LateFilingPenaltyRefType, "penalty_ref": "A1234567"})
+	rr := httptest.NewRecorder()
+
+	HandleGetPenaltyPayability(nil, &config.PenaltyDetailsMap{}, &models.AllowedTransactionMap{}, nil, nil).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestUnitHandleGetPenaltyPayability(t *testing.T) {
+	getCompanyCode = func(_ *config.PenaltyDetailsMap, penaltyRefType string) (string, error) {
+		return utils.LateFilingPenaltyCompanyCode, nil
+	}
+	defer func() {
+		getCompanyCode = (*config.PenaltyDetailsMap).GetCompanyCode
+		penaltyPayability = api.PenaltyPayability
+	}()
+
+	Convey("Given a penalty that cannot be paid", t, func() {
+		var gotAmount *float64
+		penaltyPayability = func(params types.AccountPenaltiesParams, penaltyRef string, amount *float64) (*api.PenaltyPayabilityReport, services.ResponseType, error) {
+			gotAmount = amount
+			return &api.PenaltyPayabilityReport{
+				Penalty: models.TransactionListItem{ID: penaltyRef, PayableStatus: private.ClosedPayableStatus},
+				E5Transaction: models.AccountPenaltiesDataDao{
+					DunningStatus:     "DCA",
+					AccountStatus:     "DCA",
+					Amount:            250,
+					OutstandingAmount: 250,
+				},
+				FailedRules: []error{private.ErrPenaltyDCA, private.ErrPenaltyNotOpen},
+			}, services.Success, nil
+		}
+
+		Convey("Then every failed rule is returned with the E5 fields", func() {
+			rr := servePenaltyPayability("/company/12345678/penalties/LATE_FILING/A1234567/payability?amount=250")
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(*gotAmount, ShouldEqual, 250.0)
+			So(rr.Body.String(), ShouldEqual, `{"penalty_ref":"A1234567","payable":false,"payable_status":"CLOSED",`+
+				`"failed_rules":[{"rule":"WITH_DEBT_COLLECTION_AGENCY","message":"the penalty is with a debt collecting agency"},`+
+				`{"rule":"PAYABLE_STATUS_NOT_OPEN","message":"the penalty is not open for payment","advisory":true}],`+
+				`"e5_transaction":{"transaction_type":"","transaction_sub_type":"","dunning_status":"DCA","account_status":"DCA",`+
+				`"original_amount":250,"outstanding_amount":250,"is_paid":false}}`+"\n")
+		})
+
+		Convey("Then a penalty that only breaks advisory rules is payable", func() {
+			penaltyPayability = func(params types.AccountPenaltiesParams, penaltyRef string, amount *float64) (*api.PenaltyPayabilityReport, services.ResponseType, error) {
+				return &api.PenaltyPayabilityReport{
+					Penalty:     models.TransactionListItem{ID: penaltyRef, PayableStatus: private.ClosedPayableStatus},
+					FailedRules: []error{private.ErrPenaltyNotOpen},
+				}, services.Success, nil
+			}
+
+			rr := servePenaltyPayability("/company/12345678/penalties/LATE_FILING/A1234567/payability")
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Body.String(), ShouldContainSubstring, `"payable":true`)
+			So(rr.Body.String(), ShouldContainSubstring, `"advisory":true`)
+		})
+
+		Convey("Then a penalty of a type whose payments are not enabled is not payable", func() {
+			req := httptest.NewRequest(http.MethodGet, "/company/12345678/penalties/LATE_FILING/A1234567/payability", nil)
+			req = req.WithContext(context.WithValue(req.Context(), config.CustomerCode, "12345678"))
+			req = mux.SetURLVars(req, map[string]string{"penalty_reference_type": utils.LateFilingPenaltyRefType, "penalty_ref": "A1234567"})
+			rr := httptest.NewRecorder()
+			disabledPenaltyDetailsMap := &config.PenaltyDetailsMap{Details: map[string]config.PenaltyDetails{
+				utils.LateFilingPenaltyRefType: {EnabledTo: "2000-01-01T00:00:00Z"},
+			}}
+
+			HandleGetPenaltyPayability(nil, disabledPenaltyDetailsMap, &models.AllowedTransactionMap{}, nil, nil).ServeHTTP(rr, req)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(rr.Body.String(), ShouldContainSubstring, `"payable":false`)
+			So(rr.Body.String(), ShouldContainSubstring,
+				`"failed_rules":[{"rule":"PAYMENTS_NOT_ENABLED","message":"payments were disabled at 2000-01-01T00:00:00Z"},`)
+		})
+
+		Convey("Then an invalid amount is a bad request", func() {
+			rr := servePenaltyPayability("/company/12345678/penalties/LATE_FILING/A1234567/payability?amount=abc")
+
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+
+	Convey("Given the penalty does not exist", t, func() {
+		penaltyPayability = func(params types.AccountPenaltiesParams, penaltyRef string, amount *float64) (*api.PenaltyPayabilityReport, services.ResponseType, error) {
+			return nil, services.NotFound, private.ErrPenaltyDoesNotExist
+		}
+
+		So(servePenaltyPayability("/company/12345678/penalties/LATE_FILING/A1234567/payability").Code, ShouldEqual, http.StatusNotFound)
+	})
+
+	Convey("Given the finance backend cannot be reached", t, func() {
+		penaltyPayability = func(params types.AccountPenaltiesParams, penaltyRef string, amount *float64) (*api.PenaltyPayabilityReport, services.ResponseType, error) {
+			return nil, services.Error, errors.New("e5 unavailable")
+		}
+
+		So(servePenaltyPayability("/company/12345678/penalties/LATE_FILING/A1234567/payability").Code, ShouldEqual, http.StatusInternalServerError)
+	})
+}
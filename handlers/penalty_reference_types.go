@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+)
+
+// PenaltyReferenceType describes a type of penalty that can be looked up and paid. The reason explains why
+// penalties of the type cannot currently be paid.
+type PenaltyReferenceType struct {
+	ReferenceType       string `json:"reference_type"`
+	CompanyCode         string `json:"company_code"`
+	Description         string `json:"description"`
+	ResourceKind        string `json:"resource_kind"`
+	ReferenceStartsWith string `json:"reference_starts_with"`
+	ReferenceRegex      string `json:"reference_regex"`
+	EnabledFrom         string `json:"enabled_from,omitempty"`
+	EnabledTo           string `json:"enabled_to,omitempty"`
+	PaymentsEnabled     bool   `json:"payments_enabled"`
+	Reason              string `json:"reason,omitempty"`
+}
+
+// HandleGetPenaltyReferenceTypes lists the penalty reference types configured in the penalty details
+func HandleGetPenaltyReferenceTypes(penaltyDetailsMap *config.PenaltyDetailsMap) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestId := log.Context(req)
+		log.InfoC(requestId, "start GET penalty reference types request")
+
+		now := time.Now()
+		penaltyReferenceTypes := make([]PenaltyReferenceType, 0, len(penaltyDetailsMap.Details))
+		for penaltyRefType, details := range penaltyDetailsMap.Details {
+			penaltyReferenceTypes = append(penaltyReferenceTypes, PenaltyReferenceType{
+				ReferenceType:       penaltyRefType,
+				CompanyCode:         details.CompanyCode,
+				Description:         details.Description,
+				ResourceKind:        details.ResourceKind,
+				ReferenceStartsWith: details.ReferencePrefix,
+				ReferenceRegex:      details.ReferencePattern(),
+				EnabledFrom:         details.EnabledFrom,
+				EnabledTo:           details.EnabledTo,
+				PaymentsEnabled:     details.PaymentsEnabled(now),
+				Reason:              details.PaymentsDisabledReason(now),
+			})
+		}
+
+		sort.Slice(penaltyReferenceTypes, func(i, j int) bool {
+			return penaltyReferenceTypes[i].ReferenceType < penaltyReferenceTypes[j].ReferenceType
+		})
+
+		utils.WriteJSON(w, req, penaltyReferenceTypes)
+
+		log.InfoC(requestId, "GET penalty reference types request completed successfully")
+	}
+}
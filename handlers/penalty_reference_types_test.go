@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serveGetPenaltyReferenceTypesHandler(penaltyDetailsMap *config.PenaltyDetailsMap) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/penalty-payment-api/penalty-reference-types", nil)
+	res := httptest.NewRecorder()
+
+	HandleGetPenaltyReferenceTypes(penaltyDetailsMap).ServeHTTP(res, req)
+
+	return res
+}
+
+func TestUnitHandleGetPenaltyReferenceTypes(t *testing.T) {
+	Convey("Get penalty reference types", t, func() {
+		Convey("Penalty reference types are built from the penalty details", func() {
+			penaltyDetailsMap := &config.PenaltyDetailsMap{
+				Details: map[string]config.PenaltyDetails{
+					utils.SanctionsPenaltyRefType: {
+						CompanyCode:     utils.SanctionsCompanyCode,
+						ReferencePrefix: "P",
+						Description:     "Sanctions Penalty Payment",
+						ResourceKind:    "penalty#sanctions",
+						EnabledFrom:     "2999-01-01T00:00:00Z",
+					},
+					utils.LateFilingPenaltyRefType: {
+						CompanyCode:     utils.LateFilingPenaltyCompanyCode,
+						ReferencePrefix: "A",
+						Description:     "Late Filing Penalty",
+						ResourceKind:    "late-filing-penalty#late-filing-penalty",
+					},
+					utils.SanctionsRoePenaltyRefType: {
+						CompanyCode:     utils.SanctionsCompanyCode,
+						ReferencePrefix: "U",
+						Description:     "Overseas Entity Penalty Payment",
+						ResourceKind:    "penalty#sanctions",
+						EnabledTo:       "2000-01-01T00:00:00Z",
+					},
+				},
+			}
+
+			res := serveGetPenaltyReferenceTypesHandler(penaltyDetailsMap)
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			var penaltyReferenceTypes []PenaltyReferenceType
+			So(json.NewDecoder(res.Body).Decode(&penaltyReferenceTypes), ShouldBeNil)
+			So(penaltyReferenceTypes, ShouldResemble, []PenaltyReferenceType{
+				{
+					ReferenceType:       utils.LateFilingPenaltyRefType,
+					CompanyCode:         utils.LateFilingPenaltyCompanyCode,
+					Description:         "Late Filing Penalty",
+					ResourceKind:        "late-filing-penalty#late-filing-penalty",
+					ReferenceStartsWith: "A",
+					ReferenceRegex:      "^A[0-9]{7}$",
+					PaymentsEnabled:     true,
+				},
+				{
+					ReferenceType:       utils.SanctionsPenaltyRefType,
+					CompanyCode:         utils.SanctionsCompanyCode,
+					Description:         "Sanctions Penalty Payment",
+					ResourceKind:        "penalty#sanctions",
+					ReferenceStartsWith: "P",
+					ReferenceRegex:      "^P[0-9]{7}$",
+					EnabledFrom:         "2999-01-01T00:00:00Z",
+					PaymentsEnabled:     false,
+					Reason:              "payments are enabled from 2999-01-01T00:00:00Z",
+				},
+				{
+					ReferenceType:       utils.SanctionsRoePenaltyRefType,
+					CompanyCode:         utils.SanctionsCompanyCode,
+					Description:         "Overseas Entity Penalty Payment",
+					ResourceKind:        "penalty#sanctions",
+					ReferenceStartsWith: "U",
+					ReferenceRegex:      "^U[0-9]{7}$",
+					EnabledTo:           "2000-01-01T00:00:00Z",
+					PaymentsEnabled:     false,
+					Reason:              "payments were disabled at 2000-01-01T00:00:00Z",
+				},
+			})
+		})
+	})
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+)
+
+// PenaltyStatementResponse is every penalty held for a customer across all of the penalty reference types
+type PenaltyStatementResponse struct {
+	Sections         []PenaltyStatementSection `json:"sections"`
+	OutstandingTotal float64                   `json:"outstanding_total"`
+	PayableTotal     float64                   `json:"payable_total"`
+	Complete         bool                      `json:"complete"`
+}
+
+// PenaltyStatementSection is the penalties of one penalty reference type. If the penalties could not be
+// retrieved the error is set and there are no items.
+type PenaltyStatementSection struct {
+	PenaltyReferenceType string                       `json:"penalty_reference_type"`
+	CompanyCode          string                       `json:"company_code"`
+	Items                []models.TransactionListItem `json:"items"`
+	OutstandingTotal     float64                      `json:"outstanding_total"`
+	PayableTotal         float64                      `json:"payable_total"`
+	Error                string                       `json:"error,omitempty"`
+}
+
+type companyCodePenalties struct {
+	transactionList *models.TransactionListResponse
+	err             error
+}
+
+// HandleGetPenaltyStatement retrieves the penalties for the supplied customer code for every penalty reference
+// type, with the outstanding and payable totals of each type. The transactions of each company code are
+// requested from e5 in parallel, if one company code fails the statement is returned without it.
+func HandleGetPenaltyStatement(apDaoSvc dao.AccountPenaltiesDaoService, penaltyDetailsMap *config.PenaltyDetailsMap,
+	allowedTransactionsMap *models.AllowedTransactionMap, payableStatusRules *config.PayableStatusRules,
+	reasonsCatalogue *config.ReasonsCatalogue) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestId := log.Context(req)
+		log.InfoC(requestId, "start GET penalty statement request")
+
+		customerCode := req.Context().Value(config.CustomerCode).(string)
+		language := utils.GetLanguage(req)
+
+		// penalty reference types that share a company code share the same e5 transactions so they are
+		// only requested once
+		penaltyRefTypesByCompanyCode := map[string][]string{}
+		for penaltyRefType, details := range penaltyDetailsMap.Details {
+			penaltyRefTypesByCompanyCode[details.CompanyCode] = append(penaltyRefTypesByCompanyCode[details.CompanyCode], penaltyRefType)
+		}
+		for _, penaltyRefTypes := range penaltyRefTypesByCompanyCode {
+			slices.Sort(penaltyRefTypes)
+		}
+
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		penaltiesByCompanyCode := make(map[string]companyCodePenalties, len(penaltyRefTypesByCompanyCode))
+		for companyCode, penaltyRefTypes := range penaltyRefTypesByCompanyCode {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				params := types.AccountPenaltiesParams{
+					PenaltyRefType:             penaltyRefTypes[0],
+					CustomerCode:               customerCode,
+					CompanyCode:                companyCode,
+					PenaltyDetailsMap:          penaltyDetailsMap,
+					AllowedTransactionsMap:     allowedTransactionsMap,
+					PayableStatusRules:         payableStatusRules,
+					ReasonsCatalogue:           reasonsCatalogue,
+					Language:                   language,
+					AccountPenaltiesDaoService: apDaoSvc,
+					RequestId:                  requestId,
+				}
+				transactionList, responseType, err := accountPenalties(params)
+				if err == nil && (responseType != services.Success || transactionList == nil) {
+					err = fmt.Errorf("no transactions returned, response type [%v]", responseType)
+				}
+				if err != nil {
+					log.ErrorC(requestId, fmt.Errorf("error calling e5 to get transactions for company code %s: %v", companyCode, err))
+				}
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				penaltiesByCompanyCode[companyCode] = companyCodePenalties{transactionList: transactionList, err: err}
+			}()
+		}
+		wg.Wait()
+
+		statement, err := buildPenaltyStatement(penaltyDetailsMap, penaltyRefTypesByCompanyCode, penaltiesByCompanyCode)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error building penalty statement: %v", err), log.Data{"customer_code": customerCode})
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+			return
+		}
+		if len(statement.Sections) > 0 && !slices.ContainsFunc(statement.Sections, func(section PenaltyStatementSection) bool {
+			return section.Error == ""
+		}) {
+			writeProblem(w, req, http.StatusInternalServerError, utils.ErrorCodeFinanceSystemError, "there was a problem communicating with the finance backend")
+			return
+		}
+
+		utils.SetContentLanguage(w, language)
+		utils.WriteJSON(w, req, statement)
+
+		log.InfoC(requestId, "GET penalty statement request completed successfully", log.Data{
+			"customer_code": customerCode,
+			"complete":      statement.Complete,
+		})
+	}
+}
+
+// buildPenaltyStatement splits the transactions of each company code into sections by the reference prefix of
+// the penalty reference type. Transactions that do not have the prefix of any penalty reference type, such
+// as costs, are put in the first penalty reference type of their company code.
+func buildPenaltyStatement(penaltyDetailsMap *config.PenaltyDetailsMap, penaltyRefTypesByCompanyCode map[string][]string,
+	penaltiesByCompanyCode map[string]companyCodePenalties) (PenaltyStatementResponse, error) {
+	sectionsByPenaltyRefType := map[string]*PenaltyStatementSection{}
+	statement := PenaltyStatementResponse{Sections: []PenaltyStatementSection{}, Complete: true}
+
+	for companyCode, penaltyRefTypes := range penaltyRefTypesByCompanyCode {
+		penalties := penaltiesByCompanyCode[companyCode]
+		for _, penaltyRefType := range penaltyRefTypes {
+			section := &PenaltyStatementSection{
+				PenaltyReferenceType: penaltyRefType,
+				CompanyCode:          companyCode,
+				Items:                []models.TransactionListItem{},
+			}
+			if penalties.err != nil {
+				section.Error = "there was a problem communicating with the finance backend"
+				statement.Complete = false
+			}
+			sectionsByPenaltyRefType[penaltyRefType] = section
+		}
+		if penalties.err != nil {
+			continue
+		}
+
+		for _, item := range penalties.transactionList.Items {
+			penaltyRefType := penaltyRefTypes[0]
+			for _, otherPenaltyRefType := range penaltyRefTypes {
+				if strings.HasPrefix(item.ID, penaltyDetailsMap.Details[otherPenaltyRefType].ReferencePrefix) {
+					penaltyRefType = otherPenaltyRefType
+					break
+				}
+			}
+			if kind := penaltyDetailsMap.Details[penaltyRefType].ResourceKind; item.Kind != kind {
+				// the etag is generated from the content of the item, so it is generated again with its kind
+				item.Kind = kind
+				item.Etag = ""
+				etag, err := utils.GenerateContentEtag(item)
+				if err != nil {
+					return PenaltyStatementResponse{}, err
+				}
+				item.Etag = etag
+			}
+
+			section := sectionsByPenaltyRefType[penaltyRefType]
+			section.Items = append(section.Items, item)
+			section.OutstandingTotal += item.Outstanding
+			if item.Type == types.Penalty.String() && item.PayableStatus == private.OpenPayableStatus {
+				section.PayableTotal += item.Outstanding
+			}
+		}
+	}
+
+	for _, section := range sectionsByPenaltyRefType {
+		section.OutstandingTotal = roundToPence(section.OutstandingTotal)
+		section.PayableTotal = roundToPence(section.PayableTotal)
+		statement.OutstandingTotal += section.OutstandingTotal
+		statement.PayableTotal += section.PayableTotal
+		statement.Sections = append(statement.Sections, *section)
+	}
+	statement.OutstandingTotal = roundToPence(statement.OutstandingTotal)
+	statement.PayableTotal = roundToPence(statement.PayableTotal)
+	slices.SortFunc(statement.Sections, func(a, b PenaltyStatementSection) int {
+		return strings.Compare(a.PenaltyReferenceType, b.PenaltyReferenceType)
+	})
+
+	return statement, nil
+}
+
+func roundToPence(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var statementPenaltyDetailsMap = &config.PenaltyDetailsMap{
+	Details: map[string]config.PenaltyDetails{
+		utils.LateFilingPenaltyRefType: {
+			CompanyCode:     utils.LateFilingPenaltyCompanyCode,
+			ReferencePrefix: "A",
+			ResourceKind:    "late-filing-penalty#late-filing-penalty",
+		},
+		utils.SanctionsPenaltyRefType: {
+			CompanyCode:     utils.SanctionsCompanyCode,
+			ReferencePrefix: "P",
+			ResourceKind:    "penalty#sanctions",
+		},
+		utils.SanctionsRoePenaltyRefType: {
+			CompanyCode:     utils.SanctionsCompanyCode,
+			ReferencePrefix: "U",
+			ResourceKind:    "penalty#sanctions-roe",
+		},
+	},
+}
+
+func servePenaltyStatement() *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/company/12345678/penalties/statement", nil)
+	req = req.WithContext(context.WithValue(req.Context(), config.CustomerCode, "12345678"))
+	rr := httptest.NewRecorder()
+
+	HandleGetPenaltyStatement(nil, statementPenaltyDetailsMap, &models.AllowedTransactionMap{}, nil, nil).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestUnitHandleGetPenaltyStatement(t *testing.T) {
+	defer func() {
+		accountPenalties = api.AccountPenalties
+	}()
+
+	lateFilingPenalties := &models.TransactionListResponse{Items: []models.TransactionListItem{
+		{ID: "A1234567", Type: types.Penalty.String(), Outstanding: 150.1, PayableStatus: private.OpenPayableStatus},
+		{ID: "A7654321", Type: types.Penalty.String(), Outstanding: 100.2, PayableStatus: private.ClosedPayableStatus},
+	}}
+	sanctionsPenalties := &models.TransactionListResponse{Items: []models.TransactionListItem{
+		{ID: "P1234567", Type: types.Penalty.String(), Outstanding: 250, PayableStatus: private.OpenPayableStatus},
+		{ID: "U1234567", Type: types.Penalty.String(), Outstanding: 500, PayableStatus: private.OpenPayableStatus, Etag: "etag-without-kind"},
+		{ID: "F1", Type: types.Other.String(), Outstanding: 50},
+	}}
+
+	Convey("Given the penalties of every company code can be retrieved", t, func() {
+		var requestedCompanyCodes []string
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			if params.CompanyCode == utils.LateFilingPenaltyCompanyCode {
+				return lateFilingPenalties, services.Success, nil
+			}
+			requestedCompanyCodes = append(requestedCompanyCodes, params.CompanyCode)
+			return sanctionsPenalties, services.Success, nil
+		}
+
+		rr := servePenaltyStatement()
+		var statement PenaltyStatementResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &statement)
+
+		Convey("Then each penalty reference type has its penalties and totals", func() {
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(requestedCompanyCodes, ShouldResemble, []string{utils.SanctionsCompanyCode})
+			So(statement.Complete, ShouldBeTrue)
+			So(statement.Sections, ShouldHaveLength, 3)
+
+			So(statement.Sections[0].PenaltyReferenceType, ShouldEqual, utils.LateFilingPenaltyRefType)
+			So(statement.Sections[0].Items, ShouldHaveLength, 2)
+			So(statement.Sections[0].OutstandingTotal, ShouldEqual, 250.3)
+			So(statement.Sections[0].PayableTotal, ShouldEqual, 150.1)
+
+			So(statement.Sections[1].PenaltyReferenceType, ShouldEqual, utils.SanctionsPenaltyRefType)
+			So(statement.Sections[1].Items, ShouldHaveLength, 2)
+			So(statement.Sections[1].Items[0].Kind, ShouldEqual, "penalty#sanctions")
+			So(statement.Sections[1].OutstandingTotal, ShouldEqual, 300.0)
+			So(statement.Sections[1].PayableTotal, ShouldEqual, 250.0)
+
+			So(statement.Sections[2].PenaltyReferenceType, ShouldEqual, utils.SanctionsRoePenaltyRefType)
+			So(statement.Sections[2].Items, ShouldHaveLength, 1)
+			So(statement.Sections[2].Items[0].Kind, ShouldEqual, "penalty#sanctions-roe")
+			So(statement.Sections[2].Items[0].Etag, ShouldNotEqual, sanctionsPenalties.Items[1].Etag)
+			So(statement.Sections[2].PayableTotal, ShouldEqual, 500.0)
+
+			So(statement.OutstandingTotal, ShouldEqual, 1050.3)
+			So(statement.PayableTotal, ShouldEqual, 900.1)
+		})
+
+		Convey("Then the etag of each penalty is generated from the penalty with its kind", func() {
+			for _, section := range statement.Sections {
+				for _, item := range section.Items {
+					etag := item.Etag
+					item.Etag = ""
+					wantEtag, err := utils.GenerateContentEtag(item)
+					So(err, ShouldBeNil)
+					So(etag, ShouldEqual, wantEtag)
+				}
+			}
+		})
+	})
+
+	Convey("Given the penalties of one company code cannot be retrieved", t, func() {
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			if params.CompanyCode == utils.LateFilingPenaltyCompanyCode {
+				return lateFilingPenalties, services.Success, nil
+			}
+			return nil, services.Error, errors.New("e5 unavailable")
+		}
+
+		rr := servePenaltyStatement()
+		var statement PenaltyStatementResponse
+		_ = json.Unmarshal(rr.Body.Bytes(), &statement)
+
+		Convey("Then the statement is returned without them", func() {
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(statement.Complete, ShouldBeFalse)
+			So(statement.Sections[0].Error, ShouldBeEmpty)
+			So(statement.Sections[1].Error, ShouldNotBeEmpty)
+			So(statement.Sections[1].Items, ShouldBeEmpty)
+			So(statement.Sections[2].Error, ShouldNotBeEmpty)
+			So(statement.OutstandingTotal, ShouldEqual, 250.3)
+		})
+	})
+
+	Convey("Given the penalties of no company code can be retrieved", t, func() {
+		accountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			return nil, services.Error, nil
+		}
+
+		So(servePenaltyStatement().Code, ShouldEqual, http.StatusInternalServerError)
+	})
+}
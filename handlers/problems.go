@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/e5"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+)
+
+// errorCodes is the catalogue of stable error codes for the errors returned by the services. The penalty
+// validation errors use the name of the rule they break, see private.PenaltyRule.
+var errorCodes = map[error]string{
+	services.ErrAlreadyPaid:          utils.ErrorCodePenaltyAlreadyPaid,
+	services.ErrPenaltyNotFound:      utils.ErrorCodePayableResourceNotFound,
+	e5.ErrE5BadRequest:               utils.ErrorCodeFinanceSystemBadRequest,
+	e5.ErrE5NotFound:                 utils.ErrorCodeFinanceSystemNotFound,
+	e5.ErrE5InternalServer:           utils.ErrorCodeFinanceSystemError,
+	e5.ErrUnexpectedServerError:      utils.ErrorCodeFinanceSystemError,
+	e5.ErrFailedToReadBody:           utils.ErrorCodeFinanceSystemInvalidResponse,
+	dao.ErrMaintenanceWindowNotFound: utils.ErrorCodeMaintenanceWindowNotFound,
+}
+
+// errorCode returns the stable error code for an error, or the default code if the error is not in the
+// catalogue
+func errorCode(err error, defaultCode string) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if rule := private.PenaltyRule(e); rule != "" {
+			return rule
+		}
+	}
+	for target, code := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+	return defaultCode
+}
+
+// writeProblem writes an error response with a stable error code
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	utils.WriteProblem(w, r, utils.NewProblem(status, code, detail))
+}
+
+// writeFieldProblem writes an error response with a stable error code for an invalid field of the request
+func writeFieldProblem(w http.ResponseWriter, r *http.Request, status int, code, detail, field string) {
+	utils.WriteProblem(w, r, utils.NewProblem(status, code, detail).WithField(field))
+}
+
+// writeErrorProblem writes an error response with the code of the error from the catalogue, or the default
+// code if the error is not in the catalogue. The field is included if the error is for a field of the request.
+func writeErrorProblem(w http.ResponseWriter, r *http.Request, status int, err error, defaultCode, detail string) {
+	problem := utils.NewProblem(status, errorCode(err, defaultCode), detail)
+	var fieldErr fieldError
+	if errors.As(err, &fieldErr) {
+		problem = problem.WithField(fieldErr.field)
+	}
+	utils.WriteProblem(w, r, problem)
+}
+
+// fieldError is an error caused by a field of the request
+type fieldError struct {
+	field string
+	err   error
+}
+
+// newFieldError returns an error for the field of the request with the formatted message
+func newFieldError(field, format string, a ...any) error {
+	return fieldError{field: field, err: fmt.Errorf(format, a...)}
+}
+
+func (e fieldError) Error() string {
+	return e.err.Error()
+}
+
+func (e fieldError) Unwrap() error {
+	return e.err
+}
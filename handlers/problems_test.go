@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api/common/e5"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitErrorCode(t *testing.T) {
+	Convey("Errors are mapped to stable error codes", t, func() {
+		testCases := []struct {
+			err  error
+			code string
+		}{
+			{err: private.ErrPenaltyIsPaid, code: "PAID"},
+			{err: private.ErrPenaltyNotOpen, code: "PAYABLE_STATUS_NOT_OPEN"},
+			{err: services.ErrAlreadyPaid, code: utils.ErrorCodePenaltyAlreadyPaid},
+			{err: fmt.Errorf("calling e5: %w", e5.ErrE5NotFound), code: utils.ErrorCodeFinanceSystemNotFound},
+			{err: fieldError{field: "transactions[0]", err: private.ErrPenaltyDCA}, code: "WITH_DEBT_COLLECTION_AGENCY"},
+			{err: errors.New("unknown"), code: utils.ErrorCodeInternalError},
+			{err: nil, code: utils.ErrorCodeInternalError},
+		}
+
+		for _, tc := range testCases {
+			So(errorCode(tc.err, utils.ErrorCodeInternalError), ShouldEqual, tc.code)
+		}
+	})
+}
+
+func TestUnitWriteErrorProblem(t *testing.T) {
+	Convey("The code and field of an error are written as problem details", t, func() {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Accept", utils.ProblemContentType)
+
+		err := fieldError{field: "transactions[1]", err: private.ErrPenaltyAmountMismatch}
+		writeErrorProblem(w, r, http.StatusBadRequest, err, utils.ErrorCodeValidationFailed, "not payable")
+
+		var problem utils.Problem
+		So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(w.Header().Get("Content-Type"), ShouldEqual, utils.ProblemContentType)
+		So(problem.Code, ShouldEqual, "AMOUNT_MISMATCH")
+		So(problem.Field, ShouldEqual, "transactions[1]")
+		So(problem.Detail, ShouldEqual, "not payable")
+	})
+
+	Convey("The legacy message response is written if problem details are not accepted", t, func() {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+		writeErrorProblem(w, r, http.StatusBadRequest, newFieldError("sort", "sort must be due_date"), utils.ErrorCodeInvalidQueryParameter, "invalid query parameter")
+
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(w.Body.String(), ShouldEqual, "{\"message\":\"invalid query parameter\"}\n")
+	})
+}
@@ -1,15 +1,17 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/chs.go/log"
-	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/dao"
 	"github.com/companieshouse/penalty-payment-api/common/e5"
 	"github.com/companieshouse/penalty-payment-api/common/services"
 	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 	"github.com/companieshouse/penalty-payment-api/middleware"
 	"github.com/companieshouse/penalty-payment-api/penalty_payments/interceptors"
 	"github.com/companieshouse/penalty-payment-api/penalty_payments/service"
@@ -18,10 +20,12 @@ import (
 
 var payableResourceService *services.PayableResourceService
 
-// Register defines the route mappings for the main router and it's subrouters
+// Register defines the route mappings for the main router and it's subrouters. Requests are validated by the
+// request validator after they are authenticated, so that unauthenticated requests are not told why a
+// request is invalid.
 func Register(mainRouter *mux.Router, cfg *config.Config, prDaoService dao.PayableResourceDaoService,
-	apDaoService dao.AccountPenaltiesDaoService, penaltyDetailsMap *config.PenaltyDetailsMap,
-	allowedTransactionsMap *models.AllowedTransactionMap) {
+	apDaoService dao.AccountPenaltiesDaoService, prSearchDaoService dao.PayableResourceSearchDaoService, penaltyConfigProvider *config.PenaltyConfigProvider,
+	maintenanceWindowsCache *api.MaintenanceWindowsCache, idempotencyKeys *IdempotencyKeys, requestValidator *middleware.RequestValidator) {
 
 	payableResourceService = &services.PayableResourceService{
 		Config: cfg,
@@ -50,36 +54,109 @@ func Register(mainRouter *mux.Router, cfg *config.Config, prDaoService dao.Payab
 		RequireElevatedAPIKeyPrivilege: true,
 	}
 
-	mainRouter.HandleFunc("/penalty-payment-api/healthcheck", healthCheck).Methods(http.MethodGet).Name("healthcheck")
-	mainRouter.HandleFunc("/penalty-payment-api/healthcheck/finance-system", HandleHealthCheckFinanceSystem).Methods(http.MethodGet).Name("healthcheck-finance-system")
+	// routes that do not need authentication have no parameters or body, apart from the penalty lookup
+	mainRouter.Handle("/penalty-payment-api/healthcheck", requestValidator.Middleware(HandleHealthCheck(penaltyConfigProvider))).Methods(http.MethodGet).Name("healthcheck")
+	mainRouter.Handle("/penalty-payment-api/healthcheck/finance-system", requestValidator.Middleware(HandleHealthCheckFinanceSystem(maintenanceWindowsCache))).Methods(http.MethodGet).Name("healthcheck-finance-system")
+	mainRouter.Handle("/penalty-payment-api/penalty-reference-types", requestValidator.Middleware(withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleGetPenaltyReferenceTypes(penaltyConfig.PenaltyDetails)
+	}))).Methods(http.MethodGet).Name("get-penalty-ref-types")
+
+	// penalties can be looked up without the customer code so lookups are rate limited to stop enumeration
+	penaltyLookupRateLimit := cfg.PenaltyLookupRateLimit
+	if penaltyLookupRateLimit <= 0 {
+		penaltyLookupRateLimit = DefaultPenaltyLookupRateLimit
+	}
+	penaltyLookupRouter := mainRouter.PathPrefix("/penalty-payment-api/penalty-lookup").Subrouter()
+	penaltyLookupRouter.Handle("", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleLookupPenalty(apDaoService, penaltyConfig.PenaltyDetails, penaltyConfig.AllowedTransactions,
+			penaltyConfig.PayableStatusRules, penaltyConfig.ReasonsCatalogue)
+	})).Methods(http.MethodPost).Name("lookup-penalty")
+	// the trusted proxies are validated at startup
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Error(fmt.Errorf("error parsing trusted proxies: %v", err))
+	}
+	penaltyLookupRouter.Use(middleware.NewRateLimiter(penaltyLookupRateLimit, time.Minute, trustedProxies).Middleware, requestValidator.Middleware)
+
+	// only API keys with elevated privileges can manage maintenance windows, look up penalties in bulk and search
+	// payable resources
+	adminRouter := mainRouter.PathPrefix("/penalty-payment-api/admin").Subrouter()
+	adminRouter.Handle("/maintenance-windows", HandleGetMaintenanceWindows(maintenanceWindowsCache)).Methods(http.MethodGet).Name("get-maintenance-windows")
+	adminRouter.Handle("/maintenance-windows", HandleCreateMaintenanceWindow(maintenanceWindowsCache)).Methods(http.MethodPost).Name("create-maintenance-window")
+	adminRouter.Handle("/maintenance-windows/{maintenance_window_id}", HandleCancelMaintenanceWindow(maintenanceWindowsCache)).Methods(http.MethodDelete).Name("cancel-maintenance-window")
+	adminRouter.Handle("/penalties", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleBulkGetPenalties(apDaoService, penaltyConfig.PenaltyDetails, penaltyConfig.AllowedTransactions,
+			penaltyConfig.PayableStatusRules, penaltyConfig.ReasonsCatalogue, cfg.BulkLookupConcurrency)
+	})).Methods(http.MethodPost).Name("bulk-get-penalties")
+	adminRouter.Handle("/payable-resources", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleSearchPayableResources(prSearchDaoService, penaltyConfig.PenaltyDetails)
+	})).Methods(http.MethodGet).Name("search-payables")
+	adminRouter.Use(authentication.ElevatedPrivilegesInterceptor, requestValidator.Middleware)
 
 	appRouter := mainRouter.PathPrefix("/company/{customer_code}").Subrouter()
-	appRouter.HandleFunc("/penalties/late-filing", HandleGetPenalties(apDaoService, penaltyDetailsMap, allowedTransactionsMap)).Methods(http.MethodGet).Name("get-penalties-legacy")
-	appRouter.HandleFunc("/penalties/{penalty_reference_type}", HandleGetPenalties(apDaoService, penaltyDetailsMap, allowedTransactionsMap)).Methods(http.MethodGet).Name("get-penalties")
-	appRouter.Handle("/penalties/payable", CreatePayableResourceHandler(prDaoService, apDaoService, penaltyDetailsMap, allowedTransactionsMap)).Methods(http.MethodPost).Name("create-payable")
+	getPenaltiesHandler := withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleGetPenalties(apDaoService, penaltyConfig.PenaltyDetails, penaltyConfig.AllowedTransactions, penaltyConfig.PayableStatusRules,
+			penaltyConfig.ReasonsCatalogue)
+	})
+	appRouter.Handle("/penalties/late-filing", getPenaltiesHandler).Methods(http.MethodGet).Name("get-penalties-legacy")
+	appRouter.Handle("/penalties/statement", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleGetPenaltyStatement(apDaoService, penaltyConfig.PenaltyDetails, penaltyConfig.AllowedTransactions,
+			penaltyConfig.PayableStatusRules, penaltyConfig.ReasonsCatalogue)
+	})).Methods(http.MethodGet).Name("get-penalty-statement")
+	appRouter.Handle("/penalties/payable", HandleListPayableResources(prSearchDaoService)).Methods(http.MethodGet).Name("list-payables")
+	appRouter.Handle("/penalties/{penalty_reference_type}", getPenaltiesHandler).Methods(http.MethodGet).Name("get-penalties")
+	appRouter.Handle("/penalties/{penalty_reference_type}/export", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleExportPenalties(prDaoService, apDaoService, penaltyConfig.PenaltyDetails, penaltyConfig.AllowedTransactions,
+			penaltyConfig.PayableStatusRules, penaltyConfig.ReasonsCatalogue)
+	})).Methods(http.MethodGet).Name("export-penalties")
+	appRouter.Handle("/penalties/{penalty_reference_type}/{penalty_ref}/payability", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleGetPenaltyPayability(apDaoService, penaltyConfig.PenaltyDetails, penaltyConfig.AllowedTransactions,
+			penaltyConfig.PayableStatusRules, penaltyConfig.ReasonsCatalogue)
+	})).Methods(http.MethodGet).Name("get-penalty-payability")
+	appRouter.Handle("/penalties/payable", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return CreatePayableResourceHandler(prDaoService, apDaoService, penaltyConfig.PenaltyDetails, penaltyConfig.AllowedTransactions,
+			penaltyConfig.PayableStatusRules, penaltyConfig.ReasonsCatalogue, maintenanceWindowsCache, idempotencyKeys, payableResourceService)
+	})).Methods(http.MethodPost).Name("create-payable")
 	appRouter.Use(
 		oauth2OnlyInterceptor.OAuth2OnlyAuthenticationIntercept,
 		userAuthInterceptor.UserAuthenticationIntercept,
 		middleware.CompanyMiddleware,
+		requestValidator.Middleware,
 	)
 
 	// sub router for handling interactions with existing payable resources to apply relevant
 	// PayableAuthenticationInterceptor
 	existingPayableRouter := appRouter.PathPrefix("/penalties/payable/{payable_ref}").Subrouter()
-	existingPayableRouter.HandleFunc("", HandleGetPayableResource).Name("get-payable").Methods(http.MethodGet)
-	existingPayableRouter.HandleFunc("/payment", HandleGetPaymentDetails(penaltyDetailsMap)).Methods(http.MethodGet).Name("get-payment-details")
+	existingPayableRouter.Handle("", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleGetPayableResource(penaltyConfig.ReasonsCatalogue)
+	})).Name("get-payable").Methods(http.MethodGet)
+	existingPayableRouter.Handle("", HandleCancelPayableResource(payableResourceService)).Name("cancel-payable").Methods(http.MethodDelete)
+	existingPayableRouter.Handle("/payment", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleGetPaymentDetails(penaltyConfig.PenaltyDetails)
+	})).Methods(http.MethodGet).Name("get-payment-details")
+	existingPayableRouter.Handle("/receipt", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return HandleGetPayableResourceReceipt(penaltyConfig.ReasonsCatalogue)
+	})).Methods(http.MethodGet).Name("get-payable-receipt")
 	existingPayableRouter.Use(payableAuthInterceptor.PayableAuthenticationIntercept)
 
 	// separate router for the patch request so that we can apply the interceptor to it without interfering with
 	// other routes
 	payResourceRouter := appRouter.PathPrefix("/penalties/payable/{payable_ref}/payment").Methods(http.MethodPatch).Subrouter()
 	payResourceRouter.Use(payableAuthInterceptor.PayableAuthenticationIntercept, authentication.ElevatedPrivilegesInterceptor)
-	payResourceRouter.Handle("", PayResourceHandler(payableResourceService, e5Client, penaltyDetailsMap, allowedTransactionsMap, apDaoService)).Name("mark-as-paid")
+	payResourceRouter.Handle("", withPenaltyConfig(penaltyConfigProvider, func(penaltyConfig *config.PenaltyConfig) http.Handler {
+		return PayResourceHandler(payableResourceService, e5Client, penaltyConfig.PenaltyDetails, penaltyConfig.AllowedTransactions, apDaoService,
+			maintenanceWindowsCache)
+	})).Name("mark-as-paid")
 
 	// Set middleware across all routers and sub routers
 	mainRouter.Use(log.Handler)
 }
 
-func healthCheck(w http.ResponseWriter, _ *http.Request) {
-	w.WriteHeader(http.StatusOK)
+// withPenaltyConfig builds the handler from the penalty config that is active when each request is
+// received, so that a reloaded config is used without re-registering the routes
+func withPenaltyConfig(penaltyConfigProvider *config.PenaltyConfigProvider,
+	handler func(penaltyConfig *config.PenaltyConfig) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(penaltyConfigProvider.Get()).ServeHTTP(w, r)
+	})
 }
@@ -3,52 +3,182 @@ package handlers
 import (
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
+	"github.com/companieshouse/penalty-payment-api/common/openapi"
 	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/middleware"
 	"github.com/companieshouse/penalty-payment-api/mocks"
+	"github.com/companieshouse/penalty-payment-api/spec"
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// resolved before any test changes the working directory
+var assetsDir, _ = filepath.Abs("../assets")
+
+func newTestRequestValidator() (*middleware.RequestValidator, error) {
+	document, err := openapi.Load(spec.OpenAPI)
+	if err != nil {
+		return nil, err
+	}
+	return middleware.NewRequestValidator(document, middleware.DefaultMaxRequestBodyBytes, false), nil
+}
+
+func newTestPenaltyConfigProvider() (*config.PenaltyConfigProvider, error) {
+	return config.NewPenaltyConfigProvider(config.PenaltyConfigFiles{
+		PenaltyDetails:      filepath.Join(assetsDir, "penalty_details.yml"),
+		AllowedTransactions: filepath.Join(assetsDir, "penalty_types.yml"),
+		PayableStatusRules:  filepath.Join(assetsDir, "payable_status_rules.yml"),
+		ReasonsCatalogue:    filepath.Join(assetsDir, "reasons.yml"),
+	})
+}
+
 func TestUnitRegisterRoutes(t *testing.T) {
 	Convey("Register routes", t, func() {
-		penaltyDetailsMap = &config.PenaltyDetailsMap{}
+		penaltyConfigProvider, err := newTestPenaltyConfigProvider()
+		So(err, ShouldBeNil)
+		requestValidator, err := newTestRequestValidator()
+		So(err, ShouldBeNil)
 		router := mux.NewRouter()
 		mockCtrl := gomock.NewController(t)
 		defer mockCtrl.Finish()
 
 		mockPrDaoSvc := mocks.NewMockPayableResourceDaoService(mockCtrl)
 		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(mockCtrl)
-		Register(router, &config.Config{}, mockPrDaoSvc, mockApDaoSvc, penaltyDetailsMap, allowedTransactionsMap)
+		Register(router, &config.Config{}, mockPrDaoSvc, mockApDaoSvc, &fakePayableResourceSearchDaoService{}, penaltyConfigProvider, &api.MaintenanceWindowsCache{}, nil, requestValidator)
 
 		healthCheckPath, _ := router.GetRoute("healthcheck").GetPathTemplate()
 		healthFinanceCheckPath, _ := router.GetRoute("healthcheck-finance-system").GetPathTemplate()
+		getPenaltyRefTypesPath, _ := router.GetRoute("get-penalty-ref-types").GetPathTemplate()
 		getPenaltiesPath, _ := router.GetRoute("get-penalties").GetPathTemplate()
 		getPenaltiesOriginalPath, _ := router.GetRoute("get-penalties-legacy").GetPathTemplate()
+		getPenaltyPayabilityPath, _ := router.GetRoute("get-penalty-payability").GetPathTemplate()
+		getPenaltyStatementPath, _ := router.GetRoute("get-penalty-statement").GetPathTemplate()
+		exportPenaltiesPath, _ := router.GetRoute("export-penalties").GetPathTemplate()
 		createPayablePath, _ := router.GetRoute("create-payable").GetPathTemplate()
+		listPayablesPath, _ := router.GetRoute("list-payables").GetPathTemplate()
 		getPayablePath, _ := router.GetRoute("get-payable").GetPathTemplate()
+		cancelPayablePath, _ := router.GetRoute("cancel-payable").GetPathTemplate()
 		getPaymentDetailsPath, _ := router.GetRoute("get-payment-details").GetPathTemplate()
+		getPayableReceiptPath, _ := router.GetRoute("get-payable-receipt").GetPathTemplate()
 		markAsPaidPath, _ := router.GetRoute("mark-as-paid").GetPathTemplate()
+		getMaintenanceWindowsPath, _ := router.GetRoute("get-maintenance-windows").GetPathTemplate()
+		createMaintenanceWindowPath, _ := router.GetRoute("create-maintenance-window").GetPathTemplate()
+		cancelMaintenanceWindowPath, _ := router.GetRoute("cancel-maintenance-window").GetPathTemplate()
+		bulkGetPenaltiesPath, _ := router.GetRoute("bulk-get-penalties").GetPathTemplate()
+		searchPayablesPath, _ := router.GetRoute("search-payables").GetPathTemplate()
+		lookupPenaltyPath, _ := router.GetRoute("lookup-penalty").GetPathTemplate()
 
 		So(healthCheckPath, ShouldEqual, "/penalty-payment-api/healthcheck")
 		So(healthFinanceCheckPath, ShouldEqual, "/penalty-payment-api/healthcheck/finance-system")
+		So(getPenaltyRefTypesPath, ShouldEqual, "/penalty-payment-api/penalty-reference-types")
 		So(getPenaltiesPath, ShouldEqual, "/company/{customer_code}/penalties/{penalty_reference_type}")
 		So(getPenaltiesOriginalPath, ShouldEqual, "/company/{customer_code}/penalties/late-filing")
+		So(getPenaltyPayabilityPath, ShouldEqual, "/company/{customer_code}/penalties/{penalty_reference_type}/{penalty_ref}/payability")
+		So(getPenaltyStatementPath, ShouldEqual, "/company/{customer_code}/penalties/statement")
+		So(exportPenaltiesPath, ShouldEqual, "/company/{customer_code}/penalties/{penalty_reference_type}/export")
 		So(createPayablePath, ShouldEqual, "/company/{customer_code}/penalties/payable")
+		So(listPayablesPath, ShouldEqual, "/company/{customer_code}/penalties/payable")
 		So(getPayablePath, ShouldEqual, "/company/{customer_code}/penalties/payable/{payable_ref}")
+		So(cancelPayablePath, ShouldEqual, "/company/{customer_code}/penalties/payable/{payable_ref}")
 		So(getPaymentDetailsPath, ShouldEqual, "/company/{customer_code}/penalties/payable/{payable_ref}/payment")
+		So(getPayableReceiptPath, ShouldEqual, "/company/{customer_code}/penalties/payable/{payable_ref}/receipt")
 		So(markAsPaidPath, ShouldEqual, "/company/{customer_code}/penalties/payable/{payable_ref}/payment")
+		So(getMaintenanceWindowsPath, ShouldEqual, "/penalty-payment-api/admin/maintenance-windows")
+		So(createMaintenanceWindowPath, ShouldEqual, "/penalty-payment-api/admin/maintenance-windows")
+		So(cancelMaintenanceWindowPath, ShouldEqual, "/penalty-payment-api/admin/maintenance-windows/{maintenance_window_id}")
+		So(bulkGetPenaltiesPath, ShouldEqual, "/penalty-payment-api/admin/penalties")
+		So(searchPayablesPath, ShouldEqual, "/penalty-payment-api/admin/payable-resources")
+		So(lookupPenaltyPath, ShouldEqual, "/penalty-payment-api/penalty-lookup")
+	})
+}
+
+func TestUnitRegisteredRoutesMatchOpenAPIDocument(t *testing.T) {
+	Convey("Every registered route is in the OpenAPI document and every operation is registered", t, func() {
+		penaltyConfigProvider, err := newTestPenaltyConfigProvider()
+		So(err, ShouldBeNil)
+		requestValidator, err := newTestRequestValidator()
+		So(err, ShouldBeNil)
+		router := mux.NewRouter()
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		Register(router, &config.Config{}, mocks.NewMockPayableResourceDaoService(mockCtrl),
+			mocks.NewMockAccountPenaltiesDaoService(mockCtrl), &fakePayableResourceSearchDaoService{}, penaltyConfigProvider, &api.MaintenanceWindowsCache{}, nil, requestValidator)
+
+		var registeredRoutes []openapi.Route
+		err = router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+			if route.GetHandler() == nil {
+				return nil
+			}
+			path, err := route.GetPathTemplate()
+			if err != nil {
+				return err
+			}
+			// the method of a route can be set on the subrouter it is registered with
+			methods, err := route.GetMethods()
+			for i := len(ancestors) - 1; err != nil && i >= 0; i-- {
+				methods, err = ancestors[i].GetMethods()
+			}
+			for _, method := range methods {
+				registeredRoutes = append(registeredRoutes, openapi.Route{Method: method, Path: path})
+			}
+			return nil
+		})
+		So(err, ShouldBeNil)
+
+		document, err := openapi.Load(spec.OpenAPI)
+		So(err, ShouldBeNil)
+		documentedRoutes := document.Routes()
+		So(registeredRoutes, ShouldHaveLength, len(documentedRoutes))
+
+		for _, route := range registeredRoutes {
+			So(documentedRoutes, ShouldContain, route)
+		}
+		for _, route := range documentedRoutes {
+			So(registeredRoutes, ShouldContain, route)
+		}
+	})
+}
+
+func TestUnitRequestValidatorPenaltyReferenceTypes(t *testing.T) {
+	Convey("Given the request validator of the spec", t, func() {
+		requestValidator, err := newTestRequestValidator()
+		So(err, ShouldBeNil)
+		var handledType string
+		router := mux.NewRouter()
+		router.HandleFunc("/company/{customer_code}/penalties/{penalty_reference_type}/{penalty_ref}/payability",
+			func(w http.ResponseWriter, r *http.Request) {
+				handledType = mux.Vars(r)["penalty_reference_type"]
+				w.WriteHeader(http.StatusOK)
+			}).Methods(http.MethodGet)
+		router.Use(requestValidator.Middleware)
+
+		Convey("Then a penalty reference type added to the registry is not rejected", func() {
+			req := httptest.NewRequest(http.MethodGet, "/company/12345678/penalties/NEW_TYPE/N1234567/payability", nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			So(rr.Code, ShouldEqual, http.StatusOK)
+			So(handledType, ShouldEqual, "NEW_TYPE")
+		})
 	})
 }
 
 func TestUnitGetHealthCheck(t *testing.T) {
 	Convey("Get HealthCheck", t, func() {
+		penaltyConfigProvider, err := newTestPenaltyConfigProvider()
+		So(err, ShouldBeNil)
+
 		req := httptest.NewRequest("GET", "/healthcheck", nil)
 		w := httptest.NewRecorder()
-		healthCheck(w, req)
+		HandleHealthCheck(penaltyConfigProvider)(w, req)
 		So(w.Code, ShouldEqual, http.StatusOK)
+		So(w.Body.String(), ShouldStartWith, `{"message":"HEALTHY","penalty_config":{"version":1,"hash":"`+penaltyConfigProvider.Get().Hash+`"`)
 	})
 }
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+)
+
+// HandleSearchPayableResources searches the payable resources of every customer by the email of the user who
+// created them, the reference of their payment or the penalty reference of their transactions, newest first. They
+// can also be filtered and paginated like GET payable resources. Requires an API key with elevated privileges.
+func HandleSearchPayableResources(prSearchDaoSvc dao.PayableResourceSearchDaoService, penaltyDetailsMap *config.PenaltyDetailsMap) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestId := log.Context(r)
+		log.InfoC(requestId, "start search payable resources request")
+
+		query, err := parsePayableResourceSearchQuery(r, penaltyDetailsMap)
+		if err != nil {
+			log.ErrorC(requestId, err)
+			writeErrorProblem(w, r, http.StatusBadRequest, err, utils.ErrorCodeInvalidQueryParameter, "invalid query parameter: "+err.Error())
+			return
+		}
+		logContext := log.Data{"customer_code": query.CustomerCode, "payment_reference": query.PaymentReference,
+			"penalty_ref": query.PenaltyRef}
+
+		resources, total, err := prSearchDaoSvc.SearchPayableResources(query, requestId)
+		if err != nil {
+			log.ErrorC(requestId, fmt.Errorf("error searching payable resources: %v", err), logContext)
+			writeProblem(w, r, http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request")
+			return
+		}
+
+		response := PayableResourceListResponse{
+			ItemsPerPage: query.ItemsPerPage,
+			StartIndex:   query.StartIndex,
+			TotalResults: int(total),
+			Items:        make([]PayableResourceListItem, 0, len(resources)),
+		}
+		for _, resource := range resources {
+			response.Items = append(response.Items, payableResourceListItem(resource))
+		}
+
+		utils.WriteJSON(w, r, response)
+
+		log.InfoC(requestId, "search payable resources request completed successfully", logContext)
+	}
+}
+
+// parsePayableResourceSearchQuery reads the query parameters of a search payable resources request. At least one
+// of email, payment_reference or penalty_ref is required so that a search does not list every payable resource.
+// The penalty reference is a key of the transactions of a payable resource, so it must be a penalty reference of
+// the registry to stop it changing the path of the field that is searched.
+func parsePayableResourceSearchQuery(r *http.Request, penaltyDetailsMap *config.PenaltyDetailsMap) (dao.PayableResourceQuery, error) {
+	query, err := parsePayableResourceQuery(r)
+	if err != nil {
+		return query, err
+	}
+
+	values := r.URL.Query()
+	query.CustomerCode = strings.ToUpper(strings.TrimSpace(values.Get("customer_code")))
+	query.CreatedByEmail = strings.TrimSpace(values.Get("email"))
+	query.PaymentReference = strings.TrimSpace(values.Get("payment_reference"))
+	query.PenaltyRef = strings.ToUpper(strings.TrimSpace(values.Get("penalty_ref")))
+	if query.PenaltyRef != "" && !penaltyDetailsMap.IsPenaltyReference(query.PenaltyRef) {
+		return query, newFieldError("penalty_ref", "penalty_ref must be a reference prefix followed by seven digits")
+	}
+
+	if query.CreatedByEmail == "" && query.PaymentReference == "" && query.PenaltyRef == "" {
+		return query, errors.New("one of email, payment_reference or penalty_ref must be supplied")
+	}
+
+	return query, nil
+}
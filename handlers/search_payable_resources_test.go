@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitHandleSearchPayableResources(t *testing.T) {
+	Convey("Given payable resources created by two users", t, func() {
+		otherCustomer := payableResourceRecord("PR_4", "user-1", "paid", "")
+		otherCustomer.CustomerCode = "OE123456"
+		otherCustomer.Data.Payment.Reference = "payment-ref"
+		prSearchDaoSvc := &fakePayableResourceSearchDaoService{resources: []dao.PayableResourceRecordDao{
+			payableResourceRecord("PR_1", "user-1", "paid", ""),
+			payableResourceRecord("PR_2", "user-2", "pending", ""),
+			otherCustomer,
+		}}
+
+		serve := func(query string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/penalty-payment-api/admin/payable-resources"+query, nil)
+			req.Header.Set("Accept", utils.ProblemContentType)
+			res := httptest.NewRecorder()
+			HandleSearchPayableResources(prSearchDaoSvc, penaltyDetailsMap).ServeHTTP(res, req)
+			return res
+		}
+		decodeList := func(res *httptest.ResponseRecorder) PayableResourceListResponse {
+			So(res.Code, ShouldEqual, http.StatusOK)
+			var list PayableResourceListResponse
+			So(json.Unmarshal(res.Body.Bytes(), &list), ShouldBeNil)
+			return list
+		}
+
+		Convey("Then they are found by the email of the user across customers", func() {
+			list := decodeList(serve("?email=USER-1@ch.gov.uk"))
+
+			So(list.TotalResults, ShouldEqual, 2)
+			So(list.Items[0].PayableRef, ShouldEqual, "PR_1")
+			So(list.Items[1].CustomerCode, ShouldEqual, "OE123456")
+			So(prSearchDaoSvc.query.CreatedByEmail, ShouldEqual, "USER-1@ch.gov.uk")
+		})
+
+		Convey("Then they are found by payment reference", func() {
+			list := decodeList(serve("?payment_reference=payment-ref"))
+
+			So(list.TotalResults, ShouldEqual, 1)
+			So(list.Items[0].PayableRef, ShouldEqual, "PR_4")
+		})
+
+		Convey("Then they are found by penalty reference, filtered by customer, status and date", func() {
+			list := decodeList(serve("?penalty_ref=a1234567&customer_code=" + customerCode + "&status=pending&created_from=2025-03-01"))
+
+			So(list.TotalResults, ShouldEqual, 1)
+			So(list.Items[0].PayableRef, ShouldEqual, "PR_2")
+			So(prSearchDaoSvc.query.PenaltyRef, ShouldEqual, "A1234567")
+			So(prSearchDaoSvc.query.CustomerCode, ShouldEqual, customerCode)
+			So(prSearchDaoSvc.query.CreatedFrom.IsZero(), ShouldBeFalse)
+		})
+
+		Convey("Then a search without an email, payment reference or penalty reference is a bad request", func() {
+			res := serve("?status=paid")
+
+			So(res.Code, ShouldEqual, http.StatusBadRequest)
+			So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodeInvalidQueryParameter)
+		})
+
+		Convey("Then a penalty reference that is not a penalty reference of the registry is a bad request", func() {
+			for _, penaltyRef := range []string{"A1234567.amount", "A123", "R1234567", "%24where"} {
+				res := serve("?penalty_ref=" + penaltyRef)
+
+				So(res.Code, ShouldEqual, http.StatusBadRequest)
+				So(res.Body.String(), ShouldContainSubstring, utils.ErrorCodeInvalidQueryParameter)
+				So(res.Body.String(), ShouldContainSubstring, `"field":"penalty_ref"`)
+			}
+		})
+
+		Convey("Then an invalid filter is a bad request", func() {
+			res := serve("?email=user-1@ch.gov.uk&items_per_page=1000")
+
+			So(res.Code, ShouldEqual, http.StatusBadRequest)
+			So(res.Body.String(), ShouldContainSubstring, `"field":"items_per_page"`)
+		})
+
+		Convey("Then an error searching the payable resources is an internal error", func() {
+			prSearchDaoSvc.err = errors.New("error searching")
+
+			res := serve("?email=user-1@ch.gov.uk")
+
+			So(res.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+}
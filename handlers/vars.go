@@ -1,13 +1,18 @@
 package handlers
 
 import (
-	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/penalty_payments/service"
 )
 
 var (
 	paymentDetailsService            *service.PaymentDetailsService
-	getCompanyCode                   = utils.GetCompanyCode
-	getCompanyCodeFromTransaction    = utils.GetCompanyCodeFromTransaction
-	getPenaltyRefTypeFromTransaction = utils.GetPenaltyRefTypeFromTransaction
+	getCompanyCode                   = (*config.PenaltyDetailsMap).GetCompanyCode
+	getCompanyCodeFromTransaction    = (*config.PenaltyDetailsMap).GetCompanyCodeFromTransaction
+	getPenaltyRefTypeFromTransaction = (*config.PenaltyDetailsMap).GetPenaltyRefTypeFromTransaction
+	getCompanyName                   = service.GetCompanyName
+	getCompanyPostcode               = service.GetCompanyPostcode
+	timeNow                          = time.Now
 )
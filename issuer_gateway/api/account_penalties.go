@@ -31,6 +31,9 @@ func AccountPenalties(params types.AccountPenaltiesParams) (*models.TransactionL
 	penaltyDetailsMap := params.PenaltyDetailsMap
 	apDaoSvc := params.AccountPenaltiesDaoService
 	allowedTransactionsMap := params.AllowedTransactionsMap
+	payableStatusRules := params.PayableStatusRules
+	reasonsCatalogue := params.ReasonsCatalogue
+	language := params.Language
 	requestId := params.RequestId
 
 	cfg, err := getConfig()
@@ -41,16 +44,7 @@ func AccountPenalties(params types.AccountPenaltiesParams) (*models.TransactionL
 
 	companyInfoLogData := log.Data{"customer_code": customerCode, "company_code": companyCode}
 
-	log.InfoC(requestId, "getting account penalties from cache", companyInfoLogData)
-	accountPenalties, err := apDaoSvc.GetAccountPenalties(customerCode, companyCode, requestId)
-
-	if accountPenalties == nil {
-		log.InfoC(requestId, "account penalties not found in cache, getting account penalties from E5 transactions", companyInfoLogData)
-		accountPenalties, err = getAccountPenaltiesFromE5Transactions(customerCode, companyCode, cfg, apDaoSvc, false, requestId)
-	} else if isStale(accountPenalties, cfg, requestId) {
-		log.InfoC(requestId, "account penalties cache record is stale, getting account penalties from E5 transactions", companyInfoLogData)
-		accountPenalties, err = getAccountPenaltiesFromE5Transactions(customerCode, companyCode, cfg, apDaoSvc, true, requestId)
-	}
+	accountPenalties, err := getAccountPenaltiesDao(customerCode, companyCode, cfg, apDaoSvc, requestId)
 	if err != nil {
 		return nil, services.Error, err
 	}
@@ -58,8 +52,8 @@ func AccountPenalties(params types.AccountPenaltiesParams) (*models.TransactionL
 	// Generate the CH preferred format of the results i.e. classify the transactions into
 	// payable "penalty" types or non-payable "other" types
 	transactionListItemEnrichmentProviders := private.TransactionListItemEnrichmentProviders{
-		ReasonProvider:        &private.DefaultReasonProvider{},
-		PayableStatusProvider: &private.DefaultPayableStatusProvider{},
+		ReasonProvider:        getReasonProvider(reasonsCatalogue, language),
+		PayableStatusProvider: getPayableStatusProvider(payableStatusRules),
 	}
 	generatedTransactionListFromAccountPenalties, err :=
 		generateTransactionList(accountPenalties, penaltyRefType, penaltyDetailsMap, allowedTransactionsMap, cfg, requestId, transactionListItemEnrichmentProviders)
@@ -73,6 +67,44 @@ func AccountPenalties(params types.AccountPenaltiesParams) (*models.TransactionL
 	return generatedTransactionListFromAccountPenalties, services.Success, nil
 }
 
+// getAccountPenaltiesDao gets the account penalties from the account_penalties cache, getting them from E5
+// if there is no cache entry or the cache entry is stale
+func getAccountPenaltiesDao(customerCode, companyCode string, cfg *config.Config, apDaoSvc dao.AccountPenaltiesDaoService,
+	requestId string) (*models.AccountPenaltiesDao, error) {
+	companyInfoLogData := log.Data{"customer_code": customerCode, "company_code": companyCode}
+
+	log.InfoC(requestId, "getting account penalties from cache", companyInfoLogData)
+	accountPenalties, err := apDaoSvc.GetAccountPenalties(customerCode, companyCode, requestId)
+
+	if accountPenalties == nil {
+		log.InfoC(requestId, "account penalties not found in cache, getting account penalties from E5 transactions", companyInfoLogData)
+		accountPenalties, err = getAccountPenaltiesFromE5Transactions(customerCode, companyCode, cfg, apDaoSvc, false, requestId)
+	} else if isStale(accountPenalties, cfg, requestId) {
+		log.InfoC(requestId, "account penalties cache record is stale, getting account penalties from E5 transactions", companyInfoLogData)
+		accountPenalties, err = getAccountPenaltiesFromE5Transactions(customerCode, companyCode, cfg, apDaoSvc, true, requestId)
+	}
+
+	return accountPenalties, err
+}
+
+// getPayableStatusProvider uses the payable status rules when they are supplied, falling back to the
+// rules held in code
+func getPayableStatusProvider(payableStatusRules *config.PayableStatusRules) private.PayableStatusProvider {
+	if payableStatusRules == nil {
+		return &private.DefaultPayableStatusProvider{}
+	}
+	return &private.RulesPayableStatusProvider{Rules: payableStatusRules}
+}
+
+// getReasonProvider uses the reasons catalogue when it is supplied, falling back to the English reasons
+// held in code
+func getReasonProvider(reasonsCatalogue *config.ReasonsCatalogue, language string) private.ReasonProvider {
+	if reasonsCatalogue == nil {
+		return &private.DefaultReasonProvider{}
+	}
+	return &private.CatalogueReasonProvider{Catalogue: reasonsCatalogue, Language: language}
+}
+
 func createAccountPenaltiesEntry(customerCode string, companyCode string, e5Response *e5.GetTransactionsResponse, apDaoSvc dao.AccountPenaltiesDaoService, requestId string) *models.AccountPenaltiesDao {
 	accountPenalties := convertE5Response(customerCode, companyCode, e5Response)
 	err := apDaoSvc.CreateAccountPenalties(&accountPenalties, requestId)
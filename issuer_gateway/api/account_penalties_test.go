@@ -428,6 +428,35 @@ func TestUnitAccountPenalties(t *testing.T) {
 	})
 }
 
+func TestUnitGetReasonProvider(t *testing.T) {
+	Convey("Get reason provider", t, func() {
+		Convey("Default provider when there is no reasons catalogue", func() {
+			So(getReasonProvider(nil, utils.WelshLanguage), ShouldResemble, &private.DefaultReasonProvider{})
+		})
+
+		Convey("Catalogue provider in the requested language when there is a reasons catalogue", func() {
+			reasonsCatalogue := &config.ReasonsCatalogue{}
+
+			So(getReasonProvider(reasonsCatalogue, utils.WelshLanguage), ShouldResemble,
+				&private.CatalogueReasonProvider{Catalogue: reasonsCatalogue, Language: utils.WelshLanguage})
+		})
+	})
+}
+
+func TestUnitGetPayableStatusProvider(t *testing.T) {
+	Convey("Get payable status provider", t, func() {
+		Convey("Default provider when there are no payable status rules", func() {
+			So(getPayableStatusProvider(nil), ShouldResemble, &private.DefaultPayableStatusProvider{})
+		})
+
+		Convey("Rules provider when there are payable status rules", func() {
+			payableStatusRules := &config.PayableStatusRules{DefaultStatus: private.ClosedPayableStatus}
+
+			So(getPayableStatusProvider(payableStatusRules), ShouldResemble, &private.RulesPayableStatusProvider{Rules: payableStatusRules})
+		})
+	})
+}
+
 func assertTransactionListItem(transactionListItem models.TransactionListItem, expectedID string, expectedIsPaid bool, expectedIsDCA bool,
 	expectedDueDate string, expectedMadeUpDate string, expectedTransactionDate string,
 	expectedOriginalAmount float64, expectedOutstandingAmount float64, expectedType string, expectedReason string, expectedPayableStatus string) {
@@ -3,23 +3,25 @@ package api
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api-core/validators"
 	"github.com/companieshouse/penalty-payment-api/common/e5"
 	"github.com/companieshouse/penalty-payment-api/common/services"
-	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
 )
 
-var getCompanyCodeFromTransaction = utils.GetCompanyCodeFromTransaction
+var getCompanyCodeFromTransaction = (*config.PenaltyDetailsMap).GetCompanyCodeFromTransaction
 
 // UpdateIssuerAccountWithPenaltyPaid will update the transactions in E5 as paid.
 // resource - is the payable resource from the db representing the penalty(ies)
 // payment - is the information about the payment session
 func UpdateIssuerAccountWithPenaltyPaid(payableResourceService *services.PayableResourceService,
-	client e5.ClientInterface, resource models.PayableResource, payment validators.PaymentInformation, requestId string) error {
+	client e5.ClientInterface, resource models.PayableResource, payment validators.PaymentInformation,
+	penaltyDetailsMap *config.PenaltyDetailsMap, requestId string) error {
 	log.DebugC(requestId, "converting payment amount from string to float", log.Data{"amount": payment.Amount})
 	amountPaid, err := strconv.ParseFloat(payment.Amount, 32)
 	if err != nil {
@@ -42,7 +44,7 @@ func UpdateIssuerAccountWithPenaltyPaid(payableResourceService *services.Payable
 	paymentID := "X" + payment.PaymentID
 
 	log.DebugC(requestId, "getting company code from transaction", log.Data{"transaction": transactions[0]})
-	companyCode, err := getCompanyCodeFromTransaction(resource.Transactions)
+	companyCode, err := getCompanyCodeFromTransaction(penaltyDetailsMap, resource.Transactions)
 
 	if err != nil {
 		log.ErrorC(requestId, fmt.Errorf("error getting company code from transaction: %v", err))
@@ -115,6 +117,11 @@ func UpdateIssuerAccountWithPenaltyPaid(payableResourceService *services.Payable
 
 	log.InfoC(requestId, "marked penalty transaction(s) as paid in E5", logData)
 
+	// the payment has been taken and E5 updated, so failing to record it only leaves the resource reported as pending
+	if svcErr := RecordIssuerProcessed(payableResourceService, resource, requestId); svcErr != nil {
+		log.ErrorC(requestId, svcErr, log.Data{"payment_id": payment.PaymentID, "payable_ref": resource.PayableRef})
+	}
+
 	return nil
 }
 
@@ -123,3 +130,9 @@ func RecordIssuerCommandError(payableResourceService *services.PayableResourceSe
 	resource models.PayableResource, action e5.Action, requestId string) error {
 	return payableResourceService.DAO.SaveE5Error(resource.CustomerCode, resource.PayableRef, requestId, action)
 }
+
+// RecordIssuerProcessed will mark the resource as having been processed in E5.
+func RecordIssuerProcessed(payableResourceService *services.PayableResourceService,
+	resource models.PayableResource, requestId string) error {
+	return payableResourceService.DAO.SaveE5Processed(resource.CustomerCode, resource.PayableRef, time.Now(), requestId)
+}
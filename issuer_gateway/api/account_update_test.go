@@ -12,6 +12,7 @@ import (
 	"github.com/companieshouse/penalty-payment-api/common/e5"
 	"github.com/companieshouse/penalty-payment-api/common/services"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/jarcoal/httpmock"
@@ -81,12 +82,12 @@ func TestUnitUpdateIssuerAccountWithPenaltyPaid(t *testing.T) {
 		r := generatePayableResource(true)
 		p := generatePaymentInformation(false, false)
 
-		err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, "")
+		err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, &config.PenaltyDetailsMap{}, "")
 		So(err, ShouldNotBeNil)
 	})
 
 	Convey("invalid company code", t, func() {
-		getCompanyCodeFromTransaction = func(transactions []models.TransactionItem) (string, error) {
+		getCompanyCodeFromTransaction = func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 			return "", errors.New("cannot determine company code")
 		}
 
@@ -94,13 +95,13 @@ func TestUnitUpdateIssuerAccountWithPenaltyPaid(t *testing.T) {
 		p := generatePaymentInformation(true, false)
 		r := generatePayableResource(false)
 
-		err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, "")
+		err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, &config.PenaltyDetailsMap{}, "")
 
 		So(err, ShouldBeError, "cannot determine company code")
 	})
 
 	Convey("E5 request errors", t, func() {
-		getCompanyCodeFromTransaction = func(transactions []models.TransactionItem) (string, error) {
+		getCompanyCodeFromTransaction = func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 			return utils.LateFilingPenaltyCompanyCode, nil
 		}
 
@@ -115,7 +116,7 @@ func TestUnitUpdateIssuerAccountWithPenaltyPaid(t *testing.T) {
 			p := generatePaymentInformation(true, false)
 			r := generatePayableResource(false)
 
-			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, "")
+			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, &config.PenaltyDetailsMap{}, "")
 
 			So(err, ShouldBeError, e5.ErrE5BadRequest)
 		})
@@ -133,7 +134,7 @@ func TestUnitUpdateIssuerAccountWithPenaltyPaid(t *testing.T) {
 			p := generatePaymentInformation(true, true)
 			r := generatePayableResource(false)
 
-			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, "")
+			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, &config.PenaltyDetailsMap{}, "")
 
 			So(err, ShouldBeError, e5.ErrE5BadRequest)
 		})
@@ -152,7 +153,7 @@ func TestUnitUpdateIssuerAccountWithPenaltyPaid(t *testing.T) {
 			p := generatePaymentInformation(true, true)
 			r := generatePayableResource(false)
 
-			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, "")
+			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, &config.PenaltyDetailsMap{}, "")
 
 			So(err, ShouldBeError, e5.ErrE5BadRequest)
 		})
@@ -164,11 +165,30 @@ func TestUnitUpdateIssuerAccountWithPenaltyPaid(t *testing.T) {
 			httpmock.RegisterResponder(http.MethodPost, "/arTransactions/payment/authorise", okResponder)
 			httpmock.RegisterResponder(http.MethodPost, "/arTransactions/payment/confirm", okResponder)
 
+			mockPrDaoSvc.EXPECT().SaveE5Processed("10000024", "123", gomock.Any(), "").Return(nil)
+
+			c := &e5.Client{}
+			p := generatePaymentInformation(true, true)
+			r := generatePayableResource(false)
+
+			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, &config.PenaltyDetailsMap{}, "")
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("no errors when E5 succeeds but recording it as processed fails", func() {
+			defer httpmock.Reset()
+			okResponder := httpmock.NewBytesResponder(http.StatusOK, nil)
+			httpmock.RegisterResponder(http.MethodPost, "/arTransactions/payment", okResponder)
+			httpmock.RegisterResponder(http.MethodPost, "/arTransactions/payment/authorise", okResponder)
+			httpmock.RegisterResponder(http.MethodPost, "/arTransactions/payment/confirm", okResponder)
+			mockPrDaoSvc.EXPECT().SaveE5Processed("10000024", "123", gomock.Any(), "").Return(errors.New("error"))
+
 			c := &e5.Client{}
 			p := generatePaymentInformation(true, true)
 			r := generatePayableResource(false)
 
-			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, "")
+			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, &config.PenaltyDetailsMap{}, "")
 
 			So(err, ShouldBeNil)
 		})
@@ -202,12 +222,13 @@ func TestUnitUpdateIssuerAccountWithPenaltyPaid(t *testing.T) {
 			httpmock.RegisterResponder(http.MethodPost, "/arTransactions/payment", paymentIDResponder)
 			httpmock.RegisterResponder(http.MethodPost, "/arTransactions/payment/authorise", paymentIDResponder)
 			httpmock.RegisterResponder(http.MethodPost, "/arTransactions/payment/confirm", paymentIDResponder)
+			mockPrDaoSvc.EXPECT().SaveE5Processed("10000024", "123", gomock.Any(), "").Return(nil)
 
 			c := &e5.Client{}
 			p := generatePaymentInformation(true, true)
 			r := generatePayableResource(false)
 
-			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, "")
+			err := UpdateIssuerAccountWithPenaltyPaid(payableResourceSvc, c, r, p, &config.PenaltyDetailsMap{}, "")
 			So(err, ShouldBeNil)
 
 		})
@@ -76,6 +76,7 @@ func (p PenaltyFinancePayment) ProcessFinancialPenaltyPayment(penaltyPayment mod
 	}
 
 	log.Info("Financial penalty payment processing successful", logContext)
+	saveE5Processed(penaltyPayment, p.PayableResourceDaoService, e5PaymentID)
 	return nil
 }
 
@@ -191,3 +192,17 @@ func saveE5Error(penaltyPayment models.PenaltyPaymentsProcessing, payableResourc
 		log.Error(svcErr, logContext)
 	}
 }
+
+// saveE5Processed records that the payment was processed in E5. A failure is only logged, as E5 has been updated
+// and the message must not be processed again.
+func saveE5Processed(penaltyPayment models.PenaltyPaymentsProcessing, payableResourceDaoService dao.PayableResourceDaoService,
+	e5PaymentID string) {
+	if svcErr := payableResourceDaoService.SaveE5Processed(penaltyPayment.CustomerCode, penaltyPayment.PayableRef, time.Now(), ""); svcErr != nil {
+		log.Error(svcErr, log.Data{
+			"customer_code": penaltyPayment.CustomerCode,
+			"company_code":  penaltyPayment.CompanyCode,
+			"payable_ref":   penaltyPayment.PayableRef,
+			"e5_payment_id": e5PaymentID,
+		})
+	}
+}
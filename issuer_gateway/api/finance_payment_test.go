@@ -77,6 +77,41 @@ func (m *mockDAO) GetPayableResource(customerCode, payableRef, _ string) (*model
 	return nil, errors.New("get payable resource not used")
 }
 
+func (m *mockDAO) GetPaidPayableResources(customerCode, _ string) ([]models.PayableResourceDao, error) {
+	m.Called(customerCode)
+	return nil, errors.New("get paid payable resources not used")
+}
+
+func (m *mockDAO) GetPendingPayableResources(customerCode string, penaltyRefs []string, _ time.Time, _ string) ([]models.PayableResourceDao, error) {
+	m.Called(customerCode, penaltyRefs)
+	return nil, errors.New("get pending payable resources not used")
+}
+
+func (m *mockDAO) GetPayableResourceInPayment(customerCode string, penaltyRefs []string, _ time.Time, _ string) (*models.PayableResourceDao, error) {
+	m.Called(customerCode, penaltyRefs)
+	return nil, errors.New("get payable resource in payment not used")
+}
+
+func (m *mockDAO) SetPaymentStarted(customerCode, payableRef string, _ time.Time, _ string) error {
+	m.Called(customerCode, payableRef)
+	return errors.New("set payment started not used")
+}
+
+func (m *mockDAO) CancelPayableResource(customerCode, payableRef, _ string, _ time.Time, _ string) (bool, error) {
+	m.Called(customerCode, payableRef)
+	return false, errors.New("cancel payable resource not used")
+}
+
+func (m *mockDAO) ExpirePayableResource(customerCode, payableRef string, _, _ time.Time, _ string) (bool, error) {
+	m.Called(customerCode, payableRef)
+	return false, errors.New("expire payable resource not used")
+}
+
+func (m *mockDAO) ExpirePayableResources(_, _ time.Time, _ string) (int64, error) {
+	m.Called()
+	return 0, errors.New("expire payable resources not used")
+}
+
 func (m *mockDAO) UpdatePaymentDetails(dao *models.PayableResourceDao, _ string) error {
 	m.Called(dao)
 	return errors.New("update payment details not used")
@@ -90,6 +125,10 @@ func (m *mockDAO) SaveE5Error(customerCode, payableRef, _ string, action e5.Acti
 	return m.Called(customerCode, payableRef, action).Error(0)
 }
 
+func (m *mockDAO) SaveE5Processed(customerCode, payableRef string, _ time.Time, _ string) error {
+	return m.Called(customerCode, payableRef).Error(0)
+}
+
 func TestUnitProcessFinancialPenaltyPayment_IsAfter24Hours(t *testing.T) {
 	Convey("Process financial penalty payment is after 24 hours", t, func() {
 		// Given
@@ -117,6 +156,7 @@ func TestUnitProcessFinancialPenaltyPayment_Success(t *testing.T) {
 		e5Client.On("CreatePayment", mock.Anything).Return(nil)
 		e5Client.On("AuthorisePayment", mock.Anything).Return(nil)
 		e5Client.On("ConfirmPayment", mock.Anything).Return(nil)
+		DAO.On("SaveE5Processed", penaltyPayment.CustomerCode, penaltyPayment.PayableRef).Return(nil)
 
 		// When
 		err := handler.ProcessFinancialPenaltyPayment(penaltyPayment, e5PaymentID, cfg, false)
@@ -124,10 +164,31 @@ func TestUnitProcessFinancialPenaltyPayment_Success(t *testing.T) {
 		// Then
 		So(err, ShouldBeNil)
 		e5Client.AssertExpectations(t)
+		DAO.AssertExpectations(t)
 		DAO.AssertNotCalled(t, "SaveE5Error", mock.Anything)
 	})
 }
 
+func TestUnitProcessFinancialPenaltyPayment_SaveE5ProcessedFails(t *testing.T) {
+	Convey("Process financial penalty payment is not retried when recording it as processed fails", t, func() {
+		// Given
+		e5Client, DAO, handler := financePaymentTestSetup()
+
+		e5Client.On("CreatePayment", mock.Anything).Return(nil)
+		e5Client.On("AuthorisePayment", mock.Anything).Return(nil)
+		e5Client.On("ConfirmPayment", mock.Anything).Return(nil)
+		DAO.On("SaveE5Processed", penaltyPayment.CustomerCode, penaltyPayment.PayableRef).Return(errors.New("error updating payable resource"))
+
+		// When
+		err := handler.ProcessFinancialPenaltyPayment(penaltyPayment, e5PaymentID, cfg, false)
+
+		// Then
+		So(err, ShouldBeNil)
+		e5Client.AssertExpectations(t)
+		DAO.AssertExpectations(t)
+	})
+}
+
 func TestUnitProcessFinancialPenaltyPayment_CreatePaymentFails(t *testing.T) {
 	Convey("Process financial penalty payment create payment fails", t, func() {
 		// Given
@@ -191,6 +252,7 @@ func TestUnitProcessFinancialPenaltyPayment_Retry_Success(t *testing.T) {
 	e5Client.On("CreatePayment", mock.Anything).Return(nil)
 	e5Client.On("AuthorisePayment", mock.Anything).Return(nil)
 	e5Client.On("ConfirmPayment", mock.Anything).Return(nil)
+	DAO.On("SaveE5Processed", penaltyPayment2.CustomerCode, penaltyPayment2.PayableRef).Return(nil)
 
 	Convey("Process financial penalty payment retry success with Attempt = 2", t, func() {
 		// When
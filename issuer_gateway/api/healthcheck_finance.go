@@ -1,87 +1,142 @@
 package api
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/penalty-payment-api/config"
 )
 
-func CheckScheduledMaintenance(requestId string) (systemAvailableTime time.Time, systemUnavailable bool, parseError bool) {
-	cfg, err := config.Get()
+const (
+	weeklyMaintenanceName  = "Weekly maintenance"
+	plannedMaintenanceName = "Planned maintenance"
+)
+
+// MaintenanceStatus holds the maintenance window in progress, if there is one, and the next window
+type MaintenanceStatus struct {
+	Current *config.MaintenanceWindow
+	Next    *config.MaintenanceWindow
+}
+
+// ErrMaintenanceScheduleNotLoaded is returned when the maintenance schedule is checked before it is loaded
+var ErrMaintenanceScheduleNotLoaded = errors.New("the maintenance schedule has not been loaded")
+
+// loadedMaintenanceSchedule is the maintenance schedule loaded at startup by LoadMaintenanceSchedule
+var loadedMaintenanceSchedule atomic.Pointer[config.MaintenanceSchedule]
+
+// LoadMaintenanceSchedule loads and validates the maintenance schedule once, so that the schedule and bank
+// holidays files are not read on every check and an invalid file stops the service starting rather than
+// maintenance being ignored
+func LoadMaintenanceSchedule(cfg *config.Config) error {
+	maintenanceSchedule, err := GetMaintenanceSchedule(cfg)
 	if err != nil {
-		err = fmt.Errorf("error getting config for planned maintenance: [%v]", err)
-		log.ErrorC(requestId, err)
-		return time.Time{}, false, true
+		return fmt.Errorf("error loading maintenance schedule: %v", err)
 	}
+	loadedMaintenanceSchedule.Store(maintenanceSchedule)
+	return nil
+}
 
-	currentTime := time.Now()
-
-	systemAvailableTime, systemUnavailable = checkWeeklyDownTime(cfg, currentTime)
+// CheckScheduledMaintenance checks the loaded maintenance schedule for the window in progress at the supplied
+// time and the next window after it. The windows stored in mongo are read through the cache, if they
+// cannot be read only the config based windows are checked.
+func CheckScheduledMaintenance(requestId string, now time.Time, maintenanceWindowsCache *MaintenanceWindowsCache) (MaintenanceStatus, error) {
+	loaded := loadedMaintenanceSchedule.Load()
+	if loaded == nil {
+		log.ErrorC(requestId, ErrMaintenanceScheduleNotLoaded)
+		return MaintenanceStatus{}, ErrMaintenanceScheduleNotLoaded
+	}
+	// the managed windows are added to a copy, as the loaded schedule is shared between requests
+	maintenanceSchedule := *loaded
 
-	if isPlannedMaintenanceCheckRequired(cfg) {
-		timeDateLayout := time.RFC822
-		maintenanceStart, err := time.Parse(timeDateLayout, cfg.PlannedMaintenanceStart)
+	if maintenanceWindowsCache != nil {
+		maintenanceWindows, err := maintenanceWindowsCache.Get(requestId, now)
 		if err != nil {
-			log.ErrorC(requestId, fmt.Errorf("error parsing Maintenance Start time: [%v]", err))
-			return time.Time{}, false, true
-		}
-		maintenanceEnd, err := time.Parse(timeDateLayout, cfg.PlannedMaintenanceEnd)
-		if err != nil {
-			log.ErrorC(requestId, fmt.Errorf("error parsing Maintenance End time: [%v]", err))
-			return time.Time{}, false, true
+			log.ErrorC(requestId, fmt.Errorf("error getting maintenance windows, falling back to cached and config maintenance windows: [%v]", err))
 		}
+		maintenanceSchedule.Managed = maintenanceWindows
+	}
 
-		if maintenanceEnd.After(currentTime) && maintenanceStart.Before(currentTime) && maintenanceEnd.After(systemAvailableTime) {
-			systemAvailableTime = maintenanceEnd
-			systemUnavailable = true
-		}
+	var status MaintenanceStatus
+	if current, ok := maintenanceSchedule.ActiveWindow(now); ok {
+		status.Current = &current
+	}
+	if next, ok := maintenanceSchedule.NextWindow(now); ok {
+		status.Next = &next
 	}
-	return systemAvailableTime, systemUnavailable, false
+
+	return status, nil
 }
 
-func checkWeeklyDownTime(cfg *config.Config, currentTime time.Time) (systemAvailableTime time.Time, systemUnavailable bool) {
+// CurrentMaintenanceWindow returns the maintenance window in progress at the supplied time, or nil if the
+// finance system is available. If the maintenance schedule cannot be checked the finance system is treated
+// as available, so that a missing schedule does not stop payments being taken.
+func CurrentMaintenanceWindow(requestId string, now time.Time, maintenanceWindowsCache *MaintenanceWindowsCache) *config.MaintenanceWindow {
+	maintenanceStatus, err := CheckScheduledMaintenance(requestId, now, maintenanceWindowsCache)
+	if err != nil {
+		log.ErrorC(requestId, fmt.Errorf("error checking scheduled maintenance, treating the finance system as available: [%v]", err))
+		return nil
+	}
+	return maintenanceStatus.Current
+}
+
+// GetMaintenanceSchedule builds the maintenance schedule from the schedule and bank holidays files, and
+// the weekly and planned maintenance config values
+func GetMaintenanceSchedule(cfg *config.Config) (*config.MaintenanceSchedule, error) {
+	maintenanceSchedule := &config.MaintenanceSchedule{}
+	if cfg.MaintenanceScheduleFile != "" {
+		var err error
+		maintenanceSchedule, err = config.LoadMaintenanceSchedule(cfg.MaintenanceScheduleFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if isWeeklyMaintenanceTimeCheckRequired(cfg) {
-		// If the weekday is maintenance day
-		if currentTime.Weekday() == cfg.WeeklyMaintenanceDay {
+		maintenanceSchedule.Recurring = append(maintenanceSchedule.Recurring, config.RecurringMaintenanceWindow{
+			Name:  weeklyMaintenanceName,
+			Days:  []string{strings.ToLower(cfg.WeeklyMaintenanceDay.String())},
+			Start: toClockTime(cfg.WeeklyMaintenanceStartTime),
+			End:   toClockTime(cfg.WeeklyMaintenanceEndTime),
+		})
+	}
 
-			weeklyMaintenanceStartTime := returnWeeklyMaintenanceTime(currentTime, cfg.WeeklyMaintenanceStartTime[:2], cfg.WeeklyMaintenanceStartTime[2:])
+	if isPlannedMaintenanceCheckRequired(cfg) {
+		maintenanceSchedule.OneOff = append(maintenanceSchedule.OneOff, config.OneOffMaintenanceWindow{
+			Name:  plannedMaintenanceName,
+			Start: cfg.PlannedMaintenanceStart,
+			End:   cfg.PlannedMaintenanceEnd,
+		})
+	}
 
-			weeklyMaintenanceEndTime := returnWeeklyMaintenanceTime(currentTime, cfg.WeeklyMaintenanceEndTime[:2], cfg.WeeklyMaintenanceEndTime[2:])
+	if err := config.ValidateMaintenanceSchedule(maintenanceSchedule); err != nil {
+		return nil, err
+	}
 
-			// Check if time is within maintenance time
-			if isWithinMaintenanceTime(weeklyMaintenanceEndTime, currentTime, weeklyMaintenanceStartTime) {
-				systemAvailableTime = weeklyMaintenanceEndTime
-				systemUnavailable = true
-			}
+	if cfg.MaintenanceBankHolidaysFile != "" {
+		bankHolidays, err := config.LoadBankHolidays(cfg.MaintenanceBankHolidaysFile)
+		if err != nil {
+			return nil, err
 		}
+		maintenanceSchedule.BankHolidays = bankHolidays
 	}
-	return systemAvailableTime, systemUnavailable
+
+	return maintenanceSchedule, nil
 }
 
 func isWeeklyMaintenanceTimeCheckRequired(cfg *config.Config) bool {
 	return cfg.WeeklyMaintenanceStartTime != "" && cfg.WeeklyMaintenanceEndTime != ""
 }
 
-// returnWeeklyMaintenanceTime returns a time.Time format for the current date with the hour and minute set to the arguments passed
-func returnWeeklyMaintenanceTime(currentTime time.Time, hour, minute string) time.Time {
-
-	intHour, _ := strconv.Atoi(hour)
-	timeDifferenceInHours := time.Duration(intHour - currentTime.Hour())
-
-	intMinute, _ := strconv.Atoi(minute)
-	timeDifferenceInMinutes := time.Duration(intMinute - currentTime.Minute())
-
-	secondDuration := time.Duration(0 - currentTime.Second())
-	nanosecondDuration := time.Duration(0 - currentTime.Nanosecond())
-
-	return currentTime.Add(time.Hour*timeDifferenceInHours + time.Minute*timeDifferenceInMinutes + time.Second*secondDuration + time.Nanosecond*nanosecondDuration).Round(0)
-}
-
-func isWithinMaintenanceTime(weeklyMaintenanceEndTime time.Time, currentTime time.Time, weeklyMaintenanceStartTime time.Time) bool {
-	return weeklyMaintenanceEndTime.After(currentTime) && weeklyMaintenanceStartTime.Before(currentTime)
+// toClockTime converts a weekly maintenance time such as 1900 to the 19:00 format of the schedule
+func toClockTime(hhmm string) string {
+	if len(hhmm) != 4 {
+		return hhmm
+	}
+	return hhmm[:2] + ":" + hhmm[2:]
 }
 
 func isPlannedMaintenanceCheckRequired(cfg *config.Config) bool {
@@ -1,287 +1,342 @@
 package api
 
 import (
-	"fmt"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/companieshouse/penalty-payment-api/common/dao"
 	"github.com/companieshouse/penalty-payment-api/config"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// sundayEvening is 19:10 UK time on Sunday 16 March 2025
+var sundayEvening = time.Date(2025, time.March, 16, 19, 10, 0, 0, config.MaintenanceLocation)
+
+func resetMaintenanceConfig(cfg *config.Config) {
+	cfg.WeeklyMaintenanceStartTime = ""
+	cfg.WeeklyMaintenanceEndTime = ""
+	cfg.WeeklyMaintenanceDay = time.Sunday
+	cfg.PlannedMaintenanceStart = ""
+	cfg.PlannedMaintenanceEnd = ""
+	cfg.MaintenanceScheduleFile = ""
+	cfg.MaintenanceBankHolidaysFile = ""
+}
+
 func TestUnitCheckScheduledMaintenance(t *testing.T) {
 	cfg, _ := config.Get()
-	currentTime := time.Now()
+	defer resetMaintenanceConfig(cfg)
 
 	Convey("No maintenance config", t, func() {
 		// Given
+		resetMaintenanceConfig(cfg)
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, nil)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Time{})
-		So(gotSystemUnavailable, ShouldBeFalse)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current, ShouldBeNil)
+		So(gotStatus.Next, ShouldBeNil)
 	})
 
 	Convey("Current time is before weekly maintenance times", t, func() {
 		// Given
-		startHour := currentTime.Hour() - 2
-		endHour := startHour + 1
-		cfg.WeeklyMaintenanceStartTime = fmt.Sprintf("%02d00", startHour)
-		cfg.WeeklyMaintenanceEndTime = fmt.Sprintf("%02d00", endHour)
-		cfg.WeeklyMaintenanceDay = currentTime.Weekday()
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "2000"
+		cfg.WeeklyMaintenanceEndTime = "2030"
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, nil)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Time{})
-		So(gotSystemUnavailable, ShouldBeFalse)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current, ShouldBeNil)
+		So(gotStatus.Next.Start, ShouldEqual, time.Date(2025, time.March, 16, 20, 0, 0, 0, config.MaintenanceLocation))
+		So(gotStatus.Next.End, ShouldEqual, time.Date(2025, time.March, 16, 20, 30, 0, 0, config.MaintenanceLocation))
 	})
 
 	Convey("Current time is during weekly maintenance times", t, func() {
 		// Given
-		endHour := currentTime.Hour() + 1
-		cfg.WeeklyMaintenanceStartTime = fmt.Sprintf("%02d00", currentTime.Hour())
-		cfg.WeeklyMaintenanceEndTime = fmt.Sprintf("%02d00", endHour)
-		cfg.WeeklyMaintenanceDay = currentTime.Weekday()
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "1900"
+		cfg.WeeklyMaintenanceEndTime = "1930"
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, nil)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), endHour, 0, 0, 0, currentTime.Location()))
-		So(gotSystemUnavailable, ShouldBeTrue)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current.Name, ShouldEqual, weeklyMaintenanceName)
+		So(gotStatus.Current.End, ShouldEqual, time.Date(2025, time.March, 16, 19, 30, 0, 0, config.MaintenanceLocation))
+		So(gotStatus.Next.Start, ShouldEqual, time.Date(2025, time.March, 23, 19, 0, 0, 0, config.MaintenanceLocation))
 	})
 
-	Convey("Current time is after weekly maintenance times", t, func() {
+	Convey("Current time is during weekly maintenance that crosses midnight", t, func() {
 		// Given
-		startHour := currentTime.Hour() + 1
-		endHour := currentTime.Hour() + 2
-		cfg.WeeklyMaintenanceStartTime = fmt.Sprintf("%02d00", startHour)
-		cfg.WeeklyMaintenanceEndTime = fmt.Sprintf("%02d00", endHour)
-		cfg.WeeklyMaintenanceDay = currentTime.Weekday()
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "2300"
+		cfg.WeeklyMaintenanceEndTime = "0100"
+		cfg.WeeklyMaintenanceDay = time.Saturday
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", time.Date(2025, time.March, 16, 0, 30, 0, 0, config.MaintenanceLocation), nil)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Time{})
-		So(gotSystemUnavailable, ShouldBeFalse)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current.End, ShouldEqual, time.Date(2025, time.March, 16, 1, 0, 0, 0, config.MaintenanceLocation))
 	})
 
-	Convey("Planned maintenance times in wrong format", t, func() {
+	Convey("Weekly maintenance time in wrong format", t, func() {
 		// Given
-		cfg.WeeklyMaintenanceStartTime = "1900"
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "19"
 		cfg.WeeklyMaintenanceEndTime = "1930"
-		cfg.WeeklyMaintenanceDay = time.Sunday
-		startTime := currentTime.Add(time.Hour * -4).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceStart = startTime.Format(time.RFC3339)
-		endTime := currentTime.Add(time.Hour * -3).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceEnd = endTime.Format(time.RFC3339)
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		gotErr := LoadMaintenanceSchedule(cfg)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Time{})
-		So(gotSystemUnavailable, ShouldBeFalse)
-		So(gotParseError, ShouldBeTrue)
+		So(gotErr, ShouldNotBeNil)
 	})
 
-	Convey("Planned maintenance end time is in wrong format", t, func() {
+	Convey("Planned maintenance start time in wrong format", t, func() {
 		// Given
-		weeklyEndHour := currentTime.Hour() + 2
-		cfg.WeeklyMaintenanceStartTime = fmt.Sprintf("%02d00", currentTime.Hour())
-		cfg.WeeklyMaintenanceEndTime = fmt.Sprintf("%02d00", weeklyEndHour)
-		cfg.WeeklyMaintenanceDay = currentTime.Weekday()
-		plannedStartTime := currentTime.Add(time.Hour * -1).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceStart = plannedStartTime.Format(time.RFC822)
-		cfg.PlannedMaintenanceEnd = "1111111111"
+		resetMaintenanceConfig(cfg)
+		cfg.PlannedMaintenanceStart = "invalid"
+		cfg.PlannedMaintenanceEnd = "16 Mar 25 20:00 GMT"
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		gotErr := LoadMaintenanceSchedule(cfg)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Time{})
-		So(gotSystemUnavailable, ShouldBeFalse)
-		So(gotParseError, ShouldBeTrue)
+		So(gotErr, ShouldNotBeNil)
 	})
 
-	Convey("Current time is before planned maintenance times", t, func() {
+	Convey("Planned maintenance end time is in wrong format", t, func() {
 		// Given
-		cfg.WeeklyMaintenanceStartTime = "1900"
-		cfg.WeeklyMaintenanceEndTime = "1930"
-		cfg.WeeklyMaintenanceDay = time.Sunday
-		startTime := currentTime.Add(time.Hour * 3).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceStart = startTime.Format(time.RFC822)
-		endTime := currentTime.Add(time.Hour * 4).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceEnd = endTime.Format(time.RFC822)
+		resetMaintenanceConfig(cfg)
+		cfg.PlannedMaintenanceStart = "16 Mar 25 19:00 GMT"
+		cfg.PlannedMaintenanceEnd = "1111111111"
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		gotErr := LoadMaintenanceSchedule(cfg)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Time{})
-		So(gotSystemUnavailable, ShouldBeFalse)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldNotBeNil)
 	})
 
 	Convey("Current time is during planned maintenance times", t, func() {
 		// Given
-		cfg.WeeklyMaintenanceStartTime = "1900"
-		cfg.WeeklyMaintenanceEndTime = "1930"
-		cfg.WeeklyMaintenanceDay = time.Sunday
-		startTime := currentTime.Add(time.Minute * -30).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceStart = startTime.Format(time.RFC822)
-		endTime := currentTime.Add(time.Minute * 30).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceEnd = endTime.Format(time.RFC822)
+		resetMaintenanceConfig(cfg)
+		cfg.PlannedMaintenanceStart = "16 Mar 25 18:00 GMT"
+		cfg.PlannedMaintenanceEnd = "16 Mar 25 20:00 GMT"
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, nil)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, endTime)
-		So(gotSystemUnavailable, ShouldBeTrue)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current.Name, ShouldEqual, plannedMaintenanceName)
+		So(gotStatus.Current.End, ShouldEqual, time.Date(2025, time.March, 16, 20, 0, 0, 0, config.MaintenanceLocation))
+		So(gotStatus.Next, ShouldBeNil)
 	})
 
 	Convey("Current time is after planned maintenance times", t, func() {
 		// Given
-		cfg.WeeklyMaintenanceStartTime = "1900"
-		cfg.WeeklyMaintenanceEndTime = "1930"
-		cfg.WeeklyMaintenanceDay = time.Sunday
-		startTime := currentTime.Add(time.Hour * -4).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceStart = startTime.Format(time.RFC822)
-		endTime := currentTime.Add(time.Hour * -3).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceEnd = endTime.Format(time.RFC822)
+		resetMaintenanceConfig(cfg)
+		cfg.PlannedMaintenanceStart = "16 Mar 25 15:00 GMT"
+		cfg.PlannedMaintenanceEnd = "16 Mar 25 16:00 GMT"
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, nil)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Time{})
-		So(gotSystemUnavailable, ShouldBeFalse)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current, ShouldBeNil)
+		So(gotStatus.Next, ShouldBeNil)
 	})
 
-	Convey("Current time is during scheduled maintenance times, planned ends later", t, func() {
+	Convey("Current time is during weekly maintenance, planned ends later", t, func() {
 		// Given
-		weeklyEndHour := currentTime.Hour() + 1
-		cfg.WeeklyMaintenanceStartTime = fmt.Sprintf("%02d00", currentTime.Hour())
-		cfg.WeeklyMaintenanceEndTime = fmt.Sprintf("%02d00", weeklyEndHour)
-		cfg.WeeklyMaintenanceDay = currentTime.Weekday()
-		plannedStartTime := currentTime.Add(time.Minute * -1).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceStart = plannedStartTime.Format(time.RFC822)
-		plannedEndTime := currentTime.Add(time.Hour * 2).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceEnd = plannedEndTime.Format(time.RFC822)
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "1900"
+		cfg.WeeklyMaintenanceEndTime = "1930"
+		cfg.PlannedMaintenanceStart = "16 Mar 25 19:15 GMT"
+		cfg.PlannedMaintenanceEnd = "16 Mar 25 21:00 GMT"
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, nil)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, plannedEndTime)
-		So(gotSystemUnavailable, ShouldBeTrue)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current.End, ShouldEqual, time.Date(2025, time.March, 16, 21, 0, 0, 0, config.MaintenanceLocation))
 	})
 
-	Convey("Current time is during scheduled maintenance times, planned ends earlier", t, func() {
+	Convey("Current time is during weekly maintenance, planned ends earlier", t, func() {
 		// Given
-		weeklyEndHour := currentTime.Hour() + 2
-		cfg.WeeklyMaintenanceStartTime = fmt.Sprintf("%02d00", currentTime.Hour())
-		cfg.WeeklyMaintenanceEndTime = fmt.Sprintf("%02d00", weeklyEndHour)
-		cfg.WeeklyMaintenanceDay = currentTime.Weekday()
-		plannedStartTime := currentTime.Add(time.Hour * -1).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceStart = plannedStartTime.Format(time.RFC822)
-		plannedEndTime := currentTime.Add(time.Hour * 1).Truncate(time.Minute).Round(0)
-		cfg.PlannedMaintenanceEnd = plannedEndTime.Format(time.RFC822)
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "1900"
+		cfg.WeeklyMaintenanceEndTime = "1930"
+		cfg.PlannedMaintenanceStart = "16 Mar 25 18:00 GMT"
+		cfg.PlannedMaintenanceEnd = "16 Mar 25 19:20 GMT"
 
 		// When
-		gotSystemAvailableTime, gotSystemUnavailable, gotParseError := CheckScheduledMaintenance("")
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, nil)
 
 		// Then
-		So(gotSystemAvailableTime, ShouldEqual, time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), weeklyEndHour, 0, 0, 0, currentTime.Location()))
-		So(gotSystemUnavailable, ShouldBeTrue)
-		So(gotParseError, ShouldBeFalse)
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current.End, ShouldEqual, time.Date(2025, time.March, 16, 19, 30, 0, 0, config.MaintenanceLocation))
 	})
 }
 
-func TestUnit_checkWeeklyDownTime(t *testing.T) {
-	Convey("Check weekly down time", t, func() {
-		type args struct {
-			cfg         *config.Config
-			currentTime time.Time
-		}
-		now := time.Now()
+func TestUnitCheckScheduledMaintenanceWithMaintenanceWindows(t *testing.T) {
+	cfg, _ := config.Get()
+	defer resetMaintenanceConfig(cfg)
 
-		testCases := []struct {
-			name                    string
-			args                    args
-			wantSystemAvailableTime time.Time
-			wantSystemUnavailable   bool
-		}{
-			{
-				name: "No config",
-				args: args{
-					cfg:         &config.Config{},
-					currentTime: time.Time{},
-				},
-				wantSystemAvailableTime: time.Time{},
-				wantSystemUnavailable:   false,
-			},
-			{
-				name: "Current time is before weekly maintenance times",
-				args: args{
-					cfg: &config.Config{
-						WeeklyMaintenanceStartTime: "1900",
-						WeeklyMaintenanceEndTime:   "1930",
-						WeeklyMaintenanceDay:       now.Weekday(),
-					},
-					currentTime: time.Date(now.Year(), now.Month(), now.Day(), 18, 50, 0, 0, now.Location()),
-				},
-				wantSystemAvailableTime: time.Time{},
-				wantSystemUnavailable:   false,
-			},
-			{
-				name: "Current time is during weekly maintenance times",
-				args: args{
-					cfg: &config.Config{
-						WeeklyMaintenanceStartTime: "1900",
-						WeeklyMaintenanceEndTime:   "1930",
-						WeeklyMaintenanceDay:       now.Weekday(),
-					},
-					currentTime: time.Date(now.Year(), now.Month(), now.Day(), 19, 5, 0, 0, now.Location()),
-				},
-				wantSystemAvailableTime: time.Date(now.Year(), now.Month(), now.Day(), 19, 30, 0, 0, now.Location()),
-				wantSystemUnavailable:   true,
-			},
-			{
-				name: "Current time is after weekly maintenance times",
-				args: args{
-					cfg: &config.Config{
-						WeeklyMaintenanceStartTime: "1900",
-						WeeklyMaintenanceEndTime:   "1930",
-						WeeklyMaintenanceDay:       now.Weekday(),
-					},
-					currentTime: time.Date(now.Year(), now.Month(), now.Day(), 19, 40, 0, 0, now.Location()),
-				},
-				wantSystemAvailableTime: time.Time{},
-				wantSystemUnavailable:   false,
+	Convey("Maintenance windows stored in mongo are checked with the config windows", t, func() {
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "1900"
+		cfg.WeeklyMaintenanceEndTime = "1930"
+		maintenanceWindowsCache := NewMaintenanceWindowsCache(&fakeMaintenanceWindowDaoService{
+			windows: []dao.MaintenanceWindowDao{
+				{Name: "E5 upgrade", StartTime: time.Date(2025, time.March, 16, 19, 30, 0, 0, time.UTC), EndTime: time.Date(2025, time.March, 16, 21, 0, 0, 0, time.UTC)},
 			},
-		}
-		for _, tc := range testCases {
-			Convey(tc.name, func() {
-				gotSystemAvailableTime, gotSystemUnavailable := checkWeeklyDownTime(tc.args.cfg, tc.args.currentTime)
+		}, time.Minute)
+
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, maintenanceWindowsCache)
+
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current.End, ShouldEqual, time.Date(2025, time.March, 16, 21, 0, 0, 0, config.MaintenanceLocation))
+
+		Convey("And the stored windows are not added to the loaded schedule", func() {
+			gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, nil)
+
+			So(gotErr, ShouldBeNil)
+			So(gotStatus.Current.End, ShouldEqual, time.Date(2025, time.March, 16, 19, 30, 0, 0, config.MaintenanceLocation))
+		})
+	})
+
+	Convey("The config windows are checked when the maintenance windows cannot be read", t, func() {
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "1900"
+		cfg.WeeklyMaintenanceEndTime = "1930"
+		maintenanceWindowsCache := NewMaintenanceWindowsCache(&fakeMaintenanceWindowDaoService{err: errors.New("mongo unavailable")}, time.Minute)
+
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		gotStatus, gotErr := CheckScheduledMaintenance("", sundayEvening, maintenanceWindowsCache)
+
+		So(gotErr, ShouldBeNil)
+		So(gotStatus.Current.End, ShouldEqual, time.Date(2025, time.March, 16, 19, 30, 0, 0, config.MaintenanceLocation))
+	})
+}
+
+func TestUnitCurrentMaintenanceWindow(t *testing.T) {
+	cfg, _ := config.Get()
+	defer resetMaintenanceConfig(cfg)
+
+	Convey("The maintenance window in progress is returned", t, func() {
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "1900"
+		cfg.WeeklyMaintenanceEndTime = "1930"
 
-				So(gotSystemAvailableTime, ShouldEqual, tc.wantSystemAvailableTime)
-				So(gotSystemUnavailable, ShouldEqual, tc.wantSystemUnavailable)
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+		got := CurrentMaintenanceWindow("", sundayEvening, nil)
+
+		So(got.Name, ShouldEqual, weeklyMaintenanceName)
+		So(got.End, ShouldEqual, time.Date(2025, time.March, 16, 19, 30, 0, 0, config.MaintenanceLocation))
+	})
+
+	Convey("Nil is returned outside a maintenance window", t, func() {
+		resetMaintenanceConfig(cfg)
+		cfg.WeeklyMaintenanceStartTime = "2000"
+		cfg.WeeklyMaintenanceEndTime = "2030"
+		So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+
+		So(CurrentMaintenanceWindow("", sundayEvening, nil), ShouldBeNil)
+	})
+
+	Convey("Nil is returned when the maintenance schedule has not been loaded", t, func() {
+		loadedMaintenanceSchedule.Store(nil)
+
+		So(CurrentMaintenanceWindow("", sundayEvening, nil), ShouldBeNil)
+	})
+}
+
+func TestUnitGetMaintenanceSchedule(t *testing.T) {
+	Convey("Get maintenance schedule", t, func() {
+		dir := t.TempDir()
+		scheduleFile := filepath.Join(dir, "maintenance_schedule.yml")
+		So(os.WriteFile(scheduleFile, []byte(`
+recurring:
+  - name: Nightly
+    start: "23:30"
+    end: "00:30"
+`), 0o644), ShouldBeNil)
+		bankHolidaysFile := filepath.Join(dir, "bank-holidays.ics")
+		So(os.WriteFile(bankHolidaysFile, []byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20250418\nSUMMARY:Good Friday\nEND:VEVENT\nEND:VCALENDAR\n"), 0o644), ShouldBeNil)
+
+		Convey("Schedule, bank holidays and weekly maintenance are combined", func() {
+			cfg := &config.Config{
+				MaintenanceScheduleFile:     scheduleFile,
+				MaintenanceBankHolidaysFile: bankHolidaysFile,
+				WeeklyMaintenanceStartTime:  "1900",
+				WeeklyMaintenanceEndTime:    "1930",
+				WeeklyMaintenanceDay:        time.Sunday,
+			}
+
+			maintenanceSchedule, err := GetMaintenanceSchedule(cfg)
+
+			So(err, ShouldBeNil)
+			So(maintenanceSchedule.Recurring, ShouldHaveLength, 2)
+			So(maintenanceSchedule.Recurring[1], ShouldResemble, config.RecurringMaintenanceWindow{
+				Name: weeklyMaintenanceName, Days: []string{"sunday"}, Start: "19:00", End: "19:30",
 			})
-		}
+			So(maintenanceSchedule.BankHolidays, ShouldHaveLength, 1)
+		})
+
+		Convey("The loaded schedule is checked without reading the files again", func() {
+			cfg := &config.Config{MaintenanceScheduleFile: scheduleFile, MaintenanceBankHolidaysFile: bankHolidaysFile}
+			So(LoadMaintenanceSchedule(cfg), ShouldBeNil)
+			So(os.Remove(scheduleFile), ShouldBeNil)
+			So(os.Remove(bankHolidaysFile), ShouldBeNil)
+
+			gotStatus, gotErr := CheckScheduledMaintenance("", time.Date(2025, time.March, 16, 23, 45, 0, 0, config.MaintenanceLocation), nil)
+
+			So(gotErr, ShouldBeNil)
+			So(gotStatus.Current.Name, ShouldEqual, "Nightly")
+		})
+
+		Convey("An invalid schedule file is not loaded", func() {
+			So(os.WriteFile(scheduleFile, []byte("recurring:\n  - name: Nightly\n    start: \"25:00\"\n    end: \"00:30\"\n"), 0o644), ShouldBeNil)
+
+			So(LoadMaintenanceSchedule(&config.Config{MaintenanceScheduleFile: scheduleFile}), ShouldNotBeNil)
+		})
+
+		Convey("Missing schedule file", func() {
+			maintenanceSchedule, err := GetMaintenanceSchedule(&config.Config{MaintenanceScheduleFile: filepath.Join(dir, "missing.yml")})
+
+			So(maintenanceSchedule, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Missing bank holidays file", func() {
+			maintenanceSchedule, err := GetMaintenanceSchedule(&config.Config{MaintenanceBankHolidaysFile: filepath.Join(dir, "missing.ics")})
+
+			So(maintenanceSchedule, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
 	})
 }
 
@@ -362,95 +417,10 @@ func TestUnit_isWeeklyMaintenanceTimeCheckRequired(t *testing.T) {
 	})
 }
 
-func TestUnit_isWithinMaintenanceTime(t *testing.T) {
-	Convey("Is within maintenance time", t, func() {
-		type args struct {
-			weeklyMaintenanceEndTime   time.Time
-			currentTime                time.Time
-			weeklyMaintenanceStartTime time.Time
-		}
-		testCases := []struct {
-			name string
-			args args
-			want bool
-		}{
-			{
-				name: "Current time is before weekly maintenance times",
-				args: args{
-					weeklyMaintenanceEndTime:   time.Date(2025, 2, 9, 19, 30, 0, 0, time.UTC),
-					currentTime:                time.Date(2025, 2, 6, 19, 5, 0, 0, time.UTC),
-					weeklyMaintenanceStartTime: time.Date(2025, 2, 9, 19, 0, 0, 0, time.UTC),
-				},
-				want: false,
-			},
-			{
-				name: "Current time is during weekly maintenance times",
-				args: args{
-					weeklyMaintenanceEndTime:   time.Date(2025, 2, 9, 19, 30, 0, 0, time.UTC),
-					currentTime:                time.Date(2025, 2, 9, 19, 5, 0, 0, time.UTC),
-					weeklyMaintenanceStartTime: time.Date(2025, 2, 9, 19, 0, 0, 0, time.UTC),
-				},
-				want: true,
-			},
-			{
-				name: "Current time is after weekly maintenance times",
-				args: args{
-					weeklyMaintenanceEndTime:   time.Date(2025, 2, 9, 19, 30, 0, 0, time.UTC),
-					currentTime:                time.Date(2025, 2, 10, 19, 5, 0, 0, time.UTC),
-					weeklyMaintenanceStartTime: time.Date(2025, 2, 9, 19, 0, 0, 0, time.UTC),
-				},
-				want: false,
-			},
-		}
-		for _, tc := range testCases {
-			Convey(tc.name, func() {
-				got := isWithinMaintenanceTime(tc.args.weeklyMaintenanceEndTime, tc.args.currentTime, tc.args.weeklyMaintenanceStartTime)
-
-				So(got, ShouldEqual, tc.want)
-			})
-		}
-	})
-}
-
-func TestUnit_returnWeeklyMaintenanceTime(t *testing.T) {
-	Convey("Return weekly maintenance time", t, func() {
-		type args struct {
-			currentTime time.Time
-			hour        string
-			minute      string
-		}
-		now := time.Now()
-
-		testCases := []struct {
-			name string
-			args args
-			want time.Time
-		}{
-			{
-				name: "Success WEEKLY_MAINTENANCE_START_TIME=1900",
-				args: args{
-					currentTime: now,
-					hour:        "19",
-					minute:      "00",
-				},
-				want: time.Date(now.Year(), now.Month(), now.Day(), 19, 0, 0, 0, now.Location()),
-			},
-			{
-				name: "Success WEEKLY_MAINTENANCE_END_TIME=1930",
-				args: args{
-					currentTime: now,
-					hour:        "19",
-					minute:      "30",
-				},
-				want: time.Date(now.Year(), now.Month(), now.Day(), 19, 30, 0, 0, now.Location()),
-			},
-		}
-		for _, tc := range testCases {
-			Convey(tc.name, func() {
-				got := returnWeeklyMaintenanceTime(tc.args.currentTime, tc.args.hour, tc.args.minute)
-
-				So(got, ShouldEqual, tc.want)
-			})
-		}
+func TestUnit_toClockTime(t *testing.T) {
+	Convey("To clock time", t, func() {
+		So(toClockTime("1900"), ShouldEqual, "19:00")
+		So(toClockTime("0030"), ShouldEqual, "00:30")
+		So(toClockTime("19"), ShouldEqual, "19")
 	})
 }
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/config"
+)
+
+// DefaultMaintenanceWindowsCacheTTL is how long maintenance windows are cached for if a TTL is not configured
+const DefaultMaintenanceWindowsCacheTTL = 30 * time.Second
+
+// MaintenanceWindowsCache holds the maintenance windows stored in mongo for a short time, so that every
+// maintenance check does not read the collection
+type MaintenanceWindowsCache struct {
+	DAO dao.MaintenanceWindowDaoService
+	TTL time.Duration
+
+	mtx       sync.Mutex
+	windows   []config.MaintenanceWindow
+	expiresAt time.Time
+}
+
+// NewMaintenanceWindowsCache returns a cache of the maintenance windows read through the DAO
+func NewMaintenanceWindowsCache(maintenanceWindowDaoService dao.MaintenanceWindowDaoService, ttl time.Duration) *MaintenanceWindowsCache {
+	return &MaintenanceWindowsCache{
+		DAO: maintenanceWindowDaoService,
+		TTL: ttl,
+	}
+}
+
+// Get returns the maintenance windows that have not been cancelled and end after the supplied time. If
+// the windows cannot be read the previously cached windows are returned with the error, and are served
+// for the TTL before the windows are read again so that an unavailable mongo is not queried on every check.
+func (c *MaintenanceWindowsCache) Get(requestId string, now time.Time) ([]config.MaintenanceWindow, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if now.Before(c.expiresAt) {
+		return c.windows, nil
+	}
+
+	maintenanceWindows, err := c.DAO.GetMaintenanceWindows(now, requestId)
+	if err != nil {
+		c.expiresAt = now.Add(c.TTL)
+		return c.windows, err
+	}
+
+	windows := make([]config.MaintenanceWindow, 0, len(maintenanceWindows))
+	for _, maintenanceWindow := range maintenanceWindows {
+		if maintenanceWindow.IsCancelled() {
+			continue
+		}
+		windows = append(windows, config.MaintenanceWindow{
+			Name:  maintenanceWindow.Name,
+			Start: maintenanceWindow.StartTime.In(config.MaintenanceLocation),
+			End:   maintenanceWindow.EndTime.In(config.MaintenanceLocation),
+		})
+	}
+
+	c.windows = windows
+	c.expiresAt = now.Add(c.TTL)
+
+	return c.windows, nil
+}
+
+// Invalidate makes the next Get read the maintenance windows again, it is called when a window is
+// created or cancelled
+func (c *MaintenanceWindowsCache) Invalidate() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.expiresAt = time.Time{}
+}
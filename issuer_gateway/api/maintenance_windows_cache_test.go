@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api/common/dao"
+	"github.com/companieshouse/penalty-payment-api/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeMaintenanceWindowDaoService returns its windows and counts how often they are read
+type fakeMaintenanceWindowDaoService struct {
+	windows []dao.MaintenanceWindowDao
+	err     error
+	reads   int
+}
+
+func (f *fakeMaintenanceWindowDaoService) CreateMaintenanceWindow(_ *dao.MaintenanceWindowDao, _ string) error {
+	return nil
+}
+
+func (f *fakeMaintenanceWindowDaoService) GetMaintenanceWindows(_ time.Time, _ string) ([]dao.MaintenanceWindowDao, error) {
+	f.reads++
+	return f.windows, f.err
+}
+
+func (f *fakeMaintenanceWindowDaoService) CancelMaintenanceWindow(_, _ string, _ string) error {
+	return nil
+}
+
+func TestUnitMaintenanceWindowsCache(t *testing.T) {
+	Convey("Maintenance windows cache", t, func() {
+		now := time.Date(2025, time.March, 16, 19, 10, 0, 0, time.UTC)
+		cancelledAt := now.Add(-time.Hour)
+		maintenanceWindowDaoService := &fakeMaintenanceWindowDaoService{
+			windows: []dao.MaintenanceWindowDao{
+				{Name: "E5 upgrade", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)},
+				{Name: "Cancelled", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour), CancelledAt: &cancelledAt},
+			},
+		}
+		maintenanceWindowsCache := NewMaintenanceWindowsCache(maintenanceWindowDaoService, time.Minute)
+
+		windows, err := maintenanceWindowsCache.Get("", now)
+
+		Convey("Cancelled windows are not returned", func() {
+			So(err, ShouldBeNil)
+			So(windows, ShouldResemble, []config.MaintenanceWindow{
+				{Name: "E5 upgrade", Start: now.Add(-time.Hour).In(config.MaintenanceLocation), End: now.Add(time.Hour).In(config.MaintenanceLocation)},
+			})
+		})
+
+		Convey("Windows are read again once the TTL has passed", func() {
+			_, _ = maintenanceWindowsCache.Get("", now.Add(59*time.Second))
+			So(maintenanceWindowDaoService.reads, ShouldEqual, 1)
+
+			_, _ = maintenanceWindowsCache.Get("", now.Add(time.Minute))
+			So(maintenanceWindowDaoService.reads, ShouldEqual, 2)
+		})
+
+		Convey("Windows are read again once the cache is invalidated", func() {
+			maintenanceWindowsCache.Invalidate()
+
+			_, _ = maintenanceWindowsCache.Get("", now)
+			So(maintenanceWindowDaoService.reads, ShouldEqual, 2)
+		})
+
+		Convey("The previous windows are returned with the error when they cannot be read", func() {
+			maintenanceWindowDaoService.err = errors.New("mongo unavailable")
+
+			stale, err := maintenanceWindowsCache.Get("", now.Add(time.Minute))
+
+			So(err, ShouldNotBeNil)
+			So(stale, ShouldResemble, windows)
+
+			Convey("And they are served without reading the windows again until the TTL has passed", func() {
+				stale, err = maintenanceWindowsCache.Get("", now.Add(time.Minute+59*time.Second))
+				So(err, ShouldBeNil)
+				So(stale, ShouldResemble, windows)
+				So(maintenanceWindowDaoService.reads, ShouldEqual, 2)
+
+				_, err = maintenanceWindowsCache.Get("", now.Add(2*time.Minute))
+				So(err, ShouldNotBeNil)
+				So(maintenanceWindowDaoService.reads, ShouldEqual, 3)
+			})
+		})
+	})
+}
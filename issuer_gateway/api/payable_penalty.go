@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
 )
@@ -20,12 +21,16 @@ func PayablePenalty(params types.PayablePenaltyParams) (*models.TransactionItem,
 	allowedTransactionsMap := params.AllowedTransactionsMap
 	requestId := params.RequestId
 
+	// the reason is stored against the payable resource in English and translated when it is read
 	accountPenaltiesParams := types.AccountPenaltiesParams{
 		PenaltyRefType:             penaltyRefType,
 		CustomerCode:               customerCode,
 		CompanyCode:                companyCode,
 		PenaltyDetailsMap:          penaltyDetailsMap,
 		AllowedTransactionsMap:     allowedTransactionsMap,
+		PayableStatusRules:         params.PayableStatusRules,
+		ReasonsCatalogue:           params.ReasonsCatalogue,
+		Language:                   utils.EnglishLanguage,
 		AccountPenaltiesDaoService: apDaoSvc,
 		RequestId:                  requestId,
 	}
@@ -115,6 +115,24 @@ func TestUnitPayablePenalty(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 
+	Convey("the payable status rules and reasons catalogue are used to get the account penalties", t, func() {
+		var gotParams types.AccountPenaltiesParams
+		getAccountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
+			gotParams = params
+			return accountPenaltiesResponse(1), services.Success, nil
+		}
+
+		params := generateParams(mockApDaoSvc, models.TransactionItem{PenaltyRef: "A0000001", Amount: 150})
+		params.PayableStatusRules = &config.PayableStatusRules{DefaultStatus: "CLOSED"}
+		params.ReasonsCatalogue = &config.ReasonsCatalogue{}
+		_, err := PayablePenalty(params)
+
+		So(err, ShouldBeNil)
+		So(gotParams.PayableStatusRules, ShouldEqual, params.PayableStatusRules)
+		So(gotParams.ReasonsCatalogue, ShouldEqual, params.ReasonsCatalogue)
+		So(gotParams.Language, ShouldEqual, utils.EnglishLanguage)
+	})
+
 	Convey("payable penalty is successfully returned", t, func() {
 		getAccountPenalties = func(params types.AccountPenaltiesParams) (*models.TransactionListResponse, services.ResponseType, error) {
 			return accountPenaltiesResponse(1), services.Success, nil
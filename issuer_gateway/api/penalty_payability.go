@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+)
+
+// PenaltyPayabilityReport explains whether a penalty can be paid, with every rule that stops it being paid
+// and the E5 transaction the rules were checked against
+type PenaltyPayabilityReport struct {
+	Penalty       models.TransactionListItem
+	E5Transaction models.AccountPenaltiesDataDao
+	FailedRules   []error
+}
+
+// PenaltyPayability checks the penalty with the supplied reference against every rule that stops a penalty
+// being paid. If an amount is supplied it is checked against the outstanding amount, otherwise the
+// outstanding amount is assumed to be paid.
+func PenaltyPayability(params types.AccountPenaltiesParams, penaltyRef string, amount *float64) (*PenaltyPayabilityReport, services.ResponseType, error) {
+	requestId := params.RequestId
+
+	cfg, err := getConfig()
+	if err != nil {
+		err = fmt.Errorf("error getting config: %v", err)
+		log.ErrorC(requestId, err)
+		return nil, services.Error, err
+	}
+
+	accountPenalties, err := getAccountPenaltiesDao(params.CustomerCode, params.CompanyCode, cfg, params.AccountPenaltiesDaoService, requestId)
+	if err != nil {
+		return nil, services.Error, err
+	}
+
+	transactionListItemEnrichmentProviders := private.TransactionListItemEnrichmentProviders{
+		ReasonProvider:        getReasonProvider(params.ReasonsCatalogue, params.Language),
+		PayableStatusProvider: getPayableStatusProvider(params.PayableStatusRules),
+	}
+	transactionList, err := generateTransactionList(accountPenalties, params.PenaltyRefType, params.PenaltyDetailsMap,
+		params.AllowedTransactionsMap, cfg, requestId, transactionListItemEnrichmentProviders)
+	if err != nil {
+		err = fmt.Errorf("error generating transaction list from account penalties: [%v]", err)
+		log.ErrorC(requestId, err)
+		return nil, services.Error, err
+	}
+
+	report := &PenaltyPayabilityReport{}
+	found := false
+	for _, transaction := range transactionList.Items {
+		if transaction.ID == penaltyRef {
+			report.Penalty = transaction
+			found = true
+			break
+		}
+	}
+	for _, e5Transaction := range accountPenalties.AccountPenalties {
+		if e5Transaction.TransactionReference == penaltyRef {
+			report.E5Transaction = e5Transaction
+			break
+		}
+	}
+	if !found {
+		log.InfoC(requestId, "penalty not found in E5 transactions", log.Data{
+			"customer_code": params.CustomerCode,
+			"penalty_ref":   penaltyRef,
+		})
+		return nil, services.NotFound, private.ErrPenaltyDoesNotExist
+	}
+
+	transactionToMatch := models.TransactionItem{
+		PenaltyRef: penaltyRef,
+		Amount:     report.Penalty.Outstanding,
+	}
+	if amount != nil {
+		transactionToMatch.Amount = *amount
+	}
+	report.FailedRules = private.ExplainPenalty(report.Penalty, transactionToMatch, params.CustomerCode, requestId)
+
+	return report, services.Success, nil
+}
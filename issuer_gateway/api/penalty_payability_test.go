@@ -0,0 +1,75 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/private"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	"github.com/companieshouse/penalty-payment-api/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitPenaltyPayability(t *testing.T) {
+	getConfig = config.Get
+	generateTransactionList = private.GenerateTransactionListFromAccountPenalties
+	cfg, _ := config.Get()
+	cfg.AccountPenaltiesTTL = "24h"
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	params := types.AccountPenaltiesParams{
+		PenaltyRefType:         penaltyRefType,
+		CustomerCode:           customerCode,
+		CompanyCode:            companyCode,
+		PenaltyDetailsMap:      penaltyDetailsMap,
+		AllowedTransactionsMap: allowedTransactionMap,
+		RequestId:              "",
+	}
+
+	Convey("A payable penalty breaks no rules", t, func() {
+		accountPenalties, _ := createData(false, false)
+		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(ctrl)
+		mockApDaoSvc.EXPECT().GetAccountPenalties(customerCode, companyCode, "").Return(&accountPenalties, nil)
+		params.AccountPenaltiesDaoService = mockApDaoSvc
+
+		report, responseType, err := PenaltyPayability(params, "A1234567", nil)
+
+		So(err, ShouldBeNil)
+		So(responseType, ShouldEqual, services.Success)
+		So(report.Penalty.PayableStatus, ShouldEqual, private.OpenPayableStatus)
+		So(report.E5Transaction.DunningStatus, ShouldEqual, "PEN1")
+		So(report.FailedRules, ShouldBeEmpty)
+	})
+
+	Convey("Every rule broken by a penalty is reported", t, func() {
+		accountPenalties, _ := createData(false, false)
+		accountPenalties.AccountPenalties[0].DunningStatus = "DCA"
+		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(ctrl)
+		mockApDaoSvc.EXPECT().GetAccountPenalties(customerCode, companyCode, "").Return(&accountPenalties, nil)
+		params.AccountPenaltiesDaoService = mockApDaoSvc
+		amount := 100.0
+
+		report, responseType, err := PenaltyPayability(params, "A1234567", &amount)
+
+		So(err, ShouldBeNil)
+		So(responseType, ShouldEqual, services.Success)
+		So(report.E5Transaction.AccountStatus, ShouldEqual, "CHS")
+		So(report.FailedRules, ShouldResemble, []error{private.ErrPenaltyAmountMismatch, private.ErrPenaltyDCA, private.ErrPenaltyNotOpen})
+	})
+
+	Convey("Not found when the penalty is not in the E5 transactions", t, func() {
+		accountPenalties, _ := createData(false, false)
+		mockApDaoSvc := mocks.NewMockAccountPenaltiesDaoService(ctrl)
+		mockApDaoSvc.EXPECT().GetAccountPenalties(customerCode, companyCode, "").Return(&accountPenalties, nil)
+		params.AccountPenaltiesDaoService = mockApDaoSvc
+
+		report, responseType, err := PenaltyPayability(params, "A7654321", nil)
+
+		So(err, ShouldEqual, private.ErrPenaltyDoesNotExist)
+		So(responseType, ShouldEqual, services.NotFound)
+		So(report, ShouldBeNil)
+	})
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
 )
 
-var etagGenerator = utils.GenerateEtag
+var etagGenerator = utils.GenerateContentEtag
 
 // TransactionListItemEnrichmentProviders is used to enrich the get-penalties response
 type TransactionListItemEnrichmentProviders struct {
@@ -22,14 +22,6 @@ func GenerateTransactionListFromAccountPenalties(accountPenalties *models.Accoun
 	allowedTransactionsMap *models.AllowedTransactionMap, cfg *config.Config, requestId string,
 	transactionListItemEnrichmentProviders TransactionListItemEnrichmentProviders) (*models.TransactionListResponse, error) {
 	payableTransactionList := models.TransactionListResponse{}
-	etag, err := etagGenerator()
-	if err != nil {
-		err = fmt.Errorf("error generating etag: [%v]", err)
-		log.ErrorC(requestId, err)
-		return nil, err
-	}
-
-	payableTransactionList.Etag = etag
 	payableTransactionList.TotalResults = len(accountPenalties.AccountPenalties)
 
 	// Loop through penalties and construct CH resources
@@ -45,21 +37,22 @@ func GenerateTransactionListFromAccountPenalties(accountPenalties *models.Accoun
 		payableTransactionList.Items = append(payableTransactionList.Items, transactionListItem)
 	}
 
+	// The etag of the list is generated from its items, so it only changes when an item does
+	etag, err := etagGenerator(payableTransactionList)
+	if err != nil {
+		err = fmt.Errorf("error generating etag: [%v]", err)
+		log.ErrorC(requestId, err)
+		return nil, err
+	}
+	payableTransactionList.Etag = etag
+
 	return &payableTransactionList, nil
 }
 
 func buildTransactionListItemFromAccountPenalty(dao *models.AccountPenaltiesDataDao,
 	penaltyDetailsMap *config.PenaltyDetailsMap, penaltyRefType string, transactionType string,
 	reason string, payableStatus string, requestId string) (models.TransactionListItem, error) {
-	etag, err := etagGenerator()
-	if err != nil {
-		err = fmt.Errorf("error generating etag: [%v]", err)
-		log.ErrorC(requestId, err)
-		return models.TransactionListItem{}, err
-	}
-
 	transactionListItem := models.TransactionListItem{}
-	transactionListItem.Etag = etag
 	transactionListItem.ID = dao.TransactionReference
 	transactionListItem.IsPaid = dao.IsPaid
 	transactionListItem.Kind = penaltyDetailsMap.Details[penaltyRefType].ResourceKind
@@ -73,6 +66,15 @@ func buildTransactionListItemFromAccountPenalty(dao *models.AccountPenaltiesData
 	transactionListItem.Reason = reason
 	transactionListItem.PayableStatus = payableStatus
 
+	// The etag is generated from the E5 transaction and the payable status, reason and kind derived from it
+	etag, err := etagGenerator(transactionListItem)
+	if err != nil {
+		err = fmt.Errorf("error generating etag: [%v]", err)
+		log.ErrorC(requestId, err)
+		return models.TransactionListItem{}, err
+	}
+	transactionListItem.Etag = etag
+
 	return transactionListItem, nil
 }
 
@@ -47,7 +47,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	}
 
 	Convey("error when first etag generator fails", t, func() {
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return "", errors.New("error generating etag")
 		}
 		penaltyRefType := utils.LateFilingPenaltyRefType
@@ -61,7 +61,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 
 	Convey("error when first etag generator succeeds but second etag generator fails", t, func() {
 		callCount := 0
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			callCount++
 			if callCount == 2 {
 				return "", errors.New("error generating etag")
@@ -78,7 +78,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	})
 
 	Convey("penalty list successfully generated from E5 response - unpaid costs", t, func() {
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return etag, nil
 		}
 		penaltyRefType := utils.LateFilingPenaltyRefType
@@ -112,7 +112,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	})
 
 	Convey("penalty list successfully generated from E5 response - penalty type EU", t, func() {
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return etag, nil
 		}
 		penaltyRefType := utils.LateFilingPenaltyRefType
@@ -144,7 +144,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	})
 
 	Convey("penalty list successfully generated from E5 response - penalty type Other", t, func() {
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return etag, nil
 		}
 		penaltyRefType := utils.LateFilingPenaltyRefType
@@ -179,7 +179,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 
 	Convey("penalty list successfully generated from E5 response - valid lfp with dunning status is dca", t, func() {
 		etag := "ABCDE"
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return etag, nil
 		}
 		penaltyRefType := utils.LateFilingPenaltyRefType
@@ -212,7 +212,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	})
 
 	Convey("penalty list successfully generated from E5 response - valid sanctions", t, func() {
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return etag, nil
 		}
 		penaltyRefType := utils.SanctionsPenaltyRefType
@@ -246,7 +246,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	})
 
 	Convey("penalty list successfully generated from E5 response - valid sanctions ROE", t, func() {
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return etag, nil
 		}
 		penaltyRefType := utils.SanctionsRoePenaltyRefType
@@ -280,7 +280,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	})
 
 	Convey("penalty list successfully generated from E5 response - valid sanctions with dunning status is dca", t, func() {
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return etag, nil
 		}
 		penaltyRefType := utils.SanctionsPenaltyRefType
@@ -314,7 +314,7 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	})
 
 	Convey("penalty list successfully generated from E5 response - valid sanctions ROE with dunning status is dca", t, func() {
-		etagGenerator = func() (string, error) {
+		etagGenerator = func(_ interface{}) (string, error) {
 			return etag, nil
 		}
 		penaltyRefType := utils.SanctionsRoePenaltyRefType
@@ -348,6 +348,44 @@ func TestUnitGenerateTransactionListFromE5Response(t *testing.T) {
 	})
 }
 
+func TestUnitGenerateTransactionListEtags(t *testing.T) {
+	etagGenerator = utils.GenerateContentEtag
+	penaltyRefType := utils.LateFilingPenaltyRefType
+	penaltyDetailsMap := buildTestPenaltyDetailsMap(penaltyRefType)
+	transactionListItemEnrichmentProviders := TransactionListItemEnrichmentProviders{
+		ReasonProvider:        &DefaultReasonProvider{},
+		PayableStatusProvider: &DefaultPayableStatusProvider{},
+	}
+	generate := func(accountPenaltiesDao *models.AccountPenaltiesDao) *models.TransactionListResponse {
+		transactionList, err := GenerateTransactionListFromAccountPenalties(
+			accountPenaltiesDao, penaltyRefType, penaltyDetailsMap, allowedTransactionMap, &cfg, "", transactionListItemEnrichmentProviders)
+		So(err, ShouldBeNil)
+		return transactionList
+	}
+
+	Convey("Etags are generated from the account penalties", t, func() {
+		accountPenaltiesDao := buildTestUnpaidAccountPenaltiesDao(
+			"12345678", utils.LateFilingPenaltyCompanyCode, "EU", addTrailingSpacesToDunningStatus(PEN1DunningStatus), penaltyRefType, false)
+		transactionList := generate(accountPenaltiesDao)
+
+		Convey("The etags are the same when the account penalties have not changed", func() {
+			unchangedTransactionList := generate(accountPenaltiesDao)
+
+			So(unchangedTransactionList.Etag, ShouldEqual, transactionList.Etag)
+			So(unchangedTransactionList.Items[0].Etag, ShouldEqual, transactionList.Items[0].Etag)
+		})
+
+		Convey("The etags change when the payable status changes", func() {
+			accountPenaltiesDao.AccountPenalties[0].DunningStatus = addTrailingSpacesToDunningStatus(DCADunningStatus)
+			changedTransactionList := generate(accountPenaltiesDao)
+
+			So(changedTransactionList.Items[0].PayableStatus, ShouldEqual, ClosedPayableStatus)
+			So(changedTransactionList.Etag, ShouldNotEqual, transactionList.Etag)
+			So(changedTransactionList.Items[0].Etag, ShouldNotEqual, transactionList.Items[0].Etag)
+		})
+	})
+}
+
 func addTrailingSpacesToDunningStatus(dunningStatus string) string {
 	return fmt.Sprintf("%-12s", dunningStatus)
 }
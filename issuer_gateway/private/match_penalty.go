@@ -16,8 +16,32 @@ var (
 	ErrPenaltyIsPaid         = errors.New("this penalty is already paid")
 	ErrPenaltyIsPartPaid     = errors.New("the penalty is already part paid")
 	ErrPenaltyAmountMismatch = errors.New("you can only pay off the full amount of the penalty")
+	ErrPenaltyNotOpen        = errors.New("the penalty is not open for payment")
 )
 
+// penaltyRules names the rule broken by each error, so that the reasons a penalty cannot be paid can be
+// reported to the caller
+var penaltyRules = map[error]string{
+	ErrPenaltyDoesNotExist:   "PENALTY_DOES_NOT_EXIST",
+	ErrPenaltyNotPayable:     "NOT_A_PENALTY",
+	ErrPenaltyDCA:            "WITH_DEBT_COLLECTION_AGENCY",
+	ErrPenaltyIsPaid:         "PAID",
+	ErrPenaltyIsPartPaid:     "PART_PAID",
+	ErrPenaltyAmountMismatch: "AMOUNT_MISMATCH",
+	ErrPenaltyNotOpen:        "PAYABLE_STATUS_NOT_OPEN",
+}
+
+// PenaltyRule returns the name of the rule broken by a penalty validation error
+func PenaltyRule(err error) string {
+	return penaltyRules[err]
+}
+
+// IsAdvisoryRule reports whether the rule is only reported by ExplainPenalty. An advisory rule does not stop
+// MatchPenalty matching the penalty, so it does not stop a payable resource being created.
+func IsAdvisoryRule(err error) bool {
+	return err == ErrPenaltyNotOpen
+}
+
 func MatchPenalty(referenceTransactions []models.TransactionListItem,
 	transactionToMatch models.TransactionItem, customerCode, requestId string) (*models.TransactionItem, error) {
 
@@ -53,6 +77,24 @@ func MatchPenalty(referenceTransactions []models.TransactionListItem,
 	}
 }
 
+// ExplainPenalty returns every rule that stops the transaction being paid, rather than only the first as
+// MatchPenalty does. The advisory rules are also returned, see IsAdvisoryRule. A slice without any rule that
+// is not advisory means the penalty can be paid.
+func ExplainPenalty(refTransaction models.TransactionListItem, transactionToMatch models.TransactionItem,
+	customerCode, requestId string) []error {
+	transactionInfo := map[string]interface{}{
+		"penalty_ref":   transactionToMatch.PenaltyRef,
+		"customer_code": customerCode,
+	}
+
+	_, errs := validate(refTransaction, transactionInfo, transactionToMatch, requestId)
+	if refTransaction.PayableStatus != OpenPayableStatus {
+		errs = append(errs, ErrPenaltyNotOpen)
+	}
+
+	return errs
+}
+
 func validate(
 	refTransaction models.TransactionListItem,
 	data map[string]interface{},
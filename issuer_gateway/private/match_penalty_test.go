@@ -33,6 +33,7 @@ func TestUnitMatchPenalty(t *testing.T) {
 		Reason         string
 		IsDCA          bool
 		IsPaid         bool
+		PayableStatus  string
 		OriginalAmount float64
 		Outstanding    float64
 		WantMatched    *models.TransactionItem
@@ -50,28 +51,99 @@ func TestUnitMatchPenalty(t *testing.T) {
 			OriginalAmount: 100, IsDCA: false, IsPaid: false, WantMatched: nil, WantError: ErrPenaltyAmountMismatch},
 		{PenaltyRef: "121", Outstanding: 150, Type: "penalty", MadeUpDate: "2017-06-30", Reason: "Failure to file a confirmation statement",
 			OriginalAmount: 150, IsDCA: true, IsPaid: false, WantMatched: nil, WantError: ErrPenaltyDCA},
+		{PenaltyRef: "121", Outstanding: 150, Type: "penalty", MadeUpDate: "2017-06-30", Reason: "Failure to file a confirmation statement",
+			OriginalAmount: 150, IsDCA: false, IsPaid: false, PayableStatus: DisabledPayableStatus, WantMatched: &matchedPenalty, WantError: nil},
+		{PenaltyRef: "121", Outstanding: 150, Type: "penalty", MadeUpDate: "2017-06-30", Reason: "Failure to file a confirmation statement",
+			OriginalAmount: 150, IsDCA: false, IsPaid: false, PayableStatus: ClosedInstalmentPlanPayableStatus, WantMatched: &matchedPenalty, WantError: nil},
 		{PenaltyRef: "121", Outstanding: 150, Type: "penalty", MadeUpDate: "2017-06-30", Reason: "Failure to file a confirmation statement",
 			OriginalAmount: 150, IsDCA: false, IsPaid: false, WantMatched: &matchedPenalty, WantError: nil},
 	}
 
+	refTransactions := func(testCase int) []models.TransactionListItem {
+		payableStatus := testCases[testCase].PayableStatus
+		if payableStatus == "" {
+			payableStatus = OpenPayableStatus
+		}
+		return []models.TransactionListItem{
+			{
+				ID:             testCases[testCase].PenaltyRef,
+				Type:           testCases[testCase].Type,
+				OriginalAmount: testCases[testCase].OriginalAmount,
+				Outstanding:    testCases[testCase].Outstanding,
+				IsDCA:          testCases[testCase].IsDCA,
+				IsPaid:         testCases[testCase].IsPaid,
+				MadeUpDate:     testCases[testCase].MadeUpDate,
+				Reason:         testCases[testCase].Reason,
+				PayableStatus:  payableStatus,
+			},
+		}
+	}
+
 	Convey("matchPenalty works correctly for different scenarios", t, func() {
-		for _, testCase := range testCases {
-			refTransactions := []models.TransactionListItem{
-				{
-					ID:             testCase.PenaltyRef,
-					Type:           testCase.Type,
-					OriginalAmount: testCase.OriginalAmount,
-					Outstanding:    testCase.Outstanding,
-					IsDCA:          testCase.IsDCA,
-					IsPaid:         testCase.IsPaid,
-					MadeUpDate:     testCase.MadeUpDate,
-					Reason:         testCase.Reason,
-				},
-			}
-			matched, err := MatchPenalty(refTransactions, transactionsToMatch, companyNumber, "")
+		for i, testCase := range testCases {
+			matched, err := MatchPenalty(refTransactions(i), transactionsToMatch, companyNumber, "")
 
 			So(err, ShouldEqual, testCase.WantError)
 			So(matched, ShouldResemble, testCase.WantMatched)
 		}
 	})
+
+	Convey("matchPenalty only matches a penalty that explainPenalty says can be paid", t, func() {
+		// the first test case is a penalty that does not exist, which cannot be explained
+		for i, testCase := range testCases[1:] {
+			errs := ExplainPenalty(refTransactions(i + 1)[0], transactionsToMatch, companyNumber, "")
+			matched, err := MatchPenalty(refTransactions(i+1), transactionsToMatch, companyNumber, "")
+
+			var rules []error
+			for _, e := range errs {
+				if !IsAdvisoryRule(e) {
+					rules = append(rules, e)
+				}
+			}
+			So(matched == nil, ShouldEqual, len(rules) > 0)
+			if testCase.WantError != nil {
+				So(errs, ShouldContain, err)
+			}
+		}
+	})
+
+	Convey("explainPenalty reports a penalty that is not open for payment as advisory", t, func() {
+		errs := ExplainPenalty(refTransactions(6)[0], transactionsToMatch, companyNumber, "")
+
+		So(errs, ShouldResemble, []error{ErrPenaltyNotOpen})
+		So(IsAdvisoryRule(errs[0]), ShouldBeTrue)
+		So(IsAdvisoryRule(ErrPenaltyDCA), ShouldBeFalse)
+	})
+}
+
+func TestUnitExplainPenalty(t *testing.T) {
+	Convey("Explain penalty", t, func() {
+		refTransaction := models.TransactionListItem{
+			ID:             "121",
+			Type:           "penalty",
+			OriginalAmount: 150,
+			Outstanding:    150,
+			PayableStatus:  OpenPayableStatus,
+		}
+		transactionToMatch := models.TransactionItem{PenaltyRef: "121", Amount: 150}
+
+		Convey("No rules are broken by a payable penalty", func() {
+			So(ExplainPenalty(refTransaction, transactionToMatch, "123", ""), ShouldBeEmpty)
+		})
+
+		Convey("Every broken rule is returned", func() {
+			refTransaction.Outstanding = 100
+			refTransaction.IsDCA = true
+			refTransaction.PayableStatus = ClosedPayableStatus
+
+			errs := ExplainPenalty(refTransaction, transactionToMatch, "123", "")
+
+			So(errs, ShouldResemble, []error{ErrPenaltyIsPartPaid, ErrPenaltyAmountMismatch, ErrPenaltyDCA, ErrPenaltyNotOpen})
+		})
+
+		Convey("Each rule has a name", func() {
+			So(PenaltyRule(ErrPenaltyDCA), ShouldEqual, "WITH_DEBT_COLLECTION_AGENCY")
+			So(PenaltyRule(ErrPenaltyNotOpen), ShouldEqual, "PAYABLE_STATUS_NOT_OPEN")
+		})
+	})
 }
@@ -3,6 +3,7 @@ package private
 import (
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
 )
 
 const (
@@ -33,6 +34,17 @@ func (provider *DefaultReasonProvider) GetReason(transaction *models.AccountPena
 	return ""
 }
 
+// CatalogueReasonProvider gives the reason for a transaction from the reasons catalogue in the requested
+// language
+type CatalogueReasonProvider struct {
+	Catalogue *config.ReasonsCatalogue
+	Language  string
+}
+
+func (provider *CatalogueReasonProvider) GetReason(transaction *models.AccountPenaltiesDataDao) string {
+	return provider.Catalogue.GetReason(transaction.CompanyCode, transaction.TransactionType, transaction.TransactionSubType, provider.Language)
+}
+
 func getSanctionsReason(transaction *models.AccountPenaltiesDataDao) string {
 	switch transaction.TransactionSubType {
 	case SanctionsConfirmationStatementTransactionSubType:
@@ -5,6 +5,7 @@ import (
 
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -82,3 +83,48 @@ func TestUnitDefaultReasonProvider_GetReason(t *testing.T) {
 		}
 	})
 }
+
+func TestUnitCatalogueReasonProvider_GetReason(t *testing.T) {
+	reasonsCatalogue, err := config.LoadReasonsCatalogue("../../assets/reasons.yml")
+	if err != nil {
+		t.Fatalf("Failed to load reasons catalogue: %v", err)
+	}
+
+	Convey("English reasons from the catalogue match the default reasons", t, func() {
+		defaultProvider := &DefaultReasonProvider{}
+		catalogueProvider := &CatalogueReasonProvider{Catalogue: reasonsCatalogue, Language: utils.EnglishLanguage}
+
+		for _, companyCode := range []string{utils.LateFilingPenaltyCompanyCode, utils.SanctionsCompanyCode, "XX"} {
+			for _, transactionType := range []string{InvoiceTransactionType, "5"} {
+				for _, transactionSubType := range []string{"C1", "EU", SanctionsConfirmationStatementTransactionSubType,
+					SanctionsFailedToVerifyIdentityTransactionSubType, SanctionsRoeFailureToUpdateTransactionSubType, "S2", "02"} {
+					penalty := &models.AccountPenaltiesDataDao{
+						CompanyCode:        companyCode,
+						TransactionType:    transactionType,
+						TransactionSubType: transactionSubType,
+					}
+
+					So(catalogueProvider.GetReason(penalty), ShouldEqual, defaultProvider.GetReason(penalty))
+				}
+			}
+		}
+	})
+
+	Convey("Welsh reasons from the catalogue", t, func() {
+		catalogueProvider := &CatalogueReasonProvider{Catalogue: reasonsCatalogue, Language: utils.WelshLanguage}
+
+		So(catalogueProvider.GetReason(&models.AccountPenaltiesDataDao{
+			CompanyCode:     utils.LateFilingPenaltyCompanyCode,
+			TransactionType: InvoiceTransactionType,
+		}), ShouldEqual, "Cyflwyno cyfrifon yn hwyr")
+		So(catalogueProvider.GetReason(&models.AccountPenaltiesDataDao{
+			CompanyCode:        utils.SanctionsCompanyCode,
+			TransactionType:    InvoiceTransactionType,
+			TransactionSubType: SanctionsRoeFailureToUpdateTransactionSubType,
+		}), ShouldEqual, "Methu â diweddaru'r Gofrestr Endidau Tramor")
+		So(catalogueProvider.GetReason(&models.AccountPenaltiesDataDao{
+			CompanyCode:     utils.SanctionsCompanyCode,
+			TransactionType: "5",
+		}), ShouldEqual, "")
+	})
+}
@@ -0,0 +1,79 @@
+package private
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+)
+
+// RulesPayableStatusProvider decides the payable status of a penalty using the rules in the payable
+// status rules file rather than rules held in code
+type RulesPayableStatusProvider struct {
+	Rules *config.PayableStatusRules
+}
+
+func (provider *RulesPayableStatusProvider) GetPayableStatus(transactionType string, e5Transaction *models.AccountPenaltiesDataDao, closedAt *time.Time,
+	e5Transactions []models.AccountPenaltiesDataDao, allowedTransactionsMap *models.AllowedTransactionMap, cfg *config.Config) string {
+	if types.Penalty.String() != transactionType {
+		return ClosedPayableStatus
+	}
+
+	for _, rule := range provider.Rules.Rules {
+		if ruleMatches(rule.When, e5Transaction, closedAt, e5Transactions, allowedTransactionsMap, cfg) {
+			return rule.Status
+		}
+	}
+
+	return provider.Rules.DefaultStatus
+}
+
+func ruleMatches(when config.PayableStatusCondition, penalty *models.AccountPenaltiesDataDao, closedAt *time.Time,
+	e5Transactions []models.AccountPenaltiesDataDao, allowedTransactionsMap *models.AllowedTransactionMap, cfg *config.Config) bool {
+	if when.SubTypeDisabled != nil && *when.SubTypeDisabled != penaltyTransactionSubTypeDisabled(penalty, cfg) {
+		return false
+	}
+	if when.Paid != nil && *when.Paid != penalty.IsPaid {
+		return false
+	}
+	if when.PaidToday != nil && *when.PaidToday != (closedAt != nil && penaltyPaidToday(closedAt)) {
+		return false
+	}
+	if when.PaymentAllocated != nil && *when.PaymentAllocated != penaltyPaymentAllocated(penalty) {
+		return false
+	}
+	if when.Outstanding != nil && *when.Outstanding != (penalty.OutstandingAmount > 0) {
+		return false
+	}
+	if when.UnpaidCosts != nil && *when.UnpaidCosts != (len(getUnpaidCosts(penalty, e5Transactions, allowedTransactionsMap)) > 0) {
+		return false
+	}
+	if len(when.CompanyCodes) > 0 && !slices.Contains(when.CompanyCodes, penalty.CompanyCode) {
+		return false
+	}
+	if len(when.DunningStatuses) > 0 && !slices.Contains(when.DunningStatuses, strings.TrimSpace(penalty.DunningStatus)) {
+		return false
+	}
+	if len(when.AccountStatuses) > 0 && !slices.Contains(when.AccountStatuses, penalty.AccountStatus) {
+		return false
+	}
+	if when.RelatedTransaction != nil && !hasRelatedTransaction(penalty, e5Transactions, when.RelatedTransaction) {
+		return false
+	}
+	return true
+}
+
+func hasRelatedTransaction(penalty *models.AccountPenaltiesDataDao, e5Transactions []models.AccountPenaltiesDataDao,
+	related *config.RelatedTransactionCondition) bool {
+	for _, e5Transaction := range e5Transactions {
+		if e5Transaction.MadeUpDate == penalty.MadeUpDate &&
+			e5Transaction.TransactionType == related.TransactionType &&
+			e5Transaction.TransactionSubType == related.TransactionSubType {
+			return true
+		}
+	}
+	return false
+}
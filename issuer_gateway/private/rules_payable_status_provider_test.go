@@ -0,0 +1,106 @@
+package private
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/types"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func loadTestPayableStatusRules(t *testing.T) *config.PayableStatusRules {
+	payableStatusRules, err := config.LoadPayableStatusRules("../../assets/payable_status_rules.yml")
+	if err != nil {
+		t.Fatalf("Failed to load payable status rules: %v", err)
+	}
+	return payableStatusRules
+}
+
+func TestUnitRulesPayableStatusProvider_PayableStatusRulesAsset(t *testing.T) {
+	Convey("Every status in the payable status rules is an existing payable status", t, func() {
+		payableStatusRules := loadTestPayableStatusRules(t)
+		payableStatuses := []string{OpenPayableStatus, DisabledPayableStatus, ClosedPayableStatus, ClosedPendingAllocationPayableStatus,
+			ClosedInstalmentPlanPayableStatus, ClosedPenStrategyExhaustedPayableStatus}
+
+		So(payableStatuses, ShouldContain, payableStatusRules.DefaultStatus)
+		for _, rule := range payableStatusRules.Rules {
+			So(payableStatuses, ShouldContain, rule.Status)
+		}
+		So(config.PayableStatuses, ShouldResemble, payableStatuses)
+	})
+}
+
+func TestUnitRulesPayableStatusProvider_GetPayableStatus(t *testing.T) {
+	Convey("The payable status rules give the same payable status as the default provider", t, func() {
+		rulesProvider := &RulesPayableStatusProvider{Rules: loadTestPayableStatusRules(t)}
+		defaultProvider := &DefaultPayableStatusProvider{}
+
+		relatedTransactions := map[string]func(penalty models.AccountPenaltiesDataDao) []models.AccountPenaltiesDataDao{
+			"no related transactions": func(penalty models.AccountPenaltiesDataDao) []models.AccountPenaltiesDataDao {
+				return nil
+			},
+			"instalment plan": func(penalty models.AccountPenaltiesDataDao) []models.AccountPenaltiesDataDao {
+				return []models.AccountPenaltiesDataDao{buildInstalmentPlanTransaction(penalty.TransactionReference, "2025-07-23", penalty.MadeUpDate, 150, "2025-07-23")}
+			},
+			"instalment plan for another made up date": func(penalty models.AccountPenaltiesDataDao) []models.AccountPenaltiesDataDao {
+				return []models.AccountPenaltiesDataDao{buildInstalmentPlanTransaction(penalty.TransactionReference, "2025-07-23", "2020-01-31", 150, "2025-07-23")}
+			},
+			"exhausted write off": func(penalty models.AccountPenaltiesDataDao) []models.AccountPenaltiesDataDao {
+				return []models.AccountPenaltiesDataDao{buildExhaustedWriteOffTransaction("EXHAUSTED WRITE", "2024-03-20", penalty.MadeUpDate, -150, "2024-03-20")}
+			},
+			"unpaid cost": func(penalty models.AccountPenaltiesDataDao) []models.AccountPenaltiesDataDao {
+				cost := penalty
+				cost.TransactionReference = penalty.TransactionReference + "-COST"
+				cost.TransactionSubType = "ZZ"
+				cost.IsPaid = false
+				return []models.AccountPenaltiesDataDao{cost}
+			},
+		}
+
+		var mismatches []string
+		for _, transactionType := range []string{types.Penalty.String(), types.Other.String()} {
+			for _, companyCode := range []string{utils.LateFilingPenaltyCompanyCode, utils.SanctionsCompanyCode, "XX"} {
+				for _, accountStatus := range []string{CHSAccountStatus, DCAAccountStatus, HLDAccountStatus, WDRAccountStatus, "CLS"} {
+					for _, dunningStatus := range []string{PEN1DunningStatus, PEN2DunningStatus, PEN3DunningStatus, DCADunningStatus, ""} {
+						for _, isPaid := range []bool{false, true} {
+							for _, outstandingAmount := range []float64{-10, 0, 150} {
+								for _, closedAt := range []*time.Time{nil, &now, &yesterday} {
+									for relatedName, related := range relatedTransactions {
+										for _, disabledSubtypes := range []string{"", "EU"} {
+											penalty := buildTestAccountPenaltiesDataDao(AccountPenaltiesParams{
+												CompanyCode:          companyCode,
+												TransactionReference: "A1234567",
+												Amount:               150,
+												OutstandingAmount:    outstandingAmount,
+												IsPaid:               isPaid,
+												TransactionType:      InvoiceTransactionType,
+												TransactionSubType:   "EU",
+												AccountStatus:        accountStatus,
+												DunningStatus:        addTrailingSpacesToDunningStatus(dunningStatus),
+											})
+											e5Transactions := append([]models.AccountPenaltiesDataDao{penalty}, related(penalty)...)
+											penaltyCfg := &config.Config{DisabledPenaltyTransactionSubtypes: disabledSubtypes}
+
+											want := defaultProvider.GetPayableStatus(transactionType, &penalty, closedAt, e5Transactions, allowedTransactionMap, penaltyCfg)
+											got := rulesProvider.GetPayableStatus(transactionType, &penalty, closedAt, e5Transactions, allowedTransactionMap, penaltyCfg)
+											if got != want {
+												mismatches = append(mismatches, fmt.Sprintf("%s %s %s %q paid=%t outstanding=%v closedAt=%v %s disabled=%q: want %s, got %s",
+													transactionType, companyCode, accountStatus, dunningStatus, isPaid, outstandingAmount, closedAt != nil, relatedName, disabledSubtypes, want, got))
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+
+		So(mismatches, ShouldBeEmpty)
+	})
+}
@@ -12,6 +12,9 @@ type AccountPenaltiesParams struct {
 	CompanyCode                string
 	PenaltyDetailsMap          *config.PenaltyDetailsMap
 	AllowedTransactionsMap     *models.AllowedTransactionMap
+	PayableStatusRules         *config.PayableStatusRules
+	ReasonsCatalogue           *config.ReasonsCatalogue
+	Language                   string
 	AccountPenaltiesDaoService dao.AccountPenaltiesDaoService
 	RequestId                  string
 }
@@ -23,6 +26,8 @@ type PayablePenaltyParams struct {
 	Transaction                models.TransactionItem
 	PenaltyDetailsMap          *config.PenaltyDetailsMap
 	AllowedTransactionsMap     *models.AllowedTransactionMap
+	PayableStatusRules         *config.PayableStatusRules
+	ReasonsCatalogue           *config.ReasonsCatalogue
 	AccountPenaltiesDaoService dao.AccountPenaltiesDaoService
 	RequestId                  string
 }
@@ -19,10 +19,14 @@ import (
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/penalty-payment-api/common/dao"
 	"github.com/companieshouse/penalty-payment-api/common/e5"
+	"github.com/companieshouse/penalty-payment-api/common/openapi"
+	"github.com/companieshouse/penalty-payment-api/common/services"
 	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/handlers"
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
+	"github.com/companieshouse/penalty-payment-api/middleware"
 	"github.com/companieshouse/penalty-payment-api/penalty_payments/supervisor"
+	"github.com/companieshouse/penalty-payment-api/spec"
 	"github.com/gorilla/mux"
 )
 
@@ -48,20 +52,105 @@ func main() {
 	}
 	prDaoService := dao.NewPayableResourcesDaoService(mongoClientProvider, cfg)
 	apDaoService := dao.NewAccountPenaltiesDaoService(mongoClientProvider, cfg)
+	mwDaoService := dao.NewMaintenanceWindowDaoService(mongoClientProvider, cfg)
+	ikDaoService := dao.NewIdempotencyKeyDaoService(mongoClientProvider, cfg)
+	prSearchDaoService := dao.NewPayableResourceSearchDaoService(mongoClientProvider, cfg)
 
-	penaltyDetailsMap, err := config.LoadPenaltyDetails("assets/penalty_details.yml")
+	// the penalty lookup depends on the indexes but the service can still run without them
+	if err = apDaoService.CreateIndexes(""); err != nil {
+		log.Error(fmt.Errorf("error creating account penalties indexes: %v", err), nil)
+	}
+	// repeated create payable requests are only detected by the unique index of the idempotency keys
+	if err = ikDaoService.CreateIndexes(""); err != nil {
+		log.Error(fmt.Errorf("error creating idempotency keys indexes: %v", err), nil)
+	}
+
+	// listing and searching payable resources scan the collection without the indexes
+	if err = prSearchDaoService.CreateIndexes(""); err != nil {
+		log.Error(fmt.Errorf("error creating payable resources indexes: %v", err), nil)
+	}
+
+	maintenanceWindowsCacheTTL := api.DefaultMaintenanceWindowsCacheTTL
+	if cfg.MaintenanceWindowsCacheTTL != "" {
+		maintenanceWindowsCacheTTL, err = time.ParseDuration(cfg.MaintenanceWindowsCacheTTL)
+		if err != nil {
+			log.Error(fmt.Errorf(exitErrorFormat, err), nil)
+			return
+		}
+	}
+	maintenanceWindowsCache := api.NewMaintenanceWindowsCache(mwDaoService, maintenanceWindowsCacheTTL)
+
+	// maintenance would be ignored with an invalid schedule, so the service does not start
+	if err = api.LoadMaintenanceSchedule(cfg); err != nil {
+		log.Error(fmt.Errorf(exitErrorFormat, err), nil)
+		return
+	}
+
+	idempotencyKeyTTL := handlers.DefaultIdempotencyKeyTTL
+	if cfg.IdempotencyKeyTTL != "" {
+		idempotencyKeyTTL, err = time.ParseDuration(cfg.IdempotencyKeyTTL)
+		if err != nil {
+			log.Error(fmt.Errorf(exitErrorFormat, err), nil)
+			return
+		}
+	}
+	idempotencyKeys := handlers.NewIdempotencyKeys(ikDaoService, idempotencyKeyTTL)
+
+	penaltyConfigProvider, err := config.NewPenaltyConfigProvider(config.PenaltyConfigFiles{
+		PenaltyDetails:      "assets/penalty_details.yml",
+		AllowedTransactions: "assets/penalty_types.yml",
+		PayableStatusRules:  "assets/payable_status_rules.yml",
+		ReasonsCatalogue:    "assets/reasons.yml",
+	})
 	if err != nil {
 		log.Error(fmt.Errorf(exitErrorFormat, err), nil)
 		return
 	}
 
-	allowedTransactionsMap, err := config.GetAllowedTransactions("assets/penalty_types.yml")
+	watchCtx, watchCancel := context.WithCancel(context.Background())
+	defer watchCancel()
+
+	if cfg.PenaltyConfigReloadInterval != "" {
+		reloadInterval, err := time.ParseDuration(cfg.PenaltyConfigReloadInterval)
+		if err != nil {
+			log.Error(fmt.Errorf(exitErrorFormat, err), nil)
+			return
+		}
+		go penaltyConfigProvider.Watch(watchCtx, reloadInterval)
+	}
+
+	// pending payable resources are also expired when they are read, the job expires the abandoned ones
+	pendingPayableExpiryInterval := services.DefaultPendingPayableExpiryInterval
+	if cfg.PendingPayableExpiryInterval != "" {
+		pendingPayableExpiryInterval, err = time.ParseDuration(cfg.PendingPayableExpiryInterval)
+		if err != nil {
+			log.Error(fmt.Errorf(exitErrorFormat, err), nil)
+			return
+		}
+	}
+	payableResourceService := &services.PayableResourceService{DAO: prDaoService, Config: cfg}
+	go payableResourceService.WatchPendingPayableResources(watchCtx, pendingPayableExpiryInterval)
+
+	// penalty lookups are rate limited by the client address forwarded by the trusted proxies
+	if _, err = middleware.ParseTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Error(fmt.Errorf(exitErrorFormat, err), nil)
+		return
+	}
+
+	// requests are validated against the OpenAPI document after they are authenticated
+	openAPIDocument, err := openapi.Load(spec.OpenAPI)
 	if err != nil {
 		log.Error(fmt.Errorf(exitErrorFormat, err), nil)
 		return
 	}
+	maxRequestBodyBytes := int64(middleware.DefaultMaxRequestBodyBytes)
+	if cfg.MaxRequestBodyBytes > 0 {
+		maxRequestBodyBytes = int64(cfg.MaxRequestBodyBytes)
+	}
+	requestValidator := middleware.NewRequestValidator(openAPIDocument, maxRequestBodyBytes, cfg.ValidateResponses)
 
-	handlers.Register(mainRouter, cfg, prDaoService, apDaoService, penaltyDetailsMap, allowedTransactionsMap)
+	handlers.Register(mainRouter, cfg, prDaoService, apDaoService, prSearchDaoService, penaltyConfigProvider, maintenanceWindowsCache,
+		idempotencyKeys, requestValidator)
 
 	if cfg.FeatureFlagPaymentsProcessingEnabled {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -73,13 +162,13 @@ func main() {
 			E5Client:                  e5.NewClient(cfg.E5Username, cfg.E5APIURL),
 			PayableResourceDaoService: prDaoService,
 		}
-		go supervisor.SuperviseConsumer(ctx, cfg.ConsumerGroupName, cfg, penaltyFinancePayment, nil)
+		go supervisor.SuperviseConsumer(ctx, cfg.ConsumerGroupName, cfg, penaltyFinancePayment, nil, maintenanceWindowsCache)
 
 		retry := &resilience.ServiceRetry{
 			ThrottleRate: time.Duration(cfg.ConsumerRetryThrottleRate) * time.Second,
 			MaxRetries:   cfg.ConsumerRetryMaxAttempts,
 		}
-		go supervisor.SuperviseConsumer(ctx, cfg.ConsumerRetryGroupName, cfg, penaltyFinancePayment, retry)
+		go supervisor.SuperviseConsumer(ctx, cfg.ConsumerRetryGroupName, cfg, penaltyFinancePayment, retry, maintenanceWindowsCache)
 	}
 
 	log.Info("Starting " + namespace)
@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	"github.com/companieshouse/chs.go/log"
-	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/utils"
 	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/gorilla/mux"
@@ -19,8 +18,7 @@ func CompanyMiddleware(h http.Handler) http.Handler {
 
 		if err != nil {
 			log.ErrorR(r, err)
-			m := models.NewMessageResponse("customer code not supplied")
-			utils.WriteJSONWithStatus(w, r, m, http.StatusBadRequest)
+			utils.WriteProblem(w, r, utils.NewProblem(http.StatusBadRequest, utils.ErrorCodeCustomerCodeMissing, "customer code not supplied"))
 			return
 		}
 
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+)
+
+// RateLimiter limits how many requests each client can make in a fixed window. Clients are identified by
+// the ERIC identity of the request if it was authenticated by the gateway as an oauth2 user or API key, and
+// otherwise by their IP address, as the identity of an unauthenticated request is chosen by the caller. The IP
+// address is the remote address unless it is a trusted proxy, in which case it is taken from the X-Forwarded-For
+// header so that clients behind the same proxy are limited separately. The counts are held in memory so the
+// limit applies to each instance of the service, and the windows that have ended are removed once a window.
+type RateLimiter struct {
+	limit          int
+	window         time.Duration
+	trustedProxies []*net.IPNet
+	now            func() time.Time
+	mutex          sync.Mutex
+	clients        map[string]*rateLimitWindow
+	nextSweep      time.Time
+}
+
+type rateLimitWindow struct {
+	start    time.Time
+	requests int
+}
+
+// NewRateLimiter returns a RateLimiter that allows limit requests from each client in every window. The
+// X-Forwarded-For header is only trusted on requests from the trusted proxies.
+func NewRateLimiter(limit int, window time.Duration, trustedProxies []*net.IPNet) *RateLimiter {
+	return &RateLimiter{
+		limit:          limit,
+		window:         window,
+		trustedProxies: trustedProxies,
+		now:            time.Now,
+		clients:        map[string]*rateLimitWindow{},
+	}
+}
+
+// ParseTrustedProxies parses the IP addresses and CIDR ranges of trusted proxies
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	trustedProxies := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %s is not an IP address or CIDR range", proxy)
+			}
+			trustedProxies = append(trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %s is not an IP address or CIDR range", proxy)
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+	return trustedProxies, nil
+}
+
+// Allow records a request from the client, returning false and how long until the client can make another
+// request if the client has reached the limit
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if !now.Before(l.nextSweep) {
+		l.removeExpired(now)
+		l.nextSweep = now.Add(l.window)
+	}
+
+	clientWindow, ok := l.clients[client]
+	if !ok || !now.Before(clientWindow.start.Add(l.window)) {
+		clientWindow = &rateLimitWindow{start: now}
+		l.clients[client] = clientWindow
+	}
+	if clientWindow.requests >= l.limit {
+		return false, clientWindow.start.Add(l.window).Sub(now)
+	}
+	clientWindow.requests++
+	return true, 0
+}
+
+// removeExpired removes the windows that have ended, so that the clients that stop making requests are not held
+func (l *RateLimiter) removeExpired(now time.Time) {
+	for client, clientWindow := range l.clients {
+		if !now.Before(clientWindow.start.Add(l.window)) {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// Middleware responds with 429 Too Many Requests and a Retry-After header once a client has reached the limit
+func (l *RateLimiter) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := l.client(r)
+		allowed, retryAfter := l.Allow(client)
+		if !allowed {
+			log.InfoR(r, "rate limit reached", log.Data{"client": client, "path": r.URL.Path})
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			utils.WriteProblem(w, r, utils.NewProblem(http.StatusTooManyRequests, utils.ErrorCodeRateLimited, "too many requests, try again later"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (l *RateLimiter) client(r *http.Request) string {
+	if identityType := authentication.GetAuthorisedIdentityType(r); identityType != "" {
+		if identity := r.Header.Get("ERIC-Identity"); identity != "" {
+			return identityType + ":" + identity
+		}
+	}
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+
+	// each proxy appends the address it received the request from, so the client is the last address that
+	// was not added by a trusted proxy
+	forwardedFor := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwardedFor) - 1; i >= 0 && l.isTrustedProxy(client); i-- {
+		forwarded := strings.TrimSpace(forwardedFor[i])
+		if net.ParseIP(forwarded) == nil {
+			break
+		}
+		client = forwarded
+	}
+	return client
+}
+
+func (l *RateLimiter) isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, trustedProxy := range l.trustedProxies {
+		if trustedProxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitRateLimiter(t *testing.T) {
+	Convey("Given a rate limiter allowing two requests a minute", t, func() {
+		now := time.Date(2025, time.March, 16, 12, 0, 0, 0, time.UTC)
+		limiter := NewRateLimiter(2, time.Minute, nil)
+		limiter.now = func() time.Time { return now }
+
+		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		serve := func(identity string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/penalty-payment-api/penalty-lookup", nil)
+			if identity != "" {
+				req.Header.Set("ERIC-Identity", identity)
+				req.Header.Set("ERIC-Identity-Type", "oauth2")
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			return rr
+		}
+
+		Convey("Then a client is limited once they reach the limit", func() {
+			So(serve("user1").Code, ShouldEqual, http.StatusOK)
+			now = now.Add(15 * time.Second)
+			So(serve("user1").Code, ShouldEqual, http.StatusOK)
+
+			rr := serve("user1")
+			So(rr.Code, ShouldEqual, http.StatusTooManyRequests)
+			So(rr.Header().Get("Retry-After"), ShouldEqual, "45")
+
+			Convey("And other clients are not limited", func() {
+				So(serve("user2").Code, ShouldEqual, http.StatusOK)
+				So(serve("").Code, ShouldEqual, http.StatusOK)
+			})
+
+			Convey("And the client can make requests again in the next window", func() {
+				now = now.Add(45 * time.Second)
+				So(serve("user1").Code, ShouldEqual, http.StatusOK)
+			})
+		})
+
+		Convey("Then the windows that have ended are removed once a window", func() {
+			So(serve("user1").Code, ShouldEqual, http.StatusOK)
+			now = now.Add(30 * time.Second)
+			So(serve("user2").Code, ShouldEqual, http.StatusOK)
+			So(limiter.clients, ShouldHaveLength, 2)
+
+			now = now.Add(30 * time.Second)
+			So(serve("user2").Code, ShouldEqual, http.StatusOK)
+			So(limiter.clients, ShouldHaveLength, 1)
+		})
+	})
+
+	Convey("Given a rate limiter allowing one request a minute", t, func() {
+		limiter := NewRateLimiter(1, time.Minute, nil)
+		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		serve := func(identity, identityType string) int {
+			req := httptest.NewRequest(http.MethodPost, "/penalty-payment-api/penalty-lookup", nil)
+			req.Header.Set("ERIC-Identity", identity)
+			req.Header.Set("ERIC-Identity-Type", identityType)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			return rr.Code
+		}
+
+		Convey("Then an identity that was not authenticated by the gateway is limited by address", func() {
+			So(serve("random1", ""), ShouldEqual, http.StatusOK)
+			So(serve("random2", ""), ShouldEqual, http.StatusTooManyRequests)
+		})
+
+		Convey("Then authenticated identities are limited separately", func() {
+			So(serve("user1", "oauth2"), ShouldEqual, http.StatusOK)
+			So(serve("key1", "key"), ShouldEqual, http.StatusOK)
+			So(serve("user1", "oauth2"), ShouldEqual, http.StatusTooManyRequests)
+		})
+	})
+}
+
+func TestUnitRateLimiterClient(t *testing.T) {
+	Convey("Given a rate limiter allowing one request a minute behind a trusted proxy", t, func() {
+		trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+		So(err, ShouldBeNil)
+		limiter := NewRateLimiter(1, time.Minute, trustedProxies)
+
+		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		serve := func(remoteAddr, forwardedFor string) int {
+			req := httptest.NewRequest(http.MethodPost, "/penalty-payment-api/penalty-lookup", nil)
+			req.RemoteAddr = remoteAddr
+			if forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			return rr.Code
+		}
+
+		Convey("Then two clients behind the same proxy address are limited separately", func() {
+			So(serve("10.0.0.1:1234", "203.0.113.1"), ShouldEqual, http.StatusOK)
+			So(serve("10.0.0.1:1234", "203.0.113.2"), ShouldEqual, http.StatusOK)
+			So(serve("10.0.0.1:1234", "203.0.113.1"), ShouldEqual, http.StatusTooManyRequests)
+		})
+
+		Convey("Then addresses added by trusted proxies are skipped", func() {
+			So(serve("10.0.0.1:1234", "203.0.113.1, 192.168.1.1"), ShouldEqual, http.StatusOK)
+			So(serve("10.0.0.2:1234", "203.0.113.1"), ShouldEqual, http.StatusTooManyRequests)
+		})
+
+		Convey("Then an address forged by the client before the proxy is ignored", func() {
+			So(serve("10.0.0.1:1234", "198.51.100.1, 203.0.113.1"), ShouldEqual, http.StatusOK)
+			So(serve("10.0.0.1:1234", "198.51.100.2, 203.0.113.1"), ShouldEqual, http.StatusTooManyRequests)
+		})
+
+		Convey("Then the X-Forwarded-For header of a client that is not a trusted proxy is ignored", func() {
+			So(serve("203.0.113.1:1234", "198.51.100.1"), ShouldEqual, http.StatusOK)
+			So(serve("203.0.113.1:1234", "198.51.100.2"), ShouldEqual, http.StatusTooManyRequests)
+		})
+	})
+
+	Convey("Parse trusted proxies", t, func() {
+		trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.168.1.1", ""})
+		So(err, ShouldBeNil)
+		So(len(trustedProxies), ShouldEqual, 2)
+		So(trustedProxies[1].Contains(net.ParseIP("192.168.1.1")), ShouldBeTrue)
+		So(trustedProxies[1].Contains(net.ParseIP("192.168.1.2")), ShouldBeFalse)
+
+		_, err = ParseTrustedProxies([]string{"proxy.internal"})
+		So(err, ShouldNotBeNil)
+		_, err = ParseTrustedProxies([]string{"10.0.0.0/40"})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/penalty-payment-api/common/openapi"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/gorilla/mux"
+)
+
+// DefaultMaxRequestBodyBytes is the largest request body accepted if it is not configured
+const DefaultMaxRequestBodyBytes = 1 << 20
+
+// RequestValidator validates requests against the OpenAPI document of the API before they reach the
+// handlers. Requests to routes that are not in the document are not validated.
+type RequestValidator struct {
+	document          *openapi.Document
+	maxBodyBytes      int64
+	validateResponses bool
+}
+
+// NewRequestValidator returns a RequestValidator that rejects request bodies larger than maxBodyBytes. If
+// validateResponses is set responses are also validated and any mismatch is logged, this is meant for
+// non-production environments as the responses are buffered.
+func NewRequestValidator(document *openapi.Document, maxBodyBytes int64, validateResponses bool) *RequestValidator {
+	return &RequestValidator{
+		document:          document,
+		maxBodyBytes:      maxBodyBytes,
+		validateResponses: validateResponses,
+	}
+}
+
+// Middleware responds with 400 Bad Request if the path parameters, query parameters or body of a request
+// do not match the operation in the OpenAPI document, with 413 Request Entity Too Large if the body is too
+// large and with 415 Unsupported Media Type if the body is not a media type the operation accepts
+func (v *RequestValidator) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		pathTemplate, err := route.GetPathTemplate()
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		operation, ok := v.document.Operation(r.Method, pathTemplate)
+		if !ok {
+			log.DebugR(r, "route is not in the OpenAPI document", log.Data{"method": r.Method, "path": pathTemplate})
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, v.maxBodyBytes))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				utils.WriteProblem(w, r, utils.NewProblem(http.StatusRequestEntityTooLarge, utils.ErrorCodeRequestBodyTooLarge,
+					fmt.Sprintf("the request body must not be larger than %d bytes", v.maxBodyBytes)))
+				return
+			}
+			if err != nil {
+				log.ErrorR(r, fmt.Errorf("error reading request body: %v", err))
+				utils.WriteProblem(w, r, utils.NewProblem(http.StatusBadRequest, utils.ErrorCodeInvalidRequestBody,
+					"failed to read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		err = v.document.ValidateParameters(operation, pathTemplate, mux.Vars(r), r.URL.Query(), r.Header)
+		if err == nil {
+			err = v.document.ValidateRequestBody(operation, r.Header.Get("Content-Type"), body)
+		}
+		if err != nil {
+			log.InfoR(r, "request does not match the OpenAPI document", log.Data{"path": pathTemplate, "error": err.Error()})
+			utils.WriteProblem(w, r, validationProblem(err))
+			return
+		}
+
+		if !v.validateResponses {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(recorder, r)
+		err = v.document.ValidateResponse(operation, recorder.status, w.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err != nil {
+			log.ErrorR(r, fmt.Errorf("response does not match the OpenAPI document: %v", err), log.Data{
+				"method": r.Method,
+				"path":   pathTemplate,
+				"status": recorder.status,
+			})
+		}
+	})
+}
+
+func validationProblem(err error) utils.Problem {
+	var unsupportedErr *openapi.UnsupportedMediaTypeError
+	if errors.As(err, &unsupportedErr) {
+		return utils.NewProblem(http.StatusUnsupportedMediaType, utils.ErrorCodeUnsupportedMediaType, unsupportedErr.Error())
+	}
+
+	var validationErr *openapi.ValidationError
+	if !errors.As(err, &validationErr) {
+		return utils.NewProblem(http.StatusBadRequest, utils.ErrorCodeValidationFailed, err.Error())
+	}
+
+	code := utils.ErrorCodeValidationFailed
+	switch {
+	case validationErr.In == openapi.InQuery:
+		code = utils.ErrorCodeInvalidQueryParameter
+	case validationErr.In == openapi.InBody && validationErr.Field == "":
+		code = utils.ErrorCodeInvalidRequestBody
+	}
+	return utils.NewProblem(http.StatusBadRequest, code, validationErr.Error()).WithField(validationErr.Field)
+}
+
+// responseRecorder keeps a copy of the status and body written by a handler so that they can be validated
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.status = status
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
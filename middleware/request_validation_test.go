@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/companieshouse/penalty-payment-api/common/openapi"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const requestValidationDocument = `
+paths:
+  /company/{customer_code}/penalties/payable:
+    post:
+      parameters:
+        - name: customer_code
+          in: path
+          required: true
+          schema:
+            type: string
+            maxLength: 8
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                penalty_ref:
+                  type: string
+      responses:
+        "201":
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  payable_ref:
+                    type: string
+`
+
+func TestUnitRequestValidator(t *testing.T) {
+	document, err := openapi.Load([]byte(requestValidationDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Given a router validating requests", t, func() {
+		var handledBody string
+		router := mux.NewRouter()
+		router.HandleFunc("/company/{customer_code}/penalties/payable", func(w http.ResponseWriter, r *http.Request) {
+			var request map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			handledBody = request["penalty_ref"]
+			utils.WriteJSONWithStatus(w, r, map[string]string{"payable_ref": "PR_123"}, http.StatusCreated)
+		}).Methods(http.MethodPost)
+		router.HandleFunc("/undocumented", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}).Methods(http.MethodPost)
+		router.Use(NewRequestValidator(document, 64, true).Middleware)
+
+		serve := func(path, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+			req.Header.Set("Accept", utils.ProblemContentType)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			return rr
+		}
+
+		Convey("Then a valid request reaches the handler with its body", func() {
+			rr := serve("/company/12345678/penalties/payable", `{"penalty_ref":"A1234567"}`)
+
+			So(rr.Code, ShouldEqual, http.StatusCreated)
+			So(handledBody, ShouldEqual, "A1234567")
+		})
+
+		Convey("Then an unknown field is rejected", func() {
+			rr := serve("/company/12345678/penalties/payable", `{"penalty_ref":"A1234567","amount":150}`)
+
+			var problem utils.Problem
+			_ = json.Unmarshal(rr.Body.Bytes(), &problem)
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(problem.Code, ShouldEqual, utils.ErrorCodeValidationFailed)
+			So(problem.Field, ShouldEqual, "amount")
+			So(problem.Detail, ShouldEqual, "amount is not a known field")
+			So(handledBody, ShouldBeEmpty)
+		})
+
+		Convey("Then an invalid path parameter is rejected", func() {
+			rr := serve("/company/123456789/penalties/payable", `{"penalty_ref":"A1234567"}`)
+
+			var problem utils.Problem
+			_ = json.Unmarshal(rr.Body.Bytes(), &problem)
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(problem.Field, ShouldEqual, "customer_code")
+		})
+
+		Convey("Then a missing body is rejected", func() {
+			rr := serve("/company/12345678/penalties/payable", "")
+
+			var problem utils.Problem
+			_ = json.Unmarshal(rr.Body.Bytes(), &problem)
+			So(rr.Code, ShouldEqual, http.StatusBadRequest)
+			So(problem.Code, ShouldEqual, utils.ErrorCodeInvalidRequestBody)
+		})
+
+		Convey("Then an oversized body is rejected", func() {
+			rr := serve("/company/12345678/penalties/payable", `{"penalty_ref":"`+strings.Repeat("A", 64)+`"}`)
+
+			var problem utils.Problem
+			_ = json.Unmarshal(rr.Body.Bytes(), &problem)
+			So(rr.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+			So(problem.Code, ShouldEqual, utils.ErrorCodeRequestBodyTooLarge)
+			So(handledBody, ShouldBeEmpty)
+		})
+
+		Convey("Then a body that is not json is rejected as an unsupported media type", func() {
+			req := httptest.NewRequest(http.MethodPost, "/company/12345678/penalties/payable", strings.NewReader("penalty_ref=A1234567&amount=150"))
+			req.Header.Set("Accept", utils.ProblemContentType)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			var problem utils.Problem
+			_ = json.Unmarshal(rr.Body.Bytes(), &problem)
+			So(rr.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+			So(problem.Code, ShouldEqual, utils.ErrorCodeUnsupportedMediaType)
+			So(handledBody, ShouldBeEmpty)
+		})
+
+		Convey("Then routes that are not in the document are not validated", func() {
+			So(serve("/undocumented", `{"anything":true}`).Code, ShouldEqual, http.StatusOK)
+		})
+	})
+}
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	models "github.com/companieshouse/penalty-payment-api-core/models"
 	e5 "github.com/companieshouse/penalty-payment-api/common/e5"
@@ -64,6 +65,21 @@ func (mr *MockPayableResourceDaoServiceMockRecorder) GetPayableResource(customer
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayableResource", reflect.TypeOf((*MockPayableResourceDaoService)(nil).GetPayableResource), customerCode, payableRef, requestId)
 }
 
+// GetPaidPayableResources mocks base method.
+func (m *MockPayableResourceDaoService) GetPaidPayableResources(customerCode, requestId string) ([]models.PayableResourceDao, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaidPayableResources", customerCode, requestId)
+	ret0, _ := ret[0].([]models.PayableResourceDao)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaidPayableResources indicates an expected call of GetPaidPayableResources.
+func (mr *MockPayableResourceDaoServiceMockRecorder) GetPaidPayableResources(customerCode, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaidPayableResources", reflect.TypeOf((*MockPayableResourceDaoService)(nil).GetPaidPayableResources), customerCode, requestId)
+}
+
 // SaveE5Error mocks base method.
 func (m *MockPayableResourceDaoService) SaveE5Error(customerCode, payableRef, requestId string, action e5.Action) error {
 	m.ctrl.T.Helper()
@@ -78,6 +94,109 @@ func (mr *MockPayableResourceDaoServiceMockRecorder) SaveE5Error(customerCode, p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveE5Error", reflect.TypeOf((*MockPayableResourceDaoService)(nil).SaveE5Error), customerCode, payableRef, requestId, action)
 }
 
+// SaveE5Processed mocks base method.
+func (m *MockPayableResourceDaoService) SaveE5Processed(customerCode, payableRef string, processedAt time.Time, requestId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveE5Processed", customerCode, payableRef, processedAt, requestId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveE5Processed indicates an expected call of SaveE5Processed.
+func (mr *MockPayableResourceDaoServiceMockRecorder) SaveE5Processed(customerCode, payableRef, processedAt, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveE5Processed", reflect.TypeOf((*MockPayableResourceDaoService)(nil).SaveE5Processed), customerCode, payableRef, processedAt, requestId)
+}
+
+// GetPendingPayableResources mocks base method.
+func (m *MockPayableResourceDaoService) GetPendingPayableResources(customerCode string, penaltyRefs []string, createdAfter time.Time, requestId string) ([]models.PayableResourceDao, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingPayableResources", customerCode, penaltyRefs, createdAfter, requestId)
+	ret0, _ := ret[0].([]models.PayableResourceDao)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingPayableResources indicates an expected call of GetPendingPayableResources.
+func (mr *MockPayableResourceDaoServiceMockRecorder) GetPendingPayableResources(customerCode, penaltyRefs, createdAfter, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingPayableResources", reflect.TypeOf((*MockPayableResourceDaoService)(nil).GetPendingPayableResources), customerCode, penaltyRefs, createdAfter, requestId)
+}
+
+// GetPayableResourceInPayment mocks base method.
+func (m *MockPayableResourceDaoService) GetPayableResourceInPayment(customerCode string, penaltyRefs []string, startedAfter time.Time, requestId string) (*models.PayableResourceDao, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayableResourceInPayment", customerCode, penaltyRefs, startedAfter, requestId)
+	ret0, _ := ret[0].(*models.PayableResourceDao)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayableResourceInPayment indicates an expected call of GetPayableResourceInPayment.
+func (mr *MockPayableResourceDaoServiceMockRecorder) GetPayableResourceInPayment(customerCode, penaltyRefs, startedAfter, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayableResourceInPayment", reflect.TypeOf((*MockPayableResourceDaoService)(nil).GetPayableResourceInPayment), customerCode, penaltyRefs, startedAfter, requestId)
+}
+
+// SetPaymentStarted mocks base method.
+func (m *MockPayableResourceDaoService) SetPaymentStarted(customerCode, payableRef string, startedAt time.Time, requestId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPaymentStarted", customerCode, payableRef, startedAt, requestId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPaymentStarted indicates an expected call of SetPaymentStarted.
+func (mr *MockPayableResourceDaoServiceMockRecorder) SetPaymentStarted(customerCode, payableRef, startedAt, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPaymentStarted", reflect.TypeOf((*MockPayableResourceDaoService)(nil).SetPaymentStarted), customerCode, payableRef, startedAt, requestId)
+}
+
+// CancelPayableResource mocks base method.
+func (m *MockPayableResourceDaoService) CancelPayableResource(customerCode, payableRef, cancelledBy string, paymentStartedBefore time.Time, requestId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPayableResource", customerCode, payableRef, cancelledBy, paymentStartedBefore, requestId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelPayableResource indicates an expected call of CancelPayableResource.
+func (mr *MockPayableResourceDaoServiceMockRecorder) CancelPayableResource(customerCode, payableRef, cancelledBy, paymentStartedBefore, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPayableResource", reflect.TypeOf((*MockPayableResourceDaoService)(nil).CancelPayableResource), customerCode, payableRef, cancelledBy, paymentStartedBefore, requestId)
+}
+
+// ExpirePayableResource mocks base method.
+func (m *MockPayableResourceDaoService) ExpirePayableResource(customerCode, payableRef string, createdBefore, paymentStartedBefore time.Time, requestId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePayableResource", customerCode, payableRef, createdBefore, paymentStartedBefore, requestId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePayableResource indicates an expected call of ExpirePayableResource.
+func (mr *MockPayableResourceDaoServiceMockRecorder) ExpirePayableResource(customerCode, payableRef, createdBefore, paymentStartedBefore, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePayableResource", reflect.TypeOf((*MockPayableResourceDaoService)(nil).ExpirePayableResource), customerCode, payableRef, createdBefore, paymentStartedBefore, requestId)
+}
+
+// ExpirePayableResources mocks base method.
+func (m *MockPayableResourceDaoService) ExpirePayableResources(createdBefore, paymentStartedBefore time.Time, requestId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePayableResources", createdBefore, paymentStartedBefore, requestId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePayableResources indicates an expected call of ExpirePayableResources.
+func (mr *MockPayableResourceDaoServiceMockRecorder) ExpirePayableResources(createdBefore, paymentStartedBefore, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePayableResources", reflect.TypeOf((*MockPayableResourceDaoService)(nil).ExpirePayableResources), createdBefore, paymentStartedBefore, requestId)
+}
+
 // Shutdown mocks base method.
 func (m *MockPayableResourceDaoService) Shutdown() {
 	m.ctrl.T.Helper()
@@ -156,6 +275,35 @@ func (mr *MockAccountPenaltiesDaoServiceMockRecorder) GetAccountPenalties(custom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountPenalties", reflect.TypeOf((*MockAccountPenaltiesDaoService)(nil).GetAccountPenalties), customerCode, companyCode, requestId)
 }
 
+// GetAccountPenaltiesByPenaltyRef mocks base method.
+func (m *MockAccountPenaltiesDaoService) GetAccountPenaltiesByPenaltyRef(companyCode, penaltyRef, requestId string) (*models.AccountPenaltiesDao, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountPenaltiesByPenaltyRef", companyCode, penaltyRef, requestId)
+	ret0, _ := ret[0].(*models.AccountPenaltiesDao)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountPenaltiesByPenaltyRef indicates an expected call of GetAccountPenaltiesByPenaltyRef.
+func (mr *MockAccountPenaltiesDaoServiceMockRecorder) GetAccountPenaltiesByPenaltyRef(companyCode, penaltyRef, requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountPenaltiesByPenaltyRef", reflect.TypeOf((*MockAccountPenaltiesDaoService)(nil).GetAccountPenaltiesByPenaltyRef), companyCode, penaltyRef, requestId)
+}
+
+// CreateIndexes mocks base method.
+func (m *MockAccountPenaltiesDaoService) CreateIndexes(requestId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIndexes", requestId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIndexes indicates an expected call of CreateIndexes.
+func (mr *MockAccountPenaltiesDaoServiceMockRecorder) CreateIndexes(requestId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIndexes", reflect.TypeOf((*MockAccountPenaltiesDaoService)(nil).CreateIndexes), requestId)
+}
+
 // UpdateAccountPenalties mocks base method.
 func (m *MockAccountPenaltiesDaoService) UpdateAccountPenalties(dao *models.AccountPenaltiesDao, requestId string) error {
 	m.ctrl.T.Helper()
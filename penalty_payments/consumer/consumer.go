@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/companieshouse/chs.go/avro"
@@ -17,7 +18,14 @@ import (
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 )
 
-func Consume(cfg *config.Config, penaltyFinancePayment api.FinancePayment, retry *resilience.ServiceRetry) {
+// maintenanceRecheckInterval is the longest the consumer is paused before the maintenance schedule is
+// checked again, so that a maintenance window that is cancelled or changed is picked up
+var maintenanceRecheckInterval = time.Minute
+
+var currentMaintenanceWindow = api.CurrentMaintenanceWindow
+
+func Consume(cfg *config.Config, penaltyFinancePayment api.FinancePayment, retry *resilience.ServiceRetry,
+	maintenanceWindowsCache *api.MaintenanceWindowsCache) {
 	avroSchema := getAvroSchema(cfg)
 	topic := cfg.PenaltyPaymentsProcessingTopic
 	resilienceHandler := resilience.NewHandler(topic, cfg.Namespace(), retry, getProducer(cfg), avroSchema)
@@ -68,6 +76,10 @@ func Consume(cfg *config.Config, penaltyFinancePayment api.FinancePayment, retry
 			return
 		case message := <-messages:
 			if message != nil {
+				if !waitForMaintenance(c, maintenanceWindowsCache) {
+					log.Debug("Application terminating...")
+					return
+				}
 				err := handleMessage(avroSchema, message, penaltyFinancePayment, cfg, resilienceHandler, isRetry)
 				if err != nil {
 					log.Error(err)
@@ -80,6 +92,27 @@ func Consume(cfg *config.Config, penaltyFinancePayment api.FinancePayment, retry
 
 }
 
+// waitForMaintenance pauses the consumer while the finance system is in a maintenance window, so that
+// payments are not sent to E5 and the retries are not used up while it is unavailable. It returns false if
+// the application is terminated while the consumer is paused.
+func waitForMaintenance(stop <-chan os.Signal, maintenanceWindowsCache *api.MaintenanceWindowsCache) bool {
+	for {
+		maintenanceWindow := currentMaintenanceWindow("", time.Now(), maintenanceWindowsCache)
+		if maintenanceWindow == nil {
+			return true
+		}
+
+		log.Info("Pausing consumer during planned maintenance", log.Data{
+			"maintenance_window": maintenanceWindow,
+		})
+		select {
+		case <-stop:
+			return false
+		case <-time.After(min(time.Until(maintenanceWindow.End), maintenanceRecheckInterval)):
+		}
+	}
+}
+
 func handleMessage(avroSchema *avro.Schema, message *sarama.ConsumerMessage, financePayment api.FinancePayment,
 	cfg *config.Config, resilience *resilience.Resilience, isRetry bool) error {
 	log.Debug("Received message", log.Data{
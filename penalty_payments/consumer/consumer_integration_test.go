@@ -95,7 +95,7 @@ func TestIntegrationConsume(t *testing.T) {
 	// Start consumer
 	done := make(chan struct{})
 	go func() {
-		Consume(cfg, mockFinancePayment, nil)
+		Consume(cfg, mockFinancePayment, nil, nil)
 		close(done)
 	}()
 
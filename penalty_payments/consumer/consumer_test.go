@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"errors"
+	"os"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/companieshouse/chs.go/kafka/resilience"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/config"
+	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/mock"
 )
@@ -101,6 +103,63 @@ func TestUnitHandleMessage_ProcessFinancialPenaltyPaymentFails(t *testing.T) {
 	})
 }
 
+func TestUnitWaitForMaintenance(t *testing.T) {
+	defer func() {
+		currentMaintenanceWindow = api.CurrentMaintenanceWindow
+		maintenanceRecheckInterval = time.Minute
+	}()
+
+	Convey("The consumer is not paused outside a maintenance window", t, func() {
+		currentMaintenanceWindow = func(_ string, _ time.Time, _ *api.MaintenanceWindowsCache) *config.MaintenanceWindow {
+			return nil
+		}
+
+		So(waitForMaintenance(make(chan os.Signal), nil), ShouldBeTrue)
+	})
+
+	Convey("The consumer is paused until the maintenance window ends", t, func() {
+		maintenanceWindowEnd := time.Now().Add(50 * time.Millisecond)
+		checks := 0
+		currentMaintenanceWindow = func(_ string, now time.Time, _ *api.MaintenanceWindowsCache) *config.MaintenanceWindow {
+			checks++
+			if now.Before(maintenanceWindowEnd) {
+				return &config.MaintenanceWindow{Name: "Weekly maintenance", End: maintenanceWindowEnd}
+			}
+			return nil
+		}
+
+		So(waitForMaintenance(make(chan os.Signal), nil), ShouldBeTrue)
+		So(time.Now().Before(maintenanceWindowEnd), ShouldBeFalse)
+		So(checks, ShouldEqual, 2)
+	})
+
+	Convey("The maintenance schedule is checked again while the consumer is paused", t, func() {
+		maintenanceRecheckInterval = 10 * time.Millisecond
+		checks := 0
+		currentMaintenanceWindow = func(_ string, now time.Time, _ *api.MaintenanceWindowsCache) *config.MaintenanceWindow {
+			checks++
+			if checks < 3 {
+				return &config.MaintenanceWindow{Name: "E5 upgrade", End: now.Add(time.Hour)}
+			}
+			return nil
+		}
+
+		So(waitForMaintenance(make(chan os.Signal), nil), ShouldBeTrue)
+		So(checks, ShouldEqual, 3)
+	})
+
+	Convey("The pause ends when the application is terminated", t, func() {
+		maintenanceRecheckInterval = time.Minute
+		currentMaintenanceWindow = func(_ string, now time.Time, _ *api.MaintenanceWindowsCache) *config.MaintenanceWindow {
+			return &config.MaintenanceWindow{Name: "E5 upgrade", End: now.Add(time.Hour)}
+		}
+		stop := make(chan os.Signal, 1)
+		stop <- os.Interrupt
+
+		So(waitForMaintenance(stop, nil), ShouldBeFalse)
+	})
+}
+
 func getTestAvroSchema() *avro.Schema {
 	kafkaSchema := `{
     "namespace": "uk.gov.companieshouse.financialpenalties",
@@ -35,7 +35,7 @@ func (payableAuthInterceptor *PayableAuthenticationInterceptor) PayableAuthentic
 			userDetails, ok := r.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
 			if !ok {
 				log.ErrorR(r, fmt.Errorf("PayableAuthenticationInterceptor error: invalid AuthUserDetails from UserAuthenticationInterceptor"))
-				w.WriteHeader(http.StatusInternalServerError)
+				utils.WriteProblem(w, r, utils.NewProblem(http.StatusInternalServerError, utils.ErrorCodeUserDetailsMissing, "there was a problem handling your request"))
 				return
 			}
 
@@ -43,7 +43,7 @@ func (payableAuthInterceptor *PayableAuthenticationInterceptor) PayableAuthentic
 			authorisedUser = userDetails.ID
 			if authorisedUser == "" {
 				log.ErrorR(r, fmt.Errorf("PayableAuthenticationInterceptor unauthorised: no authorised identity"))
-				w.WriteHeader(http.StatusUnauthorized)
+				utils.WriteProblem(w, r, utils.NewProblem(http.StatusUnauthorized, utils.ErrorCodeUnauthorised, "the request has no authorised user"))
 				return
 			}
 		}
@@ -90,13 +90,13 @@ func preCheckRequest(w http.ResponseWriter, r *http.Request) (string, string, st
 	customerCode := strings.ToUpper(vars["customer_code"])
 	if customerCode == "" {
 		log.InfoR(r, "PayableAuthenticationInterceptor error: no customer_code")
-		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteProblem(w, r, utils.NewProblem(http.StatusBadRequest, utils.ErrorCodeCustomerCodeMissing, "customer code not supplied"))
 		return "", "", "", true
 	}
 	payableRef := vars["payable_ref"]
 	if payableRef == "" {
 		log.InfoR(r, "PayableAuthenticationInterceptor error: no payable_ref")
-		w.WriteHeader(http.StatusBadRequest)
+		utils.WriteProblem(w, r, utils.NewProblem(http.StatusBadRequest, utils.ErrorCodePayableRefMissing, "payable ref not supplied"))
 		return "", "", "", true
 	}
 
@@ -104,7 +104,7 @@ func preCheckRequest(w http.ResponseWriter, r *http.Request) (string, string, st
 	identityType := getAuthorisedIdentityType(r)
 	if isUnauthorizedIdentityType(identityType) {
 		log.InfoR(r, "PayableAuthenticationInterceptor unauthorised: not oauth2 or API key identity type")
-		w.WriteHeader(http.StatusUnauthorized)
+		utils.WriteProblem(w, r, utils.NewProblem(http.StatusUnauthorized, utils.ErrorCodeUnauthorised, "the request is not from an oauth2 user or API key"))
 		return "", "", "", true
 	}
 	return customerCode, payableRef, identityType, false
@@ -134,7 +134,7 @@ func checkAllowedThrough(ctx context.Context, w http.ResponseWriter, r *http.Req
 	default:
 		// If none of the above conditions above are met then the request is
 		// unauthorized
-		w.WriteHeader(http.StatusUnauthorized)
+		utils.WriteProblem(w, r, utils.NewProblem(http.StatusUnauthorized, utils.ErrorCodeUnauthorised, "you are not authorised to access this payable resource"))
 		log.InfoR(r, "PayableAuthenticationInterceptor unauthorised", debugMap)
 	}
 }
@@ -150,23 +150,23 @@ func writeHeader(w http.ResponseWriter,
 		log.ErrorR(r, fmt.Errorf("PayableAuthenticationInterceptor error when retrieving payable_resource: [%v]", err), log.Data{"service_response_type": responseType.String()})
 		switch responseType {
 		case services.Forbidden:
-			w.WriteHeader(http.StatusForbidden)
+			utils.WriteProblem(w, r, utils.NewProblem(http.StatusForbidden, utils.ErrorCodeForbidden, "you are not permitted to access this payable resource"))
 			return nil, true
 		default:
-			w.WriteHeader(http.StatusInternalServerError)
+			utils.WriteProblem(w, r, utils.NewProblem(http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request"))
 			return nil, true
 		}
 	}
 
 	if responseType == services.NotFound {
 		log.InfoR(r, "PayableAuthenticationInterceptor not found", log.Data{"payable_ref": payableRef, "customer_code": customerCode})
-		w.WriteHeader(http.StatusNotFound)
+		utils.WriteProblem(w, r, utils.NewProblem(http.StatusNotFound, utils.ErrorCodePayableResourceNotFound, "payable resource not found"))
 		return nil, true
 	}
 
 	if responseType != services.Success {
 		log.ErrorR(r, fmt.Errorf("PayableAuthenticationInterceptor error when retrieving payable_resource. Status: [%s]", responseType.String()))
-		w.WriteHeader(http.StatusInternalServerError)
+		utils.WriteProblem(w, r, utils.NewProblem(http.StatusInternalServerError, utils.ErrorCodeInternalError, "there was a problem handling your request"))
 		return nil, true
 	}
 	return payableResource, false
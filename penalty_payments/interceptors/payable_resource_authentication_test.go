@@ -12,6 +12,7 @@ import (
 	"github.com/companieshouse/penalty-payment-api-core/constants"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api/common/services"
+	"github.com/companieshouse/penalty-payment-api/common/utils"
 	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/mocks"
 	"github.com/golang/mock/gomock"
@@ -62,6 +63,7 @@ func TestUnitUserPaymentInterceptor(t *testing.T) {
 		req.Header.Set("Eric-Identity-Type", authentication.Oauth2IdentityType)
 		req.Header.Set("ERIC-Authorised-User", "test@test.com;test;user")
 		req.Header.Set("ERIC-Authorised-Roles", "noroles")
+		req.Header.Set("Accept", utils.ProblemContentType)
 
 		payableAuthenticationInterceptor := createPayableAuthenticationInterceptorWithMockDAOAndService(mockCtrl, cfg)
 
@@ -69,6 +71,8 @@ func TestUnitUserPaymentInterceptor(t *testing.T) {
 		test := payableAuthenticationInterceptor.PayableAuthenticationIntercept(GetTestHandler())
 		test.ServeHTTP(w, req)
 		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(w.Header().Get("Content-Type"), ShouldEqual, utils.ProblemContentType)
+		So(w.Body.String(), ShouldContainSubstring, utils.ErrorCodeCustomerCodeMissing)
 	})
 
 	Convey("Invalid user details in context", t, func() {
@@ -185,6 +189,7 @@ func TestUnitUserPaymentInterceptor(t *testing.T) {
 		payableAuthenticationInterceptor := createPayableAuthenticationInterceptorWithMockService(&mockPayableResourceSvc)
 
 		mockPrDaoSvc.EXPECT().GetPayableResource("12345678", "1234", "").Return(nil, nil)
+		req.Header.Set("Accept", utils.ProblemContentType)
 
 		w := httptest.NewRecorder()
 		httpmock.Activate()
@@ -193,6 +198,7 @@ func TestUnitUserPaymentInterceptor(t *testing.T) {
 		test := payableAuthenticationInterceptor.PayableAuthenticationIntercept(GetTestHandler())
 		test.ServeHTTP(w, req.WithContext(ctx))
 		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(w.Body.String(), ShouldContainSubstring, utils.ErrorCodePayableResourceNotFound)
 	})
 
 	Convey("Error reading from DB", t, func() {
@@ -214,6 +220,7 @@ func TestUnitUserPaymentInterceptor(t *testing.T) {
 		payableAuthenticationInterceptor := createPayableAuthenticationInterceptorWithMockService(&mockPayableResourceSvc)
 
 		mockPrDaoSvc.EXPECT().GetPayableResource("12345678", "1234", "").Return(&models.PayableResourceDao{}, fmt.Errorf("error"))
+		req.Header.Set("Accept", utils.ProblemContentType)
 
 		w := httptest.NewRecorder()
 		httpmock.Activate()
@@ -222,6 +229,7 @@ func TestUnitUserPaymentInterceptor(t *testing.T) {
 		test := payableAuthenticationInterceptor.PayableAuthenticationIntercept(GetTestHandler())
 		test.ServeHTTP(w, req.WithContext(ctx))
 		So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		So(w.Body.String(), ShouldContainSubstring, utils.ErrorCodeInternalError)
 	})
 
 	Convey("Happy path where user is creator", t, func() {
@@ -387,6 +395,7 @@ func TestUnitUserPaymentInterceptor(t *testing.T) {
 		test := payableAuthenticationInterceptor.PayableAuthenticationIntercept(GetTestHandler())
 		test.ServeHTTP(w, req.WithContext(ctx))
 		So(w.Code, ShouldEqual, http.StatusUnauthorized)
+		So(w.Body.String(), ShouldContainSubstring, "you are not authorised to access this payable resource")
 	})
 
 	Convey("Happy path where user has elevated privileges key accessing a non-creator resource", t, func() {
@@ -0,0 +1,117 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The page is A4 in points, the unit of PDF coordinates, which start at the bottom left of the page
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 56.0
+)
+
+// pdfPage is a single page PDF of text and horizontal rules, written with the standard Helvetica fonts so that
+// no fonts need to be embedded
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func newPDFPage() *pdfPage {
+	return &pdfPage{}
+}
+
+// text writes the text with its baseline starting at x, y
+func (p *pdfPage) text(x, y, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// textRight writes the text with its baseline ending at x, y
+func (p *pdfPage) textRight(x, y, size float64, bold bool, text string) {
+	p.text(x-textWidth(text, size), y, size, bold, text)
+}
+
+// rule draws a grey line across the page between the margins
+func (p *pdfPage) rule(y float64) {
+	fmt.Fprintf(&p.content, "0.7 G 0.5 w %.2f %.2f m %.2f %.2f l S 0 G\n", pdfMargin, y, pdfPageWidth-pdfMargin, y)
+}
+
+// write writes the PDF document, recording the offset of each object for the cross-reference table
+func (p *pdfPage) write(w io.Writer) error {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pdfPageWidth, pdfPageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(document.Bytes())
+	return err
+}
+
+// pdfFallbacks are the letters of the supported languages that are not in Latin-1, written without their accent
+var pdfFallbacks = map[rune]rune{'ŵ': 'w', 'ŷ': 'y', 'Ŵ': 'W', 'Ŷ': 'Y'}
+
+// pdfString escapes the text for a PDF string in the WinAnsi encoding of the fonts. Characters that are not in
+// Latin-1, which WinAnsi shares, are replaced with a question mark, other than the Welsh letters that are written
+// without their circumflex.
+func pdfString(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		if fallback, ok := pdfFallbacks[r]; ok {
+			r = fallback
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r >= ' ' && r <= '~':
+			escaped.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+	return escaped.String()
+}
+
+// textWidth is the width of the text in Helvetica, using the widths of the characters of amounts and the
+// average width for any other character
+func textWidth(text string, size float64) float64 {
+	var width float64
+	for _, r := range text {
+		switch r {
+		case '.', ',', ' ':
+			width += 278
+		default:
+			width += 556
+		}
+	}
+	return width * size / 1000
+}
@@ -0,0 +1,185 @@
+// Package receipt renders the receipt of a paid payable resource as HTML or PDF
+package receipt
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	_ "time/tzdata" // receipts are dated in UK time wherever the service runs
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+)
+
+// receiptLocation is the location the date of a payment is shown in
+var receiptLocation, _ = time.LoadLocation("Europe/London")
+
+//go:embed receipt.html
+var templates embed.FS
+
+var htmlTemplate = template.Must(template.New("receipt.html").Funcs(template.FuncMap{
+	"amount": formatAmount,
+}).ParseFS(templates, "receipt.html"))
+
+// Labels are the text of a receipt in one language
+type Labels struct {
+	Title            string
+	Issuer           string
+	CompanyName      string
+	CompanyNumber    string
+	PaymentReference string
+	DatePaid         string
+	ReceiptNumber    string
+	PenaltyReference string
+	Reason           string
+	Amount           string
+	TotalPaid        string
+	Months           [12]string
+}
+
+// defaultLanguage is the language of a receipt requested in a language it is not translated into
+const defaultLanguage = "en"
+
+var labels = map[string]Labels{
+	"en": {
+		Title:            "Penalty payment receipt",
+		Issuer:           "Companies House",
+		CompanyName:      "Company name",
+		CompanyNumber:    "Company number",
+		PaymentReference: "Payment reference",
+		DatePaid:         "Date paid",
+		ReceiptNumber:    "Receipt number",
+		PenaltyReference: "Penalty reference",
+		Reason:           "Reason",
+		Amount:           "Amount",
+		TotalPaid:        "Total paid",
+		Months: [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September",
+			"October", "November", "December"},
+	},
+	"cy": {
+		Title:            "Derbynneb talu cosb",
+		Issuer:           "Tŷ'r Cwmnïau",
+		CompanyName:      "Enw'r cwmni",
+		CompanyNumber:    "Rhif y cwmni",
+		PaymentReference: "Cyfeirnod y taliad",
+		DatePaid:         "Dyddiad talu",
+		ReceiptNumber:    "Rhif y dderbynneb",
+		PenaltyReference: "Cyfeirnod y gosb",
+		Reason:           "Rheswm",
+		Amount:           "Swm",
+		TotalPaid:        "Cyfanswm a dalwyd",
+		Months: [12]string{"Ionawr", "Chwefror", "Mawrth", "Ebrill", "Mai", "Mehefin", "Gorffennaf", "Awst", "Medi",
+			"Hydref", "Tachwedd", "Rhagfyr"},
+	},
+}
+
+// Receipt is the record of a payment for the penalties of a payable resource, in the language it is rendered in
+type Receipt struct {
+	Language         string
+	Labels           Labels
+	CompanyName      string
+	CustomerCode     string
+	PayableRef       string
+	PaymentReference string
+	PaidAt           time.Time
+	Items            []Item
+	Total            float64
+}
+
+// Item is a penalty that was paid
+type Item struct {
+	PenaltyRef string
+	Reason     string
+	Amount     float64
+}
+
+// New returns the receipt of the paid payable resource, with its penalties in order of penalty reference. The
+// receipt is in the language if it is translated into it, otherwise it is in English, and it is dated in UK time.
+func New(payableResource *models.PayableResource, companyName, language string) (*Receipt, error) {
+	if payableResource.Payment.PaidAt == nil {
+		return nil, fmt.Errorf("payable resource %s has not been paid", payableResource.PayableRef)
+	}
+
+	if _, ok := labels[language]; !ok {
+		language = defaultLanguage
+	}
+	receipt := &Receipt{
+		Language:         language,
+		Labels:           labels[language],
+		CompanyName:      companyName,
+		CustomerCode:     payableResource.CustomerCode,
+		PayableRef:       payableResource.PayableRef,
+		PaymentReference: payableResource.Payment.Reference,
+		PaidAt:           payableResource.Payment.PaidAt.In(receiptLocation),
+	}
+	for _, transaction := range payableResource.Transactions {
+		receipt.Items = append(receipt.Items, Item{
+			PenaltyRef: transaction.PenaltyRef,
+			Reason:     transaction.Reason,
+			Amount:     transaction.Amount,
+		})
+		receipt.Total += transaction.Amount
+	}
+	slices.SortFunc(receipt.Items, func(a, b Item) int {
+		return strings.Compare(a.PenaltyRef, b.PenaltyRef)
+	})
+	return receipt, nil
+}
+
+// DatePaid is the date the payment was made, with the month in the language of the receipt
+func (r *Receipt) DatePaid() string {
+	return fmt.Sprintf("%d %s %d", r.PaidAt.Day(), r.Labels.Months[r.PaidAt.Month()-1], r.PaidAt.Year())
+}
+
+// WriteHTML writes the receipt as an HTML page
+func (r *Receipt) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
+
+// WritePDF writes the receipt as a single page A4 PDF
+func (r *Receipt) WritePDF(w io.Writer) error {
+	page := newPDFPage()
+	page.text(pdfMargin, 780, 20, true, r.Labels.Title)
+	page.text(pdfMargin, 756, 10, false, r.Labels.Issuer)
+	page.rule(740)
+
+	y := 715.0
+	for _, field := range [][2]string{
+		{r.Labels.CompanyName, r.CompanyName},
+		{r.Labels.CompanyNumber, r.CustomerCode},
+		{r.Labels.PaymentReference, r.PaymentReference},
+		{r.Labels.DatePaid, r.DatePaid()},
+		{r.Labels.ReceiptNumber, r.PayableRef},
+	} {
+		page.text(pdfMargin, y, 11, true, field[0])
+		page.text(200, y, 11, false, field[1])
+		y -= 18
+	}
+
+	y -= 20
+	page.text(pdfMargin, y, 11, true, r.Labels.PenaltyReference)
+	page.text(200, y, 11, true, r.Labels.Reason)
+	page.textRight(pdfPageWidth-pdfMargin, y, 11, true, r.Labels.Amount)
+	y -= 8
+	page.rule(y)
+	for _, item := range r.Items {
+		y -= 18
+		page.text(pdfMargin, y, 11, false, item.PenaltyRef)
+		page.text(200, y, 11, false, item.Reason)
+		page.textRight(pdfPageWidth-pdfMargin, y, 11, false, formatAmount(item.Amount))
+	}
+	y -= 10
+	page.rule(y)
+	y -= 18
+	page.text(200, y, 11, true, r.Labels.TotalPaid)
+	page.textRight(pdfPageWidth-pdfMargin, y, 11, true, formatAmount(r.Total))
+
+	return page.write(w)
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("£%.2f", amount)
+}
@@ -0,0 +1,148 @@
+package receipt
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/penalty-payment-api-core/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func paidPayableResource() *models.PayableResource {
+	paidAt := time.Date(2025, time.March, 16, 19, 10, 0, 0, time.UTC)
+	return &models.PayableResource{
+		CustomerCode: "12345678",
+		PayableRef:   "PR_123",
+		Transactions: []models.TransactionItem{
+			{PenaltyRef: "A7654321", Amount: 250, Reason: "Failure to file a confirmation statement"},
+			{PenaltyRef: "A1234567", Amount: 150.5, Reason: "Late filing of accounts"},
+		},
+		Payment: models.Payment{Amount: "400.50", Status: "paid", Reference: "payment-ref", PaidAt: &paidAt},
+	}
+}
+
+func TestUnitNew(t *testing.T) {
+	Convey("The receipt of a paid payable resource has its penalties in order and the total paid", t, func() {
+		receipt, err := New(paidPayableResource(), "ACME LIMITED", "en")
+
+		So(err, ShouldBeNil)
+		So(receipt.CompanyName, ShouldEqual, "ACME LIMITED", "en")
+		So(receipt.PaymentReference, ShouldEqual, "payment-ref")
+		So(receipt.Items, ShouldResemble, []Item{
+			{PenaltyRef: "A1234567", Reason: "Late filing of accounts", Amount: 150.5},
+			{PenaltyRef: "A7654321", Reason: "Failure to file a confirmation statement", Amount: 250},
+		})
+		So(receipt.Total, ShouldEqual, 400.5)
+		So(receipt.Language, ShouldEqual, "en")
+	})
+
+	Convey("The receipt is dated in UK time", t, func() {
+		payableResource := paidPayableResource()
+		paidAt := time.Date(2025, time.June, 30, 23, 30, 0, 0, time.UTC)
+		payableResource.Payment.PaidAt = &paidAt
+
+		receipt, err := New(payableResource, "ACME LIMITED", "en")
+
+		So(err, ShouldBeNil)
+		So(receipt.DatePaid(), ShouldEqual, "1 July 2025")
+	})
+
+	Convey("The receipt is in Welsh when it is requested in Welsh", t, func() {
+		receipt, err := New(paidPayableResource(), "ACME LIMITED", "cy")
+
+		So(err, ShouldBeNil)
+		So(receipt.Language, ShouldEqual, "cy")
+		So(receipt.Labels.Title, ShouldEqual, "Derbynneb talu cosb")
+		So(receipt.DatePaid(), ShouldEqual, "16 Mawrth 2025")
+	})
+
+	Convey("The receipt is in English when it is requested in a language it is not translated into", t, func() {
+		receipt, err := New(paidPayableResource(), "ACME LIMITED", "fr")
+
+		So(err, ShouldBeNil)
+		So(receipt.Language, ShouldEqual, "en")
+		So(receipt.Labels.Title, ShouldEqual, "Penalty payment receipt")
+	})
+
+	Convey("A payable resource that has not been paid does not have a receipt", t, func() {
+		payableResource := paidPayableResource()
+		payableResource.Payment.PaidAt = nil
+
+		receipt, err := New(payableResource, "ACME LIMITED", "en")
+
+		So(receipt, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestUnitWriteHTML(t *testing.T) {
+	Convey("The HTML receipt has the company, penalties and payment, with the company name escaped", t, func() {
+		receipt, err := New(paidPayableResource(), "SMITH & <SONS> LIMITED", "en")
+		So(err, ShouldBeNil)
+
+		var html bytes.Buffer
+		So(receipt.WriteHTML(&html), ShouldBeNil)
+
+		So(html.String(), ShouldContainSubstring, "SMITH &amp; &lt;SONS&gt; LIMITED")
+		So(html.String(), ShouldContainSubstring, "<dd>12345678</dd>")
+		So(html.String(), ShouldContainSubstring, "<dd>payment-ref</dd>")
+		So(html.String(), ShouldContainSubstring, "<dd>16 March 2025</dd>")
+		So(html.String(), ShouldContainSubstring, "<td>A1234567</td><td>Late filing of accounts</td><td class=\"amount\">£150.50</td>")
+		So(html.String(), ShouldContainSubstring, "£400.50")
+		So(strings.Index(html.String(), "A1234567") < strings.Index(html.String(), "A7654321"), ShouldBeTrue)
+	})
+}
+
+func TestUnitWritePDF(t *testing.T) {
+	Convey("The PDF receipt is a valid single page document with the receipt text", t, func() {
+		receipt, err := New(paidPayableResource(), "SMITH (HOLDINGS) LIMITED", "en")
+		So(err, ShouldBeNil)
+
+		var pdf bytes.Buffer
+		So(receipt.WritePDF(&pdf), ShouldBeNil)
+		document := pdf.String()
+
+		So(document, ShouldStartWith, "%PDF-1.4\n")
+		So(strings.HasSuffix(document, "%%EOF\n"), ShouldBeTrue)
+		So(document, ShouldContainSubstring, "/Count 1")
+		So(document, ShouldContainSubstring, `(SMITH \(HOLDINGS\) LIMITED) Tj`)
+		So(document, ShouldContainSubstring, "(payment-ref) Tj")
+		So(document, ShouldContainSubstring, "(16 March 2025) Tj")
+		So(document, ShouldContainSubstring, `(\243400.50) Tj`)
+
+		Convey("And the cross-reference table has the offset of each object", func() {
+			startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(document)
+			So(startxref, ShouldHaveLength, 2)
+			xref, err := strconv.Atoi(startxref[1])
+			So(err, ShouldBeNil)
+			So(document[xref:], ShouldStartWith, "xref\n0 7\n")
+
+			offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(document[xref:], -1)
+			So(offsets, ShouldHaveLength, 6)
+			for i, offset := range offsets {
+				position, err := strconv.Atoi(offset[1])
+				So(err, ShouldBeNil)
+				So(document[position:], ShouldStartWith, strconv.Itoa(i+1)+" 0 obj\n")
+			}
+		})
+
+		Convey("And the length of the content stream is correct", func() {
+			match := regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*)endstream`).FindStringSubmatch(document)
+			So(match, ShouldHaveLength, 3)
+			So(match[1], ShouldEqual, strconv.Itoa(len(match[2])))
+		})
+	})
+}
+
+func TestUnitPDFString(t *testing.T) {
+	Convey("Text is escaped for a PDF string in the WinAnsi encoding", t, func() {
+		So(pdfString(`a (b) \c`), ShouldEqual, `a \(b\) \\c`)
+		So(pdfString("£5 Café"), ShouldEqual, `\2435 Caf\351`)
+		So(pdfString("€ ✓"), ShouldEqual, "? ?")
+		So(pdfString("Tŷ Ŵyn"), ShouldEqual, "Ty Wyn")
+	})
+}
@@ -24,3 +24,21 @@ func GetCompanyName(companyNumber string, req *http.Request) (string, error) {
 
 	return companyProfile.CompanyName, nil
 }
+
+// GetCompanyPostcode will attempt to get the postcode of the registered office address from the CompanyProfileAPI.
+func GetCompanyPostcode(companyNumber string, req *http.Request) (string, error) {
+
+	api, err := manager.GetSDK(req)
+	if err != nil {
+		log.ErrorR(req, err, log.Data{"company_number": companyNumber})
+		return "", err
+	}
+
+	companyProfile, err := api.Profile.Get(companyNumber).Do()
+	if err != nil {
+		log.ErrorR(req, err, log.Data{"company_number": companyNumber})
+		return "", err
+	}
+
+	return companyProfile.RegisteredOfficeAddress.PostalCode, nil
+}
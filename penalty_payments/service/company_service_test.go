@@ -56,3 +56,36 @@ func TestUnitGetCompanyInformation(t *testing.T) {
 		})
 	})
 }
+
+func TestUnitGetCompanyPostcode(t *testing.T) {
+
+	Convey("GetCompanyPostcodeFromCompanyProfileAPI", t, func() {
+
+		apiURL := "https://api.companieshouse.gov.uk"
+
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		ctx := context.WithValue(context.Background(), httpsession.ContextKeySession, &session.Session{})
+		r := &http.Request{}
+		r = r.WithContext(ctx)
+
+		Convey("invalid request", func() {
+			defer httpmock.Reset()
+			httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/12345678", httpmock.NewStringResponder(http.StatusTeapot, ""))
+
+			resp, err := GetCompanyPostcode("12345678", &http.Request{})
+			So(resp, ShouldBeEmpty)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("it returns the postcode of the registered office address", func() {
+			defer httpmock.Reset()
+			httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/12345678", httpmock.NewStringResponder(http.StatusOK, companyDetailsResponse))
+
+			resp, err := GetCompanyPostcode("12345678", r)
+
+			So(err, ShouldBeNil)
+			So(resp, ShouldEqual, "SW1P 1JP")
+		})
+	})
+}
@@ -106,12 +106,12 @@ func prepareEmailKafkaMessage(emailSendSchema avro.Schema, payableResource model
 		return nil, err
 	}
 
-	companyCode, err := getCompanyCodeFromTransaction(payableResource.Transactions)
+	companyCode, err := getCompanyCodeFromTransaction(penaltyDetailsMap, payableResource.Transactions)
 	if err != nil {
 		return nil, err
 	}
 
-	penaltyRefType, err := getPenaltyRefTypeFromTransaction(payableResource.Transactions)
+	penaltyRefType, err := getPenaltyRefTypeFromTransaction(penaltyDetailsMap, payableResource.Transactions)
 	if err != nil {
 		return nil, err
 	}
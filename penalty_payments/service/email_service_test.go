@@ -106,7 +106,7 @@ func TestUnitPrepareEmailKafkaMessage(t *testing.T) {
 			Definition: emailSendSchema,
 		}
 
-		mockedGetCompanyCodeFromTransaction := func(transactions []models.TransactionItem) (string, error) {
+		mockedGetCompanyCodeFromTransaction := func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 			return "LP", nil
 		}
 
@@ -179,7 +179,7 @@ func TestUnitPrepareEmailKafkaMessage(t *testing.T) {
 				return "Brewery", nil
 			}
 
-			mockedGetCompanyCodeFromTransaction := func(transactions []models.TransactionItem) (string, error) {
+			mockedGetCompanyCodeFromTransaction := func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 				return "", errors.New("error getting company code")
 			}
 			getCompanyCodeFromTransaction = mockedGetCompanyCodeFromTransaction
@@ -202,7 +202,7 @@ func TestUnitPrepareEmailKafkaMessage(t *testing.T) {
 				return "Brewery", nil
 			}
 
-			mockedGetPenaltyRefTypeFromTransaction := func(transactions []models.TransactionItem) (string, error) {
+			mockedGetPenaltyRefTypeFromTransaction := func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 				return "", errors.New("error getting penalty ref type")
 			}
 
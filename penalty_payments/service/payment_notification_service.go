@@ -9,9 +9,11 @@ import (
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/penalty-payment-api-core/models"
 	"github.com/companieshouse/penalty-payment-api-core/validators"
+	"github.com/companieshouse/penalty-payment-api/config"
 )
 
-func PaymentProcessingKafkaMessage(payableResource models.PayableResource, payment *validators.PaymentInformation, requestId string) error {
+func PaymentProcessingKafkaMessage(payableResource models.PayableResource, payment *validators.PaymentInformation,
+	penaltyDetailsMap *config.PenaltyDetailsMap, requestId string) error {
 	cfg, err := getConfig()
 	if err != nil {
 		err = fmt.Errorf("error getting config for penalty payments processing kafka message production: [%v]", err)
@@ -45,7 +47,7 @@ func PaymentProcessingKafkaMessage(payableResource models.PayableResource, payme
 	}
 	log.DebugC(requestId, "penalty payments processing avro schema", logContext, log.Data{"schema": producerSchema})
 
-	message, err := preparePaymentProcessingKafkaMessage(*producerSchema, payableResource, payment, topic, requestId)
+	message, err := preparePaymentProcessingKafkaMessage(*producerSchema, payableResource, payment, penaltyDetailsMap, topic, requestId)
 	if err != nil {
 		err = fmt.Errorf("error preparing penalty payments processing kafka message with schema: [%v]", err)
 		return err
@@ -74,14 +76,15 @@ func PaymentProcessingKafkaMessage(payableResource models.PayableResource, payme
 
 // preparePaymentProcessingKafkaMessage generates the kafka message that is to be sent
 func preparePaymentProcessingKafkaMessage(penaltyPaymentProcessingSchema avro.Schema,
-	payableResource models.PayableResource, payment *validators.PaymentInformation, topic string, requestId string) (*producer.Message, error) {
+	payableResource models.PayableResource, payment *validators.PaymentInformation, penaltyDetailsMap *config.PenaltyDetailsMap,
+	topic string, requestId string) (*producer.Message, error) {
 	// Ensure payableResource contains at least one transaction
 	if payableResource.Transactions == nil || len(payableResource.Transactions) == 0 {
 		err := fmt.Errorf("empty transactions list in payable resource: %v", payableResource.PayableRef)
 		return nil, err
 	}
 
-	companyCode, err := getCompanyCodeFromTransaction(payableResource.Transactions)
+	companyCode, err := getCompanyCodeFromTransaction(penaltyDetailsMap, payableResource.Transactions)
 	if err != nil {
 		return nil, err
 	}
@@ -3,7 +3,6 @@ package service
 import (
 	"github.com/companieshouse/chs.go/avro/schema"
 	"github.com/companieshouse/chs.go/kafka/producer"
-	"github.com/companieshouse/penalty-payment-api/common/utils"
 	"github.com/companieshouse/penalty-payment-api/config"
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 )
@@ -19,8 +18,8 @@ var (
 	}
 
 	getCompanyName                   = GetCompanyName
-	getCompanyCodeFromTransaction    = utils.GetCompanyCodeFromTransaction
-	getPenaltyRefTypeFromTransaction = utils.GetPenaltyRefTypeFromTransaction
+	getCompanyCodeFromTransaction    = (*config.PenaltyDetailsMap).GetCompanyCodeFromTransaction
+	getPenaltyRefTypeFromTransaction = (*config.PenaltyDetailsMap).GetPenaltyRefTypeFromTransaction
 
 	getPayablePenalty = api.PayablePenalty
 )
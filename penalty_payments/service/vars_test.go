@@ -17,14 +17,14 @@ var payableResource = models.PayableResource{
 }
 
 func setGetCompanyCodeFromTransactionMock(companyCode string) {
-	mockedGetCompanyCodeFromTransaction := func(transactions []models.TransactionItem) (string, error) {
+	mockedGetCompanyCodeFromTransaction := func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 		return companyCode, nil
 	}
 	getCompanyCodeFromTransaction = mockedGetCompanyCodeFromTransaction
 }
 
 func setGetPenaltyRefTypeFromTransactionMock(penaltyRefType string) {
-	mockedGetPenaltyRefTypeFromTransaction := func(transactions []models.TransactionItem) (string, error) {
+	mockedGetPenaltyRefTypeFromTransaction := func(_ *config.PenaltyDetailsMap, transactions []models.TransactionItem) (string, error) {
 		return penaltyRefType, nil
 	}
 	getPenaltyRefTypeFromTransaction = mockedGetPenaltyRefTypeFromTransaction
@@ -15,7 +15,8 @@ import (
 var consumerFunc = consumer.Consume
 
 // SuperviseConsumer runs a consumer in a loop, restarting it if it exits unexpectedly
-func SuperviseConsumer(ctx context.Context, name string, cfg *config.Config, penaltyFinancePayment *api.PenaltyFinancePayment, retry *resilience.ServiceRetry) {
+func SuperviseConsumer(ctx context.Context, name string, cfg *config.Config, penaltyFinancePayment *api.PenaltyFinancePayment,
+	retry *resilience.ServiceRetry, maintenanceWindowsCache *api.MaintenanceWindowsCache) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -29,7 +30,7 @@ func SuperviseConsumer(ctx context.Context, name string, cfg *config.Config, pen
 						log.Error(fmt.Errorf("panic recovered in supervise consumer %s: %v", name, r))
 					}
 				}()
-				consumerFunc(cfg, penaltyFinancePayment, retry)
+				consumerFunc(cfg, penaltyFinancePayment, retry, maintenanceWindowsCache)
 			}()
 
 			log.Info(fmt.Sprintf("supervise consumer %s exited; restarting after delay", name))
@@ -10,7 +10,8 @@ import (
 	"github.com/companieshouse/penalty-payment-api/issuer_gateway/api"
 )
 
-var mockConsumerFunc = func(cfg *config.Config, penaltyFinancePayment api.FinancePayment, retry *resilience.ServiceRetry) {
+var mockConsumerFunc = func(cfg *config.Config, penaltyFinancePayment api.FinancePayment, retry *resilience.ServiceRetry,
+	maintenanceWindowsCache *api.MaintenanceWindowsCache) {
 	panic("simulated panic")
 }
 
@@ -32,7 +33,7 @@ func TestUnitSuperviseConsumer_PanicRecoveryAndShutdown(t *testing.T) {
 	done := make(chan struct{})
 
 	go func() {
-		SuperviseConsumer(ctx, "test-consumer", cfg, penaltyFinancePayment, retry)
+		SuperviseConsumer(ctx, "test-consumer", cfg, penaltyFinancePayment, retry, nil)
 		close(done)
 	}()
 
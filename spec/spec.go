@@ -0,0 +1,9 @@
+// Package spec embeds the OpenAPI document of the API, so that requests can be validated against it
+package spec
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI document of the API
+//
+//go:embed penalty-payment-api.yaml
+var OpenAPI []byte